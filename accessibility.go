@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dashboardThemeHighContrast is the dashboard_theme with strong colours, solid borders
+// and a thick focus ring; empty is the standard theme
+const dashboardThemeHighContrast = "high-contrast"
+
+func validateDashboardTheme(theme string) error {
+	if theme != "" && theme != dashboardThemeHighContrast {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown dashboard theme %q: use %s or leave it empty", theme, dashboardThemeHighContrast))
+	}
+	return nil
+}
+
+// attachmentName is how an embedded image is described: its alt text, or else its file
+// name
+func attachmentName(alt, target string) string {
+	if alt = strings.TrimSpace(alt); alt != "" {
+		return alt
+	}
+	return target[strings.LastIndex(target, "/")+1:]
+}
+
+// describeImages gives images embedded without alt text, ![](shot.png), their file name
+// as alt text, so screen readers announce something better than "image"
+func describeImages(markdown string) string {
+	return markdownImagePattern.ReplaceAllStringFunc(markdown, func(image string) string {
+		match := markdownImagePattern.FindStringSubmatch(image)
+		if strings.TrimSpace(match[1]) != "" {
+			return image
+		}
+		return "![" + attachmentName("", match[2]) + "](" + match[2] + ")"
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// activeWindowApp returns the name of the frontmost application
+func activeWindowApp() (string, error) {
+	out, err := exec.Command("osascript", "-e",
+		`tell application "System Events" to get name of first application process whose frontmost is true`).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// rememberForeground returns a func that brings the frontmost application back to the
+// front, for showing the capture window without taking the keyboard from it
+func rememberForeground() func() {
+	name, err := activeWindowApp()
+	if err != nil || name == "" {
+		return func() {}
+	}
+	script := fmt.Sprintf(`tell application "System Events" to set frontmost of first application process whose name is "%s" to true`,
+		strings.ReplaceAll(name, "\"", "\\\""))
+	return func() {
+		exec.Command("osascript", "-e", script).Run()
+	}
+}
+
+// cursorPosition returns where the mouse pointer is, in points from the top left of the
+// main screen
+func cursorPosition() (int, int, error) {
+	out, err := exec.Command("osascript", "-l", "JavaScript", "-e",
+		`ObjC.import("AppKit"); var p = $.NSEvent.mouseLocation; `+
+			`Math.round(p.x) + " " + Math.round($.NSScreen.mainScreen.frame.size.height - p.y)`).Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	var x, y int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d", &x, &y); err != nil {
+		return 0, 0, fmt.Errorf("failed to read cursor position: %v", err)
+	}
+	return x, y, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// activeWindowApp returns the executable name of the process owning the foreground window
+func activeWindowApp() (string, error) {
+	hwnd := windows.GetForegroundWindow()
+	if hwnd == 0 {
+		return "", nil
+	}
+
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(hwnd, &pid); err != nil {
+		return "", err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(process)
+
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(filepath.Base(windows.UTF16ToString(buf[:size]))), nil
+}
+
+var (
+	user32                  = windows.NewLazySystemDLL("user32.dll")
+	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+	procGetCursorPos        = user32.NewProc("GetCursorPos")
+	procGetDpiForSystem     = user32.NewProc("GetDpiForSystem")
+)
+
+// rememberForeground returns a func that gives the keyboard back to the window in the
+// foreground now, for showing the capture window without taking it
+func rememberForeground() func() {
+	hwnd := windows.GetForegroundWindow()
+	return func() {
+		if hwnd != 0 {
+			procSetForegroundWindow.Call(uintptr(hwnd))
+		}
+	}
+}
+
+// cursorPosition returns where the mouse pointer is, scaled from physical pixels to the
+// logical ones windows are positioned in
+func cursorPosition() (int, int, error) {
+	var point struct{ X, Y int32 }
+	if ok, _, err := procGetCursorPos.Call(uintptr(unsafe.Pointer(&point))); ok == 0 {
+		return 0, 0, err
+	}
+	dpi := uintptr(96)
+	if procGetDpiForSystem.Find() == nil {
+		if value, _, _ := procGetDpiForSystem.Call(); value != 0 {
+			dpi = value
+		}
+	}
+	return int(point.X) * 96 / int(dpi), int(point.Y) * 96 / int(dpi), nil
+}
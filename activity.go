@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	activeWindowPollInterval = 2 * time.Second
+	// a foreground app must keep focus this long to count as a switch, so alt-tab flicks are ignored
+	activeWindowMinDwell = 10 * time.Second
+	// entries logged this close to the previous one count as a context switch
+	rapidEntryWindow = 5 * time.Minute
+)
+
+// DailyContextSwitches is the fragmentation metric for one local day
+type DailyContextSwitches struct {
+	Date           string `json:"date"`
+	WindowSwitches int    `json:"window_switches"`
+	Markers        int    `json:"markers"`
+	RapidEntries   int    `json:"rapid_entries"`
+	Total          int    `json:"total"`
+}
+
+func (a *App) createActivityTables() error {
+	createActivitySQL := `
+	CREATE TABLE IF NOT EXISTS activity_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		detail TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_activity_events_created ON activity_events(created_at);`
+
+	if _, err := a.db.Exec(createActivitySQL); err != nil {
+		return fmt.Errorf("failed to create activity_events table: %v", err)
+	}
+	return nil
+}
+
+// trackActiveWindow records foreground application changes while TrackActiveWindow is enabled
+func (a *App) trackActiveWindow() {
+	ticks, stop := a.clock.Ticker(activeWindowPollInterval)
+	defer stop()
+
+	var current, candidate string
+	var candidateSince time.Time
+
+	for range ticks {
+		if !a.settings.TrackActiveWindow || a.db == nil {
+			continue
+		}
+
+		app, err := activeWindowApp()
+		if err != nil || app == "" || strings.EqualFold(app, "snaplog") || strings.EqualFold(app, "snaplog.exe") {
+			continue
+		}
+
+		if app == current {
+			candidate = ""
+			continue
+		}
+		if app != candidate {
+			candidate = app
+			candidateSince = a.clock.Now()
+			continue
+		}
+		if a.clock.Now().Sub(candidateSince) < activeWindowMinDwell {
+			continue
+		}
+
+		if current != "" {
+			query := `INSERT INTO activity_events (kind, detail, created_at) VALUES ('window_switch', ?, ?)`
+			if _, err := a.db.Exec(query, app, sqlTime(candidateSince)); err != nil {
+				a.logf("Warning: failed to record window switch: %v\n", err)
+			}
+		}
+		current = app
+		candidate = ""
+	}
+}
+
+// GetContextSwitches returns the daily context switch metric for the last N days, newest first
+func (a *App) GetContextSwitches(days int) ([]DailyContextSwitches, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if days <= 0 {
+		days = 14
+	}
+
+	today := a.today()
+	since := a.dayStart(today.AddDate(0, 0, -(days - 1)))
+
+	byDay := make(map[string]*DailyContextSwitches)
+	result := make([]DailyContextSwitches, days)
+	for i := 0; i < days; i++ {
+		date := today.AddDate(0, 0, -i).Format("2006-01-02")
+		result[i].Date = date
+		byDay[date] = &result[i]
+	}
+
+	switchRows, err := a.db.Query(`SELECT created_at FROM activity_events WHERE kind = 'window_switch' AND created_at >= ?`, sqlTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query window switches: %v", err)
+	}
+	defer switchRows.Close()
+	for switchRows.Next() {
+		var createdAt time.Time
+		if err := switchRows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan window switch: %v", err)
+		}
+		if day, ok := byDay[a.dayKey(createdAt)]; ok {
+			day.WindowSwitches++
+		}
+	}
+
+	entryRows, err := a.db.Query(`SELECT content, created_at FROM log_entries WHERE created_at >= ? AND deleted_at IS NULL ORDER BY created_at ASC`, sqlTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entries: %v", err)
+	}
+	defer entryRows.Close()
+	// an entry only counts as rapid after an earlier entry of the same logical day
+	var previous time.Time
+	var previousDay string
+	for entryRows.Next() {
+		var content string
+		var createdAt time.Time
+		if err := entryRows.Scan(&content, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %v", err)
+		}
+		key := a.dayKey(createdAt)
+		if key != previousDay {
+			previous, previousDay = time.Time{}, key
+		}
+		day, ok := byDay[key]
+		if ok {
+			if content == markerText {
+				day.Markers++
+			} else if !previous.IsZero() && createdAt.Sub(previous) < rapidEntryWindow {
+				day.RapidEntries++
+			}
+		}
+		previous = createdAt
+	}
+
+	for i := range result {
+		result[i].Total = result[i].WindowSwitches + result[i].Markers + result[i].RapidEntries
+	}
+
+	return result, nil
+}
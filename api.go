@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// maxBatchEntries caps POST /api/entries/batch
+	maxBatchEntries = 500
+	maxBatchPayload = 16 << 20
+)
+
+// defaultPresets returns the entries macro pads can log out of the box
+func defaultPresets() map[string]string {
+	return map[string]string{
+		"interrupted":    "Interrupted #interruption",
+		"context-switch": "Context switch #context-switch",
+		"break":          "Taking a break #break",
+	}
+}
+
+// StatusResponse is the compact payload served to key displays
+type StatusResponse struct {
+	Streak    int  `json:"streak"`
+	Today     int  `json:"today"`
+	DailyGoal int  `json:"daily_goal"`
+	GoalMet   bool `json:"goal_met"`
+	DayOff    bool `json:"day_off"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// presetText returns the named preset with its placeholders resolved
+func (a *App) presetText(name string) (string, bool) {
+	text, ok := a.settings.Presets[name]
+	if !ok && a.settings.Presets == nil {
+		text, ok = defaultPresets()[name]
+	}
+	if !ok {
+		return "", false
+	}
+	return a.expandPlaceholders(text), true
+}
+
+// handlePresetAPI logs a predefined entry: POST /api/preset/{name}
+func (a *App) handlePresetAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/preset/")
+	text, ok := a.presetText(name)
+	if name == "" || !ok {
+		writeError(w, http.StatusNotFound, codeNotFound, fmt.Sprintf("Unknown preset: %s", name))
+		return
+	}
+
+	if _, err := a.logEntry(text, sourceAPI); err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to log preset: %v", err))
+		a.logf("Error logging preset %s: %v\n", name, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Preset logged successfully",
+		"preset":  name,
+	})
+
+	a.logf("Preset %s logged via API\n", name)
+}
+
+// handleStatusAPI reports streak and goal progress: GET /api/status
+func (a *App) handleStatusAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if a.notModified(w, r) {
+		return
+	}
+
+	status, err := a.GetStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to get status: %v", err))
+		a.logf("Error getting status: %v\n", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (a *App) GetStatus() (*StatusResponse, error) {
+	streak, err := a.calculateCurrentStreak()
+	if err != nil {
+		return nil, err
+	}
+
+	today, err := a.countEntriesToday()
+	if err != nil {
+		return nil, err
+	}
+
+	// A day off counts as met so goal displays don't nag during a vacation
+	dayOff := a.isDayOff(a.clock.Now())
+	return &StatusResponse{
+		Streak:    streak,
+		Today:     today,
+		DailyGoal: a.settings.DailyGoal,
+		GoalMet:   a.settings.DailyGoal > 0 && (today >= a.settings.DailyGoal || dayOff),
+		DayOff:    dayOff,
+	}, nil
+}
+
+// BatchEntry is one entry posted to /api/entries/batch. CreatedAt is optional (RFC 3339)
+// so importers can keep original timestamps.
+type BatchEntry struct {
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+	Source    string `json:"source"`
+	Journal   string `json:"journal"`
+}
+
+// BatchResult reports the outcome of one BatchEntry, in request order
+type BatchResult struct {
+	Index int    `json:"index"`
+	OK    bool   `json:"ok"`
+	ID    int64  `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchSources are the sources a batch may record
+var batchSources = map[string]bool{sourceAPI: true, sourceImport: true, sourceAuto: true}
+
+// handleEntryBatchAPI logs up to maxBatchEntries entries in one transaction:
+// POST /api/entries/batch with {"entries": [...]}. DELETE with {"ids": [...]} deletes
+// entries instead.
+func (a *App) handleEntryBatchAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		a.handleEntryBatchDelete(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		Entries []BatchEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBatchPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+	if len(req.Entries) == 0 || len(req.Entries) > maxBatchEntries {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("A batch must contain between 1 and %d entries", maxBatchEntries))
+		return
+	}
+
+	results, err := a.logEntryBatch(r.Context(), req.Entries)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to log batch: %v", err))
+		a.logf("Error logging entry batch: %v\n", err)
+		return
+	}
+
+	created := 0
+	for _, result := range results {
+		if result.OK {
+			created++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"created": created,
+		"results": results,
+	})
+
+	a.logf("Batch of %d entries logged via API (%d created)\n", len(req.Entries), created)
+}
+
+// handleEntryBatchDelete serves DELETE /api/entries/batch with {"ids": [...]}
+func (a *App) handleEntryBatchDelete(w http.ResponseWriter, r *http.Request) {
+	if !a.confirmedRequest(w, r, actionBatchDelete) {
+		return
+	}
+
+	var req struct {
+		IDs []int `json:"ids"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBatchPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxBatchEntries {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("A batch must contain between 1 and %d entries", maxBatchEntries))
+		return
+	}
+
+	deleted, err := a.DeleteEntries(req.IDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to delete batch: %v", err))
+		a.logf("Error deleting entry batch: %v\n", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "deleted": deleted})
+	a.logf("Batch of %d entries deleted via API\n", deleted)
+}
+
+// DeleteEntries deletes each of ids that exists and returns how many were deleted
+func (a *App) DeleteEntries(ids []int) (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		if _, err := a.GetEntryByID(id); err != nil {
+			continue
+		}
+		if err := a.DeleteEntry(id); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// checkBatchEntry returns the text, source and time a BatchEntry is logged with, or
+// what is wrong with it
+func (a *App) checkBatchEntry(entry BatchEntry) (string, string, time.Time, string) {
+	text := a.normalizePunctuation(a.ExpandShortcuts(strings.TrimSpace(entry.Content)))
+	source := entry.Source
+	if source == "" {
+		source = sourceAPI
+	}
+
+	now := a.clock.Now()
+	createdAt := now
+	switch {
+	case text == "":
+		return "", "", createdAt, "content cannot be empty"
+	case len(text) > maxEntryLength:
+		return "", "", createdAt, fmt.Sprintf("entry exceeds maximum length of %d characters", maxEntryLength)
+	case !batchSources[source]:
+		return "", "", createdAt, fmt.Sprintf("unsupported source %q", source)
+	case entry.CreatedAt != "":
+		if at, err := time.Parse(time.RFC3339, entry.CreatedAt); err == nil {
+			createdAt = at
+		} else if at, ok := parseNaturalTime(entry.CreatedAt, createdAt); ok {
+			createdAt = at
+		} else {
+			return "", "", createdAt, `created_at must be an RFC 3339 timestamp or a date like "yesterday 3pm"`
+		}
+		if createdAt.After(now.Add(maxClockSkew)) {
+			return "", "", now, "an entry can't be logged in the future"
+		}
+		if createdAt.After(now) {
+			createdAt = now
+		}
+	}
+	return text, source, createdAt, ""
+}
+
+// logEntryBatch validates each entry, inserts the valid ones in a single transaction and
+// then runs the usual tag, task and hook processing for them
+func (a *App) logEntryBatch(ctx context.Context, entries []BatchEntry) ([]BatchResult, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	activeJournal, err := a.activeJournalID()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(entries))
+	texts := make([]string, len(entries))
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, entry := range entries {
+		results[i].Index = i
+		text, source, createdAt, problem := a.checkBatchEntry(entry)
+		if problem != "" {
+			results[i].Error = problem
+			continue
+		}
+		journalID := activeJournal
+		if entry.Journal != "" {
+			// journals are made with /journal, so a misspelt name isn't a new journal
+			err := tx.QueryRowContext(ctx, `SELECT id FROM journals WHERE name = ?`, strings.ToLower(strings.TrimSpace(entry.Journal))).Scan(&journalID)
+			if err == sql.ErrNoRows {
+				results[i].Error = fmt.Sprintf("unknown journal %q", entry.Journal)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up journal of entry %d: %v", i, err)
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `INSERT INTO log_entries (content, created_at, source, journal_id) VALUES (?, ?, ?, ?)`,
+			text, sqlTime(createdAt), source, journalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert entry %d: %v", i, err)
+		}
+		if results[i].ID, err = result.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("failed to get id of entry %d: %v", i, err)
+		}
+		results[i].OK = true
+		texts[i] = text
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit batch: %v", err)
+	}
+	for i, result := range results {
+		if result.OK {
+			a.processNewEntry(ctx, result.ID, texts[i])
+		}
+	}
+	return results, nil
+}
@@ -5,19 +5,17 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
@@ -31,29 +29,120 @@ var appIcon []byte
 
 // Settings represents the application configuration
 type Settings struct {
-	HotkeyModifiers []string `json:"hotkey_modifiers"`
-	HotkeyKey       string   `json:"hotkey_key"`
-	FirstRun        bool     `json:"first_run"`
-	Theme           string   `json:"theme"`
-	DashboardPort   int      `json:"dashboard_port"`
+	HotkeyModifiers          []string              `json:"hotkey_modifiers"`
+	HotkeyKey                string                `json:"hotkey_key"`
+	FirstRun                 bool                  `json:"first_run"`
+	Theme                    string                `json:"theme"`
+	DashboardPort            int                   `json:"dashboard_port"`
+	DailyGoal                int                   `json:"daily_goal"`
+	Presets                  map[string]string     `json:"presets"`
+	InboundHooks             []InboundHook         `json:"inbound_hooks"`
+	HomeAssistant            HomeAssistantSettings `json:"home_assistant"`
+	IgnoreFocusMode          bool                  `json:"ignore_focus_mode"`
+	HotkeyGestures           HotkeyGestures        `json:"hotkey_gestures"`
+	MarkerHotkeyModifiers    []string              `json:"marker_hotkey_modifiers"`
+	MarkerHotkeyKey          string                `json:"marker_hotkey_key"`
+	ScreenshotHotkeyModifiers []string             `json:"screenshot_hotkey_modifiers"`
+	ScreenshotHotkeyKey      string                `json:"screenshot_hotkey_key"`
+	TrackActiveWindow        bool                  `json:"track_active_window"`
+	Reminders                []ReminderSchedule    `json:"reminders"`
+	AutoCaptureWindowSeconds int                   `json:"auto_capture_window_seconds"`
+	Templates                map[string]string     `json:"templates"`
+	CalendarICS              string                `json:"calendar_ics"`
+	ScriptHooks              []ScriptHook          `json:"script_hooks"`
+	EnabledPlugins           []string              `json:"enabled_plugins"`
+	CustomCommands           []CustomCommand       `json:"custom_commands"`
+	CaptureSelection         bool                  `json:"capture_selection"`
+	AdvancedMode             bool                  `json:"advanced_mode"`
+	BackupPassphrase         string                `json:"backup_passphrase"`
+	BackupTargets            []BackupTarget        `json:"backup_targets"`
+	Sync                     SyncSettings          `json:"sync"`
+	SlowQueryMs              int                   `json:"slow_query_ms"`
+	Storage                  StorageSettings       `json:"storage"`
+	DayStartHour             int                   `json:"day_start_hour"`
+	ExpandHotkeyModifier     string                `json:"expand_hotkey_modifier"`
+	SpellcheckLanguage       string                `json:"spellcheck_language"`
+	Confirmations            ConfirmationSettings  `json:"confirmations"`
+	TagBlacklist             []string              `json:"tag_blacklist"`
+	GuidedFlows              map[string]GuidedFlow `json:"guided_flows"`
+	WorkdayStartHour         int                   `json:"workday_start_hour"`
+	WorkdayEndHour           int                   `json:"workday_end_hour"`
+	TimesheetCurrency        string                `json:"timesheet_currency"`
+	DisableNightlyBackups    bool                  `json:"disable_nightly_backups"`
+	BackupKeep               int                   `json:"backup_keep"`
+	SearchTrigram            bool                  `json:"search_trigram"`
+	QuoteStyle               string                `json:"quote_style"`
+	ExpandDashes             bool                  `json:"expand_dashes"`
+	DashboardTheme           string                `json:"dashboard_theme"`
+	DashboardFontScale       int                   `json:"dashboard_font_scale"`
+	DashboardDensity         string                `json:"dashboard_density"`
+	PreviewLength            int                   `json:"preview_length"`
+	PreviewFirstLine         bool                  `json:"preview_first_line"`
+	CaptureNotifySources     []string              `json:"capture_notify_sources"`
+	CaptureKeepFocus         bool                  `json:"capture_keep_focus"`
+	CapturePosition          string                `json:"capture_position"`
+	CaptureAutoHideSeconds   int                   `json:"capture_auto_hide_seconds"`
+	StayOpenAfterLog         bool                  `json:"stay_open_after_log"`
+	KeepTextAfterLog         bool                  `json:"keep_text_after_log"`
+	DefaultJournal           string                `json:"default_journal"`
 }
 
 // LogEntry represents a log entry in the database
 type LogEntry struct {
-	ID        int       `json:"id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int       `json:"id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	Source      string    `json:"source"`
+	RepeatCount int       `json:"repeat_count"`
+	EntryType   string    `json:"entry_type"`
+	JournalID   int64     `json:"journal_id"`
+}
+
+// Entry sources recorded in log_entries.source
+const (
+	sourceHotkey   = "hotkey"
+	sourceCLI      = "cli"
+	sourceAPI      = "api"
+	sourceTelegram = "telegram"
+	sourceImport   = "import"
+	sourceAuto     = "auto"
+	sourcePlugin   = "plugin"
+)
+
+// maxEntryLength is the longest entry text, in bytes, that can be logged or saved
+const maxEntryLength = 50000
+
+// maxClockSkew is how far ahead of this machine's clock a time from another machine may
+// be and still count as now rather than the future
+const maxClockSkew = time.Minute
+
+// entryColumns is the column list scanned by scanEntry
+const entryColumns = `id, content, created_at, source, repeat_count, entry_type, journal_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (LogEntry, error) {
+	var entry LogEntry
+	err := row.Scan(&entry.ID, &entry.Content, &entry.CreatedAt, &entry.Source, &entry.RepeatCount, &entry.EntryType, &entry.JournalID)
+	return entry, err
 }
 
 // DisplayEntry represents a log entry formatted for display
 type DisplayEntry struct {
-	ID           int             `json:"id"`
-	Content      string          `json:"content"`
-	RenderedHTML template.HTML   `json:"rendered_html"`
-	LocalTime    string          `json:"local_time"`
-	LocalTimeFull string         `json:"local_time_full"`
-	CreatedAt    time.Time       `json:"created_at"`
-	DateString   string          `json:"date_string"`
+	ID            int           `json:"id"`
+	Content       string        `json:"content"`
+	RenderedHTML  template.HTML `json:"rendered_html"`
+	LocalTime     string        `json:"local_time"`
+	LocalTimeFull string        `json:"local_time_full"`
+	CreatedAt     time.Time     `json:"created_at"`
+	DateString    string        `json:"date_string"`
+	Source        string        `json:"source"`
+	RepeatCount   int           `json:"repeat_count"`
+	EntryType     string        `json:"entry_type"`
+	Tags          []string      `json:"tags"`
+	Prose         string        `json:"prose"`
 }
 
 // DisplayDayGroup represents a group of display entries for a specific day
@@ -66,25 +155,59 @@ type DisplayDayGroup struct {
 
 // DisplayDashboardData represents the data structure for the dashboard with display formatting
 type DisplayDashboardData struct {
-	TotalEntries int              `json:"total_entries"`
-	TotalDays    int              `json:"total_days"`
-	ThisWeek     int              `json:"this_week"`
-	Generated    string           `json:"generated"`
-	DayGroups    []DisplayDayGroup `json:"day_groups"`
-	Tags         []Tag             `json:"tags"`
-	LogoData     template.URL     `json:"logo_data"`
-	OriginalJSONRaw template.JS   `json:"original_json_raw"`
+	TotalEntries         int               `json:"total_entries"`
+	TotalDays            int               `json:"total_days"`
+	ThisWeek             int               `json:"this_week"`
+	Streak               StreakStats       `json:"streak"`
+	PageEntries          int               `json:"page_entries"`
+	NextCursor           string            `json:"next_cursor"`
+	OlderPage            bool              `json:"older_page"`
+	Tag                  string            `json:"tag"`
+	Journal              string            `json:"journal"`
+	Journals             []Journal         `json:"journals"`
+	Generated            string            `json:"generated"`
+	DayGroups            []DisplayDayGroup `json:"day_groups"`
+	Tags                 []Tag             `json:"tags"`
+	LogoData             template.URL      `json:"logo_data"`
+	ContextSwitchesToday int               `json:"context_switches_today"`
+	PluginFormats        []string          `json:"plugin_formats"`
+	Theme                string            `json:"theme"`
+	FontScale            int               `json:"font_scale"`
+	Density              string            `json:"density"`
+	OriginalJSONRaw      template.JS       `json:"original_json_raw"`
 }
 
 type App struct {
 	ctx          context.Context
 	hotkeyId     uintptr
-	packageHotkey *hotkey.Hotkey
+	packageHotkey globalHotkey
 	settings     *Settings
 	db           *sql.DB
 	logFile      *os.File
 	httpServer   *http.Server
 	dashboardPort int
+	notifications *notificationQueue
+	extraHotkeys  []globalHotkey
+	reminders     *reminderState
+	coalesce      coalescer
+	plugins       pluginHost
+	console       queryConsole
+	syncMu        sync.Mutex
+	idempotency   idempotencyStore
+	slowOps       slowOperationLog
+	storage       storageMonitor
+	flows         entryFlows
+	guided        guidedFlowState
+	preview       previewCache
+	clock         clock
+	notifier      notifier
+	browser       browserOpener
+	hotkeys       hotkeyRegistrar
+	captureMode   string
+	captureWindow captureWindowState
+	journal       journalState
+	vault         databaseVault
+	drive         driveSignIn
 }
 
 func NewApp() *App {
@@ -95,14 +218,33 @@ func NewApp() *App {
 			FirstRun:        true,
 			Theme:           "dark",
 			DashboardPort:   37564,
+			DailyGoal:       5,
+			Presets:         defaultPresets(),
+			Templates:       defaultTemplates(),
+			HotkeyGestures: HotkeyGestures{
+				Single: gestureCapture,
+				Double: gestureDashboard,
+				Hold:   gestureVoice,
+			},
+			MarkerHotkeyModifiers: []string{"ctrl", "shift"},
+			MarkerHotkeyKey:       "m",
+			ExpandHotkeyModifier:  "alt",
 		},
 		dashboardPort: 37564,
+		notifications: &notificationQueue{},
+		reminders:     &reminderState{},
+		clock:         systemClock{},
+		notifier:      systemNotifier{},
+		browser:       systemBrowser{},
+		hotkeys:       systemHotkeys{},
 	}
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.hotkeyId = uintptr(1)
+	// the window opens visible
+	a.setCaptureWindowVisible(true)
 	
 	if err := a.initLogging(); err != nil {
 		fmt.Printf("Warning: Failed to initialize logging: %v\n", err)
@@ -115,13 +257,16 @@ func (a *App) startup(ctx context.Context) {
 	}
 	a.dashboardPort = a.settings.DashboardPort
 	
-	if err := a.initDatabase(); err != nil {
+	if a.GetDatabaseEncryption().Encrypted {
+		a.logf("Database is encrypted - waiting for the passphrase\n")
+		go a.promptForPassphrase()
+	} else if err := a.initDatabase(); err != nil {
 		a.logf("Failed to initialize database: %v\n", err)
 		return
+	} else {
+		a.startServices()
 	}
 	
-	go a.startDashboardServer()
-	
 	if a.settings.FirstRun {
 		a.logf("First run detected - showing setup window\n")
 		go func() {
@@ -134,9 +279,25 @@ func (a *App) startup(ctx context.Context) {
 	}
 }
 
+// startServices starts everything that works on the database once it is open
+func (a *App) startServices() {
+	go a.startDashboardServer()
+	go a.watchFocusMode()
+	go a.watchCaptureIdle()
+	go a.trackActiveWindow()
+	go a.runReminderScheduler()
+	go a.watchDayEnd()
+	go a.startPlugins()
+	go a.runSyncLoop()
+	go a.checkStorageQuota()
+	go a.runTaskSnapshots()
+	go a.runNightlyBackups()
+}
+
 func (a *App) shutdown(ctx context.Context) {
 	a.logf("Shutting down SnapLog...\n")
 	a.stopHotkeyDetection()
+	a.stopPlugins()
 	
 	if a.httpServer != nil {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -145,6 +306,8 @@ func (a *App) shutdown(ctx context.Context) {
 		a.logf("Dashboard server stopped\n")
 	}
 	
+	a.closeReadOnlyDB()
+	a.closeEncryptedDatabase()
 	if a.db != nil {
 		a.db.Close()
 		a.logf("Database connection closed\n")
@@ -209,7 +372,7 @@ func (a *App) initDatabase() error {
 	
 	dbPath := filepath.Join(snaplogDir, "snaplog.db")
 	
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := a.openDatabase(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %v", err)
 	}
@@ -275,9 +438,176 @@ func (a *App) createTables() error {
 		return fmt.Errorf("failed to create indexes: %v", err)
 	}
 	
+	if _, err := a.addColumnIfMissing("log_entries", "source", "TEXT NOT NULL DEFAULT 'hotkey'"); err != nil {
+		return err
+	}
+	
+	if _, err := a.addColumnIfMissing("log_entries", "repeat_count", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	
+	addedEntryType, err := a.addColumnIfMissing("log_entries", "entry_type", "TEXT NOT NULL DEFAULT 'note'")
+	if err != nil {
+		return err
+	}
+	
+	if _, err := a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_log_entries_source ON log_entries(source)`); err != nil {
+		return fmt.Errorf("failed to create source index: %v", err)
+	}
+	
+	// entries in the trash have deleted_at set; readers skip them
+	if _, err := a.addColumnIfMissing("log_entries", "deleted_at", "DATETIME"); err != nil {
+		return err
+	}
+	
+	if _, err := a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_log_entries_deleted_at ON log_entries(deleted_at)`); err != nil {
+		return fmt.Errorf("failed to create deleted_at index: %v", err)
+	}
+	
+	if err := a.createJournalTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createActivityTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createReminderTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createTaskTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createShortcutTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createPeopleTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createEntryHistoryTables(); err != nil {
+		return err
+	}
+	
+	if addedEntryType {
+		if err := a.backfillEntryMetadata(); err != nil {
+			return err
+		}
+	}
+	
+	if err := a.createSyncTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createIdempotencyTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createCaptureTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createDictionaryTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createTagAliasTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createTagSpanTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createMeetingTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createProjectTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createTaskSnapshotTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createTimerTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createDayPlanTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createDayOffTables(); err != nil {
+		return err
+	}
+	
+	// after the tables its triggers watch
+	if err := a.createDataVersionTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createAttachmentTables(); err != nil {
+		return err
+	}
+	
+	if err := a.createSettingsSecretTables(); err != nil {
+		return err
+	}
+	
+	if err := a.canonicalizeTags(); err != nil {
+		return err
+	}
+	
+	if err := a.createSearchIndex(); err != nil {
+		return err
+	}
+	
+	if err := a.createAnalyticsViews(); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
+// addColumnIfMissing adds a column to an existing table, for databases created by older versions
+// and reports whether the column was added
+func (a *App) addColumnIfMissing(table, column, definition string) (bool, error) {
+	rows, err := a.db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s table: %v", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan %s columns: %v", table, err)
+		}
+		if name == column {
+			return false, nil
+		}
+	}
+	rows.Close()
+
+	if _, err := a.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return false, fmt.Errorf("failed to add %s.%s column: %v", table, column, err)
+	}
+	a.logf("Migrated %s table: added %s column\n", table, column)
+	return true, nil
+}
+
+// sqlTime formats t the way CURRENT_TIMESTAMP stores it, so it compares correctly against created_at
+func sqlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
 func (a *App) GetEntryForEdit(id int) (string, error) {
 	entry, err := a.GetEntryByID(id)
 	if err != nil {
@@ -291,133 +621,142 @@ func (a *App) GetEntryPreview(id int) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	preview := entry.Content
-	if len(preview) > 100 {
-		preview = preview[:100] + "..."
-	}
-	return preview, nil
+	return a.entryPreview(entry.Content), nil
 }
 
 func (a *App) ProcessCommand(command string) error {
-	a.logf("Processing command: %s\n", command)
-	
-	command = strings.TrimSpace(command)
-	
-	if strings.HasPrefix(command, "/edit ") {
-		parts := strings.Fields(command)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid edit command. Usage: /edit <entry-id>")
-		}
-		
-		var entryID int
-		if _, err := fmt.Sscanf(parts[1], "%d", &entryID); err != nil {
-			return fmt.Errorf("invalid entry ID: %s", parts[1])
-		}
-		
-		content, err := a.GetEntryForEdit(entryID)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("EDIT_MODE:%d:%s", entryID, content)
-	}
-	
-	if strings.HasPrefix(command, "/delete ") {
-		parts := strings.Fields(command)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid delete command. Usage: /delete <entry-id>")
-		}
-		
-		var entryID int
-		if _, err := fmt.Sscanf(parts[1], "%d", &entryID); err != nil {
-			return fmt.Errorf("invalid entry ID: %s", parts[1])
-		}
-		
-		preview, err := a.GetEntryPreview(entryID)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("DELETE_CONFIRM:%d:%s", entryID, preview)
-	}
+	// only the name: the arguments are often the text of an entry
+	name, _ := splitCommand(command)
+	a.logf("Processing command: %s\n", name)
 	
-	switch command {
-	case "/dash":
-		return a.generateDashboard()
-	case "/settings":
-		a.OpenSettings()
-		return nil
-	case "/editprev":
-		entry, err := a.GetMostRecentEntry()
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("EDIT_MODE:%d:%s", entry.ID, entry.Content)
-	case "/delprev":
-		entry, err := a.GetMostRecentEntry()
-		if err != nil {
-			return err
-		}
-		preview := entry.Content
-		if len(preview) > 100 {
-			preview = preview[:100] + "..."
-		}
-		return fmt.Errorf("DELETE_CONFIRM:%d:%s", entry.ID, preview)
-	default:
-		return fmt.Errorf("unknown command: %s. Available commands: /dash, /settings, /edit <id>, /delete <id>, /editprev, /delprev", command)
+	cmd, args := a.lookupCommand(command)
+	if cmd == nil {
+		return fmt.Errorf("unknown command: %s. Available commands: %s", strings.TrimSpace(command), a.commandUsages())
 	}
+	return cmd.run(a, args)
 }
+
 func (a *App) LogText(text string) error {
+	_, err := a.logEntry(text, sourceHotkey)
+	return err
+}
+
+// logEntry inserts an entry attributed to source and runs the tag pipeline. A trailing
+// "@@yesterday 3pm" backdates it. It returns the new entry ID, or 0 when text is empty.
+func (a *App) logEntry(text, source string) (int64, error) {
+	text, at, err := splitBackdate(text, a.clock.Now())
+	if err != nil {
+		return 0, err
+	}
+	return a.logEntryAt(text, source, at)
+}
+
+// logEntryAt is logEntry for an entry that happened at a given time; a zero time is now
+func (a *App) logEntryAt(text, source string, at time.Time) (int64, error) {
 	if text == "" {
-		return nil
+		return 0, nil
 	}
+	now := a.clock.Now()
+	if at.IsZero() {
+		at = now
+	} else if at.After(now.Add(maxClockSkew)) {
+		return 0, newAPIError(codeInvalidRequest, "an entry can't be logged in the future")
+	} else if at.After(now) {
+		at = now
+	}
+	text = a.normalizePunctuation(a.ExpandShortcuts(text))
 
-	const maxLength = 50000
-	if len(text) > maxLength {
-		return fmt.Errorf("entry exceeds maximum length of %d characters", maxLength)
+	if len(text) > maxEntryLength {
+		return 0, fmt.Errorf("entry exceeds maximum length of %d characters", maxEntryLength)
 	}
 
-	a.logf("LogText called with: '%s'\n", text)
+	// the log file is plain text even when the database is encrypted, so it never gets
+	// the text of an entry
+	a.logf("Logging entry of %d characters (source: %s)\n", len(text), source)
 
 	if a.db == nil {
-		return fmt.Errorf("database not initialized")
+		return 0, errDatabaseUnavailable()
+	}
+	journalID, err := a.activeJournalID()
+	if err != nil {
+		return 0, err
 	}
 
-	query := `INSERT INTO log_entries (content) VALUES (?)`
-	result, err := a.db.Exec(query, text)
+	query := `INSERT INTO log_entries (content, created_at, source, journal_id) VALUES (?, ?, ?, ?)`
+	result, err := a.db.Exec(query, text, sqlTime(at), source, journalID)
 	if err != nil {
-		return fmt.Errorf("failed to insert log entry: %v", err)
+		return 0, fmt.Errorf("failed to insert log entry: %v", err)
 	}
 
 	entryID, err := result.LastInsertId()
 	if err != nil {
 		a.logf("Warning: failed to get last insert ID: %v\n", err)
 	} else {
-		if err := a.processTags(entryID, text); err != nil {
-			a.logf("Warning: failed to process tags: %v\n", err)
-		}
+		a.processNewEntry(context.Background(), entryID, text)
 	}
 
-	a.logf("Logged text: %s\n", text)
-	return nil
+	a.logf("Logged entry %d\n", entryID)
+	return entryID, nil
 }
 
-func (a *App) processTags(entryID int64, text string) error {
-	tagPattern := regexp.MustCompile(`#([a-zA-Z0-9_-]+)`)
-	matches := tagPattern.FindAllStringSubmatch(text, -1)
+// processNewEntry derives tags, tasks and metadata for a freshly inserted entry and
+// notifies hooks about it; ctx carries the request ID of an API request for tracing
+func (a *App) processNewEntry(ctx context.Context, entryID int64, text string) {
+	if err := a.processTags(ctx, entryID, text); err != nil {
+		a.logf("Warning: failed to process tags: %v\n", err)
+	}
+	if err := a.syncTasks(ctx, entryID, text); err != nil {
+		a.logf("Warning: failed to process tasks: %v\n", err)
+	}
+	if err := a.updateEntryMetadata(ctx, entryID, text); err != nil {
+		a.logf("Warning: failed to process entry metadata: %v\n", err)
+	}
+	if err := a.claimAttachments(ctx, entryID, text); err != nil {
+		a.logf("Warning: failed to link attachments: %v\n", err)
+	}
+	a.entryCreated(ctx, entryID)
+}
+
+var tagPattern = regexp.MustCompile(`#([\p{L}\p{M}\p{N}_-]+)`)
+
+// extractTagNames returns the unique tag names referenced in text, in order of appearance.
+// An escaped \#word is not a tag.
+func extractTagNames(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range tagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > 0 && text[match[0]-1] == '\\' {
+			continue
+		}
+		name := text[match[2]:match[3]]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (a *App) processTags(ctx context.Context, entryID int64, text string) error {
+	spans := a.findTagSpans(text)
+	if err := a.storeTagSpans(ctx, entryID, spans); err != nil {
+		a.logf("Warning: failed to store tag positions for entry %d: %v\n", entryID, err)
+	}
+	tagNames := spanTags(spans)
 	
-	if len(matches) == 0 {
+	if len(tagNames) == 0 {
 		return nil
 	}
 
-	for _, match := range matches {
-		tagName := match[1]
-		tagID, err := a.getOrCreateTag(tagName)
+	for _, tagName := range tagNames {
+		tagID, err := a.getOrCreateTag(ctx, tagName)
 		if err != nil {
 			a.logf("Warning: failed to get or create tag '%s': %v\n", tagName, err)
 			continue
 		}
 		
 		insertJunctionSQL := `INSERT OR IGNORE INTO log_entries_tags (log_entry_id, tag_id) VALUES (?, ?)`
-		if _, err := a.db.Exec(insertJunctionSQL, entryID, tagID); err != nil {
+		if _, err := a.db.ExecContext(ctx, insertJunctionSQL, entryID, tagID); err != nil {
 			a.logf("Warning: failed to create tag association: %v\n", err)
 		}
 	}
@@ -425,10 +764,11 @@ func (a *App) processTags(entryID int64, text string) error {
 	return nil
 }
 
-func (a *App) getOrCreateTag(tagName string) (int64, error) {
+func (a *App) getOrCreateTag(ctx context.Context, tagName string) (int64, error) {
+	tagName = canonicalTag(tagName)
 	var tagID int64
 	query := `SELECT id FROM tags WHERE name = ?`
-	err := a.db.QueryRow(query, tagName).Scan(&tagID)
+	err := a.db.QueryRowContext(ctx, query, tagName).Scan(&tagID)
 	
 	if err == nil {
 		return tagID, nil
@@ -439,7 +779,7 @@ func (a *App) getOrCreateTag(tagName string) (int64, error) {
 	}
 	
 	insertSQL := `INSERT INTO tags (name) VALUES (?)`
-	result, err := a.db.Exec(insertSQL, tagName)
+	result, err := a.db.ExecContext(ctx, insertSQL, tagName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create tag: %v", err)
 	}
@@ -452,21 +792,6 @@ func (a *App) getOrCreateTag(tagName string) (int64, error) {
 	return tagID, nil
 }
 
-func (a *App) ClearAllData() error {
-	if a.db == nil {
-		return fmt.Errorf("database not initialized")
-	}
-
-	query := `DELETE FROM log_entries`
-	_, err := a.db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("failed to delete log entries: %v", err)
-	}
-
-	a.logf("All log entries deleted successfully\n")
-	return nil
-}
-
 func (a *App) generateDashboard() error {
 	a.logf("Opening dashboard...\n")
 	
@@ -480,48 +805,58 @@ func (a *App) generateDashboard() error {
 	return nil
 }
 
-func (a *App) getDashboardData() (*DisplayDashboardData, error) {
-	entries, err := a.GetLogEntries(1000)
+// getDashboardData loads the dashboard page after cursor; an empty cursor is the newest page.
+// With a tag, only the entries filed under it are paged through. With a journal, the page,
+// counts, streak and tags only cover the entries of that journal.
+func (a *App) getDashboardData(ctx context.Context, cursor, tag, journal string) (*DisplayDashboardData, error) {
+	journals, err := a.GetJournals()
+	if err != nil {
+		return nil, err
+	}
+	if journal != "" {
+		if _, err := a.journalID(journal); err != nil {
+			return nil, err
+		}
+		journal = strings.ToLower(journal)
+	}
+	entries, nextCursor, err := a.queryDashboardPage(ctx, cursor, tag, journal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get log entries: %v", err)
+		return nil, err
 	}
 	
-	totalCount, err := a.GetLogEntriesCount()
+	totalCount, err := a.countLogEntries(ctx, journal)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get log count: %v", err)
 	}
 	
 	displayEntries := make([]DisplayEntry, len(entries))
 	for i, entry := range entries {
-		localTime := entry.CreatedAt.Local()
-		renderedHTML, err := a.RenderMarkdown(entry.Content)
-		if err != nil {
-			renderedHTML = fmt.Sprintf("<p>%s</p>", strings.ReplaceAll(entry.Content, "\n", "<br>"))
-		}
-		
-		displayEntries[i] = DisplayEntry{
-			ID:            entry.ID,
-			Content:       entry.Content,
-			RenderedHTML:  template.HTML(renderedHTML),
-			LocalTime:     localTime.Format("15:04"),
-			LocalTimeFull: localTime.Format("15:04:05"),
-			CreatedAt:     entry.CreatedAt,
-			DateString:    localTime.Format("2006-01-02"),
-		}
+		displayEntries[i] = a.toDisplayEntry(entry)
 	}
 	
 	dayGroups := a.groupDisplayEntriesByDay(displayEntries)
-	thisWeek := a.calculateThisWeekCount(entries)
+	thisWeek, err := a.countEntriesThisWeek(ctx, journal)
+	if err != nil {
+		return nil, err
+	}
+	streak, err := a.streakStats(journal)
+	if err != nil {
+		return nil, err
+	}
 	
-	tags, err := a.GetTags()
+	tags, err := a.queryTags(ctx, journal)
 	if err != nil {
 		a.logf("Warning: failed to get tags: %v\n", err)
 		tags = []Tag{}
 	}
 	
-	var logoData template.URL
-	if len(appIcon) > 0 {
-		logoData = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(appIcon))
+	logoData := logoURL()
+	
+	switchesToday := 0
+	if switches, err := a.GetContextSwitches(1); err != nil {
+		a.logf("Warning: failed to get context switches: %v\n", err)
+	} else if len(switches) > 0 {
+		switchesToday = switches[0].Total
 	}
 
     dayGroupsJSON := make([]map[string]interface{}, len(dayGroups))
@@ -534,7 +869,13 @@ func (a *App) getDashboardData() (*DisplayDashboardData, error) {
                 "rawContent":   entry.Content,
                 "localTime":    entry.LocalTime,
                 "localTimeFull": entry.LocalTimeFull,
+                "createdAt":    entry.CreatedAt,
                 "date":         entry.DateString,
+                "source":       entry.Source,
+                "repeatCount":  entry.RepeatCount,
+                "entryType":    entry.EntryType,
+                "tags":         entry.Tags,
+                "prose":        entry.Prose,
             }
         }
 
@@ -556,10 +897,15 @@ func (a *App) getDashboardData() (*DisplayDashboardData, error) {
 
     jsonSource := map[string]interface{}{
         "totalEntries": totalCount,
+        "pageEntries":  len(entries),
+        "tag":          tag,
         "totalDays":    len(dayGroups),
         "thisWeek":     thisWeek,
+        "streak":       streak,
         "dayGroups":    dayGroupsJSON,
         "tags":         tagsJSON,
+        "dayStartHour": a.dayStartHour(),
+        "confirmDelete": a.requiresConfirmation(actionDeleteEntry),
     }
 
     jsonBytes, err := json.Marshal(jsonSource)
@@ -571,29 +917,65 @@ func (a *App) getDashboardData() (*DisplayDashboardData, error) {
         TotalEntries: totalCount,
         TotalDays:    len(dayGroups),
         ThisWeek:     thisWeek,
-        Generated:    time.Now().Local().Format("2006-01-02 15:04:05"),
+        Streak:       *streak,
+        PageEntries:  len(entries),
+        NextCursor:   nextCursor,
+        OlderPage:    cursor != "",
+        Tag:          tag,
+        Journal:      journal,
+        Journals:     journals,
+        Generated:    a.clock.Now().Local().Format("2006-01-02 15:04:05"),
         DayGroups:    dayGroups,
         Tags:         tags,
         LogoData:     logoData,
+        ContextSwitchesToday: switchesToday,
+        PluginFormats:        a.pluginExportFormats(),
+        Theme:                a.settings.DashboardTheme,
+        FontScale:            a.dashboardFontScale(),
+        Density:              a.dashboardDensity(),
         OriginalJSONRaw: template.JS(string(jsonBytes)),
     }, nil
 }
 
+// toDisplayEntry renders an entry's markdown and local times for templates
+func (a *App) toDisplayEntry(entry LogEntry) DisplayEntry {
+	localTime := entry.CreatedAt.Local()
+	spans := a.entryTagSpans(entry.ID)
+	renderedHTML, err := a.renderEntryHTML(entry.Content, spans)
+	if err != nil {
+		renderedHTML = fmt.Sprintf("<p>%s</p>", strings.ReplaceAll(entry.Content, "\n", "<br>"))
+	}
+
+	return DisplayEntry{
+		ID:            entry.ID,
+		Content:       entry.Content,
+		RenderedHTML:  template.HTML(renderedHTML),
+		LocalTime:     localTime.Format("15:04"),
+		LocalTimeFull: localTime.Format("15:04:05"),
+		CreatedAt:     entry.CreatedAt,
+		DateString:    a.dayKey(entry.CreatedAt),
+		Source:        entry.Source,
+		RepeatCount:   entry.RepeatCount,
+		EntryType:     entry.EntryType,
+		Tags:          spanTags(validSpans(entry.Content, spans)),
+		Prose:         stripTagSpans(entry.Content, spans),
+	}
+}
+
 func (a *App) groupDisplayEntriesByDay(entries []DisplayEntry) []DisplayDayGroup {
 	dayMap := make(map[string][]DisplayEntry)
 	
 	for _, entry := range entries {
-		localTime := entry.CreatedAt.Local()
-		dayKey := localTime.Format("2006-01-02")
+		dayKey := a.dayKey(entry.CreatedAt)
 		dayMap[dayKey] = append(dayMap[dayKey], entry)
 	}
 	
 	var dayGroups []DisplayDayGroup
 	for _, dayEntries := range dayMap {
-		localTime := dayEntries[0].CreatedAt.Local()
+		day := a.dayOf(dayEntries[0].CreatedAt)
 		dayGroup := DisplayDayGroup{
-			DayName: localTime.Format("Monday"),
-			Date:    localTime.Format("2006-01-02"),
+			DayName: day.Format("Monday"),
+			Date:    day.Format("2006-01-02"),
 			Count:   len(dayEntries),
 			Entries: dayEntries,
 		}
@@ -613,20 +995,6 @@ func (a *App) groupDisplayEntriesByDay(entries []DisplayEntry) []DisplayDayGroup
 	return dayGroups
 }
 
-func (a *App) calculateThisWeekCount(entries []LogEntry) int {
-	now := time.Now()
-	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, weekStart.Location())
-	
-	count := 0
-	for _, entry := range entries {
-		if entry.CreatedAt.After(weekStart) {
-			count++
-		}
-	}
-	return count
-}
-
 func (a *App) generateHTMLFromTemplate(data *DisplayDashboardData) (string, error) {
 	templateContent, err := templates.ReadFile("templates/dashboard.html")
 	if err != nil {
@@ -637,7 +1005,7 @@ func (a *App) generateHTMLFromTemplate(data *DisplayDashboardData) (string, erro
 		}
 	}
 	
-	tmpl, err := template.New("dashboard").Parse(string(templateContent))
+	tmpl, err := template.New("dashboard").Funcs(pageFuncs).Parse(string(templateContent))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %v", err)
 	}
@@ -686,7 +1054,54 @@ func (a *App) findAvailablePort(preferredPort int) (int, error) {
 func (a *App) startDashboardServer() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/dash", a.serveDashboard)
+	mux.HandleFunc("/dash/stats", a.serveStatsPage)
+	mux.HandleFunc("/dash/search", a.serveSearchPage)
+	mux.HandleFunc("/dash/entry/", a.serveEntryPage)
+	mux.HandleFunc("/dash/topics", a.serveTopicsPage)
+	mux.HandleFunc("/dash/person/", a.servePersonReportPage)
+	mux.HandleFunc("/dash/project", a.serveProjectPage)
+	mux.HandleFunc("/dash/project/", a.serveProjectPage)
+	mux.HandleFunc("/dash/console", a.serveConsolePage)
+	mux.HandleFunc("/dash/trash", a.serveTrashPage)
+	mux.HandleFunc("/static/", a.serveStatic)
+	mux.HandleFunc("/attachments/", a.serveAttachment)
+	mux.HandleFunc("/api/entries", a.idempotent(a.handleEntriesAPI))
 	mux.HandleFunc("/api/entries/", a.handleEntryAPI)
+	mux.HandleFunc("/api/entries/batch", a.idempotent(a.handleEntryBatchAPI))
+	mux.HandleFunc("/api/entries/merge", a.handleMergeEntries)
+	mux.HandleFunc("/api/journals", a.handleJournalsAPI)
+	mux.HandleFunc("/api/journals/", a.handleJournalsAPI)
+	mux.HandleFunc("/api/preset/", a.idempotent(a.handlePresetAPI))
+	mux.HandleFunc("/api/status", a.handleStatusAPI)
+	mux.HandleFunc("/api/stats/heatmap", a.handleHeatmapAPI)
+	mux.HandleFunc("/api/stats/burndown", a.handleBurndownAPI)
+	mux.HandleFunc("/api/stats/estimates", a.handleEstimatesAPI)
+	mux.HandleFunc("/api/health", a.handleHealthAPI)
+	mux.HandleFunc("/api/diagnostics/slow", a.handleSlowOperationsAPI)
+	mux.HandleFunc("/api/inbound/", a.idempotent(a.handleInboundAPI))
+	mux.HandleFunc("/api/reminders/", a.handleReminderAPI)
+	mux.HandleFunc(googleDriveCallbackPath, a.handleGoogleDriveCallback)
+	mux.HandleFunc("/api/ha/capture", a.idempotent(a.handleHomeAssistantCapture))
+	mux.HandleFunc("/api/ha/sensor", a.handleHomeAssistantSensor)
+	mux.HandleFunc("/api/hooks/export", a.handleExportHookAPI)
+	mux.HandleFunc("/api/export/", a.handleExportAPI)
+	mux.HandleFunc("/api/shortcuts", a.handleShortcutsAPI)
+	mux.HandleFunc("/api/shortcuts/", a.handleShortcutsAPI)
+	mux.HandleFunc("/api/templates/", a.handleTemplateBundleAPI)
+	mux.HandleFunc("/api/dictionary", a.handleDictionaryAPI)
+	mux.HandleFunc("/api/dictionary/", a.handleDictionaryAPI)
+	mux.HandleFunc("/api/tag-aliases", a.handleTagAliasesAPI)
+	mux.HandleFunc("/api/tag-aliases/", a.handleTagAliasesAPI)
+	mux.HandleFunc("/api/people", a.handlePeopleAPI)
+	mux.HandleFunc("/api/people/", a.handlePeopleAPI)
+	mux.HandleFunc("/api/projects", a.handleProjectsAPI)
+	mux.HandleFunc("/api/projects/", a.handleProjectsAPI)
+	mux.HandleFunc("/api/plan/week", a.handleWeekPlanAPI)
+	mux.HandleFunc("/api/plan/day", a.handleDayPlanAPI)
+	mux.HandleFunc("/api/days-off", a.handleDaysOffAPI)
+	mux.HandleFunc("/api/days-off/", a.handleDaysOffAPI)
+	mux.HandleFunc("/api/trash", a.handleTrashAPI)
+	mux.HandleFunc("/api/trash/", a.handleTrashAPI)
 	
 	port, err := a.findAvailablePort(a.dashboardPort)
 	if err != nil {
@@ -707,7 +1122,7 @@ func (a *App) startDashboardServer() {
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf("localhost:%d", port),
-		Handler: mux,
+		Handler: compressResponses(a.traceRequests(guardLocalRequests(mux))),
 	}
 	
 	a.httpServer = server
@@ -720,20 +1135,41 @@ func (a *App) startDashboardServer() {
 }
 
 func (a *App) handleEntryAPI(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if path := strings.TrimPrefix(r.URL.Path, "/api/entries/"); r.Method == http.MethodPost && (strings.HasSuffix(path, "/split") || strings.HasSuffix(path, "/move")) {
+		action := path[strings.LastIndex(path, "/"):]
+		entryID, err := strconv.Atoi(strings.TrimSuffix(path, action))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "Invalid entry ID")
+			return
+		}
+		if action == "/move" {
+			a.handleMoveEntry(w, r, entryID)
+		} else {
+			a.handleSplitEntry(w, r, entryID)
+		}
+		return
+	}
+	if r.Method != http.MethodDelete && r.Method != http.MethodPatch && r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
 		return
 	}
 	
 	path := strings.TrimPrefix(r.URL.Path, "/api/entries/")
 	entryID, err := strconv.Atoi(path)
 	if err != nil {
-		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "Invalid entry ID")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		a.handleUpdateEntry(w, r, entryID)
+		return
+	}
+	if !a.confirmedRequest(w, r, actionDeleteEntry) {
 		return
 	}
 	
-	if err := a.DeleteEntry(entryID); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete entry: %v", err), http.StatusInternalServerError)
+	if err := a.deleteEntry(r.Context(), entryID); err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to delete entry: %v", err))
 		a.logf("Error deleting entry %d: %v\n", entryID, err)
 		return
 	}
@@ -749,8 +1185,21 @@ func (a *App) handleEntryAPI(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) serveDashboard(w http.ResponseWriter, r *http.Request) {
-	data, err := a.getDashboardData()
+	if a.notModified(w, r) {
+		return
+	}
+	tag := strings.TrimPrefix(strings.TrimSpace(r.URL.Query().Get("tag")), "#")
+	journal := strings.TrimSpace(r.URL.Query().Get("journal"))
+	data, err := a.getDashboardData(r.Context(), r.URL.Query().Get("before"), tag, journal)
 	if err != nil {
+		if asAPIError(err).Code == codeInvalidRequest {
+			http.Error(w, fmt.Sprintf("Invalid page: %v", err), http.StatusBadRequest)
+			return
+		}
+		if asAPIError(err).Code == codeNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to get dashboard data: %v", err), http.StatusInternalServerError)
 		a.logf("Error getting dashboard data: %v\n", err)
 		return
@@ -768,30 +1217,47 @@ func (a *App) serveDashboard(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) openInBrowser(urlOrPath string) error {
-	var cmd *exec.Cmd
-	
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", "", urlOrPath)
-	case "darwin":
-		cmd = exec.Command("open", urlOrPath)
-	case "linux":
-		cmd = exec.Command("xdg-open", urlOrPath)
-	default:
-		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
-	}
-	
-	return cmd.Start()
+	return a.browser.Open(urlOrPath)
 }
 
 
 func (a *App) GetLogEntries(limit int) ([]LogEntry, error) {
+	return a.queryLogEntries(context.Background(), limit)
+}
+
+// queryLogEntries returns the most recent entries; ctx carries the request ID for tracing
+func (a *App) queryLogEntries(ctx context.Context, limit int) ([]LogEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT ?`
+	rows, err := a.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log entries: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []LogEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// GetLogEntriesBySource returns the most recent entries created by the given source
+func (a *App) GetLogEntriesBySource(source string, limit int) ([]LogEntry, error) {
 	if a.db == nil {
-		return nil, fmt.Errorf("database not initialized")
+		return nil, errDatabaseUnavailable()
 	}
 
-	query := `SELECT id, content, created_at FROM log_entries ORDER BY created_at DESC LIMIT ?`
-	rows, err := a.db.Query(query, limit)
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE source = ? AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ?`
+	rows, err := a.db.Query(query, source, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query log entries: %v", err)
 	}
@@ -799,8 +1265,7 @@ func (a *App) GetLogEntries(limit int) ([]LogEntry, error) {
 
 	var entries []LogEntry
 	for rows.Next() {
-		var entry LogEntry
-		err := rows.Scan(&entry.ID, &entry.Content, &entry.CreatedAt)
+		entry, err := scanEntry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan log entry: %v", err)
 		}
@@ -811,13 +1276,22 @@ func (a *App) GetLogEntries(limit int) ([]LogEntry, error) {
 }
 
 func (a *App) GetLogEntriesCount() (int, error) {
+	return a.countLogEntries(context.Background(), "")
+}
+
+// countLogEntries counts the entries outside the trash, only those of journal when it
+// isn't empty
+func (a *App) countLogEntries(ctx context.Context, journal string) (int, error) {
 	if a.db == nil {
-		return 0, fmt.Errorf("database not initialized")
+		return 0, errDatabaseUnavailable()
 	}
 
 	var count int
-	query := `SELECT COUNT(*) FROM log_entries`
-	err := a.db.QueryRow(query).Scan(&count)
+	query, args := `SELECT COUNT(*) FROM log_entries WHERE deleted_at IS NULL`, []interface{}{}
+	if journal != "" {
+		query, args = query+` AND `+journalCondition, append(args, journal)
+	}
+	err := a.db.QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count log entries: %v", err)
 	}
@@ -826,13 +1300,17 @@ func (a *App) GetLogEntriesCount() (int, error) {
 }
 
 func (a *App) GetEntryByID(id int) (*LogEntry, error) {
+	return a.queryEntryByID(context.Background(), id)
+}
+
+// queryEntryByID returns an entry that isn't in the trash; ctx carries the request ID for tracing
+func (a *App) queryEntryByID(ctx context.Context, id int) (*LogEntry, error) {
 	if a.db == nil {
-		return nil, fmt.Errorf("database not initialized")
+		return nil, errDatabaseUnavailable()
 	}
 
-	var entry LogEntry
-	query := `SELECT id, content, created_at FROM log_entries WHERE id = ?`
-	err := a.db.QueryRow(query, id).Scan(&entry.ID, &entry.Content, &entry.CreatedAt)
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE id = ? AND deleted_at IS NULL`
+	entry, err := scanEntry(a.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("entry not found: %v", err)
 	}
@@ -842,12 +1320,11 @@ func (a *App) GetEntryByID(id int) (*LogEntry, error) {
 
 func (a *App) GetMostRecentEntry() (*LogEntry, error) {
 	if a.db == nil {
-		return nil, fmt.Errorf("database not initialized")
+		return nil, errDatabaseUnavailable()
 	}
 
-	var entry LogEntry
-	query := `SELECT id, content, created_at FROM log_entries ORDER BY created_at DESC LIMIT 1`
-	err := a.db.QueryRow(query).Scan(&entry.ID, &entry.Content, &entry.CreatedAt)
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT 1`
+	entry, err := scanEntry(a.db.QueryRow(query))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("no entries found")
@@ -859,25 +1336,38 @@ func (a *App) GetMostRecentEntry() (*LogEntry, error) {
 }
 
 func (a *App) UpdateEntry(id int, newContent string) error {
-	const maxLength = 50000
-	if len(newContent) > maxLength {
-		return fmt.Errorf("entry exceeds maximum length of %d characters", maxLength)
+	return a.updateEntry(context.Background(), id, newContent)
+}
+
+// updateEntry replaces an entry's text and re-derives its tags, tasks and people; ctx
+// carries the request ID for tracing
+func (a *App) updateEntry(ctx context.Context, id int, newContent string) error {
+	newContent = a.normalizePunctuation(a.ExpandShortcuts(newContent))
+	if len(newContent) > maxEntryLength {
+		return fmt.Errorf("entry exceeds maximum length of %d characters", maxEntryLength)
 	}
 	if a.db == nil {
-		return fmt.Errorf("database not initialized")
+		return errDatabaseUnavailable()
 	}
 
 	if newContent == "" {
 		return fmt.Errorf("content cannot be empty")
 	}
 
-	_, err := a.GetEntryByID(id)
+	previous, err := a.queryEntryByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("entry not found: %v", err)
 	}
+	if previous.Content == newContent {
+		return nil
+	}
+
+	if err := a.recordEntryRevision(ctx, id, previous.Content); err != nil {
+		return err
+	}
 
 	query := `UPDATE log_entries SET content = ? WHERE id = ?`
-	result, err := a.db.Exec(query, newContent, id)
+	result, err := a.db.ExecContext(ctx, query, newContent, id)
 	if err != nil {
 		return fmt.Errorf("failed to update entry: %v", err)
 	}
@@ -891,25 +1381,48 @@ func (a *App) UpdateEntry(id int, newContent string) error {
 		return fmt.Errorf("entry not found or not updated")
 	}
 
-	if err := a.processTags(int64(id), newContent); err != nil {
+	// tags removed from the text shouldn't stay attached
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_tags WHERE log_entry_id = ?`, id); err != nil {
+		a.logf("Warning: failed to clear tags for entry %d: %v\n", id, err)
+	}
+	if err := a.processTags(ctx, int64(id), newContent); err != nil {
 		a.logf("Warning: failed to update tags for entry %d: %v\n", id, err)
 	}
+	
+	if err := a.syncTasks(ctx, int64(id), newContent); err != nil {
+		a.logf("Warning: failed to update tasks for entry %d: %v\n", id, err)
+	}
+	
+	if err := a.updateEntryMetadata(ctx, int64(id), newContent); err != nil {
+		a.logf("Warning: failed to update metadata for entry %d: %v\n", id, err)
+	}
+	if err := a.claimAttachments(ctx, int64(id), newContent); err != nil {
+		a.logf("Warning: failed to link attachments to entry %d: %v\n", id, err)
+	}
 
 	return nil
 }
 
+// DeleteEntry moves an entry to the trash. Its tags, tasks and people links are
+// removed so it drops out of every view; RestoreEntry derives them again. A meeting
+// can't be derived from the text, so its row stays, hidden while the entry is trashed.
 func (a *App) DeleteEntry(id int) error {
+	return a.deleteEntry(context.Background(), id)
+}
+
+// deleteEntry is DeleteEntry with ctx carrying the request ID for tracing
+func (a *App) deleteEntry(ctx context.Context, id int) error {
 	if a.db == nil {
-		return fmt.Errorf("database not initialized")
+		return errDatabaseUnavailable()
 	}
 
-	_, err := a.GetEntryByID(id)
+	_, err := a.queryEntryByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("entry not found: %v", err)
 	}
 
-	query := `DELETE FROM log_entries WHERE id = ?`
-	result, err := a.db.Exec(query, id)
+	query := `UPDATE log_entries SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := a.db.ExecContext(ctx, query, sqlTime(a.clock.Now()), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete entry: %v", err)
 	}
@@ -922,10 +1435,31 @@ func (a *App) DeleteEntry(id int) error {
 	if rowsAffected == 0 {
 		return fmt.Errorf("entry not found or not deleted")
 	}
-
+	
+	a.unlinkTrashedEntry(ctx, id)
 	return nil
 }
 
+// unlinkTrashedEntry removes the tags, tasks and people links of an entry moved to the
+// trash
+func (a *App) unlinkTrashedEntry(ctx context.Context, id int) {
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_tags WHERE log_entry_id = ?`, id); err != nil {
+		a.logf("Warning: failed to delete tags for entry %d: %v\n", id, err)
+	}
+	
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM tasks WHERE log_entry_id = ?`, id); err != nil {
+		a.logf("Warning: failed to delete tasks for entry %d: %v\n", id, err)
+	}
+	
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_tag_spans WHERE log_entry_id = ?`, id); err != nil {
+		a.logf("Warning: failed to delete tag positions for entry %d: %v\n", id, err)
+	}
+	
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_people WHERE log_entry_id = ?`, id); err != nil {
+		a.logf("Warning: failed to delete people links for entry %d: %v\n", id, err)
+	}
+}
+
 type Tag struct {
 	ID        int64     `json:"id"`
 	Name      string    `json:"name"`
@@ -933,12 +1467,24 @@ type Tag struct {
 }
 
 func (a *App) GetTags() ([]Tag, error) {
+	return a.queryTags(context.Background(), "")
+}
+
+// queryTags returns every tag, or with a journal only the tags of its entries
+func (a *App) queryTags(ctx context.Context, journal string) ([]Tag, error) {
 	if a.db == nil {
-		return nil, fmt.Errorf("database not initialized")
+		return nil, errDatabaseUnavailable()
 	}
 
-	query := `SELECT id, name, created_at FROM tags ORDER BY name ASC`
-	rows, err := a.db.Query(query)
+	query, args := `SELECT id, name, created_at FROM tags ORDER BY name ASC`, []interface{}{}
+	if journal != "" {
+		query = `SELECT id, name, created_at FROM tags WHERE id IN (
+			SELECT tag_id FROM log_entries_tags WHERE log_entry_id IN (
+				SELECT id FROM log_entries WHERE deleted_at IS NULL AND ` + journalCondition + `))
+			ORDER BY name ASC`
+		args = append(args, journal)
+	}
+	rows, err := a.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tags: %v", err)
 	}
@@ -959,21 +1505,25 @@ func (a *App) GetTags() ([]Tag, error) {
 
 
 func (a *App) RenderMarkdown(markdown string) (string, error) {
+	markdown, rendered := a.renderPluginBlocks(linkEntryRefs(describeImages(markdown)))
+	
 	var buf bytes.Buffer
 	md := goldmark.New()
 	if err := md.Convert([]byte(markdown), &buf); err != nil {
 		return "", fmt.Errorf("failed to render markdown: %v", err)
 	}
-	return buf.String(), nil
+	return replacePluginBlocks(buf.String(), rendered), nil
 }
 
 func (a *App) ShowWindow() {
 	wailsRuntime.WindowShow(a.ctx)
 	wailsRuntime.WindowUnminimise(a.ctx)
+	a.setCaptureWindowVisible(true)
 }
 
 func (a *App) HideWindow() {
 	wailsRuntime.WindowMinimise(a.ctx)
+	a.setCaptureWindowVisible(false)
 }
 
 func (a *App) GetDatabasePath() string {
@@ -982,7 +1532,11 @@ func (a *App) GetDatabasePath() string {
 		return "unknown"
 	}
 	snaplogDir := filepath.Join(configDir, "snaplog")
-	return filepath.Join(snaplogDir, "snaplog.db")
+	path := filepath.Join(snaplogDir, "snaplog.db")
+	if databaseEncrypted(path) {
+		return path + encryptedDatabaseSuffix
+	}
+	return path
 }
 
 
@@ -994,27 +1548,8 @@ func (a *App) Quit() {
 func (a *App) startHotkeyDetection() {
 	a.logf("Starting hotkey detection...\n")
 	
-	modifiers := parseModifiers(a.settings.HotkeyModifiers)
-	
-	var key hotkey.Key
-	switch a.settings.HotkeyKey {
-	case "l":
-		key = hotkey.KeyL
-	case "s":
-		key = hotkey.KeyS
-	case "t":
-		key = hotkey.KeyT
-	case "n":
-		key = hotkey.KeyN
-	case "space":
-		key = hotkey.KeySpace
-	default:
-		key = hotkey.KeyL
-	}
-	
-	hk := hotkey.New(modifiers, key)
-	
-	if err := hk.Register(); err != nil {
+	hk, err := a.registerHotkey(a.settings.HotkeyModifiers, a.settings.HotkeyKey)
+	if err != nil {
 		a.logf("Failed to register hotkey: %v\n", err)
 		a.logf("Note: On macOS, this requires accessibility permissions.\n")
 		return
@@ -1023,15 +1558,42 @@ func (a *App) startHotkeyDetection() {
 	a.logf("Hotkey registered: %v+%v\n", a.settings.HotkeyModifiers, a.settings.HotkeyKey)
 	a.packageHotkey = hk
 	
-	go func() {
-		for {
-			select {
-			case <-hk.Keydown():
-				a.logf("Hotkey detected! Showing window...\n")
-				a.ShowWindow()
-			}
-		}
-	}()
+	go a.handleHotkeyGestures(hk)
+	
+	a.startMarkerHotkey()
+	a.startExpandHotkey()
+	a.startScreenshotHotkey()
+}
+
+// registerHotkey registers a global hotkey from its settings representation.
+// Unknown key names fall back to "l" like the primary hotkey always has.
+func (a *App) registerHotkey(modifierNames []string, keyName string) (globalHotkey, error) {
+	modifiers := parseModifiers(modifierNames)
+	
+	key, ok := parseKey(keyName)
+	if !ok {
+		key = hotkey.KeyL
+	}
+	
+	return a.hotkeys.Register(modifiers, key)
+}
+
+func parseKey(name string) (hotkey.Key, bool) {
+	keys := map[string]hotkey.Key{
+		"a": hotkey.KeyA, "b": hotkey.KeyB, "c": hotkey.KeyC, "d": hotkey.KeyD,
+		"e": hotkey.KeyE, "f": hotkey.KeyF, "g": hotkey.KeyG, "h": hotkey.KeyH,
+		"i": hotkey.KeyI, "j": hotkey.KeyJ, "k": hotkey.KeyK, "l": hotkey.KeyL,
+		"m": hotkey.KeyM, "n": hotkey.KeyN, "o": hotkey.KeyO, "p": hotkey.KeyP,
+		"q": hotkey.KeyQ, "r": hotkey.KeyR, "s": hotkey.KeyS, "t": hotkey.KeyT,
+		"u": hotkey.KeyU, "v": hotkey.KeyV, "w": hotkey.KeyW, "x": hotkey.KeyX,
+		"y": hotkey.KeyY, "z": hotkey.KeyZ,
+		"0": hotkey.Key0, "1": hotkey.Key1, "2": hotkey.Key2, "3": hotkey.Key3,
+		"4": hotkey.Key4, "5": hotkey.Key5, "6": hotkey.Key6, "7": hotkey.Key7,
+		"8": hotkey.Key8, "9": hotkey.Key9,
+		"space": hotkey.KeySpace,
+	}
+	key, ok := keys[strings.ToLower(name)]
+	return key, ok
 }
 
 func (a *App) stopHotkeyDetection() {
@@ -1040,6 +1602,10 @@ func (a *App) stopHotkeyDetection() {
 		a.packageHotkey.Unregister()
 		a.packageHotkey = nil
 	}
+	for _, hk := range a.extraHotkeys {
+		hk.Unregister()
+	}
+	a.extraHotkeys = nil
 }
 
 func (a *App) OpenSettings() {
@@ -1056,6 +1622,46 @@ func (a *App) GetSettings() *Settings {
 }
 
 func (a *App) SetSettings(settings *Settings) error {
+	if err := validateReminders(settings.Reminders); err != nil {
+		return err
+	}
+	if err := validateCustomCommands(settings.CustomCommands); err != nil {
+		return err
+	}
+	if err := validateSpellcheckLanguage(settings.SpellcheckLanguage); err != nil {
+		return err
+	}
+	if err := validateQuoteStyle(settings.QuoteStyle); err != nil {
+		return err
+	}
+	if err := validateDashboardTheme(settings.DashboardTheme); err != nil {
+		return err
+	}
+	if err := validateDashboardDisplay(settings.DashboardFontScale, settings.DashboardDensity); err != nil {
+		return err
+	}
+	if err := validatePreviewLength(settings.PreviewLength); err != nil {
+		return err
+	}
+	if err := validateCaptureNotificationSources(settings.CaptureNotifySources); err != nil {
+		return err
+	}
+	if err := validateCaptureWindow(settings.CapturePosition, settings.CaptureAutoHideSeconds); err != nil {
+		return err
+	}
+	if err := validateDefaultJournal(settings.DefaultJournal); err != nil {
+		return err
+	}
+	if _, err := compileTagBlacklist(settings.TagBlacklist); err != nil {
+		return err
+	}
+	if err := validateGuidedFlows(settings.GuidedFlows); err != nil {
+		return err
+	}
+	
+	dayStartChanged := a.settings.DayStartHour != settings.DayStartHour
+	trigramChanged := a.settings.SearchTrigram != settings.SearchTrigram
+	journalChanged := a.settings.DefaultJournal != settings.DefaultJournal
 	a.settings = settings
 	a.settings.FirstRun = false
 	
@@ -1080,6 +1686,22 @@ func (a *App) SetSettings(settings *Settings) error {
 		go a.startDashboardServer()
 	}
 	
+	if dayStartChanged && a.db != nil {
+		if err := a.createAnalyticsViews(); err != nil {
+			a.logf("Warning: failed to update analytics views: %v\n", err)
+		}
+	}
+	
+	if trigramChanged && a.db != nil {
+		if err := a.updateTrigramIndex(); err != nil {
+			a.logf("Warning: failed to update trigram search index: %v\n", err)
+		}
+	}
+	
+	if journalChanged && a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "journal", a.GetActiveJournal())
+	}
+	
 	a.stopHotkeyDetection()
 	go a.startHotkeyDetection()
 	
@@ -1129,7 +1751,15 @@ func (a *App) saveSettings() error {
 	
 	settingsFile := filepath.Join(snaplogDir, "settings.json")
 	
-	data, err := json.MarshalIndent(a.settings, "", "  ")
+	settings := a.settings
+	if a.vault.unlocked() {
+		// secrets stay in the encrypted database
+		if settings, err = a.storeSettingsSecrets(); err != nil {
+			return err
+		}
+	}
+	
+	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %v", err)
 	}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAttachmentBytes caps the size of a single attached file
+const maxAttachmentBytes = 25 << 20
+
+// attachmentLinkPattern matches links to stored attachments, [report.pdf](/attachments/12),
+// and the same links embedded as images
+var attachmentLinkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\((/attachments/\d+)\)`)
+
+// Attachment is a file stored in the attachments folder and linked from an entry
+type Attachment struct {
+	ID        int64     `json:"id"`
+	EntryID   int64     `json:"entry_id"`
+	Name      string    `json:"name"`
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (a *App) createAttachmentTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		log_entry_id INTEGER,
+		name TEXT NOT NULL,
+		mime_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		file_name TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_attachments_entry ON attachments(log_entry_id);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create attachments table: %v", err)
+	}
+	return nil
+}
+
+// attachmentsDir is the folder attached files are copied into
+func attachmentsDir() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "attachments"), nil
+}
+
+// attachmentURL is the dashboard route serving an attachment
+func attachmentURL(id int64) string {
+	return fmt.Sprintf("/attachments/%d", id)
+}
+
+// attachmentMarkdown links an attachment from an entry: images are embedded so the
+// dashboard shows them inline, other files are plain links
+func attachmentMarkdown(attachment *Attachment) string {
+	name := strings.NewReplacer("[", "(", "]", ")").Replace(attachment.Name)
+	if strings.HasPrefix(attachment.MimeType, "image/") {
+		return "![" + name + "](" + attachment.URL + ")"
+	}
+	return "[" + name + "](" + attachment.URL + ")"
+}
+
+// attachmentMimeType guesses the type of an attached file from its extension, or else
+// from its first bytes
+func attachmentMimeType(name string, data []byte) string {
+	if mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(data)
+}
+
+// storeAttachment copies data into the attachments folder and records it for entryID;
+// entryID 0 stores it without an entry
+func (a *App) storeAttachment(entryID int64, name string, data []byte) (*Attachment, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return nil, newAPIError(codeInvalidRequest, "an attachment needs a file name")
+	}
+	if len(data) > maxAttachmentBytes {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("%s is larger than %s", name, formatMB(maxAttachmentBytes)))
+	}
+	dir, err := attachmentsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create attachments folder: %v", err)
+	}
+
+	attachment := &Attachment{EntryID: entryID, Name: name, MimeType: attachmentMimeType(name, data),
+		Size: int64(len(data)), CreatedAt: a.clock.Now()}
+	var entry interface{}
+	if entryID != 0 {
+		entry = entryID
+	}
+	result, err := a.db.Exec(`INSERT INTO attachments (log_entry_id, name, mime_type, size, created_at) VALUES (?, ?, ?, ?, ?)`,
+		entry, name, attachment.MimeType, attachment.Size, sqlTime(attachment.CreatedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record attachment: %v", err)
+	}
+	attachment.ID, _ = result.LastInsertId()
+	attachment.URL = attachmentURL(attachment.ID)
+
+	// stored under its id, so two files called image.png don't collide
+	fileName := strconv.FormatInt(attachment.ID, 10) + strings.ToLower(filepath.Ext(name))
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+		a.db.Exec(`DELETE FROM attachments WHERE id = ?`, attachment.ID)
+		return nil, fmt.Errorf("failed to save attachment: %v", err)
+	}
+	if _, err := a.db.Exec(`UPDATE attachments SET file_name = ? WHERE id = ?`, fileName, attachment.ID); err != nil {
+		return nil, fmt.Errorf("failed to record attachment: %v", err)
+	}
+	return attachment, nil
+}
+
+// AttachFile copies the file at path into the attachments folder and links it at the
+// end of the entry, embedded as an image when it is one
+func (a *App) AttachFile(entryID int, path string) (*Attachment, error) {
+	if _, err := a.GetEntryByID(entryID); err != nil {
+		return nil, newAPIError(codeNotFound, fmt.Sprintf("entry %d not found", entryID))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("failed to read %s: %v", path, err))
+	}
+	if info.Size() > maxAttachmentBytes {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("%s is larger than %s", info.Name(), formatMB(maxAttachmentBytes)))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	attachment, err := a.storeAttachment(int64(entryID), info.Name(), data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.db.Exec(`UPDATE log_entries SET content = content || ? WHERE id = ?`,
+		"\n\n"+attachmentMarkdown(attachment), entryID); err != nil {
+		return nil, fmt.Errorf("failed to link attachment: %v", err)
+	}
+	a.logf("Attached %s to entry %d\n", attachment.Name, entryID)
+	return attachment, nil
+}
+
+// PasteClipboardImage stores the image on the system clipboard as an attachment and
+// returns the markdown that embeds it, for the input window to insert where the cursor is.
+// The attachment belongs to the entry whose text it ends up in once that is saved.
+func (a *App) PasteClipboardImage() (string, error) {
+	data, err := clipboardImage()
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", newAPIError(codeInvalidRequest, "the clipboard holds no image")
+	}
+	name := "Pasted image " + a.clock.Now().Format("2006-01-02 15.04.05") + ".png"
+	attachment, err := a.storeAttachment(0, name, data)
+	if err != nil {
+		return "", err
+	}
+	a.logf("Stored pasted image as attachment %d\n", attachment.ID)
+	return attachmentMarkdown(attachment), nil
+}
+
+// claimAttachments gives the attachments linked from an entry's text that don't belong to
+// an entry yet, such as pasted images, to that entry
+func (a *App) claimAttachments(ctx context.Context, entryID int64, content string) error {
+	for _, match := range attachmentLinkPattern.FindAllStringSubmatch(content, -1) {
+		id := strings.TrimPrefix(match[3], "/attachments/")
+		if _, err := a.db.ExecContext(ctx, `UPDATE attachments SET log_entry_id = ? WHERE id = ? AND log_entry_id IS NULL`, entryID, id); err != nil {
+			return fmt.Errorf("failed to link attachment %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// GetAttachments returns the files attached to an entry, oldest first
+func (a *App) GetAttachments(entryID int) ([]Attachment, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT id, log_entry_id, name, mime_type, size, created_at FROM attachments
+		WHERE log_entry_id = ? ORDER BY id`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %v", err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var attachment Attachment
+		if err := rows.Scan(&attachment.ID, &attachment.EntryID, &attachment.Name, &attachment.MimeType, &attachment.Size, &attachment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %v", err)
+		}
+		attachment.URL = attachmentURL(attachment.ID)
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// DeleteAttachment removes an attachment and its file. Links to it in the entry's text
+// are left as they are.
+func (a *App) DeleteAttachment(id int) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	var fileName string
+	err := a.db.QueryRow(`SELECT file_name FROM attachments WHERE id = ?`, id).Scan(&fileName)
+	if err == sql.ErrNoRows {
+		return newAPIError(codeNotFound, fmt.Sprintf("attachment %d not found", id))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query attachment: %v", err)
+	}
+	if _, err := a.db.Exec(`DELETE FROM attachments WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete attachment: %v", err)
+	}
+	a.removeAttachmentFile(fileName)
+	return nil
+}
+
+// removeEntryAttachments deletes the attachments of entries that are gone for good
+func (a *App) removeEntryAttachments() error {
+	rows, err := a.db.Query(`SELECT id, file_name FROM attachments
+		WHERE log_entry_id IS NOT NULL AND log_entry_id NOT IN (SELECT id FROM log_entries)`)
+	if err != nil {
+		return fmt.Errorf("failed to query attachments of deleted entries: %v", err)
+	}
+	files := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var fileName string
+		if rows.Scan(&id, &fileName) == nil {
+			files[id] = fileName
+		}
+	}
+	rows.Close()
+
+	for id, fileName := range files {
+		if _, err := a.db.Exec(`DELETE FROM attachments WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete attachment: %v", err)
+		}
+		a.removeAttachmentFile(fileName)
+	}
+	return nil
+}
+
+func (a *App) removeAttachmentFile(fileName string) {
+	dir, err := attachmentsDir()
+	if err != nil || fileName == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(dir, fileName)); err != nil && !os.IsNotExist(err) {
+		a.logf("Warning: failed to remove attachment %s: %v\n", fileName, err)
+	}
+}
+
+// serveAttachment serves GET /attachments/{id}. Images are shown inline; other files
+// are downloaded, and nothing served here may run scripts on the dashboard's origin.
+func (a *App) serveAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/attachments/"))
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "Database unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var name, mimeType, fileName string
+	var createdAt time.Time
+	err = a.db.QueryRow(`SELECT name, mime_type, file_name, created_at FROM attachments WHERE id = ?`, id).
+		Scan(&name, &mimeType, &fileName, &createdAt)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Attachment %d not found", id), http.StatusNotFound)
+		return
+	}
+	dir, err := attachmentsDir()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	file, err := os.Open(filepath.Join(dir, fileName))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Attachment %d is missing from the attachments folder", id), http.StatusNotFound)
+		a.logf("Error opening attachment %d: %v\n", id, err)
+		return
+	}
+	defer file.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml" {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(w, r, name, createdAt, file)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// backdateSuffixPattern matches a trailing "@@yesterday 3pm" that backdates an entry
+var backdateSuffixPattern = regexp.MustCompile(`\s*@@([^@\n]+)$`)
+
+// splitBackdate takes a trailing @@when off text and returns the text and when it
+// happened, or a zero time when there is no suffix
+func splitBackdate(text string, now time.Time) (string, time.Time, error) {
+	match := backdateSuffixPattern.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text, time.Time{}, nil
+	}
+	when := text[match[2]:match[3]]
+	at, ok := parseNaturalTime(when, now)
+	if !ok {
+		return "", time.Time{}, newAPIError(codeInvalidRequest, fmt.Sprintf("couldn't read the date in @@%s: try @@yesterday evening, @@last tuesday 3pm or @@2024-05-01 14:30", strings.TrimSpace(when)))
+	}
+	return strings.TrimSpace(text[:match[0]]), at, nil
+}
+
+// maxLogWhenWords is the most leading words of "/log <when> <text>" tried as the date,
+// enough for "day before yesterday at 3pm"
+const maxLogWhenWords = 6
+
+// runLogCommand logs "/log <when> <text>", taking the longest run of leading words that
+// reads as a date and time
+func (a *App) runLogCommand(args string) error {
+	fields := strings.Fields(args)
+	now := a.clock.Now()
+	for n := min(maxLogWhenWords, len(fields)-1); n >= 1; n-- {
+		at, ok := parseNaturalTime(strings.Join(fields[:n], " "), now)
+		if !ok {
+			continue
+		}
+		text := args
+		for _, field := range fields[:n] {
+			text = strings.TrimPrefix(strings.TrimSpace(text), field)
+		}
+		_, err := a.logEntryAt(strings.TrimSpace(text), sourceHotkey, at)
+		return err
+	}
+	return fmt.Errorf("invalid command. Usage: /log <when> <text>, e.g. /log yesterday evening Called the bank")
+}
@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"modernc.org/sqlite"
+)
+
+const (
+	backupFilePrefix = "snaplog-"
+	backupTimeFormat = "20060102-150405"
+	// encryptedBackupSuffix is appended to backups uploaded to remote targets
+	encryptedBackupSuffix = ".enc"
+	defaultRemoteKeep     = 14
+	defaultLocalKeep      = 7
+	// nightlyBackupHour is when the nightly backup falls due; when SnapLog isn't running
+	// then, it runs as soon as SnapLog is
+	nightlyBackupHour = 3
+)
+
+// backupMagic starts every encrypted backup so a wrong file is recognised before decrypting
+var backupMagic = []byte("SNAPLOGBK1")
+
+// localBackupPattern matches the regular backups in the backups folder, which rotation
+// may remove, .db.enc for an encrypted database; copies such as
+// snaplog-<time>-before-clear.db are left alone
+var localBackupPattern = regexp.MustCompile(`^snaplog-(\d{8}-\d{6})\.db(?:\.enc)?$`)
+
+// BackupTarget is a remote location encrypted backups are uploaded to.
+// Type is "s3" (also Backblaze B2 and other S3-compatible services), "webdav" or
+// "gdrive" (Google Drive).
+type BackupTarget struct {
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Enabled         bool   `json:"enabled"`
+	URL             string `json:"url"`
+	Bucket          string `json:"bucket"`
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	ClientID        string `json:"client_id"`
+	ClientSecret    string `json:"client_secret"`
+	RefreshToken    string `json:"refresh_token"`
+	FolderID        string `json:"folder_id"`
+	Prefix          string `json:"prefix"`
+	Keep            int    `json:"keep"`
+}
+
+// BackupUpload is the outcome of uploading one backup to one target
+type BackupUpload struct {
+	Target  string `json:"target"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+	Removed int    `json:"removed"`
+}
+
+// BackupReport is returned by UploadBackup
+type BackupReport struct {
+	Path     string         `json:"path"`
+	Entries  int            `json:"entries"`
+	Verified bool           `json:"verified"`
+	Uploads  []BackupUpload `json:"uploads"`
+	RanAt    time.Time      `json:"ran_at"`
+}
+
+// remoteStore is implemented by each kind of backup target
+type remoteStore interface {
+	upload(name string, data []byte) error
+	download(name string) ([]byte, error)
+	list() ([]string, error)
+	remove(name string) error
+}
+
+func backupDir() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backups directory: %v", err)
+	}
+	return dir, nil
+}
+
+// sqliteConn is the part of the modernc driver connection used for online backups
+type sqliteConn interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+func rawSQLiteConn(ctx context.Context, db *sql.DB, fn func(sqliteConn) (*sqlite.Backup, error)) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(sqliteConn)
+		if !ok {
+			return fmt.Errorf("database driver does not support online backups")
+		}
+		backup, err := fn(c)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+		}
+		return backup.Finish()
+	})
+}
+
+// snapshotDatabase writes a consistent copy of the live database to dst with SQLite's
+// online backup API, so entries logged meanwhile never leave a torn file
+func (a *App) snapshotDatabase(dst string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	err := rawSQLiteConn(context.Background(), a.db, func(c sqliteConn) (*sqlite.Backup, error) {
+		return c.NewBackup(dst)
+	})
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to back up database: %v", err)
+	}
+	return nil
+}
+
+// verifyBackup restores the backup at path into a scratch database and checks it,
+// returning the number of entries it holds
+func verifyBackup(path string) (int, error) {
+	dir, err := os.MkdirTemp("", "snaplog-verify-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create verification directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "restore.db"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open verification database: %v", err)
+	}
+	defer db.Close()
+
+	err = rawSQLiteConn(context.Background(), db, func(c sqliteConn) (*sqlite.Backup, error) {
+		return c.NewRestore(path)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore backup: %v", err)
+	}
+
+	return checkRestoredDatabase(db)
+}
+
+// checkRestoredDatabase runs an integrity check on a restored backup and counts its
+// entries
+func checkRestoredDatabase(db *sql.DB) (int, error) {
+	var integrity string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("failed to check restored backup: %v", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("restored backup is corrupt: %s", integrity)
+	}
+	var entries int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM log_entries`).Scan(&entries); err != nil {
+		return 0, fmt.Errorf("restored backup has no entries table: %v", err)
+	}
+	return entries, nil
+}
+
+// passphraseKey derives a 256-bit key from a passphrase with scrypt
+func passphraseKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	return key, nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %v", err)
+	}
+	return buf, nil
+}
+
+// sealWithKey encrypts data with AES-256-GCM, returning the nonce followed by the ciphertext
+func sealWithKey(key, data, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, aad), nil
+}
+
+func openWithKey(key, sealed, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+}
+
+// encryptBackup seals data under a key derived from passphrase.
+// The output is backupMagic, a 16-byte salt, the nonce and the ciphertext.
+func encryptBackup(data []byte, passphrase string) ([]byte, error) {
+	salt, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealWithKey(key, data, backupMagic)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(append([]byte{}, backupMagic...), salt...), sealed...), nil
+}
+
+func decryptBackup(data []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(data, backupMagic) {
+		return nil, fmt.Errorf("not an encrypted SnapLog backup")
+	}
+	data = data[len(backupMagic):]
+	if len(data) < 16 {
+		return nil, fmt.Errorf("encrypted backup is truncated")
+	}
+	key, err := passphraseKey(passphrase, data[:16])
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openWithKey(key, data[16:], backupMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup: wrong passphrase or damaged file")
+	}
+	return plain, nil
+}
+
+// VerifyBackup restores a local or encrypted backup file into a scratch database and
+// reports how many entries it holds
+func (a *App) VerifyBackup(path string) (int, error) {
+	if !strings.HasSuffix(path, encryptedBackupSuffix) {
+		return verifyBackup(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read backup: %v", err)
+	}
+	if bytes.HasPrefix(data, databaseMagic) {
+		plain, err := a.vault.open(data)
+		if err != nil {
+			return 0, err
+		}
+		return verifySnapshot(plain)
+	}
+	plain, err := decryptBackup(data, a.settings.BackupPassphrase)
+	if err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp("", "snaplog-backup-*.db")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(plain)
+	tmp.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to write temporary file: %v", err)
+	}
+	return verifyBackup(tmp.Name())
+}
+
+func newRemoteStore(target BackupTarget) (remoteStore, error) {
+	switch target.Type {
+	case "s3":
+		return newS3Store(target)
+	case "webdav":
+		return newWebDAVStore(target)
+	case "gdrive":
+		return newDriveStore(target)
+	default:
+		return nil, fmt.Errorf("unsupported backup target type %q", target.Type)
+	}
+}
+
+// UploadBackup takes a fresh backup, verifies it by restoring it, and uploads it encrypted
+// to every enabled target, removing the oldest remote copies beyond each target's Keep
+func (a *App) UploadBackup() (*BackupReport, error) {
+	if a.settings.BackupPassphrase == "" {
+		return nil, fmt.Errorf("set backup_passphrase in settings before uploading backups")
+	}
+
+	dir, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	path, entries, err := a.writeVerifiedBackup(filepath.Join(dir, backupFilePrefix+now.Format(backupTimeFormat)+".db"))
+	if err != nil {
+		return nil, err
+	}
+	report := &BackupReport{Path: path, Entries: entries, Verified: true, RanAt: now}
+	report.Uploads, err = a.uploadBackupFile(path)
+	return report, err
+}
+
+// backupTargetsEnabled reports whether any backup target is turned on
+func (a *App) backupTargetsEnabled() bool {
+	for _, target := range a.settings.BackupTargets {
+		if target.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadBackupFile encrypts the verified backup at path with the backup passphrase and
+// uploads it to every enabled target. A failed target is reported in its BackupUpload.
+func (a *App) uploadBackupFile(path string) ([]BackupUpload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %v", err)
+	}
+	if bytes.HasPrefix(data, databaseMagic) {
+		if data, err = a.vault.open(data); err != nil {
+			return nil, err
+		}
+	}
+	sealed, err := encryptBackup(data, a.settings.BackupPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), encryptedDatabaseSuffix) + encryptedBackupSuffix
+	var uploads []BackupUpload
+	for _, target := range a.settings.BackupTargets {
+		if !target.Enabled {
+			continue
+		}
+		upload := BackupUpload{Target: target.Name, OK: true, Message: "uploaded"}
+		if removed, err := uploadToTarget(target, name, sealed); err != nil {
+			upload.OK, upload.Message = false, err.Error()
+			a.logf("Backup upload to %s failed: %v\n", target.Name, err)
+		} else {
+			upload.Removed = removed
+			a.logf("Backup uploaded to %s (%d old copies removed)\n", target.Name, removed)
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+func uploadToTarget(target BackupTarget, name string, data []byte) (int, error) {
+	store, err := newRemoteStore(target)
+	if err != nil {
+		return 0, err
+	}
+	if err := store.upload(name, data); err != nil {
+		return 0, err
+	}
+
+	keep := target.Keep
+	if keep <= 0 {
+		keep = defaultRemoteKeep
+	}
+	names, err := store.list()
+	if err != nil {
+		return 0, fmt.Errorf("uploaded, but failed to list old backups: %v", err)
+	}
+	var backups []string
+	for _, n := range names {
+		if strings.HasPrefix(n, backupFilePrefix) && strings.HasSuffix(n, encryptedBackupSuffix) {
+			backups = append(backups, n)
+		}
+	}
+	// timestamped names sort oldest first
+	sort.Strings(backups)
+
+	removed := 0
+	for i := 0; i < len(backups)-keep; i++ {
+		if err := store.remove(backups[i]); err != nil {
+			return removed, fmt.Errorf("uploaded, but failed to remove %s: %v", backups[i], err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// localBackups returns the names of the regular backups in dir, oldest first
+func localBackups(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && localBackupPattern.MatchString(f.Name()) {
+			names = append(names, f.Name())
+		}
+	}
+	// timestamped names sort oldest first
+	sort.Strings(names)
+	return names, nil
+}
+
+// writeVerifiedBackup snapshots the database to path and verifies the copy, which is
+// deleted when it fails. An encrypted database is backed up encrypted, to path with
+// .enc added. It returns the path written and the entries in the backup.
+func (a *App) writeVerifiedBackup(path string) (string, int, error) {
+	if a.vault.unlocked() {
+		path += encryptedDatabaseSuffix
+		entries, err := a.writeEncryptedBackup(path)
+		if err != nil {
+			return "", 0, err
+		}
+		return path, entries, nil
+	}
+
+	if err := a.snapshotDatabase(path); err != nil {
+		return "", 0, err
+	}
+	entries, err := verifyBackup(path)
+	if err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("backup verification failed: %v", err)
+	}
+	return path, entries, nil
+}
+
+// writeLocalBackup snapshots the database into the backups folder, verifies the copy and
+// then removes the oldest backups beyond backup_keep. A copy that fails verification is
+// deleted instead, so a damaged database never rotates out the good backups.
+func (a *App) writeLocalBackup() (string, error) {
+	dir, err := backupDir()
+	if err != nil {
+		return "", err
+	}
+	path, _, err := a.writeVerifiedBackup(filepath.Join(dir, backupFilePrefix+a.clock.Now().Format(backupTimeFormat)+".db"))
+	if err != nil {
+		return "", err
+	}
+
+	keep := a.settings.BackupKeep
+	if keep <= 0 {
+		keep = defaultLocalKeep
+	}
+	names, err := localBackups(dir)
+	if err != nil {
+		return path, err
+	}
+	for i := 0; i < len(names)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, names[i])); err != nil {
+			return path, fmt.Errorf("failed to remove old backup %s: %v", names[i], err)
+		}
+	}
+	return path, nil
+}
+
+// BackupNow writes a verified backup to the backups folder straight away and returns its
+// path. It counts towards backup_keep like the nightly ones.
+func (a *App) BackupNow() (string, error) {
+	if a.db == nil {
+		return "", errDatabaseUnavailable()
+	}
+	path, err := a.writeLocalBackup()
+	if err != nil {
+		return "", err
+	}
+	a.logf("Backup written to %s\n", path)
+	return path, nil
+}
+
+// runNightlyBackups backs the database up once a day, after nightlyBackupHour
+func (a *App) runNightlyBackups() {
+	ticks, stop := a.clock.Ticker(15 * time.Minute)
+	defer stop()
+
+	a.backupIfDue(a.clock.Now())
+	for now := range ticks {
+		a.backupIfDue(now)
+	}
+}
+
+// backupIfDue writes a backup unless nightly backups are off or one was already written
+// since the last nightlyBackupHour, and uploads it to the enabled backup targets
+func (a *App) backupIfDue(now time.Time) {
+	if a.settings.DisableNightlyBackups || a.db == nil {
+		return
+	}
+	due := time.Date(now.Year(), now.Month(), now.Day(), nightlyBackupHour, 0, 0, 0, now.Location())
+	if now.Before(due) {
+		due = due.AddDate(0, 0, -1)
+	}
+
+	dir, err := backupDir()
+	if err != nil {
+		a.logf("Warning: nightly backup skipped: %v\n", err)
+		return
+	}
+	names, err := localBackups(dir)
+	if err != nil {
+		a.logf("Warning: nightly backup skipped: %v\n", err)
+		return
+	}
+	if len(names) > 0 {
+		match := localBackupPattern.FindStringSubmatch(names[len(names)-1])
+		if last, err := time.ParseInLocation(backupTimeFormat, match[1], now.Location()); err == nil && !last.Before(due) {
+			return
+		}
+	}
+
+	path, err := a.writeLocalBackup()
+	if err != nil {
+		a.logf("ERROR: nightly backup failed: %v\n", err)
+		a.notify("SnapLog backup failed", err.Error(), priorityNormal)
+		return
+	}
+	a.logf("Nightly backup written to %s\n", path)
+
+	if !a.backupTargetsEnabled() {
+		return
+	}
+	if a.settings.BackupPassphrase == "" {
+		a.logf("Warning: nightly backup not uploaded, backup_passphrase isn't set\n")
+		a.notify("SnapLog backup not uploaded", "Set a backup passphrase to upload backups to your backup targets", priorityNormal)
+		return
+	}
+	uploads, err := a.uploadBackupFile(path)
+	if err != nil {
+		a.logf("ERROR: nightly backup upload failed: %v\n", err)
+		a.notify("SnapLog backup upload failed", err.Error(), priorityNormal)
+		return
+	}
+	for _, upload := range uploads {
+		if !upload.OK {
+			a.notify("SnapLog backup upload failed", upload.Target+": "+upload.Message, priorityNormal)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Google's OAuth and Drive endpoints
+var (
+	googleAuthURL        = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL       = "https://oauth2.googleapis.com/token"
+	googleDriveAPI       = "https://www.googleapis.com/drive/v3"
+	googleDriveUploadAPI = "https://www.googleapis.com/upload/drive/v3"
+)
+
+// googleDriveScope only reaches the files SnapLog created, not the rest of the Drive
+const googleDriveScope = "https://www.googleapis.com/auth/drive.file"
+
+// googleDriveCallbackPath is where Google sends the browser back after the sign-in
+const googleDriveCallbackPath = "/api/backup/gdrive/callback"
+
+// driveSignIn is the Google sign-in waiting for its callback
+type driveSignIn struct {
+	mu     sync.Mutex
+	state  string
+	target string
+}
+
+// driveStore uploads into Google Drive, into FolderID when set. ClientID and ClientSecret
+// are those of a "Desktop app" OAuth client; RefreshToken is saved by ConnectGoogleDrive.
+type driveStore struct {
+	target BackupTarget
+	token  string
+}
+
+func newDriveStore(target BackupTarget) (*driveStore, error) {
+	if target.ClientID == "" || target.ClientSecret == "" {
+		return nil, fmt.Errorf("gdrive target %q needs client_id and client_secret", target.Name)
+	}
+	if target.RefreshToken == "" {
+		return nil, fmt.Errorf("gdrive target %q isn't signed in yet, use ConnectGoogleDrive", target.Name)
+	}
+	return &driveStore{target: target}, nil
+}
+
+// accessToken trades the refresh token for an access token, once per store
+func (s *driveStore) accessToken() (string, error) {
+	if s.token != "" {
+		return s.token, nil
+	}
+	token, err := requestGoogleToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {s.target.ClientID},
+		"client_secret": {s.target.ClientSecret},
+		"refresh_token": {s.target.RefreshToken},
+	})
+	if err != nil {
+		return "", err
+	}
+	s.token = token.AccessToken
+	return s.token, nil
+}
+
+type googleToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func requestGoogleToken(form url.Values) (*googleToken, error) {
+	req, err := http.NewRequest(http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := doBackupRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("google sign-in failed: %v", err)
+	}
+	var token googleToken
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("google sign-in failed: unexpected token response")
+	}
+	return &token, nil
+}
+
+func (s *driveStore) request(method, rawURL string, body []byte) (*http.Request, error) {
+	token, err := s.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// upload starts a resumable upload with the file's metadata and sends the data to the
+// session it returns, which works for backups of any size
+func (s *driveStore) upload(name string, data []byte) error {
+	metadata := map[string]interface{}{"name": s.target.Prefix + name}
+	if s.target.FolderID != "" {
+		metadata["parents"] = []string{s.target.FolderID}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(http.MethodPost, googleDriveUploadAPI+"/files?uploadType=resumable", encoded)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", "application/octet-stream")
+	req.Header.Set("X-Upload-Content-Length", strconv.Itoa(len(data)))
+	resp, err := backupHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	session := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusOK || session == "" {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+
+	req, err = s.request(http.MethodPut, session, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	_, err = doBackupRequest(req, http.StatusOK, http.StatusCreated)
+	return err
+}
+
+type driveFile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// files lists the backup files SnapLog can see that match the query condition
+func (s *driveStore) files(condition string) ([]driveFile, error) {
+	q := "trashed = false"
+	if s.target.FolderID != "" {
+		q += " and " + driveQuote(s.target.FolderID) + " in parents"
+	}
+	if condition != "" {
+		q += " and " + condition
+	}
+
+	var files []driveFile
+	pageToken := ""
+	for {
+		query := url.Values{"q": {q}, "fields": {"nextPageToken,files(id,name)"}, "pageSize": {"1000"}, "spaces": {"drive"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := s.request(http.MethodGet, googleDriveAPI+"/files?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := doBackupRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			NextPageToken string      `json:"nextPageToken"`
+			Files         []driveFile `json:"files"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse Google Drive listing: %v", err)
+		}
+		files = append(files, page.Files...)
+		if page.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// fileID finds the Drive ID of the backup called name
+func (s *driveStore) fileID(name string) (string, error) {
+	files, err := s.files("name = " + driveQuote(s.target.Prefix+name))
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("%s not found on Google Drive", name)
+	}
+	return files[0].ID, nil
+}
+
+func (s *driveStore) download(name string) ([]byte, error) {
+	id, err := s.fileID(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.request(http.MethodGet, googleDriveAPI+"/files/"+url.PathEscape(id)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	return doBackupRequest(req)
+}
+
+func (s *driveStore) list() ([]string, error) {
+	files, err := s.files("")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, s.target.Prefix) {
+			names = append(names, strings.TrimPrefix(f.Name, s.target.Prefix))
+		}
+	}
+	return names, nil
+}
+
+func (s *driveStore) remove(name string) error {
+	id, err := s.fileID(name)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(http.MethodDelete, googleDriveAPI+"/files/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+	_, err = doBackupRequest(req, http.StatusNoContent, http.StatusOK)
+	return err
+}
+
+// driveQuote quotes a string for a Drive search query
+func driveQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// driveTarget returns the gdrive backup target called name in settings, or nil
+func (a *App) driveTarget(name string) *BackupTarget {
+	for i := range a.settings.BackupTargets {
+		if a.settings.BackupTargets[i].Name == name && a.settings.BackupTargets[i].Type == "gdrive" {
+			return &a.settings.BackupTargets[i]
+		}
+	}
+	return nil
+}
+
+func (a *App) googleDriveRedirectURL() string {
+	return fmt.Sprintf("http://localhost:%d%s", a.dashboardPort, googleDriveCallbackPath)
+}
+
+// ConnectGoogleDrive signs the gdrive backup target called name in to Google: it opens
+// Google's consent page in the browser, and the dashboard server saves the refresh token
+// when Google sends the browser back
+func (a *App) ConnectGoogleDrive(name string) error {
+	target := a.driveTarget(name)
+	if target == nil {
+		return newAPIError(codeNotFound, fmt.Sprintf("no gdrive backup target called %q", name))
+	}
+	if target.ClientID == "" || target.ClientSecret == "" {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("gdrive target %q needs client_id and client_secret", name))
+	}
+
+	state, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	a.drive.mu.Lock()
+	a.drive.state, a.drive.target = hex.EncodeToString(state), name
+	a.drive.mu.Unlock()
+
+	consent := googleAuthURL + "?" + url.Values{
+		"client_id":     {target.ClientID},
+		"redirect_uri":  {a.googleDriveRedirectURL()},
+		"response_type": {"code"},
+		"scope":         {googleDriveScope},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"},
+		"state":         {hex.EncodeToString(state)},
+	}.Encode()
+	a.logf("Opening the Google sign-in for backup target %s\n", name)
+	return a.openInBrowser(consent)
+}
+
+// handleGoogleDriveCallback finishes ConnectGoogleDrive: it trades the code Google sent
+// back for a refresh token and saves it on the target
+func (a *App) handleGoogleDriveCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	query := r.URL.Query()
+
+	a.drive.mu.Lock()
+	state, name := a.drive.state, a.drive.target
+	if state != "" && query.Get("state") == state {
+		a.drive.state, a.drive.target = "", ""
+	}
+	a.drive.mu.Unlock()
+
+	if state == "" || query.Get("state") != state {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "No Google sign-in is waiting for this answer")
+		return
+	}
+	if reason := query.Get("error"); reason != "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "Google sign-in was not completed: "+reason)
+		return
+	}
+
+	target := a.driveTarget(name)
+	if target == nil {
+		writeError(w, http.StatusNotFound, codeNotFound, fmt.Sprintf("No gdrive backup target called %q", name))
+		return
+	}
+
+	token, err := requestGoogleToken(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"client_id":     {target.ClientID},
+		"client_secret": {target.ClientSecret},
+		"redirect_uri":  {a.googleDriveRedirectURL()},
+	})
+	if err == nil && token.RefreshToken == "" {
+		err = fmt.Errorf("google sign-in failed: no refresh token was returned")
+	}
+	if err != nil {
+		a.logf("Google sign-in for %s failed: %v\n", name, err)
+		writeError(w, http.StatusBadGateway, codeInternal, err.Error())
+		return
+	}
+	target.RefreshToken = token.RefreshToken
+	if err := a.saveSettings(); err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to save settings: %v", err))
+		return
+	}
+
+	a.logf("Backup target %s is signed in to Google Drive\n", name)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SnapLog can now upload backups to Google Drive (%s). You can close this tab.\n", name)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+var backupHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+func doBackupRequest(req *http.Request, expect ...int) ([]byte, error) {
+	resp, err := backupHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range expect {
+		if resp.StatusCode == code {
+			return body, nil
+		}
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && len(expect) == 0 {
+		return body, nil
+	}
+	return nil, fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+}
+
+// s3Store talks to S3 and S3-compatible services (Backblaze B2, MinIO, ...) using
+// path-style URLs and Signature Version 4
+type s3Store struct {
+	target   BackupTarget
+	endpoint *url.URL
+}
+
+func newS3Store(target BackupTarget) (*s3Store, error) {
+	if target.URL == "" || target.Bucket == "" || target.AccessKeyID == "" || target.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 target %q needs url, bucket, access_key_id and secret_access_key", target.Name)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(target.URL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 url: %v", err)
+	}
+	if target.Region == "" {
+		target.Region = "us-east-1"
+	}
+	return &s3Store{target: target, endpoint: endpoint}, nil
+}
+
+func (s *s3Store) request(method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = "/" + s.target.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, body, time.Now().UTC())
+	return req, nil
+}
+
+func (s *s3Store) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.target.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.target.SecretAccessKey)
+	for _, part := range []string{day, s.target.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.target.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery encodes query sorted by key with RFC 3986 escaping, as SigV4 requires
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func (s *s3Store) upload(name string, data []byte) error {
+	req, err := s.request(http.MethodPut, s.target.Prefix+name, nil, data)
+	if err != nil {
+		return err
+	}
+	_, err = doBackupRequest(req)
+	return err
+}
+
+func (s *s3Store) download(name string) ([]byte, error) {
+	req, err := s.request(http.MethodGet, s.target.Prefix+name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doBackupRequest(req)
+}
+
+func (s *s3Store) list() ([]string, error) {
+	req, err := s.request(http.MethodGet, "", url.Values{"list-type": {"2"}, "prefix": {s.target.Prefix}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := doBackupRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Contents []struct {
+			Key string `xml:"Key"`
+		} `xml:"Contents"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse bucket listing: %v", err)
+	}
+	var names []string
+	for _, c := range result.Contents {
+		names = append(names, strings.TrimPrefix(c.Key, s.target.Prefix))
+	}
+	return names, nil
+}
+
+func (s *s3Store) remove(name string) error {
+	req, err := s.request(http.MethodDelete, s.target.Prefix+name, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = doBackupRequest(req)
+	return err
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// webdavStore uploads into a WebDAV collection, e.g. Nextcloud or a NAS share.
+// URL is the collection; Prefix is prepended to file names.
+type webdavStore struct {
+	target BackupTarget
+	base   string
+}
+
+func newWebDAVStore(target BackupTarget) (*webdavStore, error) {
+	if target.URL == "" {
+		return nil, fmt.Errorf("webdav target %q needs a url", target.Name)
+	}
+	return &webdavStore{target: target, base: strings.TrimSuffix(target.URL, "/") + "/"}, nil
+}
+
+func (s *webdavStore) request(method, name string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, s.base+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.target.Username != "" {
+		req.SetBasicAuth(s.target.Username, s.target.Password)
+	}
+	return req, nil
+}
+
+func (s *webdavStore) upload(name string, data []byte) error {
+	req, err := s.request(http.MethodPut, s.target.Prefix+name, data)
+	if err != nil {
+		return err
+	}
+	_, err = doBackupRequest(req)
+	return err
+}
+
+func (s *webdavStore) download(name string) ([]byte, error) {
+	req, err := s.request(http.MethodGet, s.target.Prefix+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doBackupRequest(req)
+}
+
+func (s *webdavStore) list() ([]string, error) {
+	req, err := s.request("PROPFIND", "", []byte(`<?xml version="1.0"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml")
+	body, err := doBackupRequest(req, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse WebDAV listing: %v", err)
+	}
+	var names []string
+	for _, r := range result.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			continue
+		}
+		name := path.Base(strings.TrimSuffix(href, "/"))
+		if strings.HasPrefix(name, s.target.Prefix) {
+			names = append(names, strings.TrimPrefix(name, s.target.Prefix))
+		}
+	}
+	return names, nil
+}
+
+func (s *webdavStore) remove(name string) error {
+	req, err := s.request(http.MethodDelete, s.target.Prefix+name, nil)
+	if err != nil {
+		return err
+	}
+	_, err = doBackupRequest(req)
+	return err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNightlyBackupUploadsToTargets(t *testing.T) {
+	a, clock, notifier := newFakeApp(t, time.Date(2026, time.March, 2, 4, 0, 0, 0, time.Local))
+	dav, target := newFakeDAV(t)
+	a.settings.BackupTargets = []BackupTarget{target}
+	if _, err := a.logEntry("backed up overnight", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a passphrase the backup is only kept locally
+	a.backupIfDue(a.clock.Now())
+	dav.mu.Lock()
+	uploaded := len(dav.files)
+	dav.mu.Unlock()
+	if uploaded != 0 {
+		t.Fatalf("uploaded %d files without a backup passphrase", uploaded)
+	}
+	notifier.WaitForSent(1)
+	if sent := notifier.Sent(); len(sent) != 1 || !strings.Contains(sent[0].Title, "not uploaded") {
+		t.Fatalf("got notifications %+v, want one saying the backup wasn't uploaded", sent)
+	}
+
+	a.settings.BackupPassphrase = "a long passphrase"
+	clock.Advance(24 * time.Hour)
+	a.backupIfDue(a.clock.Now())
+	name := backupFilePrefix + a.clock.Now().Format(backupTimeFormat) + ".db" + encryptedBackupSuffix
+	data := dav.File(name)
+	if data == nil {
+		t.Fatalf("%s wasn't uploaded", name)
+	}
+	plain, err := decryptBackup(data, a.settings.BackupPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain) == 0 {
+		t.Fatal("the uploaded backup is empty")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// burndownDays is how many days of open-task counts the stats and project pages chart
+const burndownDays = 60
+
+// TaskSnapshot is the number of open tasks at the end of a day, or now for today
+type TaskSnapshot struct {
+	Date string `json:"date"`
+	Open int    `json:"open"`
+}
+
+func (a *App) createTaskSnapshotTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS task_snapshots (
+		day TEXT NOT NULL,
+		tag TEXT NOT NULL DEFAULT '',
+		open_tasks INTEGER NOT NULL,
+		PRIMARY KEY (day, tag)
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create task_snapshots table: %v", err)
+	}
+	return nil
+}
+
+// recordTaskSnapshots stores today's open-task count overall and for each project's tag.
+// Today's row is overwritten until the day ends, so each day keeps its last count.
+func (a *App) recordTaskSnapshots(now time.Time) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	tags := []string{""}
+	projects, err := a.GetProjects()
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		tags = append(tags, project.Tag)
+	}
+
+	day := a.dayKey(now)
+	for _, tag := range tags {
+		open, err := a.countOpenTasks(tag)
+		if err != nil {
+			return err
+		}
+		_, err = a.db.Exec(`INSERT INTO task_snapshots (day, tag, open_tasks) VALUES (?, ?, ?)
+			ON CONFLICT(day, tag) DO UPDATE SET open_tasks = excluded.open_tasks`, day, tag, open)
+		if err != nil {
+			return fmt.Errorf("failed to record task snapshot: %v", err)
+		}
+	}
+	return nil
+}
+
+// runTaskSnapshots records open-task counts at startup and then every hour
+func (a *App) runTaskSnapshots() {
+	ticks, stop := a.clock.Ticker(time.Hour)
+	defer stop()
+
+	if err := a.recordTaskSnapshots(a.clock.Now()); err != nil {
+		a.logf("Warning: failed to record task snapshots: %v\n", err)
+	}
+	for now := range ticks {
+		if err := a.recordTaskSnapshots(now); err != nil {
+			a.logf("Warning: failed to record task snapshots: %v\n", err)
+		}
+	}
+}
+
+// GetTaskBurndown returns the recorded open-task counts of the last days for a tag (all
+// tasks when tag is empty), oldest first, with today's count taken live. Days SnapLog
+// wasn't running have no snapshot.
+func (a *App) GetTaskBurndown(tag string, days int) ([]TaskSnapshot, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if days <= 0 {
+		days = burndownDays
+	}
+	tag = canonicalTag(tag)
+
+	since := a.dayKey(a.clock.Now().AddDate(0, 0, -days+1))
+	rows, err := a.db.Query(`SELECT day, open_tasks FROM task_snapshots
+		WHERE tag = ? AND day >= ? ORDER BY day`, tag, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	snapshots := []TaskSnapshot{}
+	for rows.Next() {
+		var snapshot TaskSnapshot
+		if err := rows.Scan(&snapshot.Date, &snapshot.Open); err != nil {
+			return nil, fmt.Errorf("failed to scan task snapshot: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	today := TaskSnapshot{Date: a.dayKey(a.clock.Now())}
+	if today.Open, err = a.countOpenTasks(tag); err != nil {
+		return nil, err
+	}
+	if n := len(snapshots); n > 0 && snapshots[n-1].Date == today.Date {
+		snapshots[n-1] = today
+	} else {
+		snapshots = append(snapshots, today)
+	}
+	return snapshots, nil
+}
+
+// handleBurndownAPI serves GET /api/stats/burndown?tag=&days= with the open-task counts
+// of every day recorded, oldest first
+func (a *App) handleBurndownAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	days := burndownDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("invalid days: %s", value))
+			return
+		}
+		days = parsed
+	}
+
+	snapshots, err := a.GetTaskBurndown(r.URL.Query().Get("tag"), days)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+// burndownBars scales snapshots into bars for the burndown chart
+func burndownBars(snapshots []TaskSnapshot) []HistogramBucket {
+	peak := 1
+	for _, snapshot := range snapshots {
+		peak = max(peak, snapshot.Open)
+	}
+	bars := make([]HistogramBucket, len(snapshots))
+	for i, snapshot := range snapshots {
+		bars[i] = HistogramBucket{Label: snapshot.Date, Count: snapshot.Open, Ratio: float64(snapshot.Open) / float64(peak)}
+	}
+	return bars
+}
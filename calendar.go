@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxCalendarSize = 10 << 20
+
+// CalendarEvent is a single VEVENT read from the configured ICS calendar
+type CalendarEvent struct {
+	Summary string    `json:"summary"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	AllDay  bool      `json:"all_day"`
+}
+
+// openCalendar reads the ICS source, which may be a local path or an http(s)/webcal URL
+func openCalendar(source string) (io.ReadCloser, error) {
+	if strings.HasPrefix(source, "webcal://") {
+		source = "https://" + strings.TrimPrefix(source, "webcal://")
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch calendar: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch calendar: %s", resp.Status)
+		}
+		return resp.Body, nil
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar: %v", err)
+	}
+	return f, nil
+}
+
+// unfoldICS joins RFC 5545 continuation lines
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(io.LimitReader(r, maxCalendarSize))
+	scanner.Buffer(make([]byte, 64*1024), maxCalendarSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSTime handles UTC, floating, TZID and all-day DATE values
+func parseICSTime(params, value string) (time.Time, bool, error) {
+	loc := time.Local
+	for _, param := range strings.Split(params, ";") {
+		if tzid, ok := strings.CutPrefix(param, "TZID="); ok {
+			if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+
+	allDay := strings.Contains(params, "VALUE=DATE") && !strings.Contains(params, "VALUE=DATE-TIME")
+	if allDay || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.Local(), false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t.Local(), false, err
+}
+
+func unescapeICS(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// parseICS extracts events from an ICS feed. Recurrence rules are not expanded,
+// so only the first occurrence of a recurring event is returned.
+func parseICS(r io.Reader) ([]CalendarEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %v", err)
+	}
+
+	var events []CalendarEvent
+	var current *CalendarEvent
+	for _, line := range lines {
+		switch line {
+		case "BEGIN:VEVENT":
+			current = &CalendarEvent{}
+			continue
+		case "END:VEVENT":
+			if current != nil && !current.Start.IsZero() {
+				if current.End.IsZero() {
+					current.End = current.Start
+					if current.AllDay {
+						current.End = current.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "SUMMARY":
+			current.Summary = unescapeICS(value)
+		case "DTSTART":
+			if t, allDay, err := parseICSTime(params, value); err == nil {
+				current.Start, current.AllDay = t, allDay
+			}
+		case "DTEND":
+			if t, _, err := parseICSTime(params, value); err == nil {
+				current.End = t
+			}
+		}
+	}
+	return events, nil
+}
+
+// GetTodaysCalendar returns today's events from the configured ICS calendar
+func (a *App) GetTodaysCalendar() ([]CalendarEvent, error) {
+	return a.calendarEvents(a.clock.Now())
+}
+
+// calendarEvents returns events overlapping the local day containing day
+func (a *App) calendarEvents(day time.Time) ([]CalendarEvent, error) {
+	if a.settings.CalendarICS == "" {
+		return nil, nil
+	}
+
+	r, err := openCalendar(a.settings.CalendarICS)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	events, err := parseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	day = day.Local()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	var todays []CalendarEvent
+	for _, event := range events {
+		if event.Start.Before(end) && event.End.After(start) || event.Start.Equal(start) {
+			todays = append(todays, event)
+		}
+	}
+	sort.Slice(todays, func(i, j int) bool {
+		return todays[i].Start.Before(todays[j].Start)
+	})
+	return todays, nil
+}
+
+// formatCalendarEvents renders events as a markdown list
+func formatCalendarEvents(events []CalendarEvent) string {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.AllDay {
+			lines = append(lines, fmt.Sprintf("- All day: %s", event.Summary))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %s–%s %s", event.Start.Format("15:04"), event.End.Format("15:04"), event.Summary))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Capture window profiles: the quick one-liner box and a larger, resizable long-form
+// editor with a preview pane
+const (
+	captureModeQuick = "quick"
+	captureModeLong  = "long"
+)
+
+type captureProfile struct {
+	width, height       int
+	minWidth, minHeight int
+	resizable           bool
+}
+
+var captureProfiles = map[string]captureProfile{
+	captureModeQuick: {width: 800, height: 300},
+	captureModeLong:  {width: 1100, height: 720, minWidth: 640, minHeight: 400, resizable: true},
+}
+
+// CaptureDraft is unsaved capture window text. EntryID is 0 for a new entry, or the
+// entry being edited.
+type CaptureDraft struct {
+	EntryID   int       `json:"entry_id"`
+	Content   string    `json:"content"`
+	Mode      string    `json:"mode"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (a *App) createCaptureTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS capture_drafts (
+		entry_id INTEGER PRIMARY KEY,
+		content TEXT NOT NULL,
+		mode TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create capture drafts table: %v", err)
+	}
+	return nil
+}
+
+// GetCaptureMode returns the current capture window profile
+func (a *App) GetCaptureMode() string {
+	if a.captureMode == "" {
+		return captureModeQuick
+	}
+	return a.captureMode
+}
+
+// SetCaptureMode resizes the capture window to the quick or long-form profile and tells
+// the frontend to switch layouts
+func (a *App) SetCaptureMode(mode string) error {
+	profile, ok := captureProfiles[mode]
+	if !ok {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown capture mode: %s", mode))
+	}
+	a.captureMode = mode
+	if a.ctx == nil {
+		return nil
+	}
+
+	if profile.resizable {
+		wailsRuntime.WindowSetMinSize(a.ctx, profile.minWidth, profile.minHeight)
+		wailsRuntime.WindowSetMaxSize(a.ctx, 0, 0)
+	} else {
+		wailsRuntime.WindowSetMinSize(a.ctx, profile.width, profile.height)
+		wailsRuntime.WindowSetMaxSize(a.ctx, profile.width, profile.height)
+	}
+	wailsRuntime.WindowSetSize(a.ctx, profile.width, profile.height)
+	wailsRuntime.WindowCenter(a.ctx)
+	wailsRuntime.EventsEmit(a.ctx, "capture-mode", mode)
+	return nil
+}
+
+// toggleCaptureMode switches between the quick and long-form profiles
+func (a *App) toggleCaptureMode() error {
+	if a.GetCaptureMode() == captureModeLong {
+		return a.SetCaptureMode(captureModeQuick)
+	}
+	return a.SetCaptureMode(captureModeLong)
+}
+
+// restoreCaptureMode switches to the mode the new-entry draft was written in, or to the
+// quick profile when there is no draft
+func (a *App) restoreCaptureMode() {
+	mode := captureModeQuick
+	if draft, err := a.GetDraft(0); err == nil && draft != nil {
+		mode = draft.Mode
+	}
+	if err := a.SetCaptureMode(mode); err != nil {
+		a.logf("Failed to switch capture mode: %v\n", err)
+	}
+}
+
+// showLongFormCapture opens the capture window in the long-form profile
+func (a *App) showLongFormCapture() {
+	if err := a.SetCaptureMode(captureModeLong); err != nil {
+		a.logf("Failed to switch capture mode: %v\n", err)
+	}
+	a.showCaptureWindow()
+}
+
+// startExpandHotkey registers the primary hotkey plus ExpandHotkeyModifier, which opens
+// the long-form editor directly; an empty modifier disables it
+func (a *App) startExpandHotkey() {
+	if a.settings.ExpandHotkeyModifier == "" {
+		return
+	}
+
+	modifiers := append(append([]string{}, a.settings.HotkeyModifiers...), a.settings.ExpandHotkeyModifier)
+	hk, err := a.registerHotkey(modifiers, a.settings.HotkeyKey)
+	if err != nil {
+		a.logf("Failed to register long-form capture hotkey: %v\n", err)
+		return
+	}
+
+	a.logf("Long-form capture hotkey registered: %v+%v\n", modifiers, a.settings.HotkeyKey)
+	a.extraHotkeys = append(a.extraHotkeys, hk)
+
+	go func() {
+		for range hk.Keydown() {
+			a.showLongFormCapture()
+		}
+	}()
+}
+
+// SaveDraft keeps the capture window text and mode so they survive hiding the window
+// or restarting. Saving empty content discards the draft.
+func (a *App) SaveDraft(entryID int, content, mode string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	if content == "" {
+		if _, err := a.db.Exec(`DELETE FROM capture_drafts WHERE entry_id = ?`, entryID); err != nil {
+			return fmt.Errorf("failed to discard draft: %v", err)
+		}
+		return nil
+	}
+	if _, ok := captureProfiles[mode]; !ok {
+		mode = captureModeQuick
+	}
+
+	_, err := a.db.Exec(`INSERT INTO capture_drafts (entry_id, content, mode, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(entry_id) DO UPDATE SET content = excluded.content, mode = excluded.mode, updated_at = excluded.updated_at`,
+		entryID, content, mode, sqlTime(a.clock.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to save draft: %v", err)
+	}
+	return nil
+}
+
+// GetDraft returns the saved draft for a new entry (0) or an entry being edited, or nil
+func (a *App) GetDraft(entryID int) (*CaptureDraft, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	draft := &CaptureDraft{EntryID: entryID}
+	err := a.db.QueryRow(`SELECT content, mode, updated_at FROM capture_drafts WHERE entry_id = ?`, entryID).
+		Scan(&draft.Content, &draft.Mode, &draft.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load draft: %v", err)
+	}
+	return draft, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keys of the buttons on the notification shown after a capture
+const (
+	captureActionEdit = "edit"
+	captureActionTag  = "tag"
+	captureActionUndo = "undo"
+)
+
+var captureNotificationActions = []notificationAction{
+	{Key: captureActionEdit, Label: "Edit"},
+	{Key: captureActionTag, Label: "Tag"},
+	{Key: captureActionUndo, Label: "Undo"},
+}
+
+// captureNotificationSourceNames are the sources capture_notify_sources may list.
+// Imports and automatic entries would flood the screen, so they can't be picked.
+var captureNotificationSourceNames = []string{sourceHotkey, sourceCLI, sourceAPI, sourceTelegram, sourcePlugin}
+
+func validateCaptureNotificationSources(sources []string) error {
+	for _, source := range sources {
+		if !containsString(captureNotificationSourceNames, source) {
+			return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown capture notification source %q: use %s",
+				source, strings.Join(captureNotificationSourceNames, ", ")))
+		}
+	}
+	return nil
+}
+
+// notifyCapture confirms a new entry with a "Logged ✓" notification when its source is in
+// capture_notify_sources, and carries out the Edit, Tag or Undo button clicked on it
+func (a *App) notifyCapture(entry *LogEntry) {
+	if !containsString(a.settings.CaptureNotifySources, entry.Source) {
+		return
+	}
+	switch a.notifyWithActions("Logged ✓", a.entryPreview(entry.Content), captureNotificationActions) {
+	case captureActionEdit:
+		a.captureFollowUp(EntryFlowRequest{Flow: flowEdit, ID: entry.ID, Content: entry.Content})
+	case captureActionTag:
+		// opens the entry for editing with a # at the end, ready for the tag's name
+		a.captureFollowUp(EntryFlowRequest{Flow: flowEdit, ID: entry.ID, Content: strings.TrimRight(entry.Content, " \n") + " #"})
+	case captureActionUndo:
+		if err := a.DeleteEntry(entry.ID); err != nil {
+			a.logf("Warning: failed to undo entry %d: %v\n", entry.ID, err)
+			return
+		}
+		a.logf("Entry %d moved to the trash from its capture notification\n", entry.ID)
+	}
+}
+
+// captureFollowUp brings the input window back to finish a flow started from a notification
+func (a *App) captureFollowUp(req EntryFlowRequest) {
+	if a.ctx != nil {
+		a.ShowWindow()
+	}
+	a.requestEntryFlow(req)
+}
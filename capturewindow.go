@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Placements for capture_position; empty leaves the window where it was last
+const (
+	capturePositionCenter = "center"
+	capturePositionCursor = "cursor"
+)
+
+const (
+	maxCaptureAutoHideSeconds = 3600
+	// captureCursorGap keeps the window from covering what the cursor points at
+	captureCursorGap = 24
+	// captureIdlePollInterval is how often the auto-hide countdown is checked
+	captureIdlePollInterval = time.Second
+)
+
+// captureWindowState tracks whether the capture window is up and when it was last used,
+// for capture_auto_hide_seconds
+type captureWindowState struct {
+	mu           sync.Mutex
+	visible      bool
+	lastActivity time.Time
+}
+
+func validateCaptureWindow(position string, autoHideSeconds int) error {
+	switch position {
+	case "", capturePositionCenter, capturePositionCursor:
+	default:
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown capture position %q: use %s or %s", position, capturePositionCenter, capturePositionCursor))
+	}
+	if autoHideSeconds < 0 || autoHideSeconds > maxCaptureAutoHideSeconds {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("auto-hide must be between 0 and %d seconds", maxCaptureAutoHideSeconds))
+	}
+	return nil
+}
+
+// presentCaptureWindow shows the capture window for a hotkey, placed by capture_position.
+// With capture_keep_focus the app that was in front keeps the keyboard, unless the window
+// was already up, in which case pressing the hotkey again is a request to type into it.
+func (a *App) presentCaptureWindow() {
+	a.captureWindow.mu.Lock()
+	wasVisible := a.captureWindow.visible
+	a.captureWindow.mu.Unlock()
+
+	restoreFocus := func() {}
+	if a.settings.CaptureKeepFocus && !wasVisible {
+		restoreFocus = rememberForeground()
+	}
+	if !wasVisible {
+		a.placeCaptureWindow()
+	}
+	a.ShowWindow()
+	restoreFocus()
+}
+
+// placeCaptureWindow moves the window to the center of the screen or next to the cursor
+func (a *App) placeCaptureWindow() {
+	switch a.settings.CapturePosition {
+	case capturePositionCenter:
+		wailsRuntime.WindowCenter(a.ctx)
+	case capturePositionCursor:
+		x, y, err := cursorPosition()
+		if err != nil {
+			a.logf("Warning: failed to find the cursor, centering the capture window: %v\n", err)
+			wailsRuntime.WindowCenter(a.ctx)
+			return
+		}
+		screens, err := wailsRuntime.ScreenGetAll(a.ctx)
+		if err != nil {
+			a.logf("Warning: failed to read screen size: %v\n", err)
+			return
+		}
+		for _, screen := range screens {
+			if screen.IsCurrent {
+				width, height := wailsRuntime.WindowGetSize(a.ctx)
+				x, y = nearCursor(x, y, width, height, screen.Size.Width, screen.Size.Height)
+				wailsRuntime.WindowSetPosition(a.ctx, x, y)
+				return
+			}
+		}
+	}
+}
+
+// nearCursor places a width×height window just below the cursor at x, y, or above it when
+// there is no room below, kept inside a screen of screenWidth×screenHeight
+func nearCursor(x, y, width, height, screenWidth, screenHeight int) (int, int) {
+	left := max(0, min(x-width/2, screenWidth-width))
+	top := y + captureCursorGap
+	if top+height > screenHeight {
+		top = y - captureCursorGap - height
+	}
+	return left, max(0, min(top, screenHeight-height))
+}
+
+// CaptureWindowActivity restarts the auto-hide countdown; the input window calls it while
+// it is being typed in or clicked
+func (a *App) CaptureWindowActivity() {
+	a.captureWindow.mu.Lock()
+	a.captureWindow.lastActivity = a.clock.Now()
+	a.captureWindow.mu.Unlock()
+}
+
+// setCaptureWindowVisible records the window being shown or hidden; showing it starts the
+// auto-hide countdown
+func (a *App) setCaptureWindowVisible(visible bool) {
+	a.captureWindow.mu.Lock()
+	a.captureWindow.visible = visible
+	a.captureWindow.lastActivity = a.clock.Now()
+	a.captureWindow.mu.Unlock()
+}
+
+// watchCaptureIdle hides the capture window once it has gone capture_auto_hide_seconds
+// without being used. Hiding keeps whatever was typed.
+func (a *App) watchCaptureIdle() {
+	ticks, stop := a.clock.Ticker(captureIdlePollInterval)
+	defer stop()
+
+	for now := range ticks {
+		seconds := a.settings.CaptureAutoHideSeconds
+		if seconds == 0 {
+			continue
+		}
+		a.captureWindow.mu.Lock()
+		idle := a.captureWindow.visible && now.Sub(a.captureWindow.lastActivity) >= time.Duration(seconds)*time.Second
+		a.captureWindow.mu.Unlock()
+		if idle {
+			a.logf("Capture window idle for %ds - hiding it\n", seconds)
+			a.HideWindow()
+		}
+	}
+}
+
+// SubmitResult tells the input window what LogAndHide did after logging
+type SubmitResult struct {
+	ID        int64 `json:"id"`
+	Hidden    bool  `json:"hidden"`
+	ClearText bool  `json:"clear_text"`
+}
+
+// LogAndHide logs text from the input window and then, unless stay_open_after_log is set,
+// hides the window, so the usual capture is hotkey, type, Enter and gone. The text and its
+// draft are cleared unless keep_text_after_log is set.
+func (a *App) LogAndHide(text string) (SubmitResult, error) {
+	id, err := a.logEntry(text, sourceHotkey)
+	if err != nil {
+		return SubmitResult{}, err
+	}
+
+	result := SubmitResult{ID: id, Hidden: !a.settings.StayOpenAfterLog, ClearText: !a.settings.KeepTextAfterLog}
+	if result.ClearText {
+		if err := a.SaveDraft(0, "", ""); err != nil {
+			a.logf("Warning: failed to discard draft: %v\n", err)
+		}
+	}
+	if result.Hidden && a.ctx != nil {
+		a.HideWindow()
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// What ClearAllData removes. Each scope includes the ones before it.
+const (
+	clearScopeEntries    = "entries"
+	clearScopeEntryTags  = "entries_tags"
+	clearScopeEverything = "everything"
+)
+
+// clearScopeTables lists the tables each scope empties, in delete order
+var clearScopeTables = map[string][]string{
+	clearScopeEntries: {
+		"log_entries_tags", "log_entries_tag_spans", "log_entries_people", "meetings", "timers", "day_plans", "tasks", "task_snapshots", "entry_history", "capture_drafts", "log_entries",
+	},
+	clearScopeEntryTags: {"tags", "people", "projects"},
+	clearScopeEverything: {
+		"attachments", "shortcuts", "tag_aliases", "dictionary_words", "activity_events", "reminder_events", "days_off", "api_idempotency",
+		"sync_outbox", "sync_entries", "sync_peers", "sync_conflicts", "sync_state", "journals",
+	},
+}
+
+var clearScopeIncludes = map[string][]string{
+	clearScopeEntries:    {clearScopeEntries},
+	clearScopeEntryTags:  {clearScopeEntries, clearScopeEntryTags},
+	clearScopeEverything: {clearScopeEntries, clearScopeEntryTags, clearScopeEverything},
+}
+
+// ClearReport says what ClearAllData removed and where the backup taken first is
+type ClearReport struct {
+	Scope            string           `json:"scope"`
+	BackupPath       string           `json:"backup_path"`
+	Removed          map[string]int64 `json:"removed"`
+	AttachmentsBytes int64            `json:"attachments_bytes"`
+	SettingsReset    bool             `json:"settings_reset"`
+}
+
+// ClearAllData wipes the data in scope: entries (the default) with their attachments,
+// entries_tags, or everything, which also removes every attachment and resets settings. A backup snapshot is
+// always taken first. While clear-all confirmation is on, confirmation has to be the
+// typed clearAllPhrase.
+func (a *App) ClearAllData(scope, confirmation string) (*ClearReport, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if scope == "" {
+		scope = clearScopeEntries
+	}
+	scopes, ok := clearScopeIncludes[scope]
+	if !ok {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("unknown clear scope: %s", scope))
+	}
+	if a.requiresConfirmation(actionClearAll) && confirmation != clearAllPhrase {
+		return nil, errConfirmationRequired(actionClearAll).withDetail("phrase", clearAllPhrase)
+	}
+
+	dir, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+	report := &ClearReport{Scope: scope, Removed: make(map[string]int64)}
+	report.BackupPath, _, err = a.writeVerifiedBackup(filepath.Join(dir, backupFilePrefix+a.clock.Now().Format(backupTimeFormat)+"-before-clear.db"))
+	if err != nil {
+		return nil, fmt.Errorf("not clearing data, the backup failed: %v", err)
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	for _, s := range scopes {
+		for _, table := range clearScopeTables[s] {
+			result, err := tx.Exec(`DELETE FROM ` + table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete %s: %v", table, err)
+			}
+			report.Removed[table], _ = result.RowsAffected()
+		}
+	}
+	if scope == clearScopeEverything {
+		// the main journal always exists, new entries fall back to it
+		if _, err := tx.Exec(`INSERT INTO journals (id, name) VALUES (?, ?)`, mainJournalID, mainJournalName); err != nil {
+			return nil, fmt.Errorf("failed to recreate the main journal: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit clear: %v", err)
+	}
+
+	attachments := ""
+	if dataDir, err := snaplogDataDir(); err == nil {
+		attachments = filepath.Join(dataDir, "attachments")
+	}
+	if scope != clearScopeEverything {
+		// the attachments of the cleared entries go with them, files included
+		before := dirSize(attachments)
+		var count int64
+		a.db.QueryRow(`SELECT COUNT(*) FROM attachments WHERE log_entry_id IS NOT NULL`).Scan(&count)
+		if err := a.removeEntryAttachments(); err != nil {
+			return report, fmt.Errorf("data cleared, but failed to remove attachments: %v", err)
+		}
+		report.Removed["attachments"] = count
+		report.AttachmentsBytes = before - dirSize(attachments)
+	} else {
+		if attachments != "" {
+			report.AttachmentsBytes = dirSize(attachments)
+			if err := os.RemoveAll(attachments); err != nil {
+				a.logf("Warning: failed to remove attachments: %v\n", err)
+			}
+		}
+		a.journal.mu.Lock()
+		a.journal.active = ""
+		a.journal.mu.Unlock()
+		if err := a.SetSettings(NewApp().settings); err != nil {
+			return report, fmt.Errorf("data cleared, but failed to reset settings: %v", err)
+		}
+		report.SettingsReset = true
+	}
+
+	a.logf("Cleared %s (%d entries); backup at %s\n", scope, report.Removed["log_entries"], report.BackupPath)
+	return report, nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// clipboardImage returns the image on the clipboard as PNG, or nil when there is none.
+// AppleScript prints the data as «data PNGf89504E47…», which is decoded from hex.
+func clipboardImage() ([]byte, error) {
+	output, err := exec.Command("osascript", "-e", "the clipboard as «class PNGf»").Output()
+	if err != nil {
+		// osascript fails when the clipboard holds nothing it can turn into a PNG
+		return nil, nil
+	}
+	text := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(text, "«data PNGf") {
+		return nil, nil
+	}
+	data, err := hex.DecodeString(strings.TrimSuffix(strings.TrimPrefix(text, "«data PNGf"), "»"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode clipboard image: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// clipboardImageScript prints the clipboard image as base64 PNG, or nothing when the
+// clipboard holds no image. The clipboard needs a single-threaded apartment, hence -STA.
+const clipboardImageScript = `Add-Type -AssemblyName System.Windows.Forms, System.Drawing; ` +
+	`$img = [System.Windows.Forms.Clipboard]::GetImage(); ` +
+	`if ($img) { $ms = New-Object System.IO.MemoryStream; ` +
+	`$img.Save($ms, [System.Drawing.Imaging.ImageFormat]::Png); [Convert]::ToBase64String($ms.ToArray()) }`
+
+// clipboardImage returns the image on the clipboard as PNG, or nil when there is none
+func clipboardImage() ([]byte, error) {
+	output, err := exec.Command("powershell", "-NoProfile", "-STA", "-WindowStyle", "Hidden", "-Command", clipboardImageScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard image: %v", err)
+	}
+	text := strings.TrimSpace(string(output))
+	if text == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode clipboard image: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCoalesceWindow = 2 * time.Minute
+
+var coalesceNumberPattern = regexp.MustCompile(`\d+`)
+
+// burst tracks the entry that similar automated events are merged into.
+type burst struct {
+	entryID int64
+	lastAt  time.Time
+}
+
+// coalescer merges bursts of similar automated events into a single entry.
+type coalescer struct {
+	mu     sync.Mutex
+	bursts map[string]*burst
+}
+
+// coalesceWindow returns the merge window. Zero uses the default, negative disables merging.
+func (a *App) coalesceWindow() time.Duration {
+	seconds := a.settings.AutoCaptureWindowSeconds
+	if seconds == 0 {
+		return defaultCoalesceWindow
+	}
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// coalesceKey groups events by origin and text, ignoring case, spacing and numbers
+// so that "Build 41 failed" and "Build 42 failed" count as the same event.
+func coalesceKey(origin, text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	normalized = coalesceNumberPattern.ReplaceAllString(normalized, "#")
+	return origin + "\x00" + normalized
+}
+
+// logAutoEntry logs an entry from an automated source, see logCoalescedEntry
+func (a *App) logAutoEntry(origin, text string) (int64, bool, error) {
+	return a.logCoalescedEntry(origin, text, sourceAuto)
+}
+
+// logCoalescedEntry logs an entry unless a similar one from origin was logged within
+// the coalesce window, in which case that entry's repeat count is bumped instead.
+// It reports whether the event was merged into an existing entry.
+func (a *App) logCoalescedEntry(origin, text, source string) (int64, bool, error) {
+	window := a.coalesceWindow()
+	if window == 0 {
+		id, err := a.logEntry(text, source)
+		return id, false, err
+	}
+
+	key := coalesceKey(origin, text)
+	now := a.clock.Now()
+
+	a.coalesce.mu.Lock()
+	defer a.coalesce.mu.Unlock()
+
+	if a.coalesce.bursts == nil {
+		a.coalesce.bursts = make(map[string]*burst)
+	}
+	for k, b := range a.coalesce.bursts {
+		if now.Sub(b.lastAt) > window {
+			delete(a.coalesce.bursts, k)
+		}
+	}
+
+	if b, ok := a.coalesce.bursts[key]; ok {
+		result, err := a.db.Exec(`UPDATE log_entries SET repeat_count = repeat_count + 1 WHERE id = ? AND deleted_at IS NULL`, b.entryID)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to update repeat count: %v", err)
+		}
+		if rows, _ := result.RowsAffected(); rows > 0 {
+			b.lastAt = now
+			return b.entryID, true, nil
+		}
+		// The entry was deleted in the meantime; start a new burst
+		delete(a.coalesce.bursts, key)
+	}
+
+	id, err := a.logEntry(text, source)
+	if err != nil {
+		return 0, false, err
+	}
+	a.coalesce.bursts[key] = &burst{entryID: id, lastAt: now}
+	return id, false, nil
+}
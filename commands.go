@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CustomCommand is a user-defined slash command from settings. Its actions run in
+// order: log an entry, call the webhook, turn on Do Not Disturb, open a saved
+// dashboard filter, then prefill the input from a template.
+type CustomCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Log is the entry text to log; {{args}} is replaced with the command arguments
+	Log string `json:"log"`
+	// Template names an entry template to prefill the input with
+	Template string `json:"template"`
+	// Filter is a dashboard query string, e.g. "tags=meeting&source=api&start=2024-01-01"
+	Filter string `json:"filter"`
+	// Webhook is POSTed {"command", "args", "text"} as JSON
+	Webhook    string `json:"webhook"`
+	DNDMinutes int    `json:"dnd_minutes"`
+}
+
+// CommandInfo describes a registered slash command for help screens
+type CommandInfo struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+}
+
+type slashCommand struct {
+	name        string
+	usage       string
+	description string
+	takesArgs   bool
+	run         func(a *App, args string) error
+}
+
+var builtinCommands = []slashCommand{
+	{name: "/dash", description: "Open dashboard with all logs", run: func(a *App, _ string) error {
+		return a.generateDashboard()
+	}},
+	{name: "/settings", description: "Open settings window", run: func(a *App, _ string) error {
+		a.OpenSettings()
+		return nil
+	}},
+	{name: "/expand", description: "Switch between the quick box and the long-form editor", run: func(a *App, _ string) error {
+		return a.toggleCaptureMode()
+	}},
+	{name: "/edit", usage: "/edit <id>", description: "Edit an entry by ID", takesArgs: true, run: func(a *App, args string) error {
+		entryID, err := parseCommandEntryID(args, "/edit <entry-id>")
+		if err != nil {
+			return err
+		}
+		content, err := a.GetEntryForEdit(entryID)
+		if err != nil {
+			return err
+		}
+		return a.requestEntryFlow(EntryFlowRequest{Flow: flowEdit, ID: entryID, Content: content})
+	}},
+	{name: "/delete", usage: "/delete <id>", description: "Move an entry to the trash by ID", takesArgs: true, run: func(a *App, args string) error {
+		entryID, err := parseCommandEntryID(args, "/delete <entry-id>")
+		if err != nil {
+			return err
+		}
+		if !a.requiresConfirmation(actionDeleteEntry) {
+			return a.trashEntry(entryID)
+		}
+		preview, err := a.GetEntryPreview(entryID)
+		if err != nil {
+			return err
+		}
+		return a.requestEntryFlow(EntryFlowRequest{Flow: flowDelete, ID: entryID, Preview: preview})
+	}},
+	{name: "/log", usage: "/log <when> <text>", description: "Log something that happened earlier, e.g. /log yesterday evening Called the bank", takesArgs: true, run: (*App).runLogCommand},
+	{name: "/multi", description: "Log several entries at once, one per line", run: func(a *App, _ string) error {
+		return a.startMultiCapture("")
+	}},
+	{name: "/multi", usage: "/multi [#tags]", description: "Log each line below as its own entry, with the tags added to each", takesArgs: true, run: (*App).runMultiCommand},
+	{name: "/attach", usage: "/attach <path>", description: "Attach a file to the most recent entry", takesArgs: true, run: func(a *App, args string) error {
+		path := strings.Trim(strings.TrimSpace(args), `"'`)
+		if path == "" {
+			return fmt.Errorf("invalid command. Usage: /attach <path>")
+		}
+		entry, err := a.GetMostRecentEntry()
+		if err != nil {
+			return err
+		}
+		_, err = a.AttachFile(entry.ID, path)
+		return err
+	}},
+	{name: "/editprev", description: "Edit the most recent entry", run: func(a *App, _ string) error {
+		entry, err := a.GetMostRecentEntry()
+		if err != nil {
+			return err
+		}
+		return a.requestEntryFlow(EntryFlowRequest{Flow: flowEdit, ID: entry.ID, Content: entry.Content})
+	}},
+	{name: "/delprev", description: "Move the most recent entry to the trash", run: func(a *App, _ string) error {
+		entry, err := a.GetMostRecentEntry()
+		if err != nil {
+			return err
+		}
+		if !a.requiresConfirmation(actionDeleteEntry) {
+			return a.trashEntry(entry.ID)
+		}
+		return a.requestEntryFlow(EntryFlowRequest{Flow: flowDelete, ID: entry.ID, Preview: a.entryPreview(entry.Content)})
+	}},
+	{name: "/search", usage: "/search <terms>", description: "Find entries by full-text search", takesArgs: true, run: func(a *App, args string) error {
+		if args == "" {
+			return fmt.Errorf("invalid command. Usage: /search <terms>")
+		}
+		results, err := a.SearchEntries(args, defaultSearchLimit)
+		if err != nil {
+			return err
+		}
+		a.showSearchResults(args, results)
+		return nil
+	}},
+	{name: "/today", description: "Open today's entries in the dashboard search", run: func(a *App, _ string) error {
+		return a.openDaySearch("today")
+	}},
+	{name: "/on", usage: "/on <day>", description: "Open a day's entries in the dashboard search, e.g. /on last tuesday", takesArgs: true, run: func(a *App, args string) error {
+		return a.openDaySearch(args)
+	}},
+	{name: "/alias", usage: "/alias <alias> <tag>", description: "File a tag under another tag from now on", takesArgs: true, run: func(a *App, args string) error {
+		parts := strings.Fields(args)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid command. Usage: /alias <alias> <tag>")
+		}
+		return a.SetTagAlias(parts[0], parts[1])
+	}},
+	{name: "/apply-aliases", description: "Move entries tagged with an alias to its tag", run: func(a *App, _ string) error {
+		report, err := a.ApplyTagAliases()
+		if err != nil {
+			return err
+		}
+		a.notify("SnapLog tag aliases", fmt.Sprintf("%d tags merged, %d entries moved", report.TagsMerged, report.EntriesMoved), priorityNormal)
+		return nil
+	}},
+	{name: "/meeting", usage: `/meeting "<title>" @attendee ...`, description: "Log a meeting with its attendees and start timing it", takesArgs: true, run: func(a *App, args string) error {
+		_, err := a.StartMeeting(args)
+		return err
+	}},
+	{name: "/endmeeting", description: "End the meeting, log its duration and note action items", run: func(a *App, _ string) error {
+		draft, err := a.EndMeeting()
+		if err != nil {
+			return err
+		}
+		return a.requestPrefill(draft)
+	}},
+	{name: "/flow", usage: "/flow <name>", description: "Answer a guided flow's questions one at a time, e.g. /flow standup", takesArgs: true, run: func(a *App, args string) error {
+		_, err := a.StartGuidedFlow(args)
+		return err
+	}},
+	{name: "/project", usage: "/project <name> [#tag]", description: "Create a project collecting the entries with a tag", takesArgs: true, run: func(a *App, args string) error {
+		project, err := a.SetProject(parseProjectArgs(args))
+		if err != nil {
+			return err
+		}
+		return a.openInBrowser(fmt.Sprintf("http://localhost:%d/dash/project/%s", a.dashboardPort, url.PathEscape(project.Name)))
+	}},
+	{name: "/journal", description: "List the journals and show which one new entries go to", run: func(a *App, _ string) error {
+		return a.showJournals()
+	}},
+	{name: "/journal", usage: "/journal <name>", description: "Log new entries to another journal, e.g. /journal work", takesArgs: true, run: (*App).runJournalCommand},
+	{name: "/move", usage: "/move <id> <journal>", description: "Move an entry to another journal, e.g. /move 42 personal", takesArgs: true, run: (*App).runMoveCommand},
+	{name: "/milestone", usage: "/milestone <what happened> [#tag]", description: "Log a milestone, e.g. /milestone Shipped v2 #redesign", takesArgs: true, run: func(a *App, args string) error {
+		_, err := a.LogMilestone(args)
+		return err
+	}},
+	{name: "/backup", description: "Back up the database to the backups folder now", run: func(a *App, _ string) error {
+		path, err := a.BackupNow()
+		if err != nil {
+			return err
+		}
+		a.notify("SnapLog backup", "Saved "+path, priorityNormal)
+		return nil
+	}},
+	{name: "/export", description: "Export every entry to one Markdown file per day", run: func(a *App, _ string) error {
+		return a.runExportCommand("")
+	}},
+	{name: "/export", usage: "/export <from> [<to>]", description: "Export the entries of some days to Markdown files, e.g. /export last monday to yesterday", takesArgs: true, run: (*App).runExportCommand},
+	{name: "/timesheet", description: "Export this month's timesheet as CSV and PDF", run: func(a *App, _ string) error {
+		return a.runTimesheetCommand("")
+	}},
+	{name: "/timesheet", usage: "/timesheet <YYYY-MM>", description: "Export a month's timesheet as CSV and PDF, e.g. /timesheet 2024-05", takesArgs: true, run: (*App).runTimesheetCommand},
+	{name: "/template", usage: "/template <name>", description: "Pre-fill the input from an entry template", takesArgs: true, run: func(a *App, args string) error {
+		text, err := a.ExpandTemplate(args)
+		if err != nil {
+			return err
+		}
+		return a.requestPrefill(text)
+	}},
+	{name: "/plan-week", description: "Draft a plan for the week from open tasks, goals and the week template", run: func(a *App, _ string) error {
+		plan, err := a.GetWeekPlanDraft()
+		if err != nil {
+			return err
+		}
+		return a.requestPrefill(plan.Draft)
+	}},
+	{name: "/dayoff", description: "Take today off: no reminders, and the streak and goal rest", run: func(a *App, _ string) error {
+		return a.runDayOffCommand("")
+	}},
+	{name: "/dayoff", usage: "/dayoff <from> [<to>] [name]", description: "Mark days off, e.g. /dayoff 2024-08-05 2024-08-16 Vacation", takesArgs: true, run: (*App).runDayOffCommand},
+	{name: "/plan-day", description: "Schedule today's open tasks around meetings and save it as the day note", run: func(a *App, _ string) error {
+		plan, err := a.PlanDay()
+		if err != nil {
+			return err
+		}
+		a.notify("SnapLog day plan", fmt.Sprintf("%d blocks planned, %d tasks left over", len(plan.Blocks), len(plan.Unscheduled)), priorityNormal)
+		return nil
+	}},
+	{name: "/timer", usage: "/timer <task or activity> [#tag]", description: "Start timing a task or activity", takesArgs: true, run: func(a *App, args string) error {
+		_, err := a.StartTimer(args)
+		return err
+	}},
+	{name: "/stoptimer", description: "Stop the timer and log how long it ran", run: func(a *App, _ string) error {
+		duration, err := a.StopTimer()
+		if err != nil {
+			return err
+		}
+		a.notify("SnapLog timer", "Timed "+duration, priorityNormal)
+		return nil
+	}},
+}
+
+func parseCommandEntryID(args, usage string) (int, error) {
+	parts := strings.Fields(args)
+	if len(parts) != 1 {
+		return 0, fmt.Errorf("invalid command. Usage: %s", usage)
+	}
+	var entryID int
+	if _, err := fmt.Sscanf(parts[0], "%d", &entryID); err != nil {
+		return 0, fmt.Errorf("invalid entry ID: %s", parts[0])
+	}
+	return entryID, nil
+}
+
+func splitCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	// the name ends at a space or a line break, as in "/multi\nline one\nline two"; a
+	// line break there is kept, so /multi can tell its first line from the rest
+	if end := strings.IndexAny(text, " \t\r\n"); end >= 0 {
+		return text[:end], strings.TrimLeft(text[end:], " \t")
+	}
+	return text, ""
+}
+
+// lookupCommand resolves text to a built-in, custom or plugin command, in that order
+func (a *App) lookupCommand(text string) (*slashCommand, string) {
+	name, args := splitCommand(text)
+	if !strings.HasPrefix(name, "/") {
+		return nil, ""
+	}
+
+	var needsArgs *slashCommand
+	for i := range builtinCommands {
+		cmd := &builtinCommands[i]
+		if cmd.name != name {
+			continue
+		}
+		if cmd.takesArgs == (args != "") {
+			return cmd, args
+		}
+		if cmd.takesArgs {
+			needsArgs = cmd
+		}
+	}
+	// a bare "/edit" is still the command, given without what it needs, and not an entry
+	if needsArgs != nil {
+		usage := needsArgs.usage
+		return &slashCommand{
+			name:        needsArgs.name,
+			usage:       usage,
+			description: needsArgs.description,
+			run: func(*App, string) error {
+				return fmt.Errorf("invalid command. Usage: %s", usage)
+			},
+		}, ""
+	}
+
+	for _, custom := range a.settings.CustomCommands {
+		if custom.Name == name {
+			custom := custom
+			return &slashCommand{
+				name:        custom.Name,
+				description: custom.Description,
+				run: func(a *App, args string) error {
+					return a.runCustomCommand(custom, args)
+				},
+			}, args
+		}
+	}
+
+	for _, plugin := range a.pluginCommands() {
+		if plugin == name {
+			return &slashCommand{
+				name: name,
+				run: func(a *App, args string) error {
+					_, err := a.runPluginCommand(strings.TrimSpace(name + " " + args))
+					return err
+				},
+			}, args
+		}
+	}
+
+	return nil, ""
+}
+
+// IsCommand reports whether text would be handled by ProcessCommand rather than logged
+func (a *App) IsCommand(text string) bool {
+	cmd, _ := a.lookupCommand(text)
+	return cmd != nil
+}
+
+// GetCommands lists every registered slash command
+func (a *App) GetCommands() []CommandInfo {
+	var commands []CommandInfo
+	for _, cmd := range builtinCommands {
+		usage := cmd.usage
+		if usage == "" {
+			usage = cmd.name
+		}
+		commands = append(commands, CommandInfo{Name: cmd.name, Usage: usage, Description: cmd.description, Kind: "builtin"})
+	}
+	for _, custom := range a.settings.CustomCommands {
+		commands = append(commands, CommandInfo{Name: custom.Name, Usage: custom.Name, Description: custom.Description, Kind: "custom"})
+	}
+	for _, name := range a.pluginCommands() {
+		commands = append(commands, CommandInfo{Name: name, Usage: name, Description: "Provided by a plugin", Kind: "plugin"})
+	}
+	return commands
+}
+
+func (a *App) commandUsages() string {
+	var usages []string
+	for _, cmd := range a.GetCommands() {
+		usages = append(usages, cmd.Usage)
+	}
+	return strings.Join(usages, ", ")
+}
+
+func (a *App) runCustomCommand(cmd CustomCommand, args string) error {
+	var text string
+	if cmd.Log != "" {
+		text = a.expandPlaceholders(strings.ReplaceAll(cmd.Log, "{{args}}", args))
+		if _, err := a.logEntry(strings.TrimSpace(text), sourceHotkey); err != nil {
+			return err
+		}
+	}
+
+	if cmd.Webhook != "" {
+		go a.callCommandWebhook(cmd, args, text)
+	}
+
+	if cmd.DNDMinutes > 0 {
+		a.SetDoNotDisturb(cmd.DNDMinutes)
+	}
+
+	if cmd.Filter != "" {
+		url := fmt.Sprintf("http://localhost:%d/dash?%s", a.dashboardPort, strings.TrimPrefix(cmd.Filter, "?"))
+		if err := a.openInBrowser(url); err != nil {
+			return fmt.Errorf("failed to open dashboard: %v", err)
+		}
+	}
+
+	if cmd.Template != "" {
+		text, err := a.ExpandTemplate(cmd.Template)
+		if err != nil {
+			return err
+		}
+		return a.requestPrefill(strings.ReplaceAll(text, "{{args}}", args))
+	}
+
+	a.logf("Custom command %s ran\n", cmd.Name)
+	return nil
+}
+
+func (a *App) callCommandWebhook(cmd CustomCommand, args, text string) {
+	body, err := json.Marshal(map[string]string{
+		"command": cmd.Name,
+		"args":    args,
+		"text":    text,
+	})
+	if err != nil {
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(cmd.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		a.logf("Command %s webhook failed: %v\n", cmd.Name, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		a.logf("Command %s webhook returned %s\n", cmd.Name, resp.Status)
+	}
+}
+
+// validateCustomCommands rejects malformed names and names that shadow built-in commands
+func validateCustomCommands(commands []CustomCommand) error {
+	seen := make(map[string]bool)
+	for _, cmd := range commands {
+		if !strings.HasPrefix(cmd.Name, "/") || len(cmd.Name) < 2 || strings.ContainsAny(cmd.Name, " \t\n") {
+			return fmt.Errorf("custom command %q must start with / and contain no spaces", cmd.Name)
+		}
+		for _, builtin := range builtinCommands {
+			if builtin.name == cmd.Name {
+				return fmt.Errorf("custom command %s conflicts with a built-in command", cmd.Name)
+			}
+		}
+		if seen[cmd.Name] {
+			return fmt.Errorf("custom command %s is defined twice", cmd.Name)
+		}
+		seen[cmd.Name] = true
+	}
+	return nil
+}
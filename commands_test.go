@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupBuiltinCommand(t *testing.T) {
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+
+	tests := []struct {
+		text      string
+		name      string // "" when text isn't a command
+		takesArgs bool
+		args      string
+	}{
+		{"/dash", "/dash", false, ""},
+		{"  /dash  ", "/dash", false, ""},
+		{"/multi", "/multi", false, ""},
+		{"/multi #work", "/multi", true, "#work"},
+		{"/multi\nline one", "/multi", true, "\nline one"},
+		{"/edit 42", "/edit", true, "42"},
+		{"/edit   42 ", "/edit", true, "42"},
+		{"/timesheet", "/timesheet", false, ""},
+		{"/timesheet 2026-02", "/timesheet", true, "2026-02"},
+		{"/edit", "/edit", false, ""},
+		{"/search  ", "/search", false, ""},
+		{"/dashboard", "", false, ""},
+		{"/Dash", "", false, ""},
+		{"/unknown foo", "", false, ""},
+		{"dash", "", false, ""},
+		{"called the bank /dash", "", false, ""},
+		{"", "", false, ""},
+	}
+	for _, tt := range tests {
+		cmd, args := a.lookupCommand(tt.text)
+		if tt.name == "" {
+			if cmd != nil {
+				t.Errorf("%q resolved to %s, want no command", tt.text, cmd.name)
+			}
+			if a.IsCommand(tt.text) {
+				t.Errorf("IsCommand(%q) = true", tt.text)
+			}
+			continue
+		}
+		if cmd == nil {
+			t.Errorf("%q resolved to no command, want %s", tt.text, tt.name)
+			continue
+		}
+		if cmd.name != tt.name || cmd.takesArgs != tt.takesArgs || args != tt.args {
+			t.Errorf("%q resolved to %s (takesArgs %v) with args %q, want %s (takesArgs %v) with %q",
+				tt.text, cmd.name, cmd.takesArgs, args, tt.name, tt.takesArgs, tt.args)
+		}
+	}
+}
+
+func TestCustomCommands(t *testing.T) {
+	a, clock, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+	a.dashboardPort = 8123
+
+	webhook := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		webhook <- body
+	}))
+	defer server.Close()
+
+	a.settings.CustomCommands = []CustomCommand{
+		{Name: "/standup", Log: "Standup: {{args}} #standup"},
+		{Name: "/meetings", Filter: "?tags=meeting"},
+		{Name: "/ping", Log: "pinged {{args}}", Webhook: server.URL},
+	}
+
+	tests := []struct {
+		text  string
+		args  string
+		entry string // "" when nothing is logged
+	}{
+		{"/standup shipped the export", "shipped the export", "Standup: shipped the export #standup"},
+		{"/standup", "", "Standup:  #standup"},
+		{"/meetings", "", ""},
+		{"/ping the team", "the team", "pinged the team"},
+	}
+	for _, tt := range tests {
+		cmd, args := a.lookupCommand(tt.text)
+		if cmd == nil {
+			t.Fatalf("%q resolved to no command", tt.text)
+		}
+		if args != tt.args {
+			t.Errorf("%q got args %q, want %q", tt.text, args, tt.args)
+		}
+		clock.Advance(time.Minute)
+		if err := a.ProcessCommand(tt.text); err != nil {
+			t.Fatalf("%q: %v", tt.text, err)
+		}
+		entry, err := a.GetMostRecentEntry()
+		if tt.entry == "" {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Content != tt.entry {
+			t.Errorf("%q logged %q, want %q", tt.text, entry.Content, tt.entry)
+		}
+	}
+
+	opened := a.browser.(*fakeBrowser).Opened()
+	if len(opened) != 1 || opened[0] != "http://localhost:8123/dash?tags=meeting" {
+		t.Errorf("opened %v, want the dashboard filtered by the meeting tag", opened)
+	}
+
+	select {
+	case body := <-webhook:
+		if body["command"] != "/ping" || body["args"] != "the team" || body["text"] != "pinged the team" {
+			t.Errorf("webhook got %v", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't called")
+	}
+}
+
+func TestCommandWithoutArgs(t *testing.T) {
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+
+	for _, text := range []string{"/edit", "/search", "/delete ", "/alias"} {
+		err := a.ProcessCommand(text)
+		if err == nil || !strings.Contains(err.Error(), "Usage: "+strings.TrimSpace(text)) {
+			t.Errorf("%q: got %v, want a usage error", text, err)
+		}
+	}
+	if count, err := a.GetLogEntriesCount(); err != nil || count != 0 {
+		t.Errorf("commands without arguments logged %d entries (%v)", count, err)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+	a.settings.CustomCommands = []CustomCommand{{Name: "/standup", Log: "Standup"}}
+
+	for _, text := range []string{"/nope", "/nope with args", "/standups"} {
+		err := a.ProcessCommand(text)
+		if err == nil || !strings.Contains(err.Error(), "unknown command") {
+			t.Errorf("%q: got %v, want an unknown command error", text, err)
+		}
+	}
+	if count, err := a.GetLogEntriesCount(); err != nil || count != 0 {
+		t.Errorf("unknown commands logged %d entries (%v)", count, err)
+	}
+}
+
+func TestParseCommandEntryID(t *testing.T) {
+	tests := []struct {
+		args    string
+		id      int
+		wantErr string
+	}{
+		{"42", 42, ""},
+		{" 7 ", 7, ""},
+		{"", 0, "Usage: /edit <entry-id>"},
+		{"1 2", 0, "Usage: /edit <entry-id>"},
+		{"abc", 0, "invalid entry ID: abc"},
+	}
+	for _, tt := range tests {
+		id, err := parseCommandEntryID(tt.args, "/edit <entry-id>")
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: got %v, want an error containing %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || id != tt.id {
+			t.Errorf("%q: got %d, %v, want %d", tt.args, id, err, tt.id)
+		}
+	}
+}
+
+func TestValidateCustomCommands(t *testing.T) {
+	tests := []struct {
+		names   []string
+		wantErr string
+	}{
+		{[]string{"/standup", "/ping"}, ""},
+		{[]string{"standup"}, "must start with /"},
+		{[]string{"/"}, "must start with /"},
+		{[]string{"/stand up"}, "must start with /"},
+		{[]string{"/dash"}, "conflicts with a built-in command"},
+		{[]string{"/standup", "/standup"}, "defined twice"},
+	}
+	for _, tt := range tests {
+		var commands []CustomCommand
+		for _, name := range tt.names {
+			commands = append(commands, CustomCommand{Name: name})
+		}
+		err := validateCustomCommands(commands)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%v: %v", tt.names, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: got %v, want an error containing %q", tt.names, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// compressible reports whether a response of contentType benefits from gzip.
+// Images, archives and other already-compressed formats are sent as they are.
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range []string{"text/", "application/json", "application/javascript", "application/xml", "application/rss+xml", "image/svg+xml"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter decides on the first write whether to compress, once the handler
+// has set its Content-Type
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.decided = true
+		h := w.Header()
+		// a 206 from http.ServeContent is a byte range of the file; gzipping it would make
+		// its Content-Range wrong, so ranges go out as they are
+		if status != http.StatusNoContent && status != http.StatusNotModified &&
+			status != http.StatusPartialContent && h.Get("Content-Range") == "" &&
+			h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+			h.Set("Content-Encoding", "gzip")
+			h.Del("Content-Length")
+			// ranges of the compressed body can't be served, so don't offer them
+			h.Del("Accept-Ranges")
+			w.gz = gzipWriters.Get().(*gzip.Writer)
+			w.gz.Reset(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+	}
+}
+
+// compressResponses gzips dashboard pages and API responses for clients that accept it.
+// Dashboards with thousands of entries shrink several times, which matters over the LAN.
+func compressResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(fields[0]), "gzip") {
+			return len(fields) < 2 || strings.TrimSpace(fields[1]) != "q=0"
+		}
+	}
+	return false
+}
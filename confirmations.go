@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Destructive actions whose confirmation can be turned off in settings
+const (
+	actionDeleteEntry = "delete_entry"
+	actionBatchDelete = "batch_delete"
+	actionClearAll    = "clear_all"
+	actionPurgeTrash  = "purge_trash"
+)
+
+// clearAllPhrase has to be typed to clear all data while clear-all confirmation is on
+const clearAllPhrase = "DELETE ALL"
+
+// ConfirmationSettings turn off the confirmation of destructive actions. Everything is
+// confirmed by default.
+type ConfirmationSettings struct {
+	SkipDeleteEntry bool `json:"skip_delete_entry"`
+	SkipBatchDelete bool `json:"skip_batch_delete"`
+	SkipClearAll    bool `json:"skip_clear_all"`
+	SkipPurgeTrash  bool `json:"skip_purge_trash"`
+}
+
+// requiresConfirmation reports whether action has to be confirmed before it runs
+func (a *App) requiresConfirmation(action string) bool {
+	policy := a.settings.Confirmations
+	switch action {
+	case actionDeleteEntry:
+		return !policy.SkipDeleteEntry
+	case actionBatchDelete:
+		return !policy.SkipBatchDelete
+	case actionClearAll:
+		return !policy.SkipClearAll
+	case actionPurgeTrash:
+		return !policy.SkipPurgeTrash
+	}
+	return true
+}
+
+// GetConfirmationPolicy reports which destructive actions are confirmed, keyed by action
+func (a *App) GetConfirmationPolicy() map[string]bool {
+	policy := make(map[string]bool)
+	for _, action := range []string{actionDeleteEntry, actionBatchDelete, actionClearAll, actionPurgeTrash} {
+		policy[action] = a.requiresConfirmation(action)
+	}
+	return policy
+}
+
+func errConfirmationRequired(action string) *APIError {
+	return newAPIError(codeConfirmationRequired, fmt.Sprintf("%s has to be confirmed", action)).withDetail("action", action)
+}
+
+// confirmedRequest checks that an API request for a destructive action carries
+// confirm=true when the action has to be confirmed, writing the error response if not
+func (a *App) confirmedRequest(w http.ResponseWriter, r *http.Request, action string) bool {
+	if !a.requiresConfirmation(action) || r.URL.Query().Get("confirm") == "true" {
+		return true
+	}
+	writeJSON(w, http.StatusConflict, newAPIError(codeConfirmationRequired,
+		fmt.Sprintf("%s has to be confirmed: repeat the request with ?confirm=true", action)).withDetail("action", action))
+	return false
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"modernc.org/libc"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	maxConsoleRows = 1000
+	consoleTimeout = 5 * time.Second
+)
+
+// queryConsole holds the separate read-only connection used by RunReadOnlyQuery
+type queryConsole struct {
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// QueryResult is the outcome of a read-only console query. Values are rendered as text.
+type QueryResult struct {
+	Columns   []string   `json:"columns"`
+	Rows      [][]string `json:"rows"`
+	Truncated bool       `json:"truncated"`
+	Elapsed   string     `json:"elapsed"`
+}
+
+// ConsolePageData holds everything rendered on /dash/console
+type ConsolePageData struct {
+	Enabled bool
+	Query   string
+	Result  *QueryResult
+	Error   string
+}
+
+// readOnlyDB opens the database a second time in read-only, query-only mode, so SQLite
+// itself rejects any statement that would write
+func (a *App) readOnlyDB() (*sql.DB, error) {
+	a.console.mu.Lock()
+	defer a.console.mu.Unlock()
+
+	if a.console.db != nil {
+		return a.console.db, nil
+	}
+
+	dsn := (&url.URL{Scheme: "file", Path: a.GetDatabasePath(), RawQuery: "mode=ro&_pragma=query_only(1)"}).String()
+	if a.vault.unlocked() {
+		dsn = unlockedDatabaseURI + "&mode=ro&_pragma=query_only(1)"
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read-only database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open read-only database: %v", err)
+	}
+	a.console.db = db
+	return db, nil
+}
+
+func (a *App) closeReadOnlyDB() {
+	a.console.mu.Lock()
+	defer a.console.mu.Unlock()
+	if a.console.db != nil {
+		a.console.db.Close()
+		a.console.db = nil
+	}
+}
+
+// consoleAuthorizer is the SQLite authorizer of console connections. It runs while a
+// statement is prepared and allows reading tables and calling functions, nothing else,
+// so writes, PRAGMA, ATTACH and transaction statements fail to compile. A recursive CTE
+// is reported as its own action and is allowed as part of a SELECT.
+func consoleAuthorizer(tls *libc.TLS, arg uintptr, action int32, arg1, arg2, database, trigger uintptr) int32 {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqlite3.SQLITE_RECURSIVE:
+		return sqlite3.SQLITE_OK
+	default:
+		return sqlite3.SQLITE_DENY
+	}
+}
+
+// consoleAuthorizerPointer is consoleAuthorizer as a C function pointer
+var consoleAuthorizerPointer = *(*uintptr)(unsafe.Pointer(&struct {
+	f func(*libc.TLS, uintptr, int32, uintptr, uintptr, uintptr, uintptr) int32
+}{consoleAuthorizer}))
+
+// installConsoleAuthorizer sets consoleAuthorizer on a connection of the sqlite driver.
+// The driver doesn't expose its handle, so it is read from the connection's fields.
+func installConsoleAuthorizer(driverConn interface{}) error {
+	v := reflect.ValueOf(driverConn)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unexpected sqlite connection type %T", driverConn)
+	}
+	handle, tls := v.Elem().FieldByName("db"), v.Elem().FieldByName("tls")
+	if !handle.IsValid() || handle.Kind() != reflect.Uintptr || !tls.IsValid() || tls.Type() != reflect.TypeOf((*libc.TLS)(nil)) {
+		return fmt.Errorf("unexpected sqlite connection type %T", driverConn)
+	}
+	t := (*libc.TLS)(tls.UnsafePointer())
+	if rc := sqlite3.Xsqlite3_set_authorizer(t, uintptr(handle.Uint()), consoleAuthorizerPointer, 0); rc != sqlite3.SQLITE_OK {
+		return fmt.Errorf("sqlite error %d", rc)
+	}
+	return nil
+}
+
+// validateReadOnlyQuery trims the query and a trailing semicolon. Whether the statement
+// only reads is left to consoleAuthorizer.
+func validateReadOnlyQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+	if query == "" {
+		return "", fmt.Errorf("query is empty")
+	}
+	return query, nil
+}
+
+// RunReadOnlyQuery runs a single SELECT statement against a read-only connection that
+// only authorizes reads, and returns up to maxConsoleRows rows. It requires advanced mode to be enabled in settings.
+func (a *App) RunReadOnlyQuery(query string) (*QueryResult, error) {
+	if !a.settings.AdvancedMode {
+		return nil, fmt.Errorf("the query console requires advanced mode to be enabled in settings")
+	}
+	query, err := validateReadOnlyQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	db, err := a.readOnlyDB()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), consoleTimeout)
+	defer cancel()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read-only database: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.Raw(installConsoleAuthorizer); err != nil {
+		return nil, fmt.Errorf("failed to restrict the console connection: %v", err)
+	}
+
+	started := time.Now()
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %v", err)
+	}
+
+	result := &QueryResult{Columns: columns, Rows: [][]string{}}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if len(result.Rows) == maxConsoleRows {
+			result.Truncated = true
+			break
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = formatConsoleValue(value)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %v", err)
+	}
+
+	result.Elapsed = time.Since(started).Round(time.Millisecond).String()
+	a.logf("Console query returned %d rows in %s\n", len(result.Rows), result.Elapsed)
+	return result, nil
+}
+
+func formatConsoleValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (a *App) serveConsolePage(w http.ResponseWriter, r *http.Request) {
+	data := ConsolePageData{Enabled: a.settings.AdvancedMode, Query: r.URL.Query().Get("q")}
+	if data.Enabled && data.Query != "" {
+		result, err := a.RunReadOnlyQuery(data.Query)
+		if err != nil {
+			data.Error = err.Error()
+		}
+		data.Result = result
+	}
+
+	a.renderPage(w, "console.html", "SQL Console", data)
+}
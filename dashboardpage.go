@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// dashboardPageSize is about how many entries one dashboard page shows. Pages end on a
+// day boundary, so a busy last day makes a page longer rather than splitting the day.
+const dashboardPageSize = 300
+
+// taggedEntryCondition limits a log_entries query to the entries filed under a tag
+const taggedEntryCondition = `id IN (SELECT lt.log_entry_id FROM log_entries_tags lt
+	JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)`
+
+// queryDashboardPage returns the entries of the dashboard page after cursor (the newest
+// page when empty), newest first, and the cursor of the next page if there are older
+// entries. A non-empty tag limits the pages to the entries with that tag, and a non-empty
+// journal to the entries of that journal.
+func (a *App) queryDashboardPage(ctx context.Context, cursor, tag, journal string) ([]LogEntry, string, error) {
+	if a.db == nil {
+		return nil, "", errDatabaseUnavailable()
+	}
+
+	filter, filterArgs := "1 = 1", []interface{}{}
+	if tag != "" {
+		filter, filterArgs = taggedEntryCondition, []interface{}{canonicalTag(tag)}
+	}
+	if journal != "" {
+		filter += ` AND ` + journalCondition
+		filterArgs = append(filterArgs, strings.ToLower(journal))
+	}
+
+	where, args := filter, filterArgs
+	if cursor != "" {
+		createdAt, id, err := parseEntryCursor(cursor)
+		if err != nil {
+			return nil, "", newAPIError(codeInvalidRequest, err.Error())
+		}
+		where += ` AND (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, createdAt, createdAt, id)
+	}
+
+	entries, err := a.queryEntriesWhere(ctx, where+` ORDER BY created_at DESC, id DESC LIMIT ?`, append(args, dashboardPageSize+1)...)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(entries) <= dashboardPageSize {
+		return entries, "", nil
+	}
+
+	// finish the last day so it isn't split across pages
+	entries = entries[:dashboardPageSize]
+	last := entries[len(entries)-1]
+	boundary := sqlTime(a.dayStart(a.dayOf(last.CreatedAt)))
+	rest, err := a.queryEntriesWhere(ctx, filter+` AND created_at >= ? AND (created_at < ? OR (created_at = ? AND id < ?))
+		ORDER BY created_at DESC, id DESC`, append(filterArgs, boundary, sqlTime(last.CreatedAt), sqlTime(last.CreatedAt), last.ID)...)
+	if err != nil {
+		return nil, "", err
+	}
+	entries = append(entries, rest...)
+
+	var older int
+	if err := a.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM log_entries WHERE `+filter+` AND created_at < ? AND deleted_at IS NULL)`,
+		append(filterArgs, boundary)...).Scan(&older); err != nil {
+		return nil, "", fmt.Errorf("failed to check for older entries: %v", err)
+	}
+	if older == 0 {
+		return entries, "", nil
+	}
+	return entries, entryCursor(entries[len(entries)-1]), nil
+}
+
+// GetEntriesByTag returns the newest entries filed under tag, newest first
+func (a *App) GetEntriesByTag(tag string, limit int) ([]LogEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	entries, err := a.queryEntriesWhere(context.Background(), taggedEntryCondition+` ORDER BY created_at DESC, id DESC LIMIT ?`,
+		canonicalTag(tag), limit)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	return entries, nil
+}
+
+func (a *App) queryEntriesWhere(ctx context.Context, condition string, args ...interface{}) ([]LogEntry, error) {
+	rows, err := a.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM log_entries WHERE deleted_at IS NULL AND `+condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log entries: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []LogEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// countEntriesThisWeek counts the entries since the start of the week (Sunday), only
+// those of journal when it isn't empty
+func (a *App) countEntriesThisWeek(ctx context.Context, journal string) (int, error) {
+	today := a.today()
+	weekStart := a.dayStart(today.AddDate(0, 0, -int(today.Weekday())))
+
+	query, args := `SELECT COUNT(*) FROM log_entries WHERE created_at > ? AND deleted_at IS NULL`, []interface{}{sqlTime(weekStart)}
+	if journal != "" {
+		query, args = query+` AND `+journalCondition, append(args, journal)
+	}
+	var count int
+	if err := a.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count entries this week: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCountEntriesThisWeek(t *testing.T) {
+	// Wednesday; the week started on Sunday, March 1st
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 4, 12, 0, 0, 0, time.Local))
+
+	for _, at := range []time.Time{
+		time.Date(2026, time.February, 28, 23, 0, 0, 0, time.Local), // Saturday, last week
+		time.Date(2026, time.March, 1, 0, 30, 0, 0, time.Local),
+		time.Date(2026, time.March, 3, 9, 0, 0, 0, time.Local),
+		time.Date(2026, time.March, 4, 11, 0, 0, 0, time.Local),
+	} {
+		if _, err := a.logEntryAt("weekly", sourceHotkey, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := a.countEntriesThisWeek(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("got %d entries this week, want 3", count)
+	}
+
+	// with the day starting at 4am, 00:30 on Sunday still belongs to Saturday
+	a.settings.DayStartHour = 4
+	if count, _ = a.countEntriesThisWeek(context.Background(), ""); count != 2 {
+		t.Errorf("got %d entries this week with a 4am day start, want 2", count)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// dataVersionTables are the other tables whose changes show on the dashboard and in
+// /api/status: tag lists, and days off through DayOff and GoalMet
+var dataVersionTables = []string{"tags", "log_entries_tags", "days_off"}
+
+// createDataVersionTables keeps a counter that triggers bump on every entry change and
+// recorded activity, so conditional requests can be answered without rebuilding the response
+func (a *App) createDataVersionTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS data_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL
+	);
+	INSERT OR IGNORE INTO data_version (id, version) VALUES (1, 0);
+	CREATE TRIGGER IF NOT EXISTS data_version_insert AFTER INSERT ON log_entries BEGIN
+		UPDATE data_version SET version = version + 1;
+	END;
+	CREATE TRIGGER IF NOT EXISTS data_version_update AFTER UPDATE ON log_entries BEGIN
+		UPDATE data_version SET version = version + 1;
+	END;
+	CREATE TRIGGER IF NOT EXISTS data_version_delete AFTER DELETE ON log_entries BEGIN
+		UPDATE data_version SET version = version + 1;
+	END;
+	CREATE TRIGGER IF NOT EXISTS data_version_activity AFTER INSERT ON activity_events BEGIN
+		UPDATE data_version SET version = version + 1;
+	END;`
+
+	for _, table := range dataVersionTables {
+		for _, event := range []string{"INSERT", "UPDATE", "DELETE"} {
+			createSQL += fmt.Sprintf(`
+	CREATE TRIGGER IF NOT EXISTS data_version_%s_%s AFTER %s ON %s BEGIN
+		UPDATE data_version SET version = version + 1;
+	END;`, table, strings.ToLower(event), event, table)
+		}
+	}
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create data version table: %v", err)
+	}
+	return nil
+}
+
+// dataVersion is a monotonic counter bumped whenever an entry is added, edited or deleted,
+// activity is recorded, or tags or days off change
+func (a *App) dataVersion(ctx context.Context) int64 {
+	var version int64
+	a.db.QueryRowContext(ctx, `SELECT version FROM data_version WHERE id = 1`).Scan(&version)
+	return version
+}
+
+// dataETag identifies the current state of entries and settings. The date is part of it
+// because pages label days relative to today.
+func (a *App) dataETag(ctx context.Context) string {
+	settings, _ := json.Marshal(a.settings)
+	return fmt.Sprintf(`W/"%d-%s-%s"`, a.dataVersion(ctx), a.dayKey(a.clock.Now()), sha256Hex(settings)[:12])
+}
+
+// notModified sets the ETag for a GET response and reports whether the client's
+// If-None-Match already matches it, in which case a 304 has been written
+func (a *App) notModified(w http.ResponseWriter, r *http.Request) bool {
+	if a.db == nil {
+		return false
+	}
+	etag := a.dataETag(r.Context())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" || "W/"+candidate == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// The logical day starts at DayStartHour rather than midnight, so an entry written at
+// 1am by a night owl still belongs to the evening before. Everything that groups or
+// counts by day goes through these helpers.
+
+// dayStartHour returns the configured hour the day rolls over at, 0 to 23
+func (a *App) dayStartHour() int {
+	if h := a.settings.DayStartHour; h > 0 && h < 24 {
+		return h
+	}
+	return 0
+}
+
+// dayOf returns the logical day t belongs to, as local midnight of its date
+func (a *App) dayOf(t time.Time) time.Time {
+	shifted := t.Local().Add(-time.Duration(a.dayStartHour()) * time.Hour)
+	return time.Date(shifted.Year(), shifted.Month(), shifted.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// dayKey returns the logical date of t as 2006-01-02
+func (a *App) dayKey(t time.Time) string {
+	return a.dayOf(t).Format("2006-01-02")
+}
+
+// dayStart returns the instant the logical day dated day begins
+func (a *App) dayStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), a.dayStartHour(), 0, 0, 0, time.Local)
+}
+
+// today returns the current logical day
+func (a *App) today() time.Time {
+	return a.dayOf(a.clock.Now())
+}
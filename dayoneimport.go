@@ -0,0 +1,105 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// dayOneMomentPattern matches the photo, video and audio references Day One leaves in
+// the text of an exported entry
+var dayOneMomentPattern = regexp.MustCompile(`!?\[[^\]]*\]\(dayone-moment:[^)]*\)`)
+
+// dayOneEscapePattern matches the backslash escapes Day One adds to plain punctuation
+var dayOneEscapePattern = regexp.MustCompile(`\\([\\*_{}\[\]()#+\-.!>|~<` + "`" + `])`)
+
+var dayOneTagSeparators = regexp.MustCompile(`[^\p{L}\p{M}\p{N}_-]+`)
+
+// dayOneExport is the part of a Day One JSON export SnapLog reads
+type dayOneExport struct {
+	Metadata map[string]interface{} `json:"metadata"`
+	Entries  []struct {
+		CreationDate string   `json:"creationDate"`
+		Text         string   `json:"text"`
+		Tags         []string `json:"tags"`
+	} `json:"entries"`
+}
+
+// parseImportDayOne reads a Day One JSON export, or the ZIP file Day One writes it in
+// with one JSON file per journal
+func parseImportDayOne(data []byte) ([]importRecord, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return parseDayOneJournal(data)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, newAPIError(codeInvalidPayload, fmt.Sprintf("invalid Day One export: %v", err))
+	}
+	records := []importRecord{}
+	for _, file := range archive.File {
+		if strings.ToLower(path.Ext(file.Name)) != ".json" || strings.Contains(file.Name, "/") {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s in Day One export: %v", file.Name, err)
+		}
+		journal, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s in Day One export: %v", file.Name, err)
+		}
+		parsed, err := parseDayOneJournal(journal)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, parsed...)
+	}
+	return records, nil
+}
+
+// parseDayOneJournal turns the entries of one Day One journal into import records
+func parseDayOneJournal(data []byte) ([]importRecord, error) {
+	var export dayOneExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, newAPIError(codeInvalidPayload, fmt.Sprintf("invalid Day One export: %v", err))
+	}
+	if export.Metadata == nil {
+		return nil, newAPIError(codeInvalidPayload, "the file is not a Day One JSON export")
+	}
+
+	records := make([]importRecord, 0, len(export.Entries))
+	for _, entry := range export.Entries {
+		record := importRecord{Content: dayOneMarkdown(entry.Text), CreatedAt: entry.CreationDate}
+		for _, tag := range entry.Tags {
+			if tag = strings.Trim(dayOneTagSeparators.ReplaceAllString(tag, "-"), "-"); tag != "" {
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// dayOneMarkdown cleans up the Markdown of a Day One entry: media references, which
+// point into Day One's own library, are dropped and escaped punctuation is unescaped
+func dayOneMarkdown(text string) string {
+	text = dayOneMomentPattern.ReplaceAllString(text, "")
+	text = dayOneEscapePattern.ReplaceAllString(text, "$1")
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && (len(kept) == 0 || kept[len(kept)-1] == "") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWorkdayStartHour = 9
+	defaultWorkdayEndHour   = 17
+	defaultTaskEstimate     = 30 * time.Minute
+	planSlotGranularity     = 15 * time.Minute
+)
+
+// estimatePattern matches effort estimates in task text: ~30m, ~2h, ~1h30m, ~1.5h
+var estimatePattern = regexp.MustCompile(`(?:^|\s)~(?:(\d+(?:\.\d+)?)h)?(?:(\d+)m)?(?:\s|$)`)
+
+// PlanBlock is one stretch of the day plan: a task or a calendar meeting. Minutes is
+// the estimate for a task and the length of a meeting; ActualMinutes is filled in from
+// the task's timers when the day is reconciled.
+type PlanBlock struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Title         string    `json:"title"`
+	Kind          string    `json:"kind"`
+	TaskID        int64     `json:"task_id,omitempty"`
+	Minutes       int       `json:"minutes"`
+	ActualMinutes *float64  `json:"actual_minutes,omitempty"`
+}
+
+// DayPlan is the schedule PlanDay saved as the day note in entry EntryID. Unscheduled
+// holds the open tasks that did not fit in the workday.
+type DayPlan struct {
+	Date        string      `json:"date"`
+	EntryID     int64       `json:"entry_id"`
+	Blocks      []PlanBlock `json:"blocks"`
+	Unscheduled []Task      `json:"unscheduled"`
+}
+
+func (a *App) createDayPlanTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS day_plans (
+		day TEXT PRIMARY KEY,
+		log_entry_id INTEGER NOT NULL,
+		blocks TEXT NOT NULL,
+		reconciled INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create day_plans table: %v", err)
+	}
+	return nil
+}
+
+// parseEstimate returns the effort estimate written in text, e.g. "write report ~2h"
+func parseEstimate(text string) (time.Duration, bool) {
+	for _, match := range estimatePattern.FindAllStringSubmatch(text, -1) {
+		if match[1] == "" && match[2] == "" {
+			continue
+		}
+		hours, _ := strconv.ParseFloat(match[1], 64)
+		minutes, _ := strconv.Atoi(match[2])
+		if d := time.Duration(hours*float64(time.Hour)) + time.Duration(minutes)*time.Minute; d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// workdayHours returns the configured start and end hours of the workday
+func (a *App) workdayHours() (int, int) {
+	start, end := a.settings.WorkdayStartHour, a.settings.WorkdayEndHour
+	if start <= 0 || start > 23 {
+		start = defaultWorkdayStartHour
+	}
+	if end <= start || end > 24 {
+		end = max(defaultWorkdayEndHour, start+1)
+	}
+	return start, end
+}
+
+// PlanDay schedules today's open tasks, oldest first, into the free time of the workday
+// around the calendar's meetings, using each task's ~estimate or 30 minutes. The plan is
+// saved as the day note, replacing today's earlier plan.
+func (a *App) PlanDay() (*DayPlan, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if err := a.reconcileDayPlans(); err != nil {
+		a.logf("Warning: failed to reconcile day plans: %v\n", err)
+	}
+
+	now := a.clock.Now()
+	day := a.today()
+	startHour, endHour := a.workdayHours()
+	workStart := time.Date(day.Year(), day.Month(), day.Day(), startHour, 0, 0, 0, time.Local)
+	workEnd := time.Date(day.Year(), day.Month(), day.Day(), endHour, 0, 0, 0, time.Local)
+
+	events, err := a.calendarEvents(now)
+	if err != nil {
+		a.logf("Warning: planning without the calendar: %v\n", err)
+	}
+	plan := &DayPlan{Date: day.Format("2006-01-02"), Blocks: []PlanBlock{}, Unscheduled: []Task{}}
+	var busy []PlanBlock
+	for _, event := range events {
+		if event.AllDay || !event.End.After(workStart) || !event.Start.Before(workEnd) {
+			continue
+		}
+		busy = append(busy, PlanBlock{Start: event.Start, End: event.End, Title: event.Summary, Kind: entryTypeMeeting,
+			Minutes: int(event.End.Sub(event.Start).Minutes())})
+	}
+	plan.Blocks = append(plan.Blocks, busy...)
+
+	tasks, err := a.GetOpenTasks(maxPlanTasks)
+	if err != nil {
+		return nil, err
+	}
+	cursor := now.Truncate(planSlotGranularity)
+	if cursor.Before(now) {
+		cursor = cursor.Add(planSlotGranularity)
+	}
+	if cursor.Before(workStart) {
+		cursor = workStart
+	}
+	for _, task := range tasks {
+		length, ok := parseEstimate(task.Text)
+		if !ok {
+			length = defaultTaskEstimate
+		}
+		start, ok := freeSlot(busy, cursor, workEnd, length)
+		if !ok {
+			plan.Unscheduled = append(plan.Unscheduled, task)
+			continue
+		}
+		block := PlanBlock{Start: start, End: start.Add(length), Title: planTaskTitle(task.Text), Kind: entryTypeTask,
+			TaskID: task.ID, Minutes: int(length.Minutes())}
+		busy = append(busy, block)
+		plan.Blocks = append(plan.Blocks, block)
+		cursor = block.End
+	}
+	sort.SliceStable(plan.Blocks, func(i, j int) bool {
+		return plan.Blocks[i].Start.Before(plan.Blocks[j].Start)
+	})
+
+	if err := a.saveDayPlan(plan); err != nil {
+		return nil, err
+	}
+	a.logf("Planned %s: %d blocks, %d tasks left over\n", plan.Date, len(plan.Blocks), len(plan.Unscheduled))
+	return plan, nil
+}
+
+// freeSlot returns the earliest start from cursor at which length fits before end
+// without overlapping a busy block
+func freeSlot(busy []PlanBlock, cursor, end time.Time, length time.Duration) (time.Time, bool) {
+	for {
+		if cursor.Add(length).After(end) {
+			return time.Time{}, false
+		}
+		moved := false
+		for _, block := range busy {
+			if cursor.Before(block.End) && cursor.Add(length).After(block.Start) {
+				cursor, moved = block.End, true
+			}
+		}
+		if !moved {
+			return cursor, true
+		}
+	}
+}
+
+// planTaskTitle is a task's text without its estimate
+func planTaskTitle(text string) string {
+	return strings.Join(strings.Fields(estimatePattern.ReplaceAllString(text, " ")), " ")
+}
+
+// dayPlanText renders the day note. Tasks are written as plain list items, not
+// checkboxes, so the plan doesn't create a second copy of each task.
+func dayPlanText(plan *DayPlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Day plan %s #plan\n", plan.Date)
+	if len(plan.Blocks) > 0 {
+		b.WriteString("\n")
+	}
+	for _, block := range plan.Blocks {
+		fmt.Fprintf(&b, "- %s–%s %s", block.Start.Local().Format("15:04"), block.End.Local().Format("15:04"), block.Title)
+		if block.Kind == entryTypeMeeting {
+			b.WriteString(" (meeting)")
+		} else {
+			fmt.Fprintf(&b, " (~%s)", formatMeetingDuration(time.Duration(block.Minutes)*time.Minute))
+		}
+		b.WriteString("\n")
+	}
+	if len(plan.Unscheduled) > 0 {
+		b.WriteString("\nNot scheduled:\n")
+		for _, task := range plan.Unscheduled {
+			fmt.Fprintf(&b, "- %s\n", task.Text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// saveDayPlan logs the plan as the day note, or rewrites the note of an earlier plan
+// for the same day
+func (a *App) saveDayPlan(plan *DayPlan) error {
+	blocks, err := json.Marshal(plan.Blocks)
+	if err != nil {
+		return fmt.Errorf("failed to encode day plan: %v", err)
+	}
+	text := dayPlanText(plan)
+
+	var entryID int64
+	err = a.db.QueryRow(`SELECT log_entry_id FROM day_plans WHERE day = ?`, plan.Date).Scan(&entryID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query day plan: %v", err)
+	}
+	if err == nil {
+		if _, getErr := a.GetEntryByID(int(entryID)); getErr != nil {
+			err = sql.ErrNoRows
+		} else if err := a.UpdateEntry(int(entryID), text); err != nil {
+			return err
+		}
+	}
+	if err == sql.ErrNoRows {
+		if entryID, err = a.logEntry(text, sourceHotkey); err != nil {
+			return err
+		}
+	}
+
+	plan.EntryID = entryID
+	_, err = a.db.Exec(`INSERT INTO day_plans (day, log_entry_id, blocks) VALUES (?, ?, ?)
+		ON CONFLICT(day) DO UPDATE SET log_entry_id = excluded.log_entry_id, blocks = excluded.blocks, reconciled = 0`,
+		plan.Date, entryID, string(blocks))
+	if err != nil {
+		return fmt.Errorf("failed to save day plan: %v", err)
+	}
+	return nil
+}
+
+// reconcileDayPlans adds the actual time spent to the notes of plans for days that have
+// ended: the minutes timed for each planned task, and the timers outside the plan
+func (a *App) reconcileDayPlans() error {
+	rows, err := a.db.Query(`SELECT day, log_entry_id, blocks FROM day_plans WHERE reconciled = 0 AND day < ?`, a.dayKey(a.clock.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to query day plans: %v", err)
+	}
+	type pending struct {
+		day     string
+		entryID int64
+		blocks  string
+	}
+	var plans []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.day, &p.entryID, &p.blocks); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan day plan: %v", err)
+		}
+		plans = append(plans, p)
+	}
+	rows.Close()
+
+	for _, p := range plans {
+		if err := a.reconcileDayPlan(p.day, p.entryID, p.blocks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *App) reconcileDayPlan(date string, entryID int64, encoded string) error {
+	var blocks []PlanBlock
+	if err := json.Unmarshal([]byte(encoded), &blocks); err != nil {
+		return fmt.Errorf("failed to decode day plan %s: %v", date, err)
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid day plan date %s: %v", date, err)
+	}
+	spans, err := a.timerSpans(a.dayStart(day), a.dayStart(day.AddDate(0, 0, 1)))
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nActuals:\n")
+	counted := make([]bool, len(spans))
+	for i, block := range blocks {
+		if block.Kind != entryTypeTask {
+			continue
+		}
+		actual := 0.0
+		for j, span := range spans {
+			if span.TaskID != nil && *span.TaskID == block.TaskID || span.TaskID == nil && sameTaskTitle(span.Label, block.Title) {
+				actual += span.Minutes
+				counted[j] = true
+			}
+		}
+		blocks[i].ActualMinutes = &actual
+		fmt.Fprintf(&b, "- %s: ~%s planned, %s\n", block.Title, formatMeetingDuration(time.Duration(block.Minutes)*time.Minute), formatActualMinutes(actual))
+	}
+	for j, span := range spans {
+		if !counted[j] {
+			fmt.Fprintf(&b, "- %s: unplanned, %s\n", span.Label, formatActualMinutes(span.Minutes))
+		}
+	}
+
+	if entry, err := a.GetEntryByID(int(entryID)); err == nil {
+		if err := a.UpdateEntry(entry.ID, strings.TrimSpace(entry.Content+strings.TrimRight(b.String(), "\n"))); err != nil {
+			return err
+		}
+	}
+	updated, err := json.Marshal(blocks)
+	if err != nil {
+		return fmt.Errorf("failed to encode day plan: %v", err)
+	}
+	if _, err := a.db.Exec(`UPDATE day_plans SET blocks = ?, reconciled = 1 WHERE day = ?`, string(updated), date); err != nil {
+		return fmt.Errorf("failed to save day plan actuals: %v", err)
+	}
+	a.logf("Reconciled day plan %s with %d timers\n", date, len(spans))
+	return nil
+}
+
+// formatActualMinutes renders timed minutes as "50 min timed", or "not timed"
+func formatActualMinutes(minutes float64) string {
+	if minutes <= 0 {
+		return "not timed"
+	}
+	return formatMeetingDuration(time.Duration(math.Round(minutes))*time.Minute) + " timed"
+}
+
+// handleDayPlanAPI plans today: POST /api/plan/day
+func (a *App) handleDayPlanAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	plan, err := a.PlanDay()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
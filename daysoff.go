@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	dayOffSourceManual = "manual"
+	dayOffSourceICS    = "ics"
+	// maxDaysOffRange caps how many days one AddDaysOff call can mark
+	maxDaysOffRange = 366
+)
+
+// DayOff is a public holiday or a day of leave. Days off don't break the streak, count
+// the daily goal as met and silence reminders.
+type DayOff struct {
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+func (a *App) createDayOffTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS days_off (
+		day TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT 'manual'
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create days_off table: %v", err)
+	}
+	return nil
+}
+
+// AddDaysOff marks the days from from to to (both 2006-01-02, to defaults to from) as
+// days off, e.g. a week of vacation, and returns how many days were marked
+func (a *App) AddDaysOff(from, to, name string) (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+
+	first, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(from), time.Local)
+	if err != nil {
+		return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid date %q: use YYYY-MM-DD", from))
+	}
+	last := first
+	if strings.TrimSpace(to) != "" {
+		if last, err = time.ParseInLocation("2006-01-02", strings.TrimSpace(to), time.Local); err != nil {
+			return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid date %q: use YYYY-MM-DD", to))
+		}
+	}
+	if last.Before(first) || last.Sub(first) > maxDaysOffRange*24*time.Hour {
+		return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("days off must end after they start and span at most %d days", maxDaysOffRange))
+	}
+
+	count := 0
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		_, err := a.db.Exec(`INSERT INTO days_off (day, name, source) VALUES (?, ?, ?)
+			ON CONFLICT(day) DO UPDATE SET name = excluded.name, source = excluded.source`,
+			day.Format("2006-01-02"), strings.TrimSpace(name), dayOffSourceManual)
+		if err != nil {
+			return count, fmt.Errorf("failed to save day off: %v", err)
+		}
+		count++
+	}
+	a.logf("Marked %d days off from %s\n", count, first.Format("2006-01-02"))
+	return count, nil
+}
+
+// RemoveDayOff turns a day (2006-01-02) back into a working day
+func (a *App) RemoveDayOff(date string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	result, err := a.db.Exec(`DELETE FROM days_off WHERE day = ?`, strings.TrimSpace(date))
+	if err != nil {
+		return fmt.Errorf("failed to remove day off: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return newAPIError(codeNotFound, fmt.Sprintf("%s is not a day off", date))
+	}
+	return nil
+}
+
+// ImportHolidays marks the all-day events of an ICS calendar, a path or URL such as a
+// public holiday feed, as days off. Days already marked by hand keep their name.
+func (a *App) ImportHolidays(source string) (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+
+	r, err := openCalendar(strings.TrimSpace(source))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	events, err := parseICS(r)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, event := range events {
+		if !event.AllDay {
+			continue
+		}
+		for day := event.Start; day.Before(event.End) && day.Sub(event.Start) < maxDaysOffRange*24*time.Hour; day = day.AddDate(0, 0, 1) {
+			result, err := a.db.Exec(`INSERT INTO days_off (day, name, source) VALUES (?, ?, ?)
+				ON CONFLICT(day) DO UPDATE SET name = excluded.name WHERE days_off.source = excluded.source`,
+				day.Format("2006-01-02"), event.Summary, dayOffSourceICS)
+			if err != nil {
+				return count, fmt.Errorf("failed to save holiday: %v", err)
+			}
+			if n, _ := result.RowsAffected(); n > 0 {
+				count++
+			}
+		}
+	}
+	a.logf("Imported %d holidays from %s\n", count, source)
+	return count, nil
+}
+
+// GetDaysOff returns the days off from from to to (2006-01-02, either may be empty),
+// oldest first
+func (a *App) GetDaysOff(from, to string) ([]DayOff, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	query, args := `SELECT day, name, source FROM days_off WHERE 1 = 1`, []interface{}{}
+	if from = strings.TrimSpace(from); from != "" {
+		query, args = query+` AND day >= ?`, append(args, from)
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		query, args = query+` AND day <= ?`, append(args, to)
+	}
+	rows, err := a.db.Query(query+` ORDER BY day`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query days off: %v", err)
+	}
+	defer rows.Close()
+
+	days := []DayOff{}
+	for rows.Next() {
+		var day DayOff
+		if err := rows.Scan(&day.Date, &day.Name, &day.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan day off: %v", err)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// getDaysOff returns the set of days off (2006-01-02)
+func (a *App) getDaysOff() (map[string]bool, error) {
+	days, err := a.GetDaysOff("", "")
+	if err != nil {
+		return nil, err
+	}
+	off := make(map[string]bool, len(days))
+	for _, day := range days {
+		off[day.Date] = true
+	}
+	return off, nil
+}
+
+// isDayOff reports whether the logical day of t is a day off
+func (a *App) isDayOff(t time.Time) bool {
+	if a.db == nil {
+		return false
+	}
+	var count int
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM days_off WHERE day = ?`, a.dayKey(t)).Scan(&count); err != nil {
+		a.logf("Warning: failed to check for a day off: %v\n", err)
+		return false
+	}
+	return count > 0
+}
+
+// runDayOffCommand handles `/dayoff [<from> [<to>]] [name]`; without dates it marks today
+func (a *App) runDayOffCommand(args string) error {
+	words := strings.Fields(args)
+	var dates []string
+	for len(words) > 0 && len(dates) < 2 {
+		if _, err := time.Parse("2006-01-02", words[0]); err != nil {
+			break
+		}
+		dates, words = append(dates, words[0]), words[1:]
+	}
+	if len(dates) == 0 {
+		dates = append(dates, a.dayKey(a.clock.Now()))
+	}
+	if len(dates) == 1 {
+		dates = append(dates, "")
+	}
+
+	count, err := a.AddDaysOff(dates[0], dates[1], strings.Join(words, " "))
+	if err != nil {
+		return err
+	}
+	a.notify("SnapLog days off", fmt.Sprintf("%d days off from %s: streak and reminders will rest", count, dates[0]), priorityNormal)
+	return nil
+}
+
+// handleDaysOffAPI serves GET /api/days-off?from=&to=, POST /api/days-off with
+// {"from", "to", "name"} or {"ics": "<path or URL>"} and DELETE /api/days-off/{date}
+func (a *App) handleDaysOffAPI(w http.ResponseWriter, r *http.Request) {
+	date := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/days-off"), "/")
+	switch {
+	case r.Method == http.MethodGet && date == "":
+		days, err := a.GetDaysOff(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, days)
+	case r.Method == http.MethodPost && date == "":
+		var req struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+			Name string `json:"name"`
+			ICS  string `json:"ics"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		var count int
+		var err error
+		if req.ICS != "" {
+			count, err = a.ImportHolidays(req.ICS)
+		} else {
+			count, err = a.AddDaysOff(req.From, req.To, req.Name)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "days": count})
+	case r.Method == http.MethodDelete && date != "":
+		if err := a.RemoveDayOff(date); err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,667 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"modernc.org/sqlite/vfs"
+)
+
+const (
+	// encryptedDatabaseSuffix is added to the database path while the database is encrypted
+	encryptedDatabaseSuffix = ".enc"
+	// unlockedDatabaseURI is where an unlocked database lives: an in-memory database shared
+	// by every connection of the process, so the plaintext never reaches the disk
+	unlockedDatabaseURI = "file:/snaplog.db?vfs=memdb&_pragma=busy_timeout(5000)"
+	// databaseSaveInterval is how often changes to an unlocked database are written back
+	databaseSaveInterval  = 5 * time.Second
+	minDatabasePassphrase = 8
+)
+
+// databaseMagic starts an encrypted database file, which is databaseMagic, a 16-byte
+// salt, the nonce and the ciphertext
+var databaseMagic = []byte("SNAPLOGDB1")
+
+// plainBackupPattern matches the unencrypted copies of the database in the backups
+// folder: regular backups, and snapshots such as snaplog-<time>-before-clear.db
+var plainBackupPattern = regexp.MustCompile(`^snaplog-.*\.db$`)
+
+// DatabaseEncryption tells the settings window whether the database is encrypted and
+// still waiting for its passphrase
+type DatabaseEncryption struct {
+	Encrypted bool   `json:"encrypted"`
+	Locked    bool   `json:"locked"`
+	Path      string `json:"path"`
+}
+
+// databaseVault holds the key of an unlocked encrypted database and the connection that
+// keeps its in-memory copy alive
+type databaseVault struct {
+	mu    sync.Mutex
+	conn  *sql.Conn
+	salt  []byte
+	key   []byte
+	saved [sha256.Size]byte
+	saver sync.Once
+	// unlocking is held by UnlockDatabase from checking a.db until the services are
+	// started, so an unlock from the window and one from the API can't both start them
+	unlocking sync.Mutex
+}
+
+// serializingConn is the part of the modernc driver connection that copies a database
+// into memory
+type serializingConn interface {
+	Serialize() ([]byte, error)
+}
+
+func (v *databaseVault) unlocked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.conn != nil
+}
+
+// setPassphrase derives a new key under a fresh salt
+func (v *databaseVault) setPassphrase(passphrase string) error {
+	salt, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	v.salt, v.key, v.saved = salt, key, [sha256.Size]byte{}
+	return nil
+}
+
+// checkPassphrase reports whether passphrase is the one the database is encrypted with
+func (v *databaseVault) checkPassphrase(passphrase string) error {
+	key, err := passphraseKey(passphrase, v.salt)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, v.key) != 1 {
+		return newAPIError(codeInvalidRequest, "wrong passphrase")
+	}
+	return nil
+}
+
+func (v *databaseVault) seal(plain []byte) ([]byte, error) {
+	sealed, err := sealWithKey(v.key, plain, databaseMagic)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(append([]byte{}, databaseMagic...), v.salt...), sealed...), nil
+}
+
+// open decrypts a file sealed under the current key, such as a backup written since the
+// passphrase was last changed
+func (v *databaseVault) open(data []byte) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.conn == nil {
+		return nil, newAPIError(codeInvalidRequest, "unlock the database to read its encrypted backups")
+	}
+	salt, sealed, err := splitEncryptedDatabase(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(salt, v.salt) {
+		return nil, fmt.Errorf("encrypted with an earlier passphrase")
+	}
+	plain, err := openWithKey(v.key, sealed, databaseMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: damaged file")
+	}
+	return plain, nil
+}
+
+// serialize returns the unlocked database as the bytes of an SQLite file
+func (v *databaseVault) serialize() ([]byte, error) {
+	if v.conn == nil {
+		return nil, newAPIError(codeInvalidRequest, "the database is not encrypted")
+	}
+	var plain []byte
+	err := v.conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(serializingConn)
+		if !ok {
+			return fmt.Errorf("database driver does not support serialization")
+		}
+		var err error
+		plain, err = c.Serialize()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize database: %v", err)
+	}
+	return plain, nil
+}
+
+func splitEncryptedDatabase(data []byte) ([]byte, []byte, error) {
+	if !bytes.HasPrefix(data, databaseMagic) {
+		return nil, nil, fmt.Errorf("not an encrypted SnapLog database")
+	}
+	data = data[len(databaseMagic):]
+	if len(data) < 16 {
+		return nil, nil, fmt.Errorf("encrypted database is truncated")
+	}
+	return data[:16], data[16:], nil
+}
+
+// databaseEncrypted reports whether the database at path has been encrypted
+func databaseEncrypted(path string) bool {
+	_, err := os.Stat(path + encryptedDatabaseSuffix)
+	return err == nil
+}
+
+// plainDatabasePath is where the database lives unencrypted, and with
+// encryptedDatabaseSuffix added when encrypted
+func plainDatabasePath() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snaplog.db"), nil
+}
+
+// GetDatabaseEncryption reports whether the database is encrypted and locked
+func (a *App) GetDatabaseEncryption() DatabaseEncryption {
+	path, err := plainDatabasePath()
+	if err != nil {
+		return DatabaseEncryption{}
+	}
+	encrypted := databaseEncrypted(path)
+	return DatabaseEncryption{Encrypted: encrypted, Locked: encrypted && a.db == nil, Path: a.GetDatabasePath()}
+}
+
+// snapshotFS serves a serialized database to SQLite, through a read-only VFS, as the
+// file snapshot.db
+type snapshotFS []byte
+
+// snapshotFile is snapshot.db, and its own FileInfo
+type snapshotFile struct {
+	*bytes.Reader
+}
+
+func (s snapshotFS) Open(name string) (fs.File, error) {
+	if filepath.Base(name) != "snapshot.db" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return snapshotFile{bytes.NewReader(s)}, nil
+}
+
+func (f snapshotFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f snapshotFile) Close() error               { return nil }
+func (f snapshotFile) Name() string               { return "snapshot.db" }
+func (f snapshotFile) Mode() fs.FileMode          { return 0444 }
+func (f snapshotFile) ModTime() time.Time         { return time.Time{} }
+func (f snapshotFile) IsDir() bool                { return false }
+func (f snapshotFile) Sys() interface{}           { return nil }
+
+// openSnapshot registers a VFS serving data and returns the URI SQLite opens it by.
+// Closing the returned VFS unregisters it.
+func openSnapshot(data []byte) (string, *vfs.FS, error) {
+	name, fsys, err := vfs.New(snapshotFS(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open snapshot: %v", err)
+	}
+	return "file:snapshot.db?mode=ro&vfs=" + name, fsys, nil
+}
+
+// verifySnapshot checks the integrity of a serialized database without writing it to
+// disk and reports how many entries it holds
+func verifySnapshot(data []byte) (int, error) {
+	uri, fsys, err := openSnapshot(data)
+	if err != nil {
+		return 0, err
+	}
+	defer fsys.Close()
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %v", err)
+	}
+	defer db.Close()
+	return checkRestoredDatabase(db)
+}
+
+// openUnlockedDatabase loads plain into the shared in-memory database and pins a
+// connection to it, as the in-memory database is dropped with its last connection.
+// The caller holds a.vault.mu.
+func (a *App) openUnlockedDatabase(plain []byte) (*sql.DB, error) {
+	db, err := a.openDatabase(unlockedDatabaseURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	uri, fsys, err := openSnapshot(plain)
+	if err == nil {
+		err = conn.Raw(func(driverConn interface{}) error {
+			c, ok := driverConn.(sqliteConn)
+			if !ok {
+				return fmt.Errorf("database driver does not support online backups")
+			}
+			restore, err := c.NewRestore(uri)
+			if err != nil {
+				return err
+			}
+			if _, err := restore.Step(-1); err != nil {
+				restore.Finish()
+				return err
+			}
+			return restore.Finish()
+		})
+		fsys.Close()
+	}
+	if err != nil {
+		conn.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to load decrypted database: %v", err)
+	}
+
+	a.vault.conn = conn
+	a.vault.saved = sha256.Sum256(plain)
+	return db, nil
+}
+
+// UnlockDatabase decrypts the database with passphrase, asked for on startup, and starts
+// everything that waited for it
+func (a *App) UnlockDatabase(passphrase string) error {
+	a.vault.unlocking.Lock()
+	defer a.vault.unlocking.Unlock()
+	if a.db != nil {
+		return nil
+	}
+	path, err := plainDatabasePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path + encryptedDatabaseSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to read encrypted database: %v", err)
+	}
+	salt, sealed, err := splitEncryptedDatabase(data)
+	if err != nil {
+		return err
+	}
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	plain, err := openWithKey(key, sealed, databaseMagic)
+	if err != nil {
+		return newAPIError(codeInvalidRequest, "wrong passphrase")
+	}
+
+	a.vault.mu.Lock()
+	a.vault.salt, a.vault.key = salt, key
+	db, err := a.openUnlockedDatabase(plain)
+	a.vault.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	a.db = db
+	if err := a.createTables(); err != nil {
+		return fmt.Errorf("failed to create tables: %v", err)
+	}
+	if err := a.loadSettingsSecrets(); err != nil {
+		return err
+	}
+	// secrets set in settings.json while the database was locked move into it
+	if err := a.saveSettings(); err != nil {
+		a.logf("Warning: failed to save settings: %v\n", err)
+	}
+
+	a.logf("Encrypted database unlocked: %s\n", path+encryptedDatabaseSuffix)
+	a.vault.saver.Do(func() { go a.runDatabaseSaver() })
+	a.startServices()
+	return nil
+}
+
+// EncryptDatabase encrypts the database with passphrase. From then on SnapLog asks for
+// the passphrase on startup and keeps the decrypted database in memory only.
+func (a *App) EncryptDatabase(passphrase string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	if a.vault.unlocked() {
+		return newAPIError(codeInvalidRequest, "the database is already encrypted")
+	}
+	if len(passphrase) < minDatabasePassphrase {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("the passphrase must be at least %d characters", minDatabasePassphrase))
+	}
+	path, err := plainDatabasePath()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %v", err)
+	}
+	// The write lock is held until the encrypted copy has replaced the database, so an
+	// entry from the hotkey, the API or the coalescer fails with "database is locked"
+	// instead of landing in the file that is about to be deleted
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to lock database: %v", err)
+	}
+	defer func() {
+		conn.ExecContext(ctx, "ROLLBACK")
+		conn.Close()
+	}()
+	var plain []byte
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(serializingConn)
+		if !ok {
+			return fmt.Errorf("database driver does not support serialization")
+		}
+		plain, err = c.Serialize()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read database: %v", err)
+	}
+
+	a.vault.mu.Lock()
+	if err := a.vault.setPassphrase(passphrase); err != nil {
+		a.vault.mu.Unlock()
+		return err
+	}
+	db, err := a.openUnlockedDatabase(plain)
+	if err == nil {
+		a.vault.saved = [sha256.Size]byte{}
+		if err = a.writeEncryptedDatabase(); err != nil {
+			a.vault.conn.Close()
+			a.vault.conn = nil
+			db.Close()
+		}
+	}
+	a.vault.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// closing the old handle first makes anything still holding it fail rather than write
+	// once the lock is released
+	previous := a.db
+	a.db = db
+	a.closeReadOnlyDB()
+	previous.Close()
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			a.logf("Warning: failed to remove unencrypted database %s: %v\n", path+suffix, err)
+		}
+	}
+
+	a.logf("Database encrypted: %s\n", path+encryptedDatabaseSuffix)
+	if err := a.saveSettings(); err != nil {
+		a.logf("Warning: failed to move settings secrets into the encrypted database: %v\n", err)
+	}
+	a.encryptPlainBackups()
+	a.vault.saver.Do(func() { go a.runDatabaseSaver() })
+	return nil
+}
+
+// DecryptDatabase turns encryption off again, writing the database back to disk
+// unencrypted, and the secrets kept in it back to settings.json
+func (a *App) DecryptDatabase(passphrase string) error {
+	if err := a.decryptDatabase(passphrase); err != nil {
+		return err
+	}
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("database decrypted, but failed to save settings: %v", err)
+	}
+	if _, err := a.db.Exec(`DELETE FROM settings_secrets`); err != nil {
+		a.logf("Warning: failed to remove settings secrets from the database: %v\n", err)
+	}
+	return nil
+}
+
+func (a *App) decryptDatabase(passphrase string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	a.vault.mu.Lock()
+	defer a.vault.mu.Unlock()
+	if a.vault.conn == nil {
+		return newAPIError(codeInvalidRequest, "the database is not encrypted")
+	}
+	if err := a.vault.checkPassphrase(passphrase); err != nil {
+		return err
+	}
+	path, err := plainDatabasePath()
+	if err != nil {
+		return err
+	}
+
+	plain, err := a.vault.serialize()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, plain, 0600); err != nil {
+		return fmt.Errorf("failed to write database: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write database: %v", err)
+	}
+	db, err := a.openDatabase(path)
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+
+	previous := a.db
+	a.db = db
+	a.closeReadOnlyDB()
+	a.vault.conn.Close()
+	a.vault.conn, a.vault.key, a.vault.salt = nil, nil, nil
+	previous.Close()
+	if err := os.Remove(path + encryptedDatabaseSuffix); err != nil {
+		a.logf("Warning: failed to remove encrypted database: %v\n", err)
+	}
+
+	a.logf("Database decrypted: %s\n", path)
+	return nil
+}
+
+// ChangeDatabasePassphrase encrypts the database again under a new passphrase. Backups
+// written before keep the passphrase they were written with.
+func (a *App) ChangeDatabasePassphrase(current, passphrase string) error {
+	a.vault.mu.Lock()
+	defer a.vault.mu.Unlock()
+	if a.vault.conn == nil {
+		return newAPIError(codeInvalidRequest, "the database is not encrypted")
+	}
+	if err := a.vault.checkPassphrase(current); err != nil {
+		return err
+	}
+	if len(passphrase) < minDatabasePassphrase {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("the passphrase must be at least %d characters", minDatabasePassphrase))
+	}
+	salt, key := a.vault.salt, a.vault.key
+	if err := a.vault.setPassphrase(passphrase); err != nil {
+		return err
+	}
+	if err := a.writeEncryptedDatabase(); err != nil {
+		a.vault.salt, a.vault.key = salt, key
+		return err
+	}
+	a.logf("Database passphrase changed\n")
+	return nil
+}
+
+// writeEncryptedDatabase encrypts the in-memory database to disk when it changed since
+// it was last written. The caller holds a.vault.mu.
+func (a *App) writeEncryptedDatabase() error {
+	if a.vault.conn == nil {
+		return nil
+	}
+	plain, err := a.vault.serialize()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(plain)
+	if sum == a.vault.saved {
+		return nil
+	}
+	sealed, err := a.vault.seal(plain)
+	if err != nil {
+		return err
+	}
+
+	path, err := plainDatabasePath()
+	if err != nil {
+		return err
+	}
+	path += encryptedDatabaseSuffix
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write encrypted database: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write encrypted database: %v", err)
+	}
+	a.vault.saved = sum
+	return nil
+}
+
+// saveEncryptedDatabase writes pending changes of an unlocked database to disk
+func (a *App) saveEncryptedDatabase() error {
+	a.vault.mu.Lock()
+	defer a.vault.mu.Unlock()
+	return a.writeEncryptedDatabase()
+}
+
+// runDatabaseSaver writes an unlocked database back every databaseSaveInterval, so at
+// most a few seconds of entries are lost if SnapLog is killed
+func (a *App) runDatabaseSaver() {
+	ticks, stop := a.clock.Ticker(databaseSaveInterval)
+	defer stop()
+
+	for range ticks {
+		if err := a.saveEncryptedDatabase(); err != nil {
+			a.logf("Warning: failed to save encrypted database: %v\n", err)
+		}
+	}
+}
+
+// closeEncryptedDatabase writes an unlocked database back a last time on shutdown
+func (a *App) closeEncryptedDatabase() {
+	a.vault.mu.Lock()
+	defer a.vault.mu.Unlock()
+	if a.vault.conn == nil {
+		return
+	}
+	if err := a.writeEncryptedDatabase(); err != nil {
+		a.logf("Warning: failed to save encrypted database: %v\n", err)
+	}
+	a.vault.conn.Close()
+	a.vault.conn = nil
+}
+
+// writeEncryptedBackup writes a verified backup of an unlocked database to path, sealed
+// like the database itself, so the plaintext never reaches the disk. A backup is
+// restored by copying it over the encrypted database and unlocking it with the
+// passphrase it was written with.
+func (a *App) writeEncryptedBackup(path string) (int, error) {
+	a.vault.mu.Lock()
+	defer a.vault.mu.Unlock()
+	plain, err := a.vault.serialize()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := verifySnapshot(plain)
+	if err != nil {
+		return 0, fmt.Errorf("backup verification failed: %v", err)
+	}
+	sealed, err := a.vault.seal(plain)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, sealed, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write backup: %v", err)
+	}
+	return entries, nil
+}
+
+// encryptPlainBackups encrypts the backups and snapshots taken before the database was
+// encrypted, which would otherwise keep the log readable on disk, and deletes the
+// unencrypted copies. A backup that can't be encrypted is left alone and reported in the
+// log file.
+func (a *App) encryptPlainBackups() {
+	dir, err := backupDir()
+	if err != nil {
+		a.logf("Warning: failed to find backups to encrypt: %v\n", err)
+		return
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		a.logf("Warning: failed to find backups to encrypt: %v\n", err)
+		return
+	}
+
+	encrypted := 0
+	for _, f := range files {
+		if f.IsDir() || !plainBackupPattern.MatchString(f.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		if err := a.encryptBackupFile(path); err != nil {
+			a.logf("Warning: backup %s is still unencrypted: %v\n", path, err)
+			continue
+		}
+		encrypted++
+	}
+	if encrypted > 0 {
+		a.logf("Encrypted %d earlier backups\n", encrypted)
+	}
+}
+
+// encryptBackupFile replaces an unencrypted backup with one encrypted under the database key
+func (a *App) encryptBackupFile(path string) error {
+	plain, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	a.vault.mu.Lock()
+	sealed, err := a.vault.seal(plain)
+	a.vault.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+encryptedDatabaseSuffix, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// promptForPassphrase shows the window with the passphrase prompt of an encrypted
+// database
+func (a *App) promptForPassphrase() {
+	time.Sleep(500 * time.Millisecond)
+	a.ShowWindow()
+	wailsRuntime.EventsEmit(a.ctx, "show-unlock-prompt")
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const syncRevokedFile = "sync-revoked.json"
+
+// syncDevicePattern matches device files: sync-device-<device>.json
+var syncDevicePattern = regexp.MustCompile(`^sync-device-([0-9a-f]+)\.json$`)
+
+// SyncDevice is a device taking part in sync, as seen from this one
+type SyncDevice struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Fingerprint string     `json:"fingerprint"`
+	LastSeen    *time.Time `json:"last_seen"`
+	Revoked     bool       `json:"revoked"`
+	Current     bool       `json:"current"`
+}
+
+// syncDeviceFile is rewritten by each device on every sync. In an encrypted folder it also
+// carries the device's batch keys, sealed with the master key.
+type syncDeviceFile struct {
+	Device       string    `json:"device"`
+	Name         string    `json:"name"`
+	Fingerprint  string    `json:"fingerprint"`
+	LastSeen     time.Time `json:"last_seen"`
+	Key          []byte    `json:"key,omitempty"`
+	PreviousKeys [][]byte  `json:"previous_keys,omitempty"`
+}
+
+// syncRevocations is the shared list of revoked device ids. In an encrypted folder it is
+// stored sealed with the master key so the provider cannot edit it.
+type syncRevocations struct {
+	Devices []string `json:"devices"`
+	Payload []byte   `json:"payload,omitempty"`
+}
+
+// syncDeviceName is the configured device name, or the host name
+func (a *App) syncDeviceName() string {
+	if a.settings.Sync.DeviceName != "" {
+		return a.settings.Sync.DeviceName
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "unknown"
+}
+
+func syncDeviceFileName(device string) string {
+	return "sync-device-" + device + ".json"
+}
+
+// publishDevice uploads this device's name, key and last sync time for the other devices
+func (a *App) publishDevice(s *syncSession) error {
+	file := syncDeviceFile{Device: s.device, Name: a.syncDeviceName(), LastSeen: a.clock.Now().UTC()}
+	if s.encrypted() {
+		if err := s.sealDeviceFile(&file, s.deviceKey, s.previousKeys); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode device file: %v", err)
+	}
+	if err := s.store.upload(syncDeviceFileName(s.device), data); err != nil {
+		return fmt.Errorf("failed to upload device file: %v", err)
+	}
+	return nil
+}
+
+// refreshDevices records the other devices' names and last sync times and, in an
+// encrypted folder, unseals their batch keys
+func (a *App) refreshDevices(s *syncSession) error {
+	names, err := s.store.list()
+	if err != nil {
+		return fmt.Errorf("failed to list sync folder: %v", err)
+	}
+	revoked, err := a.revokedDevices()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		m := syncDevicePattern.FindStringSubmatch(name)
+		if m == nil || m[1] == s.device || revoked[m[1]] {
+			continue
+		}
+		data, err := s.store.download(name)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %v", name, err)
+		}
+		var file syncDeviceFile
+		if err := json.Unmarshal(data, &file); err != nil || file.Device != m[1] {
+			a.logf("Warning: ignoring unreadable device file %s\n", name)
+			continue
+		}
+		if s.encrypted() {
+			key, previous, err := s.openDeviceFile(file)
+			if err != nil {
+				return err
+			}
+			keys := map[string][]byte{file.Fingerprint: key}
+			for _, old := range previous {
+				keys[keyFingerprint(old)] = old
+			}
+			s.peerKeys[file.Device] = keys
+		}
+
+		if _, err := a.db.Exec(`INSERT INTO sync_peers (device_id, name, fingerprint, last_seen) VALUES (?, ?, ?, ?)
+			ON CONFLICT(device_id) DO UPDATE SET name = excluded.name, fingerprint = excluded.fingerprint, last_seen = excluded.last_seen`,
+			file.Device, file.Name, file.Fingerprint, sqlTime(file.LastSeen)); err != nil {
+			return fmt.Errorf("failed to record sync device: %v", err)
+		}
+	}
+	return nil
+}
+
+// ListDevices returns this device first, then the other devices sharing the sync folder
+func (a *App) ListDevices() ([]SyncDevice, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	current := SyncDevice{ID: a.syncState("device_id"), Name: a.syncDeviceName(), Current: true}
+	if key, err := hex.DecodeString(a.syncState("device_key")); err == nil && len(key) > 0 {
+		current.Fingerprint = keyFingerprint(key)
+	}
+	if last, err := time.Parse(time.RFC3339, a.syncState("last_sync")); err == nil {
+		current.LastSeen = &last
+	}
+	devices := []SyncDevice{current}
+
+	rows, err := a.db.Query(`SELECT device_id, name, fingerprint, last_seen, revoked_at IS NOT NULL
+		FROM sync_peers ORDER BY revoked_at IS NOT NULL, last_seen DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync devices: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var device SyncDevice
+		var lastSeen sql.NullTime
+		if err := rows.Scan(&device.ID, &device.Name, &device.Fingerprint, &lastSeen, &device.Revoked); err != nil {
+			return nil, fmt.Errorf("failed to scan sync device: %v", err)
+		}
+		if lastSeen.Valid {
+			device.LastSeen = &lastSeen.Time
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+func (a *App) revokedDevices() (map[string]bool, error) {
+	rows, err := a.db.Query(`SELECT device_id FROM sync_peers WHERE revoked_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revoked devices: %v", err)
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan revoked device: %v", err)
+		}
+		revoked[id] = true
+	}
+	return revoked, nil
+}
+
+func (a *App) markDeviceRevoked(id string) error {
+	_, err := a.db.Exec(`INSERT INTO sync_peers (device_id, revoked_at) VALUES (?, CURRENT_TIMESTAMP)
+		ON CONFLICT(device_id) DO UPDATE SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke device: %v", err)
+	}
+	return nil
+}
+
+// RevokeDevice stops accepting changes from a device. The revocation reaches the other
+// devices with the next sync. In an encrypted sync folder the revoked device knows the
+// master key and the passphrase, so both are replaced: newPassphrase becomes the sync
+// passphrase, which the other devices then need too, and new recovery codes are returned.
+func (a *App) RevokeDevice(id, newPassphrase string) ([]string, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if id == "" || id == a.syncState("device_id") {
+		return nil, fmt.Errorf("this device cannot revoke itself")
+	}
+	if a.settings.Sync.Passphrase == "" {
+		if err := a.markDeviceRevoked(id); err != nil {
+			return nil, err
+		}
+		a.logf("Revoked sync device %s\n", id)
+		return nil, nil
+	}
+	if newPassphrase == "" || newPassphrase == a.settings.Sync.Passphrase {
+		return nil, fmt.Errorf("choose a new sync passphrase, the revoked device knows the current one")
+	}
+
+	store, err := newRemoteStore(a.settings.Sync.Target)
+	if err != nil {
+		return nil, err
+	}
+	device, err := a.syncDeviceID()
+	if err != nil {
+		return nil, err
+	}
+
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+	session, err := a.openSyncSession(store, device)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.markDeviceRevoked(id); err != nil {
+		return nil, err
+	}
+	codes, err := a.rotateSyncMaster(session, newPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	a.logf("Revoked sync device %s and rotated the sync master key\n", id)
+	return codes, nil
+}
+
+// rotateSyncMaster replaces the sync folder's master key after a device was revoked. The
+// new key is sealed with passphrase and new recovery codes, which are returned; the keys
+// of the remaining devices and the revocation list are sealed with it; and this device
+// moves to a new batch key. The other devices move to new batch keys as they follow.
+func (a *App) rotateSyncMaster(s *syncSession, passphrase string) ([]string, error) {
+	revoked, err := a.revokedDevices()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.loadRevocations()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range remote {
+		if id == s.device {
+			return nil, fmt.Errorf("this device has been revoked from sync")
+		}
+		revoked[id] = true
+	}
+
+	// unseal the remaining devices' keys with the old master key
+	type peerFile struct {
+		file     syncDeviceFile
+		key      []byte
+		previous [][]byte
+	}
+	var peers []peerFile
+	names, err := s.store.list()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync folder: %v", err)
+	}
+	for _, name := range names {
+		m := syncDevicePattern.FindStringSubmatch(name)
+		if m == nil || m[1] == s.device {
+			continue
+		}
+		if revoked[m[1]] {
+			s.store.remove(name)
+			continue
+		}
+		data, err := s.store.download(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %v", name, err)
+		}
+		var file syncDeviceFile
+		if err := json.Unmarshal(data, &file); err != nil || file.Device != m[1] {
+			a.logf("Warning: ignoring unreadable device file %s\n", name)
+			continue
+		}
+		key, previous, err := s.openDeviceFile(file)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, peerFile{file: file, key: key, previous: previous})
+	}
+
+	master, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	keyring := &syncKeyring{Version: syncKeyringVersion}
+	if err := keyring.sealMaster(master, passphrase); err != nil {
+		return nil, err
+	}
+	codes, err := keyring.sealRecoveryCodes(master)
+	if err != nil {
+		return nil, err
+	}
+	s.master = master
+
+	for _, peer := range peers {
+		if err := s.sealDeviceFile(&peer.file, peer.key, peer.previous); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(peer.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode device file: %v", err)
+		}
+		if err := s.store.upload(syncDeviceFileName(peer.file.Device), data); err != nil {
+			return nil, fmt.Errorf("failed to upload device file: %v", err)
+		}
+	}
+	if err := a.rotateDeviceKey(s); err != nil {
+		return nil, err
+	}
+	if err := a.publishDevice(s); err != nil {
+		return nil, err
+	}
+	union := make([]string, 0, len(revoked))
+	for id := range revoked {
+		union = append(union, id)
+	}
+	sort.Strings(union)
+	if err := s.saveRevocations(union); err != nil {
+		return nil, err
+	}
+	if err := saveSyncKeyring(s.store, keyring); err != nil {
+		return nil, err
+	}
+
+	if err := a.setSyncState("master_fingerprint", keyFingerprint(master)); err != nil {
+		return nil, err
+	}
+	a.settings.Sync.Passphrase = passphrase
+	if err := a.saveSettings(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// exchangeRevocations merges the shared revocation list with the local one, uploads the
+// union when this device knows of new revocations, and stops if this device was revoked
+func (a *App) exchangeRevocations(s *syncSession) error {
+	remote, err := s.loadRevocations()
+	if err != nil {
+		return err
+	}
+	local, err := a.revokedDevices()
+	if err != nil {
+		return err
+	}
+
+	shared := make(map[string]bool, len(remote))
+	for _, id := range remote {
+		if id == s.device {
+			return fmt.Errorf("this device has been revoked from sync")
+		}
+		shared[id] = true
+		if !local[id] {
+			if err := a.markDeviceRevoked(id); err != nil {
+				return err
+			}
+			a.logf("Device %s was revoked by another device\n", id)
+		}
+	}
+
+	var added []string
+	for id := range local {
+		if !shared[id] {
+			added = append(added, id)
+			shared[id] = true
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	union := make([]string, 0, len(shared))
+	for id := range shared {
+		union = append(union, id)
+	}
+	sort.Strings(union)
+	if err := s.saveRevocations(union); err != nil {
+		return err
+	}
+	// a revoked device's published key is no longer needed by anyone
+	for _, id := range added {
+		s.store.remove(syncDeviceFileName(id))
+	}
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *syncSession) loadRevocations() ([]string, error) {
+	names, err := s.store.list()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync folder: %v", err)
+	}
+	if !containsString(names, syncRevokedFile) {
+		return nil, nil
+	}
+
+	data, err := s.store.download(syncRevokedFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download revoked devices: %v", err)
+	}
+	var list syncRevocations
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode revoked devices: %v", err)
+	}
+	if !s.encrypted() {
+		return list.Devices, nil
+	}
+
+	plain, err := openWithKey(s.master, list.Payload, []byte(syncRevokedFile))
+	if err != nil {
+		return nil, fmt.Errorf("revoked devices list is not sealed with this sync folder's master key")
+	}
+	var devices []string
+	if err := json.Unmarshal(plain, &devices); err != nil {
+		return nil, fmt.Errorf("failed to decode revoked devices: %v", err)
+	}
+	return devices, nil
+}
+
+func (s *syncSession) saveRevocations(devices []string) error {
+	list := syncRevocations{Devices: devices}
+	if s.encrypted() {
+		plain, err := json.Marshal(devices)
+		if err != nil {
+			return fmt.Errorf("failed to encode revoked devices: %v", err)
+		}
+		sealed, err := sealWithKey(s.master, plain, []byte(syncRevokedFile))
+		if err != nil {
+			return err
+		}
+		list = syncRevocations{Payload: sealed}
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode revoked devices: %v", err)
+	}
+	if err := s.store.upload(syncRevokedFile, data); err != nil {
+		return fmt.Errorf("failed to upload revoked devices: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// latestBatch returns the name and sequence number of device's newest batch in the folder
+func latestBatch(t *testing.T, dav *fakeDAV, device string) (string, int64) {
+	t.Helper()
+	dav.mu.Lock()
+	defer dav.mu.Unlock()
+	name, seq := "", int64(0)
+	for file := range dav.files {
+		m := syncBatchPattern.FindStringSubmatch(file)
+		if m == nil || m[1] != device {
+			continue
+		}
+		var n int64
+		fmt.Sscan(m[2], &n)
+		if n > seq {
+			name, seq = file, n
+		}
+	}
+	if name == "" {
+		t.Fatalf("no batch from device %s", device)
+	}
+	return name, seq
+}
+
+func TestRevokeDeviceRotatesSyncMasterKey(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local)
+	dav, target := newFakeDAV(t)
+	a, _, _ := newFakeApp(t, now)
+	b, _, _ := newFakeApp(t, now)
+	c, _, _ := newFakeApp(t, now)
+	for i, x := range []*App{a, b, c} {
+		x.settings.Sync = SyncSettings{Enabled: true, Passphrase: "first", DeviceName: fmt.Sprint("device ", i), Target: target}
+	}
+	if _, err := c.logEntry("written on c", "test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []*App{a, b, c, a, b} {
+		if err := x.SyncNow(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// everything c knows when it is revoked: the master key and the other devices' keys
+	store, err := newRemoteStore(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid, bid := c.syncState("device_id"), b.syncState("device_id")
+	stolen, err := c.openSyncSession(store, cid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.refreshDevices(stolen); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.RevokeDevice(cid, "first"); err == nil {
+		t.Fatal("revoking with the passphrase the revoked device knows should fail")
+	}
+	codes, err := a.RevokeDevice(cid, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != syncRecoveryCodes {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), syncRecoveryCodes)
+	}
+	if err := c.SyncNow(); err == nil {
+		t.Fatal("the old passphrase still unlocks the sync folder")
+	}
+
+	b.settings.Sync.Passphrase = "second"
+	if _, err := b.logEntry("written on b after the revocation", "test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []*App{b, a} {
+		if err := x.SyncNow(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := a.GetLogEntries(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, entry := range entries {
+		got[entry.Content] = true
+	}
+	if len(entries) != 2 || !got["written on c"] || !got["written on b after the revocation"] {
+		t.Fatalf("a has %+v, want b's new entry and c's old one", entries)
+	}
+
+	name, seq := latestBatch(t, dav, bid)
+	if _, err := stolen.decode(bid, seq, dav.File(name)); err == nil {
+		t.Fatal("the revoked device can read a batch written after the revocation")
+	}
+
+	// a revocation list sealed with the old master key is refused, and c stays revoked
+	if err := stolen.saveRevocations(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SyncNow(); err == nil {
+		t.Fatal("a revocation list sealed by the revoked device was accepted")
+	}
+	revoked, err := a.revokedDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !revoked[cid] {
+		t.Fatal("c is no longer revoked")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HealthStatus is served by GET /api/health
+type HealthStatus struct {
+	Status        string     `json:"status"`
+	Database      string     `json:"database"`
+	DiskFreeBytes uint64     `json:"disk_free_bytes"`
+	LastBackup    *time.Time `json:"last_backup"`
+	Sync          string     `json:"sync"`
+	CheckedAt     time.Time  `json:"checked_at"`
+}
+
+// SelfTestCheck is one line of the self-test checklist
+type SelfTestCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// SelfTestReport is returned by RunSelfTest for the settings UI
+type SelfTestReport struct {
+	Passed bool            `json:"passed"`
+	Checks []SelfTestCheck `json:"checks"`
+	RanAt  time.Time       `json:"ran_at"`
+}
+
+func snaplogDataDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %v", err)
+	}
+	return filepath.Join(configDir, "snaplog"), nil
+}
+
+// lastBackupTime returns the modification time of the newest file in the backups folder
+func lastBackupTime() *time.Time {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return nil
+	}
+	files, err := os.ReadDir(filepath.Join(dir, "backups"))
+	if err != nil {
+		return nil
+	}
+
+	var latest *time.Time
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || f.IsDir() {
+			continue
+		}
+		modTime := info.ModTime()
+		if latest == nil || modTime.After(*latest) {
+			latest = &modTime
+		}
+	}
+	return latest
+}
+
+func (a *App) checkDatabase() error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	var one int
+	if err := a.db.QueryRow(`SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("database query failed: %v", err)
+	}
+	return nil
+}
+
+func (a *App) GetHealth() *HealthStatus {
+	health := &HealthStatus{
+		Status:     "ok",
+		Database:   "ok",
+		LastBackup: lastBackupTime(),
+		Sync:       a.syncHealth(),
+		CheckedAt:  a.clock.Now(),
+	}
+
+	if err := a.checkDatabase(); err != nil {
+		health.Status = "degraded"
+		health.Database = err.Error()
+	}
+
+	if dir, err := snaplogDataDir(); err == nil {
+		if free, err := diskFreeBytes(dir); err == nil {
+			health.DiskFreeBytes = free
+		}
+	}
+
+	return health
+}
+
+// handleHealthAPI reports basic health: GET /api/health
+func (a *App) handleHealthAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	health := a.GetHealth()
+	status := http.StatusOK
+	if health.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+// RunSelfTest exercises hotkey registration, notifications, database writes and the dashboard server
+func (a *App) RunSelfTest() *SelfTestReport {
+	report := &SelfTestReport{RanAt: a.clock.Now()}
+	add := func(name string, err error, okMessage string) {
+		check := SelfTestCheck{Name: name, OK: err == nil, Message: okMessage}
+		if err != nil {
+			check.Message = err.Error()
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	var hotkeyErr error
+	if a.packageHotkey == nil {
+		hotkeyErr = fmt.Errorf("global hotkey is not registered (on macOS, check accessibility permissions)")
+	}
+	add("Hotkey registration", hotkeyErr, fmt.Sprintf("%v+%v registered", a.settings.HotkeyModifiers, a.settings.HotkeyKey))
+
+	add("Notifications", a.notifier.Notify("SnapLog", "Self-test notification"), "Test notification sent")
+
+	add("Database writes", a.testDatabaseWrite(), "Insert and rollback succeeded")
+
+	add("Dashboard server", a.testDashboardServer(), fmt.Sprintf("Reachable on port %d", a.dashboardPort))
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		if !check.OK {
+			report.Passed = false
+		}
+	}
+
+	a.logf("Self-test finished (passed: %v)\n", report.Passed)
+	return report
+}
+
+func (a *App) testDatabaseWrite() error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO log_entries (content) VALUES (?)`, "self-test"); err != nil {
+		return fmt.Errorf("failed to insert test entry: %v", err)
+	}
+	return nil
+}
+
+func (a *App) testDashboardServer() error {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/api/health", a.dashboardPort))
+	if err != nil {
+		return fmt.Errorf("dashboard server unreachable: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// spellcheckOff in spellcheck_language turns the capture window's spellcheck off; an
+// empty language follows the system language
+const spellcheckOff = "off"
+
+var spellcheckLanguagePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+const maxDictionaryWordLength = 64
+
+// SpellcheckDictionary is what the capture window's spellcheck needs: the language to
+// check against and the words the user added
+type SpellcheckDictionary struct {
+	Language string   `json:"language"`
+	Enabled  bool     `json:"enabled"`
+	Words    []string `json:"words"`
+}
+
+func (a *App) createDictionaryTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS dictionary_words (
+		word TEXT PRIMARY KEY COLLATE NOCASE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create dictionary table: %v", err)
+	}
+	return nil
+}
+
+func validateSpellcheckLanguage(language string) error {
+	if language != "" && language != spellcheckOff && !spellcheckLanguagePattern.MatchString(language) {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("invalid spellcheck language tag: %s", language))
+	}
+	return nil
+}
+
+func normalizeDictionaryWord(word string) (string, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return "", fmt.Errorf("word cannot be empty")
+	}
+	if len(word) > maxDictionaryWordLength {
+		return "", fmt.Errorf("word is longer than %d characters", maxDictionaryWordLength)
+	}
+	if strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("add one word at a time")
+	}
+	return word, nil
+}
+
+// GetDictionary returns the spellcheck language and the added words in alphabetical order
+func (a *App) GetDictionary() (*SpellcheckDictionary, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	dict := &SpellcheckDictionary{Language: a.settings.SpellcheckLanguage, Words: []string{}}
+	dict.Enabled = dict.Language != spellcheckOff
+	if !dict.Enabled {
+		dict.Language = ""
+	}
+
+	rows, err := a.db.Query(`SELECT word FROM dictionary_words ORDER BY word`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dictionary: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, fmt.Errorf("failed to scan dictionary word: %v", err)
+		}
+		dict.Words = append(dict.Words, word)
+	}
+	return dict, nil
+}
+
+// AddDictionaryWord adds a word the spellcheck should accept. Adding a word that differs
+// only in case keeps the existing spelling.
+func (a *App) AddDictionaryWord(word string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	word, err := normalizeDictionaryWord(word)
+	if err != nil {
+		return newAPIError(codeInvalidRequest, err.Error())
+	}
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO dictionary_words (word) VALUES (?)`, word); err != nil {
+		return fmt.Errorf("failed to add dictionary word: %v", err)
+	}
+	return nil
+}
+
+// RemoveDictionaryWord removes an added word, ignoring case
+func (a *App) RemoveDictionaryWord(word string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	result, err := a.db.Exec(`DELETE FROM dictionary_words WHERE word = ?`, strings.TrimSpace(word))
+	if err != nil {
+		return fmt.Errorf("failed to remove dictionary word: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return newAPIError(codeNotFound, fmt.Sprintf("word not in dictionary: %s", word))
+	}
+	return nil
+}
+
+// SetSpellcheckLanguage sets the language tag to check against, e.g. "en-GB". Empty
+// follows the system language and "off" disables spellcheck.
+func (a *App) SetSpellcheckLanguage(language string) error {
+	language = strings.TrimSpace(language)
+	if err := validateSpellcheckLanguage(language); err != nil {
+		return err
+	}
+
+	a.settings.SpellcheckLanguage = language
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save settings: %v", err)
+	}
+	return nil
+}
+
+// handleDictionaryAPI serves the spellcheck dictionary:
+// GET /api/dictionary, POST /api/dictionary {"word"} or {"language"},
+// DELETE /api/dictionary/{word}
+func (a *App) handleDictionaryAPI(w http.ResponseWriter, r *http.Request) {
+	word := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/dictionary"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && word == "":
+		dict, err := a.GetDictionary()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, dict)
+	case r.Method == http.MethodPost && word == "":
+		var req struct {
+			Word     string  `json:"word"`
+			Language *string `json:"language"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		if req.Word == "" && req.Language == nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "word or language is required")
+			return
+		}
+		if req.Language != nil {
+			if err := a.SetSpellcheckLanguage(*req.Language); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+				return
+			}
+		}
+		if req.Word != "" {
+			if err := a.AddDictionaryWord(req.Word); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	case r.Method == http.MethodDelete && word != "":
+		if err := a.RemoveDictionaryWord(word); err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// Densities for dashboard_density; empty is comfortable
+const (
+	dashboardDensityComfortable = "comfortable"
+	dashboardDensityCompact     = "compact"
+)
+
+// dashboard_font_scale is a percentage of the normal text size; 0 leaves it at 100
+const (
+	minDashboardFontScale = 75
+	maxDashboardFontScale = 200
+)
+
+func validateDashboardDisplay(fontScale int, density string) error {
+	if fontScale != 0 && (fontScale < minDashboardFontScale || fontScale > maxDashboardFontScale) {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("font scale must be between %d%% and %d%%", minDashboardFontScale, maxDashboardFontScale))
+	}
+	switch density {
+	case "", dashboardDensityComfortable, dashboardDensityCompact:
+		return nil
+	default:
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown dashboard density %q: use %s or %s", density, dashboardDensityComfortable, dashboardDensityCompact))
+	}
+}
+
+// dashboardFontScale is the font scale templates set on the page, or 0 when the text is
+// left at its normal size
+func (a *App) dashboardFontScale() int {
+	if a.settings.DashboardFontScale == 100 {
+		return 0
+	}
+	return a.settings.DashboardFontScale
+}
+
+// dashboardDensity is the density templates mark the page with, or empty for the
+// comfortable spacing the stylesheets have by default
+func (a *App) dashboardDensity() string {
+	if a.settings.DashboardDensity == dashboardDensityCompact {
+		return dashboardDensityCompact
+	}
+	return ""
+}
+
+// pdfDisplay sizes a PDF export like the dashboard: text scaled by the font scale and
+// lines set closer together when the dashboard is compact
+func (a *App) pdfDisplay(pdf *pdfWriter) *pdfWriter {
+	if scale := a.dashboardFontScale(); scale != 0 {
+		pdf.scale = float64(scale) / 100
+	}
+	if a.dashboardDensity() == dashboardDensityCompact {
+		pdf.leading = 1.15
+	}
+	return pdf
+}
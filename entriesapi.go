@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultEntriesPageSize = 50
+	maxEntriesPageSize     = 500
+)
+
+// EntriesPage is returned by GET /api/entries. NextCursor is empty on the last page.
+type EntriesPage struct {
+	Entries    []LogEntry `json:"entries"`
+	Total      int        `json:"total"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+	NextCursor string     `json:"next_cursor"`
+}
+
+// entryCursor encodes the position after entry for keyset pagination, which stays stable
+// while new entries are logged
+func entryCursor(entry LogEntry) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(sqlTime(entry.CreatedAt) + "|" + strconv.Itoa(entry.ID)))
+}
+
+func parseEntryCursor(cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	createdAt, idText, ok := strings.Cut(string(raw), "|")
+	id, err := strconv.Atoi(idText)
+	if !ok || err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	return createdAt, id, nil
+}
+
+// queryEntriesPage returns the newest entries matching filters, starting at cursor when
+// one is given and at offset otherwise
+func (a *App) queryEntriesPage(filters SearchFilters, limit, offset int, cursor string) (*EntriesPage, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	where, args, err := filters.where(a.today(), a.dayStart, a.settings.SearchTrigram)
+	if err != nil {
+		return nil, newAPIError(codeInvalidRequest, err.Error())
+	}
+
+	page := &EntriesPage{Entries: []LogEntry{}, Limit: limit, Offset: offset}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM log_entries WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count log entries: %v", err)
+	}
+
+	if cursor != "" {
+		createdAt, id, err := parseEntryCursor(cursor)
+		if err != nil {
+			return nil, newAPIError(codeInvalidRequest, err.Error())
+		}
+		where += ` AND (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, createdAt, createdAt, id)
+		offset, page.Offset = 0, 0
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE ` + where + ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := a.db.Query(query, append(args, limit+1, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log entries: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %v", err)
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+
+	// the extra row only tells whether there is a next page
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		page.NextCursor = entryCursor(page.Entries[limit-1])
+	}
+	return page, nil
+}
+
+// handleEntriesAPI lists entries: GET /api/entries with ?limit, ?offset or ?cursor, and
+// the /dash/search filters ?q, ?tag, ?person, ?type, ?source, ?from and ?to.
+// POST /api/entries logs one entry.
+func (a *App) handleEntriesAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		a.handleCreateEntry(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset := defaultEntriesPageSize, 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxEntriesPageSize {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxEntriesPageSize))
+			return
+		}
+		limit = n
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "offset must be 0 or more")
+			return
+		}
+		offset = n
+	}
+
+	if a.notModified(w, r) {
+		return
+	}
+
+	page, err := a.queryEntriesPage(parseSearchFilters(query), limit, offset, query.Get("cursor"))
+	if err != nil {
+		apiErr := asAPIError(err)
+		status := http.StatusInternalServerError
+		if apiErr.Code == codeInvalidRequest {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, apiErr)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleUpdateEntry serves PATCH (or PUT) /api/entries/{id} with {"content": "..."}.
+// Tags, tasks and people are re-processed as for an edit in the capture window.
+func (a *App) handleUpdateEntry(w http.ResponseWriter, r *http.Request, id int) {
+	var req struct {
+		Content *string `json:"content"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+	if req.Content == nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "content is required")
+		return
+	}
+	if _, err := a.queryEntryByID(r.Context(), id); err != nil {
+		writeError(w, http.StatusNotFound, codeNotFound, fmt.Sprintf("Entry not found: %d", id))
+		return
+	}
+
+	if err := a.updateEntry(r.Context(), id, strings.TrimSpace(*req.Content)); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	entry, err := a.queryEntryByID(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to load entry: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "entry": entry})
+	a.logf("Entry %d updated via API\n", id)
+}
+
+// handleCreateEntry serves POST /api/entries with {"content": "...", "created_at": "..."}.
+// created_at is optional (RFC 3339 or a date like "yesterday 3pm"), as is journal; the
+// entry goes through the same tag, task and hook processing as one logged from the
+// capture window.
+func (a *App) handleCreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content   string `json:"content"`
+		CreatedAt string `json:"created_at"`
+		Journal   string `json:"journal"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	results, err := a.logEntryBatch(r.Context(), []BatchEntry{{Content: req.Content, CreatedAt: req.CreatedAt, Source: sourceAPI, Journal: req.Journal}})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to log entry: %v", err))
+		a.logf("Error logging entry via API: %v\n", err)
+		return
+	}
+	if !results[0].OK {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, results[0].Error)
+		return
+	}
+
+	entry, err := a.queryEntryByID(r.Context(), int(results[0].ID))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to load entry: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "entry": entry})
+	a.logf("Entry %d logged via API\n", entry.ID)
+}
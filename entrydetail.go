@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRelatedEntries = 5
+
+// entryRefPattern matches [[123]] references to another entry
+var entryRefPattern = regexp.MustCompile(`\[\[(\d+)\]\]`)
+
+// entryLinkPattern matches permalinks pasted into an entry, e.g. http://localhost:8080/dash/entry/123
+var entryLinkPattern = regexp.MustCompile(`/dash/entry/(\d+)\b`)
+
+// markdownImagePattern matches ![alt](target) images embedded in an entry
+var markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// EntryRevision is a previous version of an entry, saved when it is edited
+type EntryRevision struct {
+	Content  string    `json:"content"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// EntryAttachment is a file or image referenced from an entry
+type EntryAttachment struct {
+	Name   string
+	Target string
+}
+
+// RelatedEntry is an entry sharing tags or people with another entry
+type RelatedEntry struct {
+	Entry  DisplayEntry
+	Shared int
+}
+
+// EntryPageData holds everything rendered on /dash/entry/{id}
+type EntryPageData struct {
+	Entry       DisplayEntry
+	Tags        []string
+	People      []Person
+	Tasks       []Task
+	History     []EntryRevision
+	Backlinks   []DisplayEntry
+	Related     []RelatedEntry
+	Attachments []EntryAttachment
+}
+
+func (a *App) createEntryHistoryTables() error {
+	createHistorySQL := `
+	CREATE TABLE IF NOT EXISTS entry_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		log_entry_id INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		edited_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);`
+
+	if _, err := a.db.Exec(createHistorySQL); err != nil {
+		return fmt.Errorf("failed to create entry_history table: %v", err)
+	}
+	if _, err := a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_entry_history_entry ON entry_history(log_entry_id)`); err != nil {
+		return fmt.Errorf("failed to create entry_history index: %v", err)
+	}
+	return nil
+}
+
+// recordEntryRevision saves content as a previous version of the entry
+func (a *App) recordEntryRevision(ctx context.Context, entryID int, content string) error {
+	return insertEntryRevision(ctx, a.db, entryID, content)
+}
+
+// insertEntryRevision is recordEntryRevision inside a transaction
+func insertEntryRevision(ctx context.Context, db execer, entryID int, content string) error {
+	if _, err := db.ExecContext(ctx, `INSERT INTO entry_history (log_entry_id, content) VALUES (?, ?)`, entryID, content); err != nil {
+		return fmt.Errorf("failed to save entry history: %v", err)
+	}
+	return nil
+}
+
+// GetEntryHistory returns the previous versions of an entry, newest first
+func (a *App) GetEntryHistory(id int) ([]EntryRevision, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT content, edited_at FROM entry_history WHERE log_entry_id = ? ORDER BY id DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entry history: %v", err)
+	}
+	defer rows.Close()
+
+	var history []EntryRevision
+	for rows.Next() {
+		var rev EntryRevision
+		if err := rows.Scan(&rev.Content, &rev.EditedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entry history: %v", err)
+		}
+		history = append(history, rev)
+	}
+	return history, nil
+}
+
+// EntryURL returns the dashboard permalink for an entry
+func (a *App) EntryURL(id int) string {
+	return fmt.Sprintf("http://localhost:%d/dash/entry/%d", a.dashboardPort, id)
+}
+
+// linkEntryRefs turns [[123]] references into markdown links to the entry's page
+func linkEntryRefs(markdown string) string {
+	return entryRefPattern.ReplaceAllString(markdown, "[#$1](/dash/entry/$1)")
+}
+
+// referencesEntry reports whether content links to the entry with the given ID
+func referencesEntry(content string, id int) bool {
+	target := strconv.Itoa(id)
+	for _, pattern := range []*regexp.Regexp{entryRefPattern, entryLinkPattern} {
+		for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+			if match[1] == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func extractAttachments(content string) []EntryAttachment {
+	var attachments []EntryAttachment
+	for _, match := range markdownImagePattern.FindAllStringSubmatch(content, -1) {
+		attachments = append(attachments, EntryAttachment{Name: attachmentName(match[1], match[2]), Target: match[2]})
+	}
+	for _, match := range attachmentLinkPattern.FindAllStringSubmatch(content, -1) {
+		if match[1] == "" {
+			attachments = append(attachments, EntryAttachment{Name: attachmentName(match[2], match[3]), Target: match[3]})
+		}
+	}
+	return attachments
+}
+
+// getBacklinks returns entries that reference the entry with [[id]] or its permalink
+func (a *App) getBacklinks(id int) ([]DisplayEntry, error) {
+	query := `SELECT ` + entryColumns + ` FROM log_entries
+		WHERE id != ? AND deleted_at IS NULL AND (content LIKE ? OR content LIKE ?) ORDER BY created_at DESC`
+	rows, err := a.db.Query(query, id, fmt.Sprintf("%%[[%d]]%%", id), fmt.Sprintf("%%/dash/entry/%d%%", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backlinks: %v", err)
+	}
+	defer rows.Close()
+
+	var backlinks []DisplayEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backlink: %v", err)
+		}
+		// LIKE also matches longer IDs such as /dash/entry/123 for 12
+		if referencesEntry(entry.Content, id) {
+			backlinks = append(backlinks, a.toDisplayEntry(entry))
+		}
+	}
+	return backlinks, nil
+}
+
+// getRelatedEntries returns the entries sharing the most tags and people with the entry
+func (a *App) getRelatedEntries(id int) ([]RelatedEntry, error) {
+	query := `SELECT other, COUNT(*) AS shared FROM (
+			SELECT b.log_entry_id AS other FROM log_entries_tags a
+			JOIN log_entries_tags b ON b.tag_id = a.tag_id AND b.log_entry_id != a.log_entry_id
+			WHERE a.log_entry_id = ?
+			UNION ALL
+			SELECT b.log_entry_id AS other FROM log_entries_people a
+			JOIN log_entries_people b ON b.person_id = a.person_id AND b.log_entry_id != a.log_entry_id
+			WHERE a.log_entry_id = ?
+		) GROUP BY other ORDER BY shared DESC, other DESC LIMIT ?`
+	rows, err := a.db.Query(query, id, id, maxRelatedEntries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related entries: %v", err)
+	}
+
+	type match struct{ id, shared int }
+	var matches []match
+	for rows.Next() {
+		var m match
+		if err := rows.Scan(&m.id, &m.shared); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan related entry: %v", err)
+		}
+		matches = append(matches, m)
+	}
+	rows.Close()
+
+	var related []RelatedEntry
+	for _, m := range matches {
+		entry, err := a.GetEntryByID(m.id)
+		if err != nil {
+			continue
+		}
+		related = append(related, RelatedEntry{Entry: a.toDisplayEntry(*entry), Shared: m.shared})
+	}
+	return related, nil
+}
+
+func (a *App) getEntryPageData(id int) (*EntryPageData, error) {
+	entry, err := a.GetEntryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &EntryPageData{
+		Entry:       a.toDisplayEntry(*entry),
+		Tags:        a.entryTags(entry.Content),
+		People:      a.peopleDetails(extractPeople(entry.Content)),
+		Attachments: extractAttachments(entry.Content),
+	}
+	if data.Tasks, err = a.getEntryTasks(id); err != nil {
+		return nil, err
+	}
+	if data.History, err = a.GetEntryHistory(id); err != nil {
+		return nil, err
+	}
+	if data.Backlinks, err = a.getBacklinks(id); err != nil {
+		return nil, err
+	}
+	if data.Related, err = a.getRelatedEntries(id); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *App) serveEntryPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/dash/entry/"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	data, err := a.getEntryPageData(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Entry %d not found", id), http.StatusNotFound)
+		a.logf("Error loading entry %d: %v\n", id, err)
+		return
+	}
+
+	a.renderPage(w, "entry.html", fmt.Sprintf("Entry #%d", id), data)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Multi-step entry flows started by commands. The command emits "entry:<flow>-requested"
+// with an EntryFlowRequest and the input window finishes the flow, e.g. by calling
+// ConfirmDelete, so a new flow needs a name, a frontend handler and its finishing methods.
+// A prefill hands prepared text to the input window for review; it isn't about a stored
+// entry, so its ID is 0 and saving it logs a new entry.
+const (
+	flowEdit    = "edit"
+	flowDelete  = "delete"
+	flowPrefill = "prefill"
+)
+
+// EntryFlowRequest is the payload of an entry:<flow>-requested event
+type EntryFlowRequest struct {
+	Flow    string `json:"flow"`
+	ID      int    `json:"id"`
+	Content string `json:"content,omitempty"`
+	Preview string `json:"preview,omitempty"`
+}
+
+// entryFlows remembers the flow each entry is in, so ConfirmDelete only deletes what the
+// user was actually asked about. A new request for an entry replaces the old one.
+type entryFlows struct {
+	mu      sync.Mutex
+	pending map[int]string
+}
+
+// requestEntryFlow starts a flow and hands it to the input window
+func (a *App) requestEntryFlow(req EntryFlowRequest) error {
+	if req.ID != 0 {
+		a.flows.mu.Lock()
+		if a.flows.pending == nil {
+			a.flows.pending = make(map[int]string)
+		}
+		a.flows.pending[req.ID] = req.Flow
+		a.flows.mu.Unlock()
+	}
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "entry:"+req.Flow+"-requested", req)
+	}
+	return nil
+}
+
+// requestPrefill puts prepared text in the input window for the user to review and log
+func (a *App) requestPrefill(text string) error {
+	return a.requestEntryFlow(EntryFlowRequest{Flow: flowPrefill, Content: text})
+}
+
+// finishEntryFlow ends the entry's flow and reports whether it was in flow
+func (a *App) finishEntryFlow(flow string, id int) bool {
+	a.flows.mu.Lock()
+	defer a.flows.mu.Unlock()
+	if a.flows.pending[id] != flow {
+		return false
+	}
+	delete(a.flows.pending, id)
+	return true
+}
+
+// ConfirmDelete deletes an entry the user was asked about with /delete or /delprev
+func (a *App) ConfirmDelete(id int) error {
+	if !a.finishEntryFlow(flowDelete, id) {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("no delete is pending for entry %d", id))
+	}
+	return a.trashEntry(id)
+}
+
+// trashEntry moves an entry deleted from the input window to the trash and emits
+// "entry:trashed" with its preview, so the window can offer to undo the delete
+func (a *App) trashEntry(id int) error {
+	preview, err := a.GetEntryPreview(id)
+	if err != nil {
+		return err
+	}
+	if err := a.DeleteEntry(id); err != nil {
+		return err
+	}
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "entry:trashed", EntryFlowRequest{Flow: flowDelete, ID: id, Preview: preview})
+	}
+	return nil
+}
+
+// CancelDelete keeps the entry and ends its delete flow
+func (a *App) CancelDelete(id int) error {
+	a.finishEntryFlow(flowDelete, id)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// mergeSeparator goes between the texts of merged entries
+	mergeSeparator = "\n\n"
+	// maxMergeEntries caps how many entries one merge takes
+	maxMergeEntries = 100
+)
+
+// MergeEntries joins entries into the earliest of them and returns its ID. The texts are
+// joined oldest first with a blank line between them, so the merged entry carries the tags,
+// tasks and people of all of them; the others move to the trash and their attachments
+// move to the merged entry.
+func (a *App) MergeEntries(ids []int) (int, error) {
+	return a.mergeEntries(context.Background(), ids)
+}
+
+// mergeEntries is MergeEntries with ctx carrying the request ID for tracing
+func (a *App) mergeEntries(ctx context.Context, ids []int) (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+	if len(ids) < 2 || len(ids) > maxMergeEntries {
+		return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("merge between 2 and %d entries", maxMergeEntries))
+	}
+
+	entries := make([]*LogEntry, 0, len(ids))
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		if seen[id] {
+			return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("entry %d is listed twice", id))
+		}
+		seen[id] = true
+		entry, err := a.queryEntryByID(ctx, id)
+		if err != nil {
+			return 0, newAPIError(codeNotFound, fmt.Sprintf("entry %d not found", id))
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	texts := make([]string, len(entries))
+	for i, entry := range entries {
+		texts[i] = strings.TrimSpace(entry.Content)
+	}
+	merged := a.normalizePunctuation(a.ExpandShortcuts(strings.Join(texts, mergeSeparator)))
+	if len(merged) > maxEntryLength {
+		return 0, newAPIError(codeInvalidRequest, fmt.Sprintf("the merged entry would exceed the maximum length of %d characters", maxEntryLength))
+	}
+	kept := entries[0]
+
+	// the merged text and the trashing of the others land together, so a failure can't
+	// leave their text in two places
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin merge: %v", err)
+	}
+	defer tx.Rollback()
+	if err := insertEntryRevision(ctx, tx, kept.ID, kept.Content); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE log_entries SET content = ? WHERE id = ?`, merged, kept.ID); err != nil {
+		return 0, fmt.Errorf("failed to update merged entry: %v", err)
+	}
+	trashedAt := sqlTime(a.clock.Now())
+	for _, entry := range entries[1:] {
+		if _, err := tx.ExecContext(ctx, `UPDATE attachments SET log_entry_id = ? WHERE log_entry_id = ?`, kept.ID, entry.ID); err != nil {
+			return 0, fmt.Errorf("failed to move attachments of entry %d: %v", entry.ID, err)
+		}
+		result, err := tx.ExecContext(ctx, `UPDATE log_entries SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, trashedAt, entry.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to trash merged entry %d: %v", entry.ID, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return 0, newAPIError(codeNotFound, fmt.Sprintf("entry %d not found", entry.ID))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit merge: %v", err)
+	}
+
+	// tags, tasks and people follow the saved texts, as after an edit or a delete
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_tags WHERE log_entry_id = ?`, kept.ID); err != nil {
+		a.logf("Warning: failed to clear tags for entry %d: %v\n", kept.ID, err)
+	}
+	a.processEntryContent(ctx, int64(kept.ID), merged)
+	for _, entry := range entries[1:] {
+		a.unlinkTrashedEntry(ctx, entry.ID)
+	}
+
+	a.logf("Merged %d entries into entry %d\n", len(entries), kept.ID)
+	return kept.ID, nil
+}
+
+// SplitEntry cuts an entry at offsets, counted in characters from the start of its text,
+// and returns the IDs of the parts in order. The first part stays in the entry, which keeps
+// its edit history; the others become new entries with the same time, source and journal.
+// Tags, tasks and attachments go with the part they are in.
+func (a *App) SplitEntry(id int, offsets []int) ([]int, error) {
+	return a.splitEntry(context.Background(), id, offsets)
+}
+
+// splitEntry is SplitEntry with ctx carrying the request ID for tracing
+func (a *App) splitEntry(ctx context.Context, id int, offsets []int) ([]int, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	entry, err := a.queryEntryByID(ctx, id)
+	if err != nil {
+		return nil, newAPIError(codeNotFound, fmt.Sprintf("entry %d not found", id))
+	}
+
+	parts, err := splitAtOffsets(entry.Content, offsets)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.updateEntry(ctx, id, parts[0]); err != nil {
+		return nil, err
+	}
+
+	ids := []int{id}
+	for _, part := range parts[1:] {
+		result, err := a.db.ExecContext(ctx, `INSERT INTO log_entries (content, created_at, source, journal_id) VALUES (?, ?, ?, ?)`,
+			part, sqlTime(entry.CreatedAt), entry.Source, entry.JournalID)
+		if err != nil {
+			return ids, fmt.Errorf("failed to insert split entry: %v", err)
+		}
+		partID, err := result.LastInsertId()
+		if err != nil {
+			return ids, fmt.Errorf("failed to get split entry ID: %v", err)
+		}
+		if err := a.moveAttachments(ctx, id, partID, part); err != nil {
+			a.logf("Warning: failed to move attachments to entry %d: %v\n", partID, err)
+		}
+		a.processEntryContent(ctx, partID, part)
+		ids = append(ids, int(partID))
+	}
+
+	a.logf("Split entry %d into %d entries\n", id, len(ids))
+	return ids, nil
+}
+
+// splitAtOffsets cuts content at character offsets, which must be increasing and inside
+// the text, and trims each part. No part may be left empty.
+func splitAtOffsets(content string, offsets []int) ([]string, error) {
+	if len(offsets) == 0 {
+		return nil, newAPIError(codeInvalidRequest, "give at least one offset to split at")
+	}
+	runes := []rune(content)
+	var parts []string
+	start := 0
+	for _, offset := range append(append([]int{}, offsets...), len(runes)) {
+		if offset <= start || offset > len(runes) {
+			return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("offset %d is out of order or outside the entry's %d characters", offset, len(runes)))
+		}
+		part := strings.TrimSpace(string(runes[start:offset]))
+		if part == "" {
+			return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("splitting at %d would leave an empty entry", offset))
+		}
+		parts = append(parts, part)
+		start = offset
+	}
+	return parts, nil
+}
+
+// moveAttachments hands the attachments of entry from that are linked in content to entry to
+func (a *App) moveAttachments(ctx context.Context, from int, to int64, content string) error {
+	for _, match := range attachmentLinkPattern.FindAllStringSubmatch(content, -1) {
+		attachmentID := strings.TrimPrefix(match[3], "/attachments/")
+		if _, err := a.db.ExecContext(ctx, `UPDATE attachments SET log_entry_id = ? WHERE id = ? AND log_entry_id = ?`, to, attachmentID, from); err != nil {
+			return fmt.Errorf("failed to move attachment %s: %v", attachmentID, err)
+		}
+	}
+	return nil
+}
+
+// handleMergeEntries serves POST /api/entries/merge with {"ids": [12, 13]}
+func (a *App) handleMergeEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req struct {
+		IDs []int `json:"ids"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	id, err := a.mergeEntries(r.Context(), req.IDs)
+	if err != nil {
+		writeEntryEditError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "id": id})
+}
+
+// handleSplitEntry serves POST /api/entries/{id}/split with {"offsets": [120, 300]}
+func (a *App) handleSplitEntry(w http.ResponseWriter, r *http.Request, id int) {
+	var req struct {
+		Offsets []int `json:"offsets"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	ids, err := a.splitEntry(r.Context(), id, req.Offsets)
+	if err != nil {
+		writeEntryEditError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "ids": ids})
+}
+
+// writeEntryEditError answers a failed merge or split with 404 for a missing entry, 400
+// for a bad request and 500 otherwise
+func writeEntryEditError(w http.ResponseWriter, err error) {
+	apiErr := asAPIError(err)
+	status := http.StatusInternalServerError
+	switch apiErr.Code {
+	case codeNotFound:
+		status = http.StatusNotFound
+	case codeInvalidRequest:
+		status = http.StatusBadRequest
+	}
+	writeError(w, status, apiErr.Code, apiErr.Message)
+}
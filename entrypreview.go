@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// preview_length is how many characters of an entry its preview shows; 0 leaves it at
+// defaultPreviewLength
+const (
+	defaultPreviewLength = 100
+	minPreviewLength     = 20
+	maxPreviewLength     = 1000
+)
+
+func validatePreviewLength(length int) error {
+	if length != 0 && (length < minPreviewLength || length > maxPreviewLength) {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("preview length must be between %d and %d characters", minPreviewLength, maxPreviewLength))
+	}
+	return nil
+}
+
+// entryPreview shortens an entry for delete confirmations, search results, reminder
+// notifications and the undo toast, following preview_length and preview_first_line
+func (a *App) entryPreview(content string) string {
+	length := a.settings.PreviewLength
+	if length == 0 {
+		length = defaultPreviewLength
+	}
+	return previewText(content, length, a.settings.PreviewFirstLine)
+}
+
+// previewText collapses the whitespace of content and cuts it to at most length
+// characters, counted in runes so multi-byte text is never split. The cut falls on a word
+// boundary when there is one in the last quarter, and is marked with "…". With firstLine
+// only the first non-empty line is used.
+func previewText(content string, length int, firstLine bool) string {
+	if firstLine {
+		for _, line := range strings.Split(content, "\n") {
+			if strings.TrimSpace(line) != "" {
+				content = line
+				break
+			}
+		}
+	}
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	if len(runes) <= length {
+		return string(runes)
+	}
+
+	cut := length
+	for i := length; i > length*3/4; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[:cut]), " ,;:") + "…"
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxTemplateTasks = 20
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// defaultTemplates returns the entry templates available out of the box
+func defaultTemplates() map[string]string {
+	return map[string]string{
+		"standup": "Standup {{date}} #standup\n\nYesterday:\n{{yesterday}}\n\nToday:\n{{open_tasks}}\n\nMeetings:\n{{calendar_today}}",
+		"week":    "Recurring:\n- [ ] Review last week's log\n- [ ] Clear inbox",
+	}
+}
+
+func (a *App) templateText(name string) (string, bool) {
+	if text, ok := a.settings.Templates[name]; ok {
+		return text, true
+	}
+	if a.settings.Templates == nil {
+		text, ok := defaultTemplates()[name]
+		return text, ok
+	}
+	return "", false
+}
+
+// GetTemplateNames lists the configured entry templates
+func (a *App) GetTemplateNames() []string {
+	templates := a.settings.Templates
+	if templates == nil {
+		templates = defaultTemplates()
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandTemplate returns the named template with its placeholders resolved
+func (a *App) ExpandTemplate(name string) (string, error) {
+	text, ok := a.templateText(name)
+	if !ok {
+		return "", fmt.Errorf("unknown template: %s", name)
+	}
+	return a.expandPlaceholders(text), nil
+}
+
+// expandPlaceholders resolves {{date}}, {{weekday}}, {{last_entry}}, {{yesterday}},
+// {{open_tasks}} and {{calendar_today}}. Unknown placeholders are left as-is and a
+// placeholder whose data cannot be loaded expands to an empty string.
+func (a *App) expandPlaceholders(text string) string {
+	now := a.clock.Now()
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, err := a.placeholderValue(name, now)
+		if err != nil {
+			a.logf("Warning: failed to resolve placeholder %s: %v\n", name, err)
+			return ""
+		}
+		if value == nil {
+			return match
+		}
+		return *value
+	})
+}
+
+func (a *App) placeholderValue(name string, now time.Time) (*string, error) {
+	var value string
+	switch name {
+	case "date":
+		value = now.Format("2006-01-02")
+	case "weekday":
+		value = now.Format("Monday")
+	case "last_entry":
+		entry, err := a.GetMostRecentEntry()
+		if err != nil {
+			return nil, err
+		}
+		value = entry.Content
+	case "yesterday":
+		entries, err := a.previousDayEntries(now)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			lines = append(lines, "- "+strings.ReplaceAll(strings.TrimSpace(entry.Content), "\n", " "))
+		}
+		value = strings.Join(lines, "\n")
+	case "open_tasks":
+		tasks, err := a.GetOpenTasks(maxTemplateTasks)
+		if err != nil {
+			return nil, err
+		}
+		value = formatTaskList(tasks)
+	case "calendar_today":
+		events, err := a.calendarEvents(now)
+		if err != nil {
+			return nil, err
+		}
+		value = formatCalendarEvents(events)
+	default:
+		return nil, nil
+	}
+	return &value, nil
+}
+
+// previousDayEntries returns the entries of the most recent logged day before today,
+// so a Monday standup picks up Friday's work
+func (a *App) previousDayEntries(now time.Time) ([]LogEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	dayStart := a.dayStart(a.dayOf(now))
+
+	var last time.Time
+	err := a.db.QueryRow(`SELECT created_at FROM log_entries WHERE created_at < ? AND deleted_at IS NULL ORDER BY created_at DESC LIMIT 1`, sqlTime(dayStart)).Scan(&last)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous day: %v", err)
+	}
+
+	day := a.dayOf(last)
+	return a.entriesBetween(a.dayStart(day), a.dayStart(day.AddDate(0, 0, 1)))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error codes are stable: clients branch on them, while messages are for people and may change
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeDisabled             = "disabled"
+	codeInvalidPayload       = "invalid_payload"
+	codeIdempotencyKeyReused = "idempotency_key_reused"
+	codeDatabaseUnavailable  = "database_unavailable"
+	codeInternal             = "internal"
+	codeConfirmationRequired = "confirmation_required"
+)
+
+// APIError is the error envelope returned by HTTP handlers and, through the Wails error
+// formatter, by bound methods: {"code": ..., "message": ..., "details": {...}}
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func newAPIError(code, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+// withDetail adds a machine-readable detail such as an entry id
+func (e *APIError) withDetail(key string, value interface{}) *APIError {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+func errDatabaseUnavailable() error {
+	return newAPIError(codeDatabaseUnavailable, "database not initialized")
+}
+
+// asAPIError returns err's envelope; errors without one are reported as internal
+func asAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return newAPIError(codeInternal, err.Error())
+}
+
+// formatBoundError is the Wails ErrorFormatter, so the frontend receives the envelope
+// as the rejection value of a bound method
+func formatBoundError(err error) any {
+	return asAPIError(err)
+}
+
+// writeError writes the error envelope with an HTTP status
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, newAPIError(code, message))
+}
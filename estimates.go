@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// estimateWeeks is how many weeks of completed tasks the stats page compares
+	estimateWeeks = 8
+	// estimateTolerance is how far off an estimate can be and still count as on target
+	estimateTolerance  = 0.25
+	maxRecentEstimates = 10
+)
+
+// TaskEstimate is a completed task with a ~estimate and the time its timers ran.
+// Ratio is actual over estimate, so 1.5 means it took half again as long as planned.
+type TaskEstimate struct {
+	TaskID          int64     `json:"task_id"`
+	Text            string    `json:"text"`
+	CompletedAt     time.Time `json:"completed_at"`
+	EstimateMinutes int       `json:"estimate_minutes"`
+	ActualMinutes   float64   `json:"actual_minutes"`
+	Ratio           float64   `json:"ratio"`
+}
+
+// WeeklyEstimates sums the estimated and timed tasks completed in a week. OnTarget is
+// the share of them that took within 25% of their estimate.
+type WeeklyEstimates struct {
+	WeekOf          string  `json:"week_of"`
+	Tasks           int     `json:"tasks"`
+	EstimateMinutes int     `json:"estimate_minutes"`
+	ActualMinutes   float64 `json:"actual_minutes"`
+	Ratio           float64 `json:"ratio"`
+	OnTarget        float64 `json:"on_target"`
+}
+
+// EstimationStats compares estimates with timed actuals for each of the last weeks,
+// newest first, with the most recently completed tasks
+type EstimationStats struct {
+	Weeks  []WeeklyEstimates `json:"weeks"`
+	Recent []TaskEstimate    `json:"recent"`
+	Ratio  float64           `json:"ratio"`
+}
+
+// GetEstimationStats finds the tasks completed in the last weeks that had an estimate
+// and were timed, and compares the two per task and per week. Tasks without a timer
+// are left out, since there is nothing to compare.
+func (a *App) GetEstimationStats(weeks int) (*EstimationStats, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if weeks <= 0 {
+		weeks = estimateWeeks
+	}
+
+	thisMonday := a.dayStart(weekStart(a.today()))
+	from := thisMonday.AddDate(0, 0, -7*(weeks-1))
+	tasks, err := a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE done = 1 AND completed_at >= ?
+		ORDER BY completed_at DESC`, sqlTime(from))
+	if err != nil {
+		return nil, err
+	}
+	spans, err := a.timerSpans(time.Time{}, a.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &EstimationStats{Weeks: make([]WeeklyEstimates, weeks), Recent: []TaskEstimate{}}
+	onTarget := make([]int, weeks)
+	for i := range stats.Weeks {
+		stats.Weeks[i].WeekOf = thisMonday.AddDate(0, 0, -7*i).Format("2006-01-02")
+	}
+	var totalEstimate, totalActual float64
+	for _, task := range tasks {
+		estimate, ok := parseEstimate(task.Text)
+		if !ok || task.CompletedAt == nil {
+			continue
+		}
+		actual := 0.0
+		for _, span := range spans {
+			if span.TaskID != nil && *span.TaskID == task.ID || span.TaskID == nil && sameTaskTitle(span.Label, task.Text) {
+				actual += span.Minutes
+			}
+		}
+		if actual = math.Round(actual); actual <= 0 {
+			continue
+		}
+
+		measured := TaskEstimate{TaskID: task.ID, Text: task.Text, CompletedAt: *task.CompletedAt,
+			EstimateMinutes: int(estimate.Minutes()), ActualMinutes: actual}
+		measured.Ratio = actual / estimate.Minutes()
+		if len(stats.Recent) < maxRecentEstimates {
+			stats.Recent = append(stats.Recent, measured)
+		}
+
+		i := int(thisMonday.Sub(a.dayStart(weekStart(a.dayOf(measured.CompletedAt)))).Hours()+12) / (24 * 7)
+		if i < 0 || i >= weeks {
+			continue
+		}
+		week := &stats.Weeks[i]
+		week.Tasks++
+		week.EstimateMinutes += measured.EstimateMinutes
+		week.ActualMinutes += measured.ActualMinutes
+		if math.Abs(measured.Ratio-1) <= estimateTolerance {
+			onTarget[i]++
+		}
+		totalEstimate += estimate.Minutes()
+		totalActual += actual
+	}
+
+	for i := range stats.Weeks {
+		if week := &stats.Weeks[i]; week.Tasks > 0 {
+			week.Ratio = week.ActualMinutes / float64(week.EstimateMinutes)
+			week.OnTarget = float64(onTarget[i]) / float64(week.Tasks)
+		}
+	}
+	if totalEstimate > 0 {
+		stats.Ratio = totalActual / totalEstimate
+	}
+	return stats, nil
+}
+
+// Summary sums up the overall ratio, e.g. "Tasks take 1.4× their estimate"
+func (s *EstimationStats) Summary() string {
+	switch {
+	case s.Ratio == 0:
+		return "No estimated tasks were timed and completed yet."
+	case math.Abs(s.Ratio-1) <= estimateTolerance/2:
+		return fmt.Sprintf("Tasks take %.1f× their estimate: your estimates are on target.", s.Ratio)
+	case s.Ratio > 1:
+		return fmt.Sprintf("Tasks take %.1f× their estimate: consider padding estimates.", s.Ratio)
+	default:
+		return fmt.Sprintf("Tasks take %.1f× their estimate: you're faster than you think.", s.Ratio)
+	}
+}
+
+// handleEstimatesAPI serves GET /api/stats/estimates?weeks=8
+func (a *App) handleEstimatesAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	weeks := estimateWeeks
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 52 {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("invalid weeks: %s", value))
+			return
+		}
+		weeks = parsed
+	}
+
+	stats, err := a.GetEstimationStats(weeks)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,18 @@
+package main
+
+import "context"
+
+// entryCreated fans a newly logged entry out to the configured integrations
+func (a *App) entryCreated(ctx context.Context, entryID int64) {
+	entry, err := a.queryEntryByID(ctx, int(entryID))
+	if err != nil {
+		a.logf("Warning: failed to load created entry %d: %v\n", entryID, err)
+		return
+	}
+
+	a.recordReminderResponse()
+	go a.publishHomeAssistantEvent("entry_created", entry)
+	a.runScriptHooks(hookEntryCreated, entry)
+	go a.checkStorageQuota()
+	go a.notifyCapture(entry)
+}
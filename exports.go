@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinExporters render entries into a downloadable file, keyed by export format.
+// Plugins can add more formats; built-in ones take precedence.
+var builtinExporters = map[string]func(a *App, entries []LogEntry) (string, string, error){
+	"pivot-csv": func(a *App, entries []LogEntry) (string, string, error) {
+		content, err := a.pivotCSV(entries)
+		return content, "snaplog-pivot.csv", err
+	},
+	"json": func(a *App, entries []LogEntry) (string, string, error) {
+		export, err := a.buildJSONExport(entries)
+		if err != nil {
+			return "", "", err
+		}
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encode export: %v", err)
+		}
+		return string(data) + "\n", "snaplog.json", nil
+	},
+}
+
+// pivotCSV writes one row per entry and tag (or one untagged row) in long format for
+// spreadsheet pivot tables. duration_minutes is the time until the next entry on the
+// same day, left empty when the next entry is more than stretchGap away.
+func (a *App) pivotCSV(entries []LogEntry) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"entry_id", "date", "weekday", "hour", "tag", "source", "entry_type", "words", "duration_minutes"})
+
+	for i, entry := range entries {
+		local := entry.CreatedAt.Local()
+
+		duration := ""
+		if i+1 < len(entries) {
+			next := entries[i+1].CreatedAt.Local()
+			if gap := next.Sub(local); gap <= stretchGap && a.dayKey(next) == a.dayKey(local) {
+				duration = strconv.FormatFloat(gap.Minutes(), 'f', 1, 64)
+			}
+		}
+
+		tags := spanTags(a.entryTagSpans(entry.ID))
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			w.Write([]string{
+				strconv.Itoa(entry.ID),
+				a.dayKey(local),
+				local.Format("Mon"),
+				strconv.Itoa(local.Hour()),
+				tag,
+				entry.Source,
+				entry.EntryType,
+				strconv.Itoa(len(strings.Fields(entry.Content))),
+				duration,
+			})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// exportEntries renders entries with a built-in exporter or, failing that, a plugin
+func (a *App) exportEntries(format string, entries []LogEntry) (string, string, error) {
+	if exporter, ok := builtinExporters[format]; ok {
+		return exporter(a, entries)
+	}
+	return a.exportWithPlugin(format, entries)
+}
+
+// ExportPivotCSV returns every entry as a long-format CSV for pivot tables
+func (a *App) ExportPivotCSV() (string, error) {
+	entries, err := a.entriesBetween(time.Time{}, a.clock.Now().AddDate(1, 0, 0))
+	if err != nil {
+		return "", err
+	}
+	return a.pivotCSV(entries)
+}
+
+// handleExportAPI downloads all entries in a built-in or plugin format: GET /api/export/{format}.
+// With ?strip_tags=true the entry text is exported without its tags.
+func (a *App) handleExportAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	format := strings.TrimPrefix(r.URL.Path, "/api/export/")
+	entries, err := a.entriesBetween(time.Time{}, a.clock.Now().AddDate(1, 0, 0))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to load entries: %v", err))
+		return
+	}
+	if r.URL.Query().Get("strip_tags") == "true" {
+		for i, entry := range entries {
+			entries[i].Content = stripTagSpans(entry.Content, a.entryTagSpans(entry.ID))
+		}
+	}
+
+	content, filename, err := a.exportEntries(format, entries)
+	if err != nil {
+		writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if strings.HasSuffix(filename, ".csv") {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else if strings.HasSuffix(filename, ".json") {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	io.WriteString(w, content)
+
+	a.runScriptHooks(hookExport, map[string]interface{}{
+		"format":      format,
+		"filename":    filename,
+		"entry_count": len(entries),
+		"content":     content,
+	})
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.design/x/hotkey"
+)
+
+// Fake seams for tests: install them on an App from NewApp to drive time, hotkeys and
+// notifications deterministically, e.g. a.clock = newFakeClock(start).
+
+// newFakeApp returns an App with an empty database in a temporary directory, a fake clock
+// standing at now and a notifier that records what it is asked to show
+func newFakeApp(t *testing.T, now time.Time) (*App, *fakeClock, *fakeNotifier) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"HOME", "XDG_CONFIG_HOME", "APPDATA"} {
+		t.Setenv(name, dir)
+	}
+
+	a := NewApp()
+	clock, notifier := newFakeClock(now), &fakeNotifier{}
+	a.clock, a.notifier, a.browser, a.hotkeys = clock, notifier, &fakeBrowser{}, &fakeHotkeys{}
+	// the real Focus state of the machine running the tests must not hold notifications back
+	a.settings.IgnoreFocusMode = true
+
+	db, err := a.openDatabase(filepath.Join(dir, "snaplog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	a.db = db
+	if err := a.createTables(); err != nil {
+		t.Fatal(err)
+	}
+	return a, clock, notifier
+}
+
+// fakeClock stands still until Advance is called, which also fires any due tickers
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	period  time.Duration
+	next    time.Time
+	ch      chan time.Time
+	stopped bool
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{period: d, next: c.now.Add(d), ch: make(chan time.Time)}
+	c.tickers = append(c.tickers, t)
+	return t.ch, func() {
+		c.mu.Lock()
+		t.stopped = true
+		c.mu.Unlock()
+	}
+}
+
+// WaitForTickers blocks until n tickers have been created, so a test can start a scheduler
+// goroutine and know it is listening before calling Advance
+func (c *fakeClock) WaitForTickers(n int) {
+	for {
+		c.mu.Lock()
+		created := len(c.tickers)
+		c.mu.Unlock()
+		if created >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Advance moves the clock forward by d. Each tick is delivered before Advance returns, so
+// the scheduler reading the ticker has started handling it.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var due *fakeTicker
+		for _, t := range c.tickers {
+			if !t.stopped && !t.next.After(target) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.period)
+		now := c.now
+		c.mu.Unlock()
+
+		due.ch <- now
+	}
+}
+
+// fakeNotifier records notifications instead of showing them
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []PendingNotification
+	// answer is the action key NotifyActions picks, as if the user had clicked it
+	answer string
+}
+
+func (n *fakeNotifier) Notify(title, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, PendingNotification{Title: title, Message: message})
+	return nil
+}
+
+func (n *fakeNotifier) NotifyActions(title, message string, actions []notificationAction) (string, error) {
+	n.Notify(title, message)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.answer, nil
+}
+
+// Answer sets the action key NotifyActions picks from now on
+func (n *fakeNotifier) Answer(key string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.answer = key
+}
+
+// WaitForSent blocks until n notifications have been sent, for those the App shows from
+// a goroutine
+func (n *fakeNotifier) WaitForSent(count int) {
+	for len(n.Sent()) < count {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (n *fakeNotifier) Sent() []PendingNotification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]PendingNotification(nil), n.sent...)
+}
+
+// fakeBrowser records the URLs and files it was asked to open
+type fakeBrowser struct {
+	mu     sync.Mutex
+	opened []string
+}
+
+func (b *fakeBrowser) Open(urlOrPath string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opened = append(b.opened, urlOrPath)
+	return nil
+}
+
+func (b *fakeBrowser) Opened() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.opened...)
+}
+
+// fakeHotkeys hands out hotkeys that are pressed by calling Press and Release
+type fakeHotkeys struct {
+	mu         sync.Mutex
+	registered []*fakeHotkey
+}
+
+type fakeHotkey struct {
+	Modifiers []hotkey.Modifier
+	Key       hotkey.Key
+	keydown   chan hotkey.Event
+	keyup     chan hotkey.Event
+	once      sync.Once
+}
+
+func (h *fakeHotkeys) Register(modifiers []hotkey.Modifier, key hotkey.Key) (globalHotkey, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hk := &fakeHotkey{Modifiers: modifiers, Key: key, keydown: make(chan hotkey.Event), keyup: make(chan hotkey.Event)}
+	h.registered = append(h.registered, hk)
+	return hk, nil
+}
+
+// Registered returns the hotkeys registered so far, in order
+func (h *fakeHotkeys) Registered() []*fakeHotkey {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*fakeHotkey(nil), h.registered...)
+}
+
+func (hk *fakeHotkey) Keydown() <-chan hotkey.Event { return hk.keydown }
+func (hk *fakeHotkey) Keyup() <-chan hotkey.Event   { return hk.keyup }
+
+func (hk *fakeHotkey) Unregister() error {
+	hk.once.Do(func() {
+		close(hk.keydown)
+		close(hk.keyup)
+	})
+	return nil
+}
+
+// Press delivers a keydown and blocks until the listener has received it
+func (hk *fakeHotkey) Press() {
+	hk.keydown <- hotkey.Event{}
+}
+
+// Release delivers a keyup and blocks until the listener has received it
+func (hk *fakeHotkey) Release() {
+	hk.keyup <- hotkey.Event{}
+}
+
+// fakeDAV is a WebDAV folder kept in memory, to sync devices through
+type fakeDAV struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// newFakeDAV serves an empty fakeDAV and returns a sync or backup target pointing at it
+func newFakeDAV(t *testing.T) (*fakeDAV, BackupTarget) {
+	t.Helper()
+	dav := &fakeDAV{files: make(map[string][]byte)}
+	srv := httptest.NewServer(dav)
+	t.Cleanup(srv.Close)
+	return dav, BackupTarget{Name: "fake", Type: "webdav", Enabled: true, URL: srv.URL + "/dav"}
+}
+
+func (d *fakeDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/dav/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		d.files[name] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := d.files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(d.files, name)
+		w.WriteHeader(http.StatusNoContent)
+	case "PROPFIND":
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"><d:response><d:href>/dav/</d:href></d:response>`)
+		for name := range d.files {
+			fmt.Fprintf(w, `<d:response><d:href>/dav/%s</d:href></d:response>`, name)
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// File returns a copy of the file called name, or nil
+func (d *fakeDAV) File(name string) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]byte(nil), d.files[name]...)
+}
+
+// Put writes a file as another client would
+func (d *fakeDAV) Put(name string, data []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files[name] = data
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// isFocusModeActive reports whether a macOS Focus mode is active, based on the
+// assertions the DoNotDisturb daemon writes for manually enabled Focus modes
+func isFocusModeActive() bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, "Library", "DoNotDisturb", "DB", "Assertions.json"))
+	if err != nil {
+		return false
+	}
+
+	var assertions struct {
+		Data []struct {
+			StoreAssertionRecords []json.RawMessage `json:"storeAssertionRecords"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &assertions); err != nil {
+		return false
+	}
+
+	for _, d := range assertions.Data {
+		if len(d.StoreAssertionRecords) > 0 {
+			return true
+		}
+	}
+	return false
+}
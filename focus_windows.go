@@ -0,0 +1,21 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// isFocusModeActive reports whether Windows "Do not disturb" has turned off toast notifications
+func isFocusModeActive() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER,
+		`Software\Microsoft\Windows\CurrentVersion\Notifications\Settings`, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("NOC_GLOBAL_SETTING_TOASTS_ENABLED")
+	if err != nil {
+		return false
+	}
+	return enabled == 0
+}
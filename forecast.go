@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// forecastWeeks is how far back task completions count towards throughput
+const forecastWeeks = 4
+
+// taggedTaskCondition limits a tasks query to the tasks in entries filed under a tag
+const taggedTaskCondition = ` AND log_entry_id IN (SELECT lt.log_entry_id FROM log_entries_tags lt
+	JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)`
+
+// TaskForecast is the recent task throughput of a tag and how long its open tasks take
+// to clear at that rate. ClearDays and ClearBy are left out when nothing was completed
+// recently.
+type TaskForecast struct {
+	Tag       string     `json:"tag"`
+	Open      int        `json:"open"`
+	Completed int        `json:"completed"`
+	Weeks     int        `json:"weeks"`
+	PerWeek   float64    `json:"per_week"`
+	ClearDays *int       `json:"clear_days,omitempty"`
+	ClearBy   *time.Time `json:"clear_by,omitempty"`
+}
+
+// GetTaskForecast counts the tasks of entries tagged tag (every task when tag is empty)
+// completed in the last forecastWeeks weeks and projects when the open ones are done
+func (a *App) GetTaskForecast(tag string) (*TaskForecast, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	tag = canonicalTag(tag)
+	now := a.clock.Now()
+	doneQuery := `SELECT COUNT(*) FROM tasks WHERE done = 1 AND completed_at >= ?`
+	doneArgs := []interface{}{sqlTime(now.AddDate(0, 0, -7*forecastWeeks))}
+	if tag != "" {
+		doneQuery, doneArgs = doneQuery+taggedTaskCondition, append(doneArgs, tag)
+	}
+
+	open, err := a.countOpenTasks(tag)
+	if err != nil {
+		return nil, err
+	}
+	forecast := &TaskForecast{Tag: tag, Open: open, Weeks: forecastWeeks}
+	if err := a.db.QueryRow(doneQuery, doneArgs...).Scan(&forecast.Completed); err != nil {
+		return nil, fmt.Errorf("failed to count completed tasks: %v", err)
+	}
+
+	forecast.PerWeek = float64(forecast.Completed) / forecastWeeks
+	if forecast.Completed > 0 {
+		days := int(math.Ceil(float64(forecast.Open) * 7 / forecast.PerWeek))
+		clearBy := now.AddDate(0, 0, days)
+		forecast.ClearDays, forecast.ClearBy = &days, &clearBy
+	}
+	return forecast, nil
+}
+
+// countOpenTasks counts the unchecked tasks of entries tagged tag, or all of them when
+// tag is empty
+func (a *App) countOpenTasks(tag string) (int, error) {
+	query, args := `SELECT COUNT(*) FROM tasks WHERE done = 0`, []interface{}{}
+	if tag != "" {
+		query, args = query+taggedTaskCondition, append(args, tag)
+	}
+	var open int
+	if err := a.db.QueryRow(query, args...).Scan(&open); err != nil {
+		return 0, fmt.Errorf("failed to count open tasks: %v", err)
+	}
+	return open, nil
+}
+
+// Summary renders the forecast as "2.5 tasks/week · open tasks clear in ~12 days"
+func (f *TaskForecast) Summary() string {
+	rate := fmt.Sprintf("%.1f tasks/week", f.PerWeek)
+	switch {
+	case f.Open == 0:
+		return rate + " · no open tasks"
+	case f.ClearDays == nil:
+		return fmt.Sprintf("no tasks completed in the last %d weeks", f.Weeks)
+	default:
+		return fmt.Sprintf("%s · at this rate, open tasks clear in ~%d days", rate, *f.ClearDays)
+	}
+}
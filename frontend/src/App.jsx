@@ -1,7 +1,7 @@
-import {useState, useEffect} from 'react';
+import {useState, useEffect, useRef} from 'react';
 import './App.css';
-import {LogText, HideWindow, Quit, GetSettings, SetSettings, RenderMarkdown, ProcessCommand, ClearAllData, GetDatabasePath, UpdateEntry, DeleteEntry} from "../wailsjs/go/main/App";
-import {EventsOn} from "../wailsjs/runtime/runtime";
+import {LogAndHide, HideWindow, Quit, GetSettings, SetSettings, RenderMarkdown, ProcessCommand, ClearAllData, GetDatabasePath, UpdateEntry, ConfirmDelete, CancelDelete, RestoreEntry, RunSelfTest, ListPlugins, EnablePlugin, DisablePlugin, IsCommand, GetCommands, ExpandShortcuts, QuickOpen, GetSyncStatus, SyncNow, ListConflicts, ResolveConflict, GenerateSyncRecoveryCodes, ListDevices, RevokeDevice, GetStorageStats, CompactDatabase, BackupNow, GetDatabaseEncryption, EncryptDatabase, DecryptDatabase, ChangeDatabasePassphrase, UnlockDatabase, GetCaptureMode, SetCaptureMode, SaveDraft, GetDraft, RenderMarkdownPreview, GetActiveMeeting, GetGuidedQuestion, AnswerGuidedFlow, CancelGuidedFlow, PasteClipboardImage, CaptureWindowActivity, GetActiveJournal} from "../wailsjs/go/main/App";
+import {EventsOn, BrowserOpenURL} from "../wailsjs/runtime/runtime";
 
 function App() {
     const [text, setText] = useState('');
@@ -10,12 +10,52 @@ function App() {
     const [showInstructions, setShowInstructions] = useState(false);
     const [previewMode, setPreviewMode] = useState(false);
     const [renderedHtml, setRenderedHtml] = useState('');
+    const [captureMode, setCaptureMode] = useState('quick');
+    const [meeting, setMeeting] = useState(null);
+    const [guided, setGuided] = useState(null);
+    const [journal, setJournal] = useState('main');
+    const [now, setNow] = useState(Date.now());
+    const draftLoaded = useRef(false);
+    const flowRequested = useRef(false);
+    const [searchResults, setSearchResults] = useState(null);
+    const [searchIndex, setSearchIndex] = useState(0);
     const [showDeleteConfirm, setShowDeleteConfirm] = useState(false);
     const [deleteSuccess, setDeleteSuccess] = useState(false);
+    const [clearPhrase, setClearPhrase] = useState('');
+    const [clearScope, setClearScope] = useState('entries');
+    const [clearReport, setClearReport] = useState(null);
     const [databasePath, setDatabasePath] = useState('');
     const [editingEntryId, setEditingEntryId] = useState(null);
     const [deleteConfirmId, setDeleteConfirmId] = useState(null);
     const [deleteConfirmPreview, setDeleteConfirmPreview] = useState('');
+    const [trashedEntry, setTrashedEntry] = useState(null);
+    const [selfTestReport, setSelfTestReport] = useState(null);
+    const [selfTestRunning, setSelfTestRunning] = useState(false);
+    const [plugins, setPlugins] = useState([]);
+    const [pluginError, setPluginError] = useState('');
+    const [syncStatus, setSyncStatus] = useState(null);
+    const [syncConflicts, setSyncConflicts] = useState([]);
+    const [syncDevices, setSyncDevices] = useState([]);
+    const [storageStats, setStorageStats] = useState(null);
+    const [compacting, setCompacting] = useState(false);
+    const [syncRunning, setSyncRunning] = useState(false);
+    const [backupRunning, setBackupRunning] = useState(false);
+    const [backupResult, setBackupResult] = useState('');
+    const [encryption, setEncryption] = useState(null);
+    const [passphrase, setPassphrase] = useState('');
+    const [newPassphrase, setNewPassphrase] = useState('');
+    const [encryptionResult, setEncryptionResult] = useState('');
+    const [showUnlock, setShowUnlock] = useState(false);
+    const [unlockPassphrase, setUnlockPassphrase] = useState('');
+    const [unlockError, setUnlockError] = useState('');
+    const [recoveryCodes, setRecoveryCodes] = useState(null);
+    const [revokePassphrase, setRevokePassphrase] = useState('');
+    const [revokeError, setRevokeError] = useState('');
+    const [extraCommands, setExtraCommands] = useState([]);
+    const [quickOpen, setQuickOpen] = useState(false);
+    const [quickQuery, setQuickQuery] = useState('');
+    const [quickItems, setQuickItems] = useState([]);
+    const [quickIndex, setQuickIndex] = useState(0);
     
     // Detect macOS
     const isMac = navigator.platform.toUpperCase().indexOf('MAC') >= 0 || navigator.userAgent.toUpperCase().indexOf('MAC') >= 0;
@@ -38,6 +78,13 @@ function App() {
         
         // Load file paths
         GetDatabasePath().then(setDatabasePath);
+
+        // An encrypted database waits for its passphrase
+        GetDatabaseEncryption().then(status => {
+            setEncryption(status);
+            setShowUnlock(status.locked);
+        });
+        EventsOn("show-unlock-prompt", () => setShowUnlock(true));
         
         // Listen for open-settings event
         EventsOn("open-settings", () => {
@@ -61,7 +108,18 @@ function App() {
 
         // Global key listener for Esc key
         const handleGlobalKeyDown = (e) => {
+            if (e.key === 'k' && (e.ctrlKey || e.metaKey)) {
+                // Ctrl+K / Cmd+K opens the quick switcher
+                e.preventDefault();
+                setQuickOpen(true);
+                return;
+            }
             if (e.key === 'Escape') {
+                // Close quick switcher if open
+                if (quickOpen) {
+                    setQuickOpen(false);
+                    return;
+                }
                 // Close settings modal if open
                 if (showSettings) {
                     setShowSettings(false);
@@ -95,7 +153,213 @@ function App() {
             document.removeEventListener('keydown', handleGlobalKeyDown);
             window.removeEventListener('focus', handleWindowFocus);
         };
-    }, [showSettings, deleteConfirmId, editingEntryId]);
+    }, [showSettings, deleteConfirmId, editingEntryId, quickOpen]);
+
+    // Quick switcher results are ranked by the backend
+    useEffect(() => {
+        if (!quickOpen) {
+            return;
+        }
+        let cancelled = false;
+        QuickOpen(quickQuery).then(items => {
+            if (!cancelled) {
+                setQuickItems(items || []);
+                setQuickIndex(0);
+            }
+        }).catch(err => console.error('Quick open failed:', err));
+        return () => {
+            cancelled = true;
+        };
+    }, [quickOpen, quickQuery]);
+
+    // Voice capture while the hotkey is held (hold gesture)
+    useEffect(() => {
+        const SpeechRecognition = window.SpeechRecognition || window.webkitSpeechRecognition;
+        let recognition = null;
+
+        const offStart = EventsOn("start-voice-capture", () => {
+            if (!SpeechRecognition) {
+                console.warn('Speech recognition is not available in this webview');
+                return;
+            }
+            recognition = new SpeechRecognition();
+            recognition.continuous = true;
+            recognition.interimResults = false;
+            recognition.onresult = (event) => {
+                let transcript = '';
+                for (let i = event.resultIndex; i < event.results.length; i++) {
+                    if (event.results[i].isFinal) {
+                        transcript += event.results[i][0].transcript;
+                    }
+                }
+                if (transcript) {
+                    setText(prev => {
+                        const next = (prev ? prev + ' ' : '') + transcript.trim();
+                        setCharCount(next.length);
+                        return next;
+                    });
+                }
+            };
+            recognition.start();
+        });
+
+        const offStop = EventsOn("stop-voice-capture", () => {
+            if (recognition) {
+                recognition.stop();
+                recognition = null;
+            }
+        });
+
+        return () => {
+            offStart();
+            offStop();
+        };
+    }, []);
+
+    // Restore the unsaved new-entry draft and follow capture mode switches from hotkeys and /expand
+    useEffect(() => {
+        GetCaptureMode().then(setCaptureMode);
+        GetDraft(0).then(draft => {
+            if (draft) {
+                setText(draft.content);
+                setCharCount(draft.content.length);
+            }
+        }).finally(() => {
+            draftLoaded.current = true;
+        });
+        return EventsOn("capture-mode", setCaptureMode);
+    }, []);
+
+    // Show the running /meeting and how long it has been going
+    useEffect(() => {
+        GetActiveMeeting().then(setMeeting).catch(() => {});
+        return EventsOn("meeting", setMeeting);
+    }, []);
+
+    // Name the journal new entries go to when it isn't the main one
+    useEffect(() => {
+        GetActiveJournal().then(setJournal).catch(() => {});
+        return EventsOn("journal", setJournal);
+    }, []);
+
+    // /flow asks its questions one at a time; each submission answers the current one
+    useEffect(() => {
+        GetGuidedQuestion().then(setGuided).catch(() => {});
+        return EventsOn("guided:question", (question) => {
+            if (question) {
+                flowRequested.current = true;
+            }
+            setGuided(question);
+        });
+    }, []);
+
+    useEffect(() => {
+        if (!meeting) {
+            return;
+        }
+        setNow(Date.now());
+        const timer = setInterval(() => setNow(Date.now()), 30000);
+        return () => clearInterval(timer);
+    }, [meeting]);
+
+    // Save the draft with its mode shortly after typing stops
+    useEffect(() => {
+        if (!draftLoaded.current || text.trim().startsWith('/')) {
+            return;
+        }
+        const timer = setTimeout(() => {
+            SaveDraft(editingEntryId || 0, text, captureMode).catch(() => {});
+        }, 400);
+        return () => clearTimeout(timer);
+    }, [text, captureMode, editingEntryId]);
+
+    // The long-form editor keeps its preview pane up to date while typing
+    useEffect(() => {
+        if (captureMode !== 'long') {
+            return;
+        }
+        const timer = setTimeout(() => {
+            RenderMarkdownPreview(text).then(setRenderedHtml).catch(() => setRenderedHtml('<p>Error rendering markdown</p>'));
+        }, 150);
+        return () => clearTimeout(timer);
+    }, [text, captureMode]);
+
+    const toggleCaptureMode = () => {
+        SetCaptureMode(captureMode === 'long' ? 'quick' : 'long');
+    };
+
+    // /edit, /delete and friends hand their entry to the input window through these events
+    useEffect(() => {
+        const offEdit = EventsOn("entry:edit-requested", async (request) => {
+            flowRequested.current = true;
+            setEditingEntryId(request.id);
+            setText(request.content);
+            setCharCount(request.content.length);
+            // Pick up where an earlier unsaved edit of this entry left off
+            const draft = await GetDraft(request.id).catch(() => null);
+            if (draft) {
+                setText(draft.content);
+                setCharCount(draft.content.length);
+                SetCaptureMode(draft.mode);
+            }
+        });
+        const offDelete = EventsOn("entry:delete-requested", (request) => {
+            flowRequested.current = true;
+            setDeleteConfirmId(request.id);
+            setDeleteConfirmPreview(request.preview);
+            setText('');
+            setCharCount(0);
+        });
+        // Commands that prepare text, such as /template, hand it over for review
+        const offPrefill = EventsOn("entry:prefill-requested", (request) => {
+            flowRequested.current = true;
+            setText(request.content);
+            setCharCount(request.content.length);
+        });
+        const offTrashed = EventsOn("entry:trashed", (trashed) => {
+            setTrashedEntry(trashed);
+        });
+        const offSearch = EventsOn("search-results", (found) => {
+            flowRequested.current = true;
+            setSearchResults(found);
+            setSearchIndex(0);
+        });
+        return () => {
+            offEdit();
+            offDelete();
+            offPrefill();
+            offTrashed();
+            offSearch();
+        };
+    }, []);
+
+    // Typing or clicking keeps the window from auto-hiding; reported at most once a second
+    useEffect(() => {
+        if (!settings.capture_auto_hide_seconds) return;
+        let last = 0;
+        const onActivity = () => {
+            const now = Date.now();
+            if (now - last >= 1000) {
+                last = now;
+                CaptureWindowActivity();
+            }
+        };
+        const events = ['keydown', 'mousedown', 'mousemove', 'wheel'];
+        events.forEach(name => window.addEventListener(name, onActivity));
+        return () => events.forEach(name => window.removeEventListener(name, onActivity));
+    }, [settings.capture_auto_hide_seconds]);
+
+    // Prefill with text selected in another app when the capture hotkey was pressed, or
+    // with a screenshot taken with the screenshot hotkey
+    useEffect(() => {
+        return EventsOn("prefill-selection", (quote) => {
+            setText(prev => {
+                const next = prev.trim() ? prev + '\n\n' + quote : quote;
+                setCharCount(next.length);
+                return next;
+            });
+        });
+    }, []);
 
     // Focus textarea when component mounts or window becomes visible
     useEffect(() => {
@@ -137,6 +401,32 @@ function App() {
         }
     }, [editingEntryId, showSettings, deleteConfirmId]);
 
+    // Load custom and plugin commands for the instructions screen
+    useEffect(() => {
+        if (showInstructions) {
+            GetCommands().then(commands => setExtraCommands((commands || []).filter(cmd => cmd.kind !== 'builtin')));
+        }
+    }, [showInstructions]);
+
+    // Refresh the plugin list whenever settings open
+    useEffect(() => {
+        if (showSettings) {
+            ListPlugins().then(list => setPlugins(list || [])).catch(() => setPlugins([]));
+            refreshSync();
+            GetStorageStats().then(setStorageStats).catch(() => setStorageStats(null));
+        }
+    }, [showSettings]);
+
+    const refreshSync = async () => {
+        try {
+            setSyncStatus(await GetSyncStatus());
+            setSyncConflicts(await ListConflicts() || []);
+            setSyncDevices(await ListDevices() || []);
+        } catch (err) {
+            setSyncStatus(null);
+        }
+    };
+
     const MAX_TEXT_LENGTH = 50000;
     
     const handleTextChange = (e) => {
@@ -144,12 +434,59 @@ function App() {
         if (newText.length <= MAX_TEXT_LENGTH) {
             setText(newText);
             setCharCount(newText.length);
+            
+            // Expand a \shortcut as soon as it is followed by a space or newline
+            const atEnd = e.target.selectionStart === newText.length;
+            if (atEnd && /\\[A-Za-z0-9_-]+\s$/.test(newText)) {
+                ExpandShortcuts(newText).then(expanded => {
+                    if (expanded !== newText) {
+                        setText(current => current === newText ? expanded : current);
+                        setCharCount(expanded.length);
+                    }
+                });
+            }
+        }
+    };
+
+    // A pasted image is stored as an attachment and embedded where the cursor is
+    const handlePaste = async (e) => {
+        const items = Array.from(e.clipboardData?.items || []);
+        if (!items.some(item => item.type.startsWith('image/'))) return;
+        e.preventDefault();
+        const { selectionStart, selectionEnd } = e.target;
+        try {
+            const markdown = await PasteClipboardImage();
+            setText(current => {
+                const next = current.slice(0, selectionStart) + markdown + current.slice(selectionEnd);
+                setCharCount(next.length);
+                return next;
+            });
+        } catch (error) {
+            console.error('Error pasting image:', error);
         }
     };
 
-    const logText = async () => {
+    const logText = async (input = text) => {
+        // A guided flow takes every submission as its answer; an empty one skips the question
+        if (guided) {
+            try {
+                const next = await AnswerGuidedFlow(input);
+                setText('');
+                setCharCount(0);
+                setGuided(next);
+                if (!next) {
+                    setTimeout(() => {
+                        HideWindow();
+                    }, 100);
+                }
+            } catch (error) {
+                console.error('Error answering guided flow:', error?.message || error);
+            }
+            return;
+        }
+
         // If text is empty, just minimize the window
-        if (!text.trim()) {
+        if (!input.trim()) {
             // If in edit mode, cancel edit
             if (editingEntryId) {
                 setEditingEntryId(null);
@@ -160,123 +497,41 @@ function App() {
             return;
         }
 
-        // Check for recognized slash commands
-        const trimmedText = text.trim();
-        const recognizedCommands = ['/dash', '/settings', '/editprev', '/delprev'];
-        
-        if (trimmedText.startsWith('/') && recognizedCommands.includes(trimmedText)) {
+        // Check for slash commands known to the command registry
+        const trimmedText = input.trim();
+        if (trimmedText.startsWith('/') && await IsCommand(trimmedText)) {
             try {
-                await ProcessCommand(trimmedText);
-                // If ProcessCommand succeeds, it shouldn't happen for editprev/delprev
-                // They should return errors with special format
-                setText(''); // Clear the input
+                flowRequested.current = false;
+                setText('');
                 setCharCount(0); // Reset character count
-                // Don't hide window for settings command
-                if (trimmedText !== '/settings') {
+                await ProcessCommand(trimmedText);
+                // Don't hide window for settings and mode switches, or when the command
+                // started an edit, delete or prefill flow
+                if (trimmedText !== '/settings' && trimmedText !== '/expand') {
                     setTimeout(() => {
-                        HideWindow();
+                        if (!flowRequested.current) {
+                            HideWindow();
+                        }
                     }, 100);
                 }
-                return;
             } catch (error) {
-                // ProcessCommand returns an error, but we use it to pass data for editprev/delprev
-                const errorMsg = error?.message || error?.toString() || '';
-                
-                if (errorMsg.startsWith('EDIT_MODE:')) {
-                    // Parse EDIT_MODE:<id>:<content>
-                    const parts = errorMsg.split(':');
-                    if (parts.length >= 3) {
-                        const entryId = parseInt(parts[1]);
-                        const content = parts.slice(2).join(':'); // Rejoin in case content has colons
-                        setEditingEntryId(entryId);
-                        setText(content);
-                        // Don't hide window, allow editing
-                        return;
-                    }
-                } else if (errorMsg.startsWith('DELETE_CONFIRM:')) {
-                    // Parse DELETE_CONFIRM:<id>:<preview>
-                    const parts = errorMsg.split(':');
-                    if (parts.length >= 3) {
-                        const entryId = parseInt(parts[1]);
-                        const preview = parts.slice(2).join(':'); // Rejoin in case preview has colons
-                        setDeleteConfirmId(entryId);
-                        setDeleteConfirmPreview(preview);
-                        setText(''); // Clear the input
-                        setCharCount(0); // Reset character count
-                        // Don't hide window, show confirmation
-                        return;
-                    }
-                } else {
-                    // Actual error or other command
-                    console.error('Error processing command:', errorMsg);
-                    setText('');
-                    setCharCount(0); // Reset character count
-                    if (trimmedText !== '/settings') {
-                        setTimeout(() => {
-                            HideWindow();
-                        }, 100);
-                    }
-                }
-                return;
-            }
-        }
-
-        // Check for edit/delete commands
-        if (trimmedText.startsWith('/edit ') || trimmedText.startsWith('/delete ')) {
-            try {
-                await ProcessCommand(trimmedText);
-                // If ProcessCommand succeeds, it shouldn't happen for edit/delete
-                // They should return errors with special format
+                console.error('Error processing command:', error?.message || error);
                 setText('');
                 setCharCount(0); // Reset character count
-                setTimeout(() => {
-                    HideWindow();
-                }, 100);
-            } catch (error) {
-                // ProcessCommand returns an error, but we use it to pass data
-                const errorMsg = error?.message || error?.toString() || '';
-                
-                if (errorMsg.startsWith('EDIT_MODE:')) {
-                    // Parse EDIT_MODE:<id>:<content>
-                    const parts = errorMsg.split(':');
-                    if (parts.length >= 3) {
-                        const entryId = parseInt(parts[1]);
-                        const content = parts.slice(2).join(':'); // Rejoin in case content has colons
-                        setEditingEntryId(entryId);
-                        setText(content);
-                        // Don't hide window, allow editing
-                        return;
-                    }
-                } else if (errorMsg.startsWith('DELETE_CONFIRM:')) {
-                    // Parse DELETE_CONFIRM:<id>:<preview>
-                    const parts = errorMsg.split(':');
-                    if (parts.length >= 3) {
-                        const entryId = parseInt(parts[1]);
-                        const preview = parts.slice(2).join(':'); // Rejoin in case preview has colons
-                        setDeleteConfirmId(entryId);
-                        setDeleteConfirmPreview(preview);
-                        setText(''); // Clear the input
-                        setCharCount(0); // Reset character count
-                        // Don't hide window, show confirmation
-                        return;
-                    }
-                } else {
-                    // Actual error
-                    console.error('Error processing command:', errorMsg);
-                    setText('');
-                    setCharCount(0); // Reset character count
+                if (trimmedText !== '/settings') {
                     setTimeout(() => {
                         HideWindow();
                     }, 100);
                 }
-                return;
             }
+            return;
         }
 
         // If in edit mode, update the entry
         if (editingEntryId) {
             try {
-                await UpdateEntry(editingEntryId, text);
+                await UpdateEntry(editingEntryId, input);
+                SaveDraft(editingEntryId, '', captureMode);
                 setText(''); // Clear the input
                 setCharCount(0); // Reset character count
                 setEditingEntryId(null); // Exit edit mode
@@ -290,29 +545,121 @@ function App() {
 
         // Log as regular text (even if it starts with / but isn't a recognized command)
         try {
-            await LogText(text);
-            setText(''); // Clear the input
-            setCharCount(0); // Reset character count
-            
-            // Hide the window after successful logging
-            setTimeout(() => {
-                HideWindow();
-            }, 100); // Small delay to ensure text is logged
-            
+            // Logs, then hides the window and clears the text unless the settings keep them
+            const result = await LogAndHide(input);
+            if (result.clear_text) {
+                setText('');
+                setCharCount(0);
+            }
         } catch (error) {
             console.error('Error logging text:', error);
         }
     };
 
     const handleKeyPress = (e) => {
+        // The long-form editor takes Enter as a new line and logs with Ctrl/Cmd+Enter
+        if (captureMode === 'long' && !(e.ctrlKey || e.metaKey)) {
+            return;
+        }
         if (e.key === 'Enter' && !e.shiftKey) {
             e.preventDefault(); // Prevent default behavior (new line)
             logText();
         }
     };
 
+    const closeQuickOpen = () => {
+        setQuickOpen(false);
+        setQuickQuery('');
+        setTimeout(() => {
+            const textInput = document.getElementById('textInput');
+            if (textInput) {
+                textInput.focus();
+            }
+        }, 0);
+    };
+
+    const chooseQuickItem = (item) => {
+        closeQuickOpen();
+        if (item.action === 'run') {
+            logText(item.value);
+        } else if (item.action === 'insert') {
+            setText(item.value);
+            setCharCount(item.value.length);
+        } else if (item.action === 'open') {
+            BrowserOpenURL(item.url);
+        }
+    };
+
+    const handleQuickKeyDown = (e) => {
+        if (e.key === 'ArrowDown') {
+            e.preventDefault();
+            setQuickIndex(i => Math.min(i + 1, quickItems.length - 1));
+        } else if (e.key === 'ArrowUp') {
+            e.preventDefault();
+            setQuickIndex(i => Math.max(i - 1, 0));
+        } else if (e.key === 'Enter') {
+            e.preventDefault();
+            if (quickItems[quickIndex]) {
+                chooseQuickItem(quickItems[quickIndex]);
+            }
+        } else if (e.key === 'Escape') {
+            e.preventDefault();
+            e.stopPropagation();
+            closeQuickOpen();
+        }
+    };
+
+    const closeSearchResults = () => {
+        setSearchResults(null);
+        setTimeout(() => {
+            const textInput = document.getElementById('textInput');
+            if (textInput) {
+                textInput.focus();
+            }
+        }, 0);
+    };
+
+    // Picking a /search result opens it for editing
+    const chooseSearchResult = (result) => {
+        setSearchResults(null);
+        logText(`/edit ${result.id}`);
+    };
+
+    const handleSearchKeyDown = (e) => {
+        const results = searchResults?.results || [];
+        if (e.key === 'ArrowDown') {
+            e.preventDefault();
+            setSearchIndex(i => Math.min(i + 1, results.length - 1));
+        } else if (e.key === 'ArrowUp') {
+            e.preventDefault();
+            setSearchIndex(i => Math.max(i - 1, 0));
+        } else if (e.key === 'Enter') {
+            e.preventDefault();
+            if (results[searchIndex]) {
+                chooseSearchResult(results[searchIndex]);
+            }
+        } else if (e.key === 'Escape') {
+            e.preventDefault();
+            e.stopPropagation();
+            closeSearchResults();
+        }
+    };
+
+    // Snippets mark matched words with [ and ]
+    const renderSnippet = (snippet) => snippet.split(/(\[[^\]]*\])/).map((part, i) => (
+        part.startsWith('[') && part.endsWith(']') ? <mark key={i}>{part.slice(1, -1)}</mark> : part
+    ));
+
     const handleKeyDown = (e) => {
         if (e.key === 'Escape') {
+            // Escape abandons a guided flow without logging its answers
+            if (guided) {
+                CancelGuidedFlow();
+                setGuided(null);
+                setText('');
+                setCharCount(0);
+                return;
+            }
             // If in edit mode, cancel edit
             if (editingEntryId) {
                 setEditingEntryId(null);
@@ -326,6 +673,10 @@ function App() {
             }
             // Hide window without saving
             HideWindow();
+        } else if (e.key === 'e' && (e.ctrlKey || e.metaKey)) {
+            // Ctrl+E / Cmd+E switches between the quick box and the long-form editor
+            e.preventDefault();
+            toggleCaptureMode();
         } else if (e.key === 'Tab' && (e.ctrlKey || e.metaKey)) {
             // Ctrl+Tab (Windows/Linux) or Cmd+Tab (macOS) to toggle preview mode
             e.preventDefault();
@@ -348,6 +699,32 @@ function App() {
         setPreviewMode(newPreviewMode);
     };
 
+    const unlockDatabase = async () => {
+        try {
+            await UnlockDatabase(unlockPassphrase);
+            setShowUnlock(false);
+            setUnlockPassphrase('');
+            setUnlockError('');
+            GetDatabaseEncryption().then(setEncryption);
+        } catch (err) {
+            setUnlockError(err?.message || String(err));
+        }
+    };
+
+    // changeEncryption runs an encryption action from settings and reports the outcome
+    const changeEncryption = async (action, done) => {
+        try {
+            await action();
+            setEncryptionResult(done);
+            setPassphrase('');
+            setNewPassphrase('');
+        } catch (err) {
+            setEncryptionResult(err?.message || String(err));
+        }
+        GetDatabaseEncryption().then(setEncryption);
+        GetDatabasePath().then(setDatabasePath);
+    };
+
     const saveSettings = async () => {
         try {
             await SetSettings(tempSettings);
@@ -394,13 +771,18 @@ function App() {
 
     const handleDeleteAll = async () => {
         try {
-            await ClearAllData();
+            const report = await ClearAllData(clearScope, clearPhrase);
+            setClearReport(report);
             setShowDeleteConfirm(false);
+            setClearPhrase('');
+            if (report.settings_reset) {
+                GetSettings().then(setSettings);
+            }
             setDeleteSuccess(true);
             // Reset success message after 3 seconds
             setTimeout(() => {
                 setDeleteSuccess(false);
-            }, 3000);
+            }, 6000);
         } catch (error) {
             console.error('Error deleting all data:', error);
             setShowDeleteConfirm(false);
@@ -412,7 +794,7 @@ function App() {
         if (!deleteConfirmId) return;
         
         try {
-            await DeleteEntry(deleteConfirmId);
+            await ConfirmDelete(deleteConfirmId);
             setDeleteConfirmId(null);
             setDeleteConfirmPreview('');
             setText('');
@@ -424,7 +806,25 @@ function App() {
         }
     };
 
+    // The undo toast stays for a few seconds after a delete from this window
+    useEffect(() => {
+        if (!trashedEntry) return;
+        const timer = setTimeout(() => setTrashedEntry(null), 8000);
+        return () => clearTimeout(timer);
+    }, [trashedEntry]);
+
+    const handleUndoDelete = async () => {
+        if (!trashedEntry) return;
+        try {
+            await RestoreEntry(trashedEntry.id);
+        } catch (error) {
+            console.error('Error restoring entry:', error);
+        }
+        setTrashedEntry(null);
+    };
+
     const handleDeleteCancel = () => {
+        CancelDelete(deleteConfirmId);
         setDeleteConfirmId(null);
         setDeleteConfirmPreview('');
         setText('');
@@ -455,7 +855,7 @@ function App() {
                     >
                         ⚙️
                     </button>
-                    <p className="subtitle">{isMac ? 'Cmd+Tab' : 'Ctrl+Tab'}: Preview | Esc: Exit</p>
+                    <p className="subtitle">{isMac ? 'Cmd+Tab' : 'Ctrl+Tab'}: Preview | {isMac ? 'Cmd+K' : 'Ctrl+K'}: Quick open | Esc: Exit</p>
                 </div>
             </div>
             
@@ -476,13 +876,73 @@ function App() {
                 </div>
             )}
             
+            {trashedEntry && (
+                <div className="undo-toast" role="status">
+                    <span className="undo-toast-text">Moved to trash: {trashedEntry.preview}</span>
+                    <button className="undo-toast-btn" onClick={handleUndoDelete}>Undo</button>
+                </div>
+            )}
+            
+            {quickOpen && (
+                <div className="quick-open-overlay" onClick={closeQuickOpen}>
+                    <div className="quick-open" onClick={(e) => e.stopPropagation()}>
+                        <input
+                            className="quick-open-input"
+                            value={quickQuery}
+                            onChange={(e) => setQuickQuery(e.target.value)}
+                            onKeyDown={handleQuickKeyDown}
+                            placeholder="Commands, entries, #tags, dates..."
+                            autoFocus
+                        />
+                        <ul className="quick-open-results">
+                            {quickItems.map((item, i) => (
+                                <li
+                                    key={`${item.kind}-${item.value}`}
+                                    className={i === quickIndex ? 'selected' : ''}
+                                    onMouseEnter={() => setQuickIndex(i)}
+                                    onClick={() => chooseQuickItem(item)}
+                                >
+                                    <span className="quick-open-kind">{item.kind}</span>
+                                    <span className="quick-open-title">{item.title}</span>
+                                    <span className="quick-open-subtitle">{item.subtitle}</span>
+                                </li>
+                            ))}
+                            {quickItems.length === 0 && <li className="quick-open-empty">No matches</li>}
+                        </ul>
+                    </div>
+                </div>
+            )}
+            
+            {searchResults && (
+                <div className="quick-open-overlay" onClick={closeSearchResults}>
+                    <div className="quick-open" onClick={(e) => e.stopPropagation()} onKeyDown={handleSearchKeyDown} tabIndex={-1} autoFocus>
+                        <div className="quick-open-input">Results for "{searchResults.query}" - Enter to edit, Esc to close</div>
+                        <ul className="quick-open-results">
+                            {searchResults.results.map((result, i) => (
+                                <li
+                                    key={result.id}
+                                    className={i === searchIndex ? 'selected' : ''}
+                                    onMouseEnter={() => setSearchIndex(i)}
+                                    onClick={() => chooseSearchResult(result)}
+                                >
+                                    <span className="quick-open-kind">#{result.id}</span>
+                                    <span className="quick-open-title">{renderSnippet(result.snippet)}</span>
+                                    <span className="quick-open-subtitle">{new Date(result.created_at).toLocaleString()}</span>
+                                </li>
+                            ))}
+                            {searchResults.results.length === 0 && <li className="quick-open-empty">No matches</li>}
+                        </ul>
+                    </div>
+                </div>
+            )}
+
             {editingEntryId && (
                 <div className="edit-mode-banner">
                     Editing entry #{editingEntryId} - Press Enter to save, Esc to cancel
                 </div>
             )}
             
-            <div className="input-container">
+            <div className={captureMode === 'long' ? 'input-container capture-long' : 'input-container'}>
                 <div className="input-header">
                     <span className="mode-indicator">
                         {editingEntryId ? `Editing Entry #${editingEntryId}` : (previewMode ? 'Preview Mode' : 'Edit Mode')}
@@ -494,6 +954,13 @@ function App() {
                     >
                         {previewMode ? 'Edit' : 'Preview'}
                     </button>
+                    <button
+                        className="preview-toggle"
+                        onClick={toggleCaptureMode}
+                        title={`Switch editor size (${isMac ? 'Cmd+E' : 'Ctrl+E'})`}
+                    >
+                        {captureMode === 'long' ? 'Compact' : 'Expand'}
+                    </button>
                 </div>
                 
                 {previewMode ? (
@@ -514,7 +981,10 @@ function App() {
                             onChange={handleTextChange}
                             onKeyPress={handleKeyPress}
                             onKeyDown={handleKeyDown}
-                            placeholder="Enter text to log... (Markdown supported)"
+                            onPaste={handlePaste}
+                            placeholder={guided ? guided.question : "Enter text to log... (Markdown supported)"}
+                            spellCheck={settings?.spellcheck_language !== 'off'}
+                            lang={settings?.spellcheck_language && settings.spellcheck_language !== 'off' ? settings.spellcheck_language : undefined}
                             rows="4"
                             autoFocus
                             maxLength={MAX_TEXT_LENGTH}
@@ -522,6 +992,29 @@ function App() {
                         <div className="char-counter">
                             {charCount.toLocaleString()}/{MAX_TEXT_LENGTH.toLocaleString()}
                         </div>
+                        {guided && (
+                            <div className="guided-indicator" title="Enter answers, an empty answer skips, Escape cancels">
+                                {guided.title} · {guided.step}/{guided.total}
+                            </div>
+                        )}
+                        {journal !== 'main' && (
+                            <div className="journal-indicator" title="/journal main to go back">
+                                {journal}
+                            </div>
+                        )}
+                        {meeting && (
+                            <div className="meeting-indicator" title="/endmeeting to finish and add action items">
+                                ● {meeting.title} · {Math.max(0, Math.floor((now - new Date(meeting.started_at)) / 60000))} min
+                            </div>
+                        )}
+                        {captureMode === 'long' && (
+                            <div
+                                className="markdown-preview side-preview"
+                                dangerouslySetInnerHTML={{
+                                    __html: renderedHtml || '<p><em>No content to preview</em></p>'
+                                }}
+                            />
+                        )}
                     </div>
                 )}
             </div>
@@ -536,6 +1029,36 @@ function App() {
                         </div>
                         
                         <div className="modal-body">
+                            {/* Storage warnings */}
+                            {storageStats && storageStats.warnings && storageStats.warnings.length > 0 && (
+                                <div className="storage-banner">
+                                    {storageStats.warnings.map(warning => (
+                                        <div key={warning.kind}>
+                                            <strong>{warning.message}</strong>
+                                            <ul>
+                                                {warning.suggestions.map(suggestion => <li key={suggestion}>{suggestion}</li>)}
+                                            </ul>
+                                        </div>
+                                    ))}
+                                    {storageStats.reclaimable_bytes > 0 && (
+                                        <button
+                                            className="cancel-btn"
+                                            disabled={compacting}
+                                            onClick={async () => {
+                                                setCompacting(true);
+                                                try {
+                                                    setStorageStats(await CompactDatabase());
+                                                } finally {
+                                                    setCompacting(false);
+                                                }
+                                            }}
+                                        >
+                                            {compacting ? 'Compacting...' : 'Compact database'}
+                                        </button>
+                                    )}
+                                </div>
+                            )}
+
                             {/* Hotkey Configuration - Compact */}
                             <div className="setting-group">
                                 <label>Hotkey</label>
@@ -595,6 +1118,371 @@ function App() {
                                 </div>
                             </div>
 
+                            {/* Dashboard Theme */}
+                            <div className="setting-group">
+                                <label>Dashboard Theme</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.dashboard_theme || ''}
+                                        onChange={(e) => setTempSettings({...tempSettings, dashboard_theme: e.target.value})}
+                                    >
+                                        <option value="">Standard</option>
+                                        <option value="high-contrast">High contrast</option>
+                                    </select>
+                                </div>
+                                <p className="setting-note">High contrast shows the dashboard pages in white and yellow on black, with solid borders and a thick focus ring.</p>
+                            </div>
+
+                            {/* Dashboard Display */}
+                            <div className="setting-group">
+                                <label>Dashboard Text Size</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.dashboard_font_scale || 100}
+                                        onChange={(e) => setTempSettings({...tempSettings, dashboard_font_scale: parseInt(e.target.value)})}
+                                    >
+                                        {[75, 90, 100, 115, 130, 150, 175, 200].map(scale => (
+                                            <option key={scale} value={scale}>{scale}%</option>
+                                        ))}
+                                    </select>
+                                    <select
+                                        value={tempSettings.dashboard_density || 'comfortable'}
+                                        onChange={(e) => setTempSettings({...tempSettings, dashboard_density: e.target.value})}
+                                    >
+                                        <option value="comfortable">Comfortable</option>
+                                        <option value="compact">Compact</option>
+                                    </select>
+                                </div>
+                                <p className="setting-note">Applies to the dashboard pages and the timesheet PDF. Larger text helps when the dashboard is projected in a meeting; compact fits more entries on screen.</p>
+                            </div>
+
+                            {/* Day Start */}
+                            <div className="setting-group">
+                                <label>Day Starts At</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.day_start_hour || 0}
+                                        onChange={(e) => setTempSettings({...tempSettings, day_start_hour: parseInt(e.target.value, 10)})}
+                                    >
+                                        {Array.from({length: 13}, (_, hour) => (
+                                            <option key={hour} value={hour}>{hour === 0 ? 'Midnight' : `${hour}:00`}</option>
+                                        ))}
+                                    </select>
+                                </div>
+                                <p className="setting-note">Entries written before this hour count towards the previous day in the dashboard, streaks, goals and reports.</p>
+                            </div>
+
+                            {/* Punctuation */}
+                            <div className="setting-group">
+                                <label>Quotes and Dashes</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.quote_style || ''}
+                                        onChange={(e) => setTempSettings({...tempSettings, quote_style: e.target.value})}
+                                    >
+                                        <option value="">Keep quotes as typed</option>
+                                        <option value="straight">Straight quotes (" ')</option>
+                                        <option value="smart">Smart quotes (“ ” ‘ ’)</option>
+                                    </select>
+                                </div>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.expand_dashes}
+                                        onChange={(e) => setTempSettings({...tempSettings, expand_dashes: e.target.checked})}
+                                    />
+                                    Turn -- into an em dash (—)
+                                </label>
+                                <p className="setting-note">Applied when an entry is saved, so pasted text is stored the same way as typed text. Code in backticks is left alone.</p>
+                            </div>
+
+                            {/* Entry Previews */}
+                            <div className="setting-group">
+                                <label>Entry Previews</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.preview_length || 100}
+                                        onChange={(e) => setTempSettings({...tempSettings, preview_length: parseInt(e.target.value, 10)})}
+                                    >
+                                        {[40, 60, 80, 100, 150, 200, 300, 500].map(length => (
+                                            <option key={length} value={length}>{length} characters</option>
+                                        ))}
+                                    </select>
+                                </div>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.preview_first_line}
+                                        onChange={(e) => setTempSettings({...tempSettings, preview_first_line: e.target.checked})}
+                                    />
+                                    Only show the first line
+                                </label>
+                                <p className="setting-note">Used for delete confirmations, search results, quick open, reminder notifications and the undo toast. Long previews are cut at a word and end in …</p>
+                            </div>
+
+                            {/* Capture Window Behavior */}
+                            <div className="setting-group">
+                                <label>Capture Window</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.capture_position || ''}
+                                        onChange={(e) => setTempSettings({...tempSettings, capture_position: e.target.value})}
+                                    >
+                                        <option value="">Where it was last</option>
+                                        <option value="center">Centered</option>
+                                        <option value="cursor">Near the cursor</option>
+                                    </select>
+                                    <select
+                                        value={tempSettings.capture_auto_hide_seconds || 0}
+                                        onChange={(e) => setTempSettings({...tempSettings, capture_auto_hide_seconds: parseInt(e.target.value, 10)})}
+                                    >
+                                        <option value={0}>Never auto-hide</option>
+                                        {[10, 30, 60, 120, 300].map(seconds => (
+                                            <option key={seconds} value={seconds}>Hide after {seconds < 60 ? `${seconds}s` : `${seconds / 60} min`} idle</option>
+                                        ))}
+                                    </select>
+                                </div>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.capture_keep_focus}
+                                        onChange={(e) => setTempSettings({...tempSettings, capture_keep_focus: e.target.checked})}
+                                    />
+                                    Don't take focus from the app I'm in
+                                </label>
+                                <p className="setting-note">Without focus the window opens on top but keystrokes keep going to your app; click the window, or press the hotkey again, to type into it. Auto-hide keeps what you typed.</p>
+                            </div>
+
+                            {/* After Logging */}
+                            <div className="setting-group">
+                                <label>After Logging</label>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!tempSettings.stay_open_after_log}
+                                        onChange={(e) => setTempSettings({...tempSettings, stay_open_after_log: !e.target.checked})}
+                                    />
+                                    Hide the window
+                                </label>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!tempSettings.keep_text_after_log}
+                                        onChange={(e) => setTempSettings({...tempSettings, keep_text_after_log: !e.target.checked})}
+                                    />
+                                    Clear the text
+                                </label>
+                                <p className="setting-note">With both on, logging is hotkey, type, Enter and gone. Keep the window and text for logging a run of similar entries.</p>
+                            </div>
+
+                            {/* Default Journal */}
+                            <div className="setting-group">
+                                <label>Default Journal</label>
+                                <input
+                                    type="text"
+                                    value={tempSettings.default_journal || ''}
+                                    onChange={(e) => setTempSettings({...tempSettings, default_journal: e.target.value.trim()})}
+                                    placeholder="main"
+                                />
+                                <p className="setting-note">The journal entries go to when SnapLog starts. Switch for a while with /journal work.</p>
+                            </div>
+
+                            {/* Capture Notifications */}
+                            <div className="setting-group">
+                                <label>Notify After Logging</label>
+                                {[
+                                    ['hotkey', 'Capture window'],
+                                    ['cli', 'Command line'],
+                                    ['api', 'HTTP API'],
+                                    ['telegram', 'Telegram'],
+                                    ['plugin', 'Plugins'],
+                                ].map(([source, label]) => (
+                                    <label key={source} className="checkbox-label">
+                                        <input
+                                            type="checkbox"
+                                            checked={(tempSettings.capture_notify_sources || []).includes(source)}
+                                            onChange={(e) => {
+                                                const sources = (tempSettings.capture_notify_sources || []).filter(s => s !== source);
+                                                setTempSettings({...tempSettings, capture_notify_sources: e.target.checked ? [...sources, source] : sources});
+                                            }}
+                                        />
+                                        {label}
+                                    </label>
+                                ))}
+                                <p className="setting-note">Shows a brief "Logged ✓" notification with Edit, Tag and Undo buttons, so you know the entry landed without keeping the window open. The buttons need notify-send 0.7.9 or later on Linux and terminal-notifier on macOS; elsewhere the notification has none.</p>
+                            </div>
+
+                            {/* Search Inside Words */}
+                            <div className="setting-group">
+                                <label>Search Inside Words</label>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.search_trigram}
+                                        onChange={(e) => setTempSettings({...tempSettings, search_trigram: e.target.checked})}
+                                    />
+                                    Match text inside longer words, for compound words and Chinese or Japanese entries
+                                </label>
+                                <p className="setting-note">Builds a second search index that takes about as much space as your entries.</p>
+                            </div>
+
+                            {/* Nightly Backups */}
+                            <div className="setting-group">
+                                <label>Nightly Backups</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.disable_nightly_backups ? 'off' : (tempSettings.backup_keep || 7)}
+                                        onChange={(e) => setTempSettings(e.target.value === 'off'
+                                            ? {...tempSettings, disable_nightly_backups: true}
+                                            : {...tempSettings, disable_nightly_backups: false, backup_keep: parseInt(e.target.value, 10)})}
+                                    >
+                                        {[3, 7, 14, 30].map((keep) => (
+                                            <option key={keep} value={keep}>Keep {keep} copies</option>
+                                        ))}
+                                        <option value="off">Off</option>
+                                    </select>
+                                </div>
+                                <p className="setting-note">Backs up the database to the backups folder every night, or at the next start if SnapLog wasn't running.</p>
+                                <button
+                                    className="cancel-btn"
+                                    disabled={backupRunning}
+                                    onClick={async () => {
+                                        setBackupRunning(true);
+                                        try {
+                                            setBackupResult('Saved ' + await BackupNow());
+                                        } catch (err) {
+                                            setBackupResult('Backup failed: ' + err);
+                                        } finally {
+                                            setBackupRunning(false);
+                                        }
+                                    }}
+                                >
+                                    {backupRunning ? 'Backing up...' : 'Back Up Now'}
+                                </button>
+                                {backupResult && <p className="setting-note">{backupResult}</p>}
+                            </div>
+
+                            {/* Database Encryption */}
+                            <div className="setting-group">
+                                <label>Database Encryption</label>
+                                {encryption?.encrypted ? (
+                                    <>
+                                        <input
+                                            type="password"
+                                            value={passphrase}
+                                            onChange={(e) => setPassphrase(e.target.value)}
+                                            placeholder="Current passphrase"
+                                        />
+                                        <input
+                                            type="password"
+                                            value={newPassphrase}
+                                            onChange={(e) => setNewPassphrase(e.target.value)}
+                                            placeholder="New passphrase"
+                                        />
+                                        <button
+                                            className="cancel-btn"
+                                            disabled={!passphrase || !newPassphrase}
+                                            onClick={() => changeEncryption(() => ChangeDatabasePassphrase(passphrase, newPassphrase), 'Passphrase changed.')}
+                                        >
+                                            Change Passphrase
+                                        </button>
+                                        <button
+                                            className="cancel-btn"
+                                            disabled={!passphrase}
+                                            onClick={() => changeEncryption(() => DecryptDatabase(passphrase), 'Encryption turned off.')}
+                                        >
+                                            Turn Off Encryption
+                                        </button>
+                                    </>
+                                ) : (
+                                    <>
+                                        <input
+                                            type="password"
+                                            value={passphrase}
+                                            onChange={(e) => setPassphrase(e.target.value)}
+                                            placeholder="Passphrase"
+                                        />
+                                        <input
+                                            type="password"
+                                            value={newPassphrase}
+                                            onChange={(e) => setNewPassphrase(e.target.value)}
+                                            placeholder="Repeat passphrase"
+                                        />
+                                        <button
+                                            className="cancel-btn"
+                                            disabled={!passphrase || passphrase !== newPassphrase}
+                                            onClick={() => changeEncryption(() => EncryptDatabase(passphrase), 'Database encrypted.')}
+                                        >
+                                            Encrypt Database
+                                        </button>
+                                    </>
+                                )}
+                                <p className="setting-note">Encrypts the database on disk. SnapLog asks for the passphrase when it starts and keeps your entries in memory only while it runs. A lost passphrase cannot be recovered. Attachments are not encrypted.</p>
+                                {encryptionResult && <p className="setting-note">{encryptionResult}</p>}
+                            </div>
+
+                            {/* Spellcheck */}
+                            <div className="setting-group">
+                                <label>Spellcheck</label>
+                                <div className="key-selection-compact">
+                                    <select
+                                        value={tempSettings.spellcheck_language || ''}
+                                        onChange={(e) => setTempSettings({...tempSettings, spellcheck_language: e.target.value})}
+                                    >
+                                        <option value="">System language</option>
+                                        <option value="en-US">English (US)</option>
+                                        <option value="en-GB">English (UK)</option>
+                                        <option value="de">German</option>
+                                        <option value="fr">French</option>
+                                        <option value="es">Spanish</option>
+                                        <option value="off">Off</option>
+                                    </select>
+                                </div>
+                            </div>
+
+                            {/* Tag Blacklist */}
+                            <div className="setting-group">
+                                <label>Ignored Tags</label>
+                                <input
+                                    type="text"
+                                    defaultValue={(tempSettings.tag_blacklist ?? [':numbers', ':hex-colors']).join(', ')}
+                                    onChange={(e) => setTempSettings({
+                                        ...tempSettings,
+                                        tag_blacklist: e.target.value.split(',').map(rule => rule.trim()).filter(Boolean),
+                                    })}
+                                    placeholder="Nothing ignored"
+                                />
+                                <p className="setting-note">Comma-separated tag names or /patterns/ that never become tags. :numbers ignores "issue #12" and :hex-colors ignores colour codes like #ff8800. Write \#word to keep a single # out of the tags.</p>
+                            </div>
+
+                            {/* Selection Capture */}
+                            <div className="setting-group">
+                                <label>Selection Capture</label>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.capture_selection}
+                                        onChange={(e) => setTempSettings({...tempSettings, capture_selection: e.target.checked})}
+                                    />
+                                    Quote the selected text from the current app when the hotkey opens SnapLog
+                                </label>
+                                <p className="setting-note">SnapLog copies the selection for you and then restores your clipboard. On macOS this needs the Accessibility permission.</p>
+                            </div>
+
+                            {/* Advanced Mode */}
+                            <div className="setting-group">
+                                <label>Advanced Mode</label>
+                                <label className="checkbox-label">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!tempSettings.advanced_mode}
+                                        onChange={(e) => setTempSettings({...tempSettings, advanced_mode: e.target.checked})}
+                                    />
+                                    Enable the read-only SQL console on the dashboard
+                                </label>
+                                <p className="setting-note">Run SELECT queries against your log at /dash/console. Queries cannot change any data.</p>
+                            </div>
+
                             {/* Dashboard Port Configuration */}
                             <div className="setting-group">
                                 <label>Dashboard Port</label>
@@ -635,12 +1523,212 @@ function App() {
                                 />
                             </div>
 
+                            {/* Plugins */}
+                            <div className="setting-group">
+                                <label>Plugins</label>
+                                <p className="setting-note">Plugins are loaded from the <code>plugins</code> folder next to your settings.</p>
+                                {plugins.length === 0 ? (
+                                    <p className="setting-note">No plugins installed.</p>
+                                ) : (
+                                    <ul className="plugin-list">
+                                        {plugins.map(plugin => (
+                                            <li key={plugin.manifest.name}>
+                                                <label className="checkbox-label">
+                                                    <input
+                                                        type="checkbox"
+                                                        checked={plugin.enabled}
+                                                        onChange={async (e) => {
+                                                            setPluginError('');
+                                                            try {
+                                                                if (e.target.checked) {
+                                                                    await EnablePlugin(plugin.manifest.name);
+                                                                } else {
+                                                                    await DisablePlugin(plugin.manifest.name);
+                                                                }
+                                                            } catch (err) {
+                                                                setPluginError(err?.message || err?.toString() || 'Failed to update plugin');
+                                                            }
+                                                            setPlugins(await ListPlugins() || []);
+                                                        }}
+                                                    />
+                                                    <strong>{plugin.manifest.name}</strong> {plugin.manifest.version}
+                                                </label>
+                                                {plugin.manifest.description && <span className="setting-note"> — {plugin.manifest.description}</span>}
+                                                {plugin.error && <div style={{color: '#e74c3c', fontSize: '12px'}}>{plugin.error}</div>}
+                                            </li>
+                                        ))}
+                                    </ul>
+                                )}
+                                {pluginError && <p style={{color: '#e74c3c', fontSize: '12px'}}>{pluginError}</p>}
+                            </div>
+
+                            {/* Sync */}
+                            <div className="setting-group">
+                                <label>Sync</label>
+                                {syncStatus && syncStatus.enabled ? (
+                                    <>
+                                        <p className="setting-note">
+                                            Last sync: {syncStatus.last_sync ? new Date(syncStatus.last_sync).toLocaleString() : 'never'}
+                                            {' · '}{syncStatus.pending_ops} pending
+                                            {' · '}{syncStatus.peers} other devices
+                                        </p>
+                                        {syncStatus.last_error && <p style={{color: '#e74c3c', fontSize: '12px'}}>{syncStatus.last_error}</p>}
+                                        <button
+                                            className="cancel-btn"
+                                            disabled={syncRunning}
+                                            onClick={async () => {
+                                                setSyncRunning(true);
+                                                try {
+                                                    await SyncNow();
+                                                } catch (err) {
+                                                    // the error is part of the refreshed status
+                                                } finally {
+                                                    setSyncRunning(false);
+                                                    refreshSync();
+                                                }
+                                            }}
+                                        >
+                                            {syncRunning ? 'Syncing...' : 'Sync Now'}
+                                        </button>
+                                        {syncStatus.encrypted && (
+                                            <button
+                                                className="cancel-btn"
+                                                onClick={async () => {
+                                                    try {
+                                                        setRecoveryCodes(await GenerateSyncRecoveryCodes());
+                                                    } catch (err) {
+                                                        refreshSync();
+                                                    }
+                                                }}
+                                            >
+                                                New Recovery Codes
+                                            </button>
+                                        )}
+                                        {recoveryCodes && (
+                                            <div className="setting-note">
+                                                <p>Store these codes offline. Each one can reset the sync passphrase, and they will not be shown again. Earlier codes no longer work.</p>
+                                                <pre>{recoveryCodes.join('\n')}</pre>
+                                            </div>
+                                        )}
+                                        {syncDevices.length > 1 && syncStatus.encrypted && (
+                                            <>
+                                                <input
+                                                    type="password"
+                                                    value={revokePassphrase}
+                                                    onChange={(e) => setRevokePassphrase(e.target.value)}
+                                                    placeholder="New sync passphrase"
+                                                />
+                                                <p className="setting-note">A revoked device knows the current passphrase, so revoking a device replaces it with this one. Your other devices need it too.</p>
+                                                {revokeError && <p className="setting-note">{revokeError}</p>}
+                                            </>
+                                        )}
+                                        {syncDevices.length > 1 && (
+                                            <ul className="sync-devices">
+                                                {syncDevices.map(device => (
+                                                    <li key={device.id}>
+                                                        <strong>{device.name || device.id}</strong>
+                                                        {device.current && ' (this device)'}
+                                                        <div className="setting-note">
+                                                            {device.fingerprint && <>Key {device.fingerprint} · </>}
+                                                            {device.revoked ? 'revoked' : 'last seen ' + (device.last_seen ? new Date(device.last_seen).toLocaleString() : 'never')}
+                                                        </div>
+                                                        {!device.current && !device.revoked && (
+                                                            <button className="cancel-btn" disabled={syncStatus.encrypted && !revokePassphrase} onClick={async () => {
+                                                                try {
+                                                                    const codes = await RevokeDevice(device.id, revokePassphrase);
+                                                                    if (codes && codes.length > 0) {
+                                                                        setRecoveryCodes(codes);
+                                                                    }
+                                                                    setRevokePassphrase('');
+                                                                    setRevokeError('');
+                                                                } catch (err) {
+                                                                    setRevokeError(String(err));
+                                                                }
+                                                                refreshSync();
+                                                            }}>Revoke</button>
+                                                        )}
+                                                    </li>
+                                                ))}
+                                            </ul>
+                                        )}
+                                        {syncConflicts.length > 0 && (
+                                            <ul className="sync-conflicts">
+                                                {syncConflicts.map(conflict => (
+                                                    <li key={conflict.id}>
+                                                        <div className="setting-note">Entry #{conflict.entry_id} was changed on this device and on {conflict.remote_device}</div>
+                                                        <div><strong>This device:</strong> {conflict.local_content}</div>
+                                                        <div><strong>Other device:</strong> {conflict.remote_deleted ? '(deleted)' : conflict.remote_content}</div>
+                                                        <button className="cancel-btn" onClick={async () => { await ResolveConflict(conflict.id, 'local'); refreshSync(); }}>Keep this device's</button>
+                                                        <button className="cancel-btn" onClick={async () => { await ResolveConflict(conflict.id, 'remote'); refreshSync(); }}>Keep other device's</button>
+                                                    </li>
+                                                ))}
+                                            </ul>
+                                        )}
+                                    </>
+                                ) : (
+                                    <p className="setting-note">Sync is off. Configure <code>sync</code> in settings.json to sync entries between devices through WebDAV or S3.</p>
+                                )}
+                            </div>
+
+                            {/* Diagnostics */}
+                            <div className="setting-group">
+                                <label>Diagnostics</label>
+                                <p className="setting-note">Checks hotkey registration, notifications, database writes and the dashboard server.</p>
+                                <button
+                                    className="cancel-btn"
+                                    disabled={selfTestRunning}
+                                    onClick={async () => {
+                                        setSelfTestRunning(true);
+                                        try {
+                                            setSelfTestReport(await RunSelfTest());
+                                        } finally {
+                                            setSelfTestRunning(false);
+                                        }
+                                    }}
+                                >
+                                    {selfTestRunning ? 'Running...' : 'Run Self-Test'}
+                                </button>
+                                {selfTestReport && (
+                                    <ul className="self-test-results">
+                                        {selfTestReport.checks.map(check => (
+                                            <li key={check.name} style={{color: check.ok ? '#27ae60' : '#e74c3c'}}>
+                                                {check.ok ? '✓' : '✗'} <strong>{check.name}</strong>: {check.message}
+                                            </li>
+                                        ))}
+                                    </ul>
+                                )}
+                            </div>
+
+                            {/* Confirmations */}
+                            <div className="setting-group">
+                                <label>Ask Before</label>
+                                {[
+                                    ['skip_delete_entry', 'Deleting an entry'],
+                                    ['skip_batch_delete', 'Deleting several entries'],
+                                    ['skip_clear_all', 'Deleting all data'],
+                                    ['skip_purge_trash', 'Emptying the trash'],
+                                ].map(([key, label]) => (
+                                    <label className="checkbox-label" key={key}>
+                                        <input
+                                            type="checkbox"
+                                            checked={!tempSettings.confirmations?.[key]}
+                                            onChange={(e) => setTempSettings({
+                                                ...tempSettings,
+                                                confirmations: {...tempSettings.confirmations, [key]: !e.target.checked}
+                                            })}
+                                        />
+                                        {label}
+                                    </label>
+                                ))}
+                            </div>
+
                             {/* Delete All Data */}
                             <div className="setting-group">
                                 <label>Danger Zone</label>
                                 {deleteSuccess ? (
                                     <div className="delete-success">
-                                        <p style={{color: '#27ae60', margin: 0}}>✓ All data deleted successfully</p>
+                                        <p style={{color: '#27ae60', margin: 0}}>✓ Deleted {clearReport?.removed?.log_entries || 0} entries</p>
+                                        {clearReport?.backup_path && <p className="setting-note">A backup was saved to {clearReport.backup_path}</p>}
                                     </div>
                                 ) : !showDeleteConfirm ? (
                                     <button className="danger-btn" onClick={() => setShowDeleteConfirm(true)}>
@@ -648,12 +1736,29 @@ function App() {
                                     </button>
                                 ) : (
                                     <div className="delete-confirm">
-                                        <p>Are you sure? This cannot be undone.</p>
+                                        <select value={clearScope} onChange={(e) => setClearScope(e.target.value)}>
+                                            <option value="entries">Entries only</option>
+                                            <option value="entries_tags">Entries, tags and people</option>
+                                            <option value="everything">Everything, including attachments and settings</option>
+                                        </select>
+                                        <p>Are you sure? A backup is taken first, but this cannot be undone from SnapLog.</p>
+                                        {!settings.confirmations?.skip_clear_all && (
+                                            <input
+                                                type="text"
+                                                value={clearPhrase}
+                                                onChange={(e) => setClearPhrase(e.target.value)}
+                                                placeholder="Type DELETE ALL to confirm"
+                                            />
+                                        )}
                                         <div className="delete-actions">
-                                            <button className="danger-btn-confirm" onClick={handleDeleteAll}>
+                                            <button
+                                                className="danger-btn-confirm"
+                                                onClick={handleDeleteAll}
+                                                disabled={!settings.confirmations?.skip_clear_all && clearPhrase !== 'DELETE ALL'}
+                                            >
                                                 Yes, Delete All
                                             </button>
-                                            <button className="cancel-delete" onClick={() => setShowDeleteConfirm(false)}>
+                                            <button className="cancel-delete" onClick={() => { setShowDeleteConfirm(false); setClearPhrase(''); }}>
                                                 Cancel
                                             </button>
                                         </div>
@@ -670,6 +1775,37 @@ function App() {
                 </div>
             )}
 
+            {/* Unlock Modal */}
+            {showUnlock && (
+                <div className="modal-overlay">
+                    <div className="modal-content" onClick={(e) => e.stopPropagation()}>
+                        <div className="modal-header">
+                            <h2>Unlock SnapLog</h2>
+                        </div>
+
+                        <div className="modal-body">
+                            <div className="setting-group">
+                                <label>Passphrase</label>
+                                <input
+                                    type="password"
+                                    value={unlockPassphrase}
+                                    onChange={(e) => setUnlockPassphrase(e.target.value)}
+                                    onKeyDown={(e) => e.key === 'Enter' && unlockDatabase()}
+                                    autoFocus
+                                />
+                                <p className="setting-note">The database is encrypted. Enter its passphrase to open your log.</p>
+                                {unlockError && <p className="setting-note">{unlockError}</p>}
+                            </div>
+                        </div>
+
+                        <div className="modal-footer">
+                            <button className="cancel-btn" onClick={Quit}>Quit</button>
+                            <button className="save-btn" disabled={!unlockPassphrase} onClick={unlockDatabase}>Unlock</button>
+                        </div>
+                    </div>
+                </div>
+            )}
+
             {/* Instructions Modal */}
             {showInstructions && (
                 <div className="modal-overlay" onClick={() => setShowInstructions(false)}>
@@ -711,11 +1847,40 @@ function App() {
                                         <code>/editprev</code> - Edit the previous (most recent) entry
                                     </div>
                                     <div className="instruction-item">
-                                        <code>/delete &lt;id&gt;</code> - Delete an entry by ID
+                                        <code>/delete &lt;id&gt;</code> - Move an entry to the trash by ID
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/delprev</code> - Move the previous (most recent) entry to the trash
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/log &lt;when&gt; &lt;text&gt;</code> - Log something that happened earlier, e.g. /log yesterday evening Called the bank; or end any entry with @@last tuesday 3pm
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/today</code>, <code>/on &lt;day&gt;</code> - Open a day's entries in the dashboard search, e.g. /on last tuesday
                                     </div>
                                     <div className="instruction-item">
-                                        <code>/delprev</code> - Delete the previous (most recent) entry
+                                        <code>/journal &lt;name&gt;</code> - Log new entries to another journal, e.g. /journal work; /journal lists them
                                     </div>
+                                    <div className="instruction-item">
+                                        <code>/move &lt;id&gt; &lt;journal&gt;</code> - Move an entry to another journal, e.g. /move 42 personal
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/multi [#tags]</code> - Log each line below it as its own entry, with the tags added to each
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/attach &lt;path&gt;</code> - Attach a file to the most recent entry
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/template &lt;name&gt;</code> - Pre-fill the input from an entry template
+                                    </div>
+                                    <div className="instruction-item">
+                                        <code>/plan-week</code> - Draft this week's plan from open tasks, goals and the week template
+                                    </div>
+                                    {extraCommands.map(cmd => (
+                                        <div className="instruction-item" key={cmd.name}>
+                                            <code>{cmd.usage}</code> - {cmd.description || (cmd.kind === 'custom' ? 'Custom command' : 'Plugin command')}
+                                        </div>
+                                    ))}
                                 </div>
                             </div>
 
@@ -2,44 +2,300 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
-export function ClearAllData():Promise<void>;
+export function AddDaysOff(arg1:string,arg2:string,arg3:string):Promise<number>;
+
+export function AddDictionaryWord(arg1:string):Promise<void>;
+
+export function AnswerGuidedFlow(arg1:string):Promise<main.GuidedQuestion>;
+
+export function ApplyTagAliases():Promise<main.TagAliasReport>;
+
+export function AttachFile(arg1:number,arg2:string):Promise<main.Attachment>;
+
+export function BackupNow():Promise<string>;
+
+export function CancelDelete(arg1:number):Promise<void>;
+
+export function CancelGuidedFlow():Promise<void>;
+
+export function CaptureWindowActivity():Promise<void>;
+
+export function ChangeDatabasePassphrase(arg1:string,arg2:string):Promise<void>;
+
+export function ClearAllData(arg1:string,arg2:string):Promise<main.ClearReport>;
+
+export function CompactDatabase():Promise<main.StorageStats>;
+
+export function ConfirmDelete(arg1:number):Promise<void>;
+
+export function ConnectGoogleDrive(arg1:string):Promise<void>;
+
+export function DecryptDatabase(arg1:string):Promise<void>;
+
+export function DeleteAttachment(arg1:number):Promise<void>;
+
+export function DeleteEntries(arg1:Array<number>):Promise<number>;
 
 export function DeleteEntry(arg1:number):Promise<void>;
 
+export function DeleteShortcut(arg1:string):Promise<void>;
+
+export function DisablePlugin(arg1:string):Promise<void>;
+
+export function EnablePlugin(arg1:string):Promise<void>;
+
+export function EncryptDatabase(arg1:string):Promise<void>;
+
+export function EndMeeting():Promise<string>;
+
+export function EntryURL(arg1:number):Promise<string>;
+
+export function ExpandShortcuts(arg1:string):Promise<string>;
+
+export function ExpandTemplate(arg1:string):Promise<string>;
+
+export function ExportJSON(arg1:string):Promise<main.JSONExportReport>;
+
+export function ExportMarkdown(arg1:string,arg2:any,arg3:any):Promise<main.MarkdownExportReport>;
+
+export function ExportPivotCSV():Promise<string>;
+
+export function ExportTemplateBundle():Promise<string>;
+
+export function ExportTimesheet(arg1:string,arg2:string):Promise<main.TimesheetReport>;
+
+export function GenerateSyncRecoveryCodes():Promise<Array<string>>;
+
+export function GetActiveJournal():Promise<string>;
+
+export function GetActiveMeeting():Promise<main.ActiveMeeting>;
+
+export function GetActiveTimer():Promise<main.ActiveTimer>;
+
+export function GetAttachments(arg1:number):Promise<Array<main.Attachment>>;
+
+export function GetCaptureMode():Promise<string>;
+
+export function GetCommands():Promise<Array<main.CommandInfo>>;
+
+export function GetConfirmationPolicy():Promise<Record<string, boolean>>;
+
+export function GetContextSwitches(arg1:number):Promise<Array<main.DailyContextSwitches>>;
+
+export function GetDatabaseEncryption():Promise<main.DatabaseEncryption>;
+
 export function GetDatabasePath():Promise<string>;
 
+export function GetDaysOff(arg1:string,arg2:string):Promise<Array<main.DayOff>>;
+
+export function GetDictionary():Promise<main.SpellcheckDictionary>;
+
+export function GetDraft(arg1:number):Promise<main.CaptureDraft>;
+
+export function GetEntriesByTag(arg1:string,arg2:number):Promise<Array<main.LogEntry>>;
+
 export function GetEntryByID(arg1:number):Promise<main.LogEntry>;
 
 export function GetEntryForEdit(arg1:number):Promise<string>;
 
+export function GetEntryHistory(arg1:number):Promise<Array<main.EntryRevision>>;
+
 export function GetEntryPreview(arg1:number):Promise<string>;
 
+export function GetEstimationStats(arg1:number):Promise<main.EstimationStats>;
+
+export function GetGuidedFlowNames():Promise<Array<string>>;
+
+export function GetGuidedQuestion():Promise<main.GuidedQuestion>;
+
+export function GetHealth():Promise<main.HealthStatus>;
+
+export function GetHeatmap(arg1:number):Promise<main.Heatmap>;
+
+export function GetJournals():Promise<Array<main.Journal>>;
+
 export function GetLogEntries(arg1:number):Promise<Array<main.LogEntry>>;
 
+export function GetLogEntriesBySource(arg1:string,arg2:number):Promise<Array<main.LogEntry>>;
+
 export function GetLogEntriesCount():Promise<number>;
 
+export function GetMilestones(arg1:string):Promise<Array<main.LogEntry>>;
+
 export function GetMostRecentEntry():Promise<main.LogEntry>;
 
+export function GetOpenTasks(arg1:number):Promise<Array<main.Task>>;
+
+export function GetPendingNotifications():Promise<Array<main.PendingNotification>>;
+
+export function GetPeople():Promise<Array<main.Person>>;
+
+export function GetPeopleDirectory():Promise<Array<main.Person>>;
+
+export function GetPerson(arg1:string):Promise<main.Person>;
+
+export function GetPersonReport(arg1:string,arg2:any):Promise<main.PersonReport>;
+
+export function GetProject(arg1:string):Promise<main.Project>;
+
+export function GetProjectReport(arg1:string):Promise<main.ProjectReport>;
+
+export function GetProjects():Promise<Array<main.Project>>;
+
+export function GetReminderStats(arg1:number):Promise<main.ReminderStats>;
+
 export function GetSettings():Promise<main.Settings>;
 
+export function GetShortcuts():Promise<Array<main.Shortcut>>;
+
+export function GetSlowOperations(arg1:number):Promise<Array<main.SlowOperation>>;
+
+export function GetStatus():Promise<main.StatusResponse>;
+
+export function GetStorageStats():Promise<main.StorageStats>;
+
+export function GetStreakStats():Promise<main.StreakStats>;
+
+export function GetSyncStatus():Promise<main.SyncStatus>;
+
+export function GetTagAliases():Promise<Array<main.TagAlias>>;
+
 export function GetTags():Promise<Array<main.Tag>>;
 
+export function GetTaskBurndown(arg1:string,arg2:number):Promise<Array<main.TaskSnapshot>>;
+
+export function GetTaskForecast(arg1:string):Promise<main.TaskForecast>;
+
+export function GetTemplateNames():Promise<Array<string>>;
+
+export function GetTimesheet(arg1:string):Promise<Array<main.TimesheetLine>>;
+
+export function GetTodaysCalendar():Promise<Array<main.CalendarEvent>>;
+
+export function GetTopicDrift(arg1:number,arg2:string):Promise<Array<main.TopicPeriod>>;
+
+export function GetTrashedEntries():Promise<Array<main.TrashedEntry>>;
+
+export function GetWeekPlanDraft():Promise<main.WeekPlan>;
+
+export function GetWorkloadStats(arg1:number):Promise<main.WorkloadStats>;
+
 export function HideWindow():Promise<void>;
 
+export function ImportEntries(arg1:string,arg2:string,arg3:boolean):Promise<main.ImportReport>;
+
+export function ImportHolidays(arg1:string):Promise<number>;
+
+export function ImportTemplateBundle(arg1:string):Promise<void>;
+
+export function IsCommand(arg1:string):Promise<boolean>;
+
 export function IsFirstRun():Promise<boolean>;
 
+export function IsFocusModeActive():Promise<boolean>;
+
+export function ListConflicts():Promise<Array<main.SyncConflict>>;
+
+export function ListDevices():Promise<Array<main.SyncDevice>>;
+
+export function ListPlugins():Promise<Array<main.PluginInfo>>;
+
+export function LogAndHide(arg1:string):Promise<main.SubmitResult>;
+
+export function LogMarker():Promise<void>;
+
+export function LogMilestone(arg1:string):Promise<number>;
+
 export function LogText(arg1:string):Promise<void>;
 
+export function MergeEntries(arg1:Array<number>):Promise<number>;
+
+export function MoveEntry(arg1:number,arg2:string):Promise<void>;
+
 export function OpenSettings():Promise<void>;
 
+export function PasteClipboardImage():Promise<string>;
+
+export function PlanDay():Promise<main.DayPlan>;
+
 export function ProcessCommand(arg1:string):Promise<void>;
 
+export function PurgeTrash():Promise<number>;
+
+export function QuickOpen(arg1:string):Promise<Array<main.QuickOpenItem>>;
+
 export function Quit():Promise<void>;
 
+export function RecoverSyncPassphrase(arg1:string,arg2:string):Promise<void>;
+
+export function RemoveDayOff(arg1:string):Promise<void>;
+
+export function RemoveDictionaryWord(arg1:string):Promise<void>;
+
+export function RemoveProject(arg1:string):Promise<void>;
+
+export function RemoveTagAlias(arg1:string):Promise<void>;
+
 export function RenderMarkdown(arg1:string):Promise<string>;
 
+export function RenderMarkdownPreview(arg1:string):Promise<string>;
+
+export function ResolveConflict(arg1:number,arg2:string):Promise<void>;
+
+export function RestoreEntry(arg1:number):Promise<void>;
+
+export function RevokeDevice(arg1:string,arg2:string):Promise<Array<string>>;
+
+export function RunReadOnlyQuery(arg1:string):Promise<main.QueryResult>;
+
+export function RunSelfTest():Promise<main.SelfTestReport>;
+
+export function SaveDraft(arg1:number,arg2:string,arg3:string):Promise<void>;
+
+export function SaveShortcut(arg1:string,arg2:string):Promise<void>;
+
+export function SearchEntries(arg1:string,arg2:number):Promise<Array<main.SearchResult>>;
+
+export function SetCaptureMode(arg1:string):Promise<void>;
+
+export function SetDoNotDisturb(arg1:number):Promise<void>;
+
+export function SetProject(arg1:string,arg2:string):Promise<main.Project>;
+
+export function SetProjectBilling(arg1:string,arg2:string,arg3:number,arg4:number):Promise<main.Project>;
+
 export function SetSettings(arg1:main.Settings):Promise<void>;
 
+export function SetSpellcheckLanguage(arg1:string):Promise<void>;
+
+export function SetTagAlias(arg1:string,arg2:string):Promise<void>;
+
 export function ShowWindow():Promise<void>;
 
+export function SkipRemindersToday():Promise<void>;
+
+export function SnoozeReminder(arg1:number):Promise<void>;
+
+export function SplitEntry(arg1:number,arg2:Array<number>):Promise<Array<number>>;
+
+export function StartGuidedFlow(arg1:string):Promise<main.GuidedQuestion>;
+
+export function StartMeeting(arg1:string):Promise<main.ActiveMeeting>;
+
+export function StartTimer(arg1:string):Promise<main.ActiveTimer>;
+
+export function StopTimer():Promise<string>;
+
+export function SwitchJournal(arg1:string):Promise<void>;
+
+export function SyncNow():Promise<void>;
+
+export function UnlockDatabase(arg1:string):Promise<void>;
+
 export function UpdateEntry(arg1:number,arg2:string):Promise<void>;
+
+export function UpdatePerson(arg1:string,arg2:main.PersonUpdate):Promise<main.Person>;
+
+export function UploadBackup():Promise<main.BackupReport>;
+
+export function VerifyBackup(arg1:string):Promise<number>;
@@ -2,18 +2,190 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function ClearAllData() {
-  return window['go']['main']['App']['ClearAllData']();
+export function AddDaysOff(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddDaysOff'](arg1, arg2, arg3);
+}
+
+export function AddDictionaryWord(arg1) {
+  return window['go']['main']['App']['AddDictionaryWord'](arg1);
+}
+
+export function AnswerGuidedFlow(arg1) {
+  return window['go']['main']['App']['AnswerGuidedFlow'](arg1);
+}
+
+export function ApplyTagAliases() {
+  return window['go']['main']['App']['ApplyTagAliases']();
+}
+
+export function AttachFile(arg1, arg2) {
+  return window['go']['main']['App']['AttachFile'](arg1, arg2);
+}
+
+export function BackupNow() {
+  return window['go']['main']['App']['BackupNow']();
+}
+
+export function CancelDelete(arg1) {
+  return window['go']['main']['App']['CancelDelete'](arg1);
+}
+
+export function CancelGuidedFlow() {
+  return window['go']['main']['App']['CancelGuidedFlow']();
+}
+
+export function CaptureWindowActivity() {
+  return window['go']['main']['App']['CaptureWindowActivity']();
+}
+
+export function ChangeDatabasePassphrase(arg1, arg2) {
+  return window['go']['main']['App']['ChangeDatabasePassphrase'](arg1, arg2);
+}
+
+export function ClearAllData(arg1, arg2) {
+  return window['go']['main']['App']['ClearAllData'](arg1, arg2);
+}
+
+export function CompactDatabase() {
+  return window['go']['main']['App']['CompactDatabase']();
+}
+
+export function ConfirmDelete(arg1) {
+  return window['go']['main']['App']['ConfirmDelete'](arg1);
+}
+
+export function ConnectGoogleDrive(arg1) {
+  return window['go']['main']['App']['ConnectGoogleDrive'](arg1);
+}
+
+export function DecryptDatabase(arg1) {
+  return window['go']['main']['App']['DecryptDatabase'](arg1);
+}
+
+export function DeleteAttachment(arg1) {
+  return window['go']['main']['App']['DeleteAttachment'](arg1);
+}
+
+export function DeleteEntries(arg1) {
+  return window['go']['main']['App']['DeleteEntries'](arg1);
 }
 
 export function DeleteEntry(arg1) {
   return window['go']['main']['App']['DeleteEntry'](arg1);
 }
 
+export function DeleteShortcut(arg1) {
+  return window['go']['main']['App']['DeleteShortcut'](arg1);
+}
+
+export function DisablePlugin(arg1) {
+  return window['go']['main']['App']['DisablePlugin'](arg1);
+}
+
+export function EnablePlugin(arg1) {
+  return window['go']['main']['App']['EnablePlugin'](arg1);
+}
+
+export function EncryptDatabase(arg1) {
+  return window['go']['main']['App']['EncryptDatabase'](arg1);
+}
+
+export function EndMeeting() {
+  return window['go']['main']['App']['EndMeeting']();
+}
+
+export function EntryURL(arg1) {
+  return window['go']['main']['App']['EntryURL'](arg1);
+}
+
+export function ExpandShortcuts(arg1) {
+  return window['go']['main']['App']['ExpandShortcuts'](arg1);
+}
+
+export function ExpandTemplate(arg1) {
+  return window['go']['main']['App']['ExpandTemplate'](arg1);
+}
+
+export function ExportJSON(arg1) {
+  return window['go']['main']['App']['ExportJSON'](arg1);
+}
+
+export function ExportMarkdown(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportMarkdown'](arg1, arg2, arg3);
+}
+
+export function ExportPivotCSV() {
+  return window['go']['main']['App']['ExportPivotCSV']();
+}
+
+export function ExportTemplateBundle() {
+  return window['go']['main']['App']['ExportTemplateBundle']();
+}
+
+export function ExportTimesheet(arg1, arg2) {
+  return window['go']['main']['App']['ExportTimesheet'](arg1, arg2);
+}
+
+export function GenerateSyncRecoveryCodes() {
+  return window['go']['main']['App']['GenerateSyncRecoveryCodes']();
+}
+
+export function GetActiveJournal() {
+  return window['go']['main']['App']['GetActiveJournal']();
+}
+
+export function GetActiveMeeting() {
+  return window['go']['main']['App']['GetActiveMeeting']();
+}
+
+export function GetActiveTimer() {
+  return window['go']['main']['App']['GetActiveTimer']();
+}
+
+export function GetAttachments(arg1) {
+  return window['go']['main']['App']['GetAttachments'](arg1);
+}
+
+export function GetCaptureMode() {
+  return window['go']['main']['App']['GetCaptureMode']();
+}
+
+export function GetCommands() {
+  return window['go']['main']['App']['GetCommands']();
+}
+
+export function GetConfirmationPolicy() {
+  return window['go']['main']['App']['GetConfirmationPolicy']();
+}
+
+export function GetContextSwitches(arg1) {
+  return window['go']['main']['App']['GetContextSwitches'](arg1);
+}
+
+export function GetDatabaseEncryption() {
+  return window['go']['main']['App']['GetDatabaseEncryption']();
+}
+
 export function GetDatabasePath() {
   return window['go']['main']['App']['GetDatabasePath']();
 }
 
+export function GetDaysOff(arg1, arg2) {
+  return window['go']['main']['App']['GetDaysOff'](arg1, arg2);
+}
+
+export function GetDictionary() {
+  return window['go']['main']['App']['GetDictionary']();
+}
+
+export function GetDraft(arg1) {
+  return window['go']['main']['App']['GetDraft'](arg1);
+}
+
+export function GetEntriesByTag(arg1, arg2) {
+  return window['go']['main']['App']['GetEntriesByTag'](arg1, arg2);
+}
+
 export function GetEntryByID(arg1) {
   return window['go']['main']['App']['GetEntryByID'](arg1);
 }
@@ -22,66 +194,406 @@ export function GetEntryForEdit(arg1) {
   return window['go']['main']['App']['GetEntryForEdit'](arg1);
 }
 
+export function GetEntryHistory(arg1) {
+  return window['go']['main']['App']['GetEntryHistory'](arg1);
+}
+
 export function GetEntryPreview(arg1) {
   return window['go']['main']['App']['GetEntryPreview'](arg1);
 }
 
+export function GetEstimationStats(arg1) {
+  return window['go']['main']['App']['GetEstimationStats'](arg1);
+}
+
+export function GetGuidedFlowNames() {
+  return window['go']['main']['App']['GetGuidedFlowNames']();
+}
+
+export function GetGuidedQuestion() {
+  return window['go']['main']['App']['GetGuidedQuestion']();
+}
+
+export function GetHealth() {
+  return window['go']['main']['App']['GetHealth']();
+}
+
+export function GetHeatmap(arg1) {
+  return window['go']['main']['App']['GetHeatmap'](arg1);
+}
+
+export function GetJournals() {
+  return window['go']['main']['App']['GetJournals']();
+}
+
 export function GetLogEntries(arg1) {
   return window['go']['main']['App']['GetLogEntries'](arg1);
 }
 
+export function GetLogEntriesBySource(arg1, arg2) {
+  return window['go']['main']['App']['GetLogEntriesBySource'](arg1, arg2);
+}
+
 export function GetLogEntriesCount() {
   return window['go']['main']['App']['GetLogEntriesCount']();
 }
 
+export function GetMilestones(arg1) {
+  return window['go']['main']['App']['GetMilestones'](arg1);
+}
+
 export function GetMostRecentEntry() {
   return window['go']['main']['App']['GetMostRecentEntry']();
 }
 
+export function GetOpenTasks(arg1) {
+  return window['go']['main']['App']['GetOpenTasks'](arg1);
+}
+
+export function GetPendingNotifications() {
+  return window['go']['main']['App']['GetPendingNotifications']();
+}
+
+export function GetPeople() {
+  return window['go']['main']['App']['GetPeople']();
+}
+
+export function GetPeopleDirectory() {
+  return window['go']['main']['App']['GetPeopleDirectory']();
+}
+
+export function GetPerson(arg1) {
+  return window['go']['main']['App']['GetPerson'](arg1);
+}
+
+export function GetPersonReport(arg1, arg2) {
+  return window['go']['main']['App']['GetPersonReport'](arg1, arg2);
+}
+
+export function GetProject(arg1) {
+  return window['go']['main']['App']['GetProject'](arg1);
+}
+
+export function GetProjectReport(arg1) {
+  return window['go']['main']['App']['GetProjectReport'](arg1);
+}
+
+export function GetProjects() {
+  return window['go']['main']['App']['GetProjects']();
+}
+
+export function GetReminderStats(arg1) {
+  return window['go']['main']['App']['GetReminderStats'](arg1);
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetShortcuts() {
+  return window['go']['main']['App']['GetShortcuts']();
+}
+
+export function GetSlowOperations(arg1) {
+  return window['go']['main']['App']['GetSlowOperations'](arg1);
+}
+
+export function GetStatus() {
+  return window['go']['main']['App']['GetStatus']();
+}
+
+export function GetStorageStats() {
+  return window['go']['main']['App']['GetStorageStats']();
+}
+
+export function GetStreakStats() {
+  return window['go']['main']['App']['GetStreakStats']();
+}
+
+export function GetSyncStatus() {
+  return window['go']['main']['App']['GetSyncStatus']();
+}
+
+export function GetTagAliases() {
+  return window['go']['main']['App']['GetTagAliases']();
+}
+
 export function GetTags() {
   return window['go']['main']['App']['GetTags']();
 }
 
+export function GetTaskBurndown(arg1, arg2) {
+  return window['go']['main']['App']['GetTaskBurndown'](arg1, arg2);
+}
+
+export function GetTaskForecast(arg1) {
+  return window['go']['main']['App']['GetTaskForecast'](arg1);
+}
+
+export function GetTemplateNames() {
+  return window['go']['main']['App']['GetTemplateNames']();
+}
+
+export function GetTimesheet(arg1) {
+  return window['go']['main']['App']['GetTimesheet'](arg1);
+}
+
+export function GetTodaysCalendar() {
+  return window['go']['main']['App']['GetTodaysCalendar']();
+}
+
+export function GetTopicDrift(arg1, arg2) {
+  return window['go']['main']['App']['GetTopicDrift'](arg1, arg2);
+}
+
+export function GetTrashedEntries() {
+  return window['go']['main']['App']['GetTrashedEntries']();
+}
+
+export function GetWeekPlanDraft() {
+  return window['go']['main']['App']['GetWeekPlanDraft']();
+}
+
+export function GetWorkloadStats(arg1) {
+  return window['go']['main']['App']['GetWorkloadStats'](arg1);
+}
+
 export function HideWindow() {
   return window['go']['main']['App']['HideWindow']();
 }
 
+export function ImportEntries(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportEntries'](arg1, arg2, arg3);
+}
+
+export function ImportHolidays(arg1) {
+  return window['go']['main']['App']['ImportHolidays'](arg1);
+}
+
+export function ImportTemplateBundle(arg1) {
+  return window['go']['main']['App']['ImportTemplateBundle'](arg1);
+}
+
+export function IsCommand(arg1) {
+  return window['go']['main']['App']['IsCommand'](arg1);
+}
+
 export function IsFirstRun() {
   return window['go']['main']['App']['IsFirstRun']();
 }
 
+export function IsFocusModeActive() {
+  return window['go']['main']['App']['IsFocusModeActive']();
+}
+
+export function ListConflicts() {
+  return window['go']['main']['App']['ListConflicts']();
+}
+
+export function ListDevices() {
+  return window['go']['main']['App']['ListDevices']();
+}
+
+export function ListPlugins() {
+  return window['go']['main']['App']['ListPlugins']();
+}
+
+export function LogAndHide(arg1) {
+  return window['go']['main']['App']['LogAndHide'](arg1);
+}
+
+export function LogMarker() {
+  return window['go']['main']['App']['LogMarker']();
+}
+
+export function LogMilestone(arg1) {
+  return window['go']['main']['App']['LogMilestone'](arg1);
+}
+
 export function LogText(arg1) {
   return window['go']['main']['App']['LogText'](arg1);
 }
 
+export function MergeEntries(arg1) {
+  return window['go']['main']['App']['MergeEntries'](arg1);
+}
+
+export function MoveEntry(arg1, arg2) {
+  return window['go']['main']['App']['MoveEntry'](arg1, arg2);
+}
+
 export function OpenSettings() {
   return window['go']['main']['App']['OpenSettings']();
 }
 
+export function PasteClipboardImage() {
+  return window['go']['main']['App']['PasteClipboardImage']();
+}
+
+export function PlanDay() {
+  return window['go']['main']['App']['PlanDay']();
+}
+
 export function ProcessCommand(arg1) {
   return window['go']['main']['App']['ProcessCommand'](arg1);
 }
 
+export function PurgeTrash() {
+  return window['go']['main']['App']['PurgeTrash']();
+}
+
+export function QuickOpen(arg1) {
+  return window['go']['main']['App']['QuickOpen'](arg1);
+}
+
 export function Quit() {
   return window['go']['main']['App']['Quit']();
 }
 
+export function RecoverSyncPassphrase(arg1, arg2) {
+  return window['go']['main']['App']['RecoverSyncPassphrase'](arg1, arg2);
+}
+
+export function RemoveDayOff(arg1) {
+  return window['go']['main']['App']['RemoveDayOff'](arg1);
+}
+
+export function RemoveDictionaryWord(arg1) {
+  return window['go']['main']['App']['RemoveDictionaryWord'](arg1);
+}
+
+export function RemoveProject(arg1) {
+  return window['go']['main']['App']['RemoveProject'](arg1);
+}
+
+export function RemoveTagAlias(arg1) {
+  return window['go']['main']['App']['RemoveTagAlias'](arg1);
+}
+
 export function RenderMarkdown(arg1) {
   return window['go']['main']['App']['RenderMarkdown'](arg1);
 }
 
+export function RenderMarkdownPreview(arg1) {
+  return window['go']['main']['App']['RenderMarkdownPreview'](arg1);
+}
+
+export function ResolveConflict(arg1, arg2) {
+  return window['go']['main']['App']['ResolveConflict'](arg1, arg2);
+}
+
+export function RestoreEntry(arg1) {
+  return window['go']['main']['App']['RestoreEntry'](arg1);
+}
+
+export function RevokeDevice(arg1, arg2) {
+  return window['go']['main']['App']['RevokeDevice'](arg1, arg2);
+}
+
+export function RunReadOnlyQuery(arg1) {
+  return window['go']['main']['App']['RunReadOnlyQuery'](arg1);
+}
+
+export function RunSelfTest() {
+  return window['go']['main']['App']['RunSelfTest']();
+}
+
+export function SaveDraft(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveDraft'](arg1, arg2, arg3);
+}
+
+export function SaveShortcut(arg1, arg2) {
+  return window['go']['main']['App']['SaveShortcut'](arg1, arg2);
+}
+
+export function SearchEntries(arg1, arg2) {
+  return window['go']['main']['App']['SearchEntries'](arg1, arg2);
+}
+
+export function SetCaptureMode(arg1) {
+  return window['go']['main']['App']['SetCaptureMode'](arg1);
+}
+
+export function SetDoNotDisturb(arg1) {
+  return window['go']['main']['App']['SetDoNotDisturb'](arg1);
+}
+
+export function SetProject(arg1, arg2) {
+  return window['go']['main']['App']['SetProject'](arg1, arg2);
+}
+
+export function SetProjectBilling(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetProjectBilling'](arg1, arg2, arg3, arg4);
+}
+
 export function SetSettings(arg1) {
   return window['go']['main']['App']['SetSettings'](arg1);
 }
 
+export function SetSpellcheckLanguage(arg1) {
+  return window['go']['main']['App']['SetSpellcheckLanguage'](arg1);
+}
+
+export function SetTagAlias(arg1, arg2) {
+  return window['go']['main']['App']['SetTagAlias'](arg1, arg2);
+}
+
 export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
 
+export function SkipRemindersToday() {
+  return window['go']['main']['App']['SkipRemindersToday']();
+}
+
+export function SnoozeReminder(arg1) {
+  return window['go']['main']['App']['SnoozeReminder'](arg1);
+}
+
+export function SplitEntry(arg1, arg2) {
+  return window['go']['main']['App']['SplitEntry'](arg1, arg2);
+}
+
+export function StartGuidedFlow(arg1) {
+  return window['go']['main']['App']['StartGuidedFlow'](arg1);
+}
+
+export function StartMeeting(arg1) {
+  return window['go']['main']['App']['StartMeeting'](arg1);
+}
+
+export function StartTimer(arg1) {
+  return window['go']['main']['App']['StartTimer'](arg1);
+}
+
+export function StopTimer() {
+  return window['go']['main']['App']['StopTimer']();
+}
+
+export function SwitchJournal(arg1) {
+  return window['go']['main']['App']['SwitchJournal'](arg1);
+}
+
+export function SyncNow() {
+  return window['go']['main']['App']['SyncNow']();
+}
+
+export function UnlockDatabase(arg1) {
+  return window['go']['main']['App']['UnlockDatabase'](arg1);
+}
+
 export function UpdateEntry(arg1, arg2) {
   return window['go']['main']['App']['UpdateEntry'](arg1, arg2);
 }
+
+export function UpdatePerson(arg1, arg2) {
+  return window['go']['main']['App']['UpdatePerson'](arg1, arg2);
+}
+
+export function UploadBackup() {
+  return window['go']['main']['App']['UploadBackup']();
+}
+
+export function VerifyBackup(arg1) {
+  return window['go']['main']['App']['VerifyBackup'](arg1);
+}
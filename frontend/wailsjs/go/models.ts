@@ -1,19 +1,1948 @@
 export namespace main {
 	
+	export class ActiveMeeting {
+	    id: number;
+	    entry_id: number;
+	    title: string;
+	    // Go type: time
+	    started_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActiveMeeting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entry_id = source["entry_id"];
+	        this.title = source["title"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ActiveTimer {
+	    id: number;
+	    entry_id: number;
+	    task_id?: number;
+	    label: string;
+	    // Go type: time
+	    started_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActiveTimer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entry_id = source["entry_id"];
+	        this.task_id = source["task_id"];
+	        this.label = source["label"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Attachment {
+	    id: number;
+	    entry_id: number;
+	    name: string;
+	    mime_type: string;
+	    size: number;
+	    url: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Attachment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entry_id = source["entry_id"];
+	        this.name = source["name"];
+	        this.mime_type = source["mime_type"];
+	        this.size = source["size"];
+	        this.url = source["url"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchResult {
+	    index: number;
+	    ok: boolean;
+	    id?: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.ok = source["ok"];
+	        this.id = source["id"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BackupReport {
+	    path: string;
+	    entries: number;
+	    verified: boolean;
+	    uploads: BackupUpload[];
+	    // Go type: time
+	    ran_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.entries = source["entries"];
+	        this.verified = source["verified"];
+	        this.uploads = this.convertValues(source["uploads"], BackupUpload);
+	        this.ran_at = this.convertValues(source["ran_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupTarget {
+	    name: string;
+	    type: string;
+	    enabled: boolean;
+	    url: string;
+	    bucket: string;
+	    region: string;
+	    access_key_id: string;
+	    secret_access_key: string;
+	    username: string;
+	    password: string;
+	    client_id: string;
+	    client_secret: string;
+	    refresh_token: string;
+	    folder_id: string;
+	    prefix: string;
+	    keep: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupTarget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.enabled = source["enabled"];
+	        this.url = source["url"];
+	        this.bucket = source["bucket"];
+	        this.region = source["region"];
+	        this.access_key_id = source["access_key_id"];
+	        this.secret_access_key = source["secret_access_key"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.client_id = source["client_id"];
+	        this.client_secret = source["client_secret"];
+	        this.refresh_token = source["refresh_token"];
+	        this.folder_id = source["folder_id"];
+	        this.prefix = source["prefix"];
+	        this.keep = source["keep"];
+	    }
+	}
+	export class BackupUpload {
+	    target: string;
+	    ok: boolean;
+	    message: string;
+	    removed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupUpload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.ok = source["ok"];
+	        this.message = source["message"];
+	        this.removed = source["removed"];
+	    }
+	}
+	export class CalendarEvent {
+	    summary: string;
+	    // Go type: time
+	    start: any;
+	    // Go type: time
+	    end: any;
+	    all_day: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalendarEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.summary = source["summary"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.all_day = source["all_day"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CaptureDraft {
+	    entry_id: number;
+	    content: string;
+	    mode: string;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CaptureDraft(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entry_id = source["entry_id"];
+	        this.content = source["content"];
+	        this.mode = source["mode"];
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ClearReport {
+	    scope: string;
+	    backup_path: string;
+	    removed: Record<string, number>;
+	    attachments_bytes: number;
+	    settings_reset: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClearReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scope = source["scope"];
+	        this.backup_path = source["backup_path"];
+	        this.removed = source["removed"];
+	        this.attachments_bytes = source["attachments_bytes"];
+	        this.settings_reset = source["settings_reset"];
+	    }
+	}
+	export class CommandInfo {
+	    name: string;
+	    usage: string;
+	    description: string;
+	    kind: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommandInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.usage = source["usage"];
+	        this.description = source["description"];
+	        this.kind = source["kind"];
+	    }
+	}
+	export class ConfirmationSettings {
+	    skip_delete_entry: boolean;
+	    skip_batch_delete: boolean;
+	    skip_clear_all: boolean;
+	    skip_purge_trash: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfirmationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.skip_delete_entry = source["skip_delete_entry"];
+	        this.skip_batch_delete = source["skip_batch_delete"];
+	        this.skip_clear_all = source["skip_clear_all"];
+	        this.skip_purge_trash = source["skip_purge_trash"];
+	    }
+	}
+	export class CustomCommand {
+	    name: string;
+	    description: string;
+	    log: string;
+	    template: string;
+	    filter: string;
+	    webhook: string;
+	    dnd_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CustomCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.log = source["log"];
+	        this.template = source["template"];
+	        this.filter = source["filter"];
+	        this.webhook = source["webhook"];
+	        this.dnd_minutes = source["dnd_minutes"];
+	    }
+	}
+	export class DailyContextSwitches {
+	    date: string;
+	    window_switches: number;
+	    markers: number;
+	    rapid_entries: number;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyContextSwitches(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.window_switches = source["window_switches"];
+	        this.markers = source["markers"];
+	        this.rapid_entries = source["rapid_entries"];
+	        this.total = source["total"];
+	    }
+	}
+	export class DatabaseEncryption {
+	    encrypted: boolean;
+	    locked: boolean;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DatabaseEncryption(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.encrypted = source["encrypted"];
+	        this.locked = source["locked"];
+	        this.path = source["path"];
+	    }
+	}
+	export class DayOff {
+	    date: string;
+	    name: string;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayOff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.name = source["name"];
+	        this.source = source["source"];
+	    }
+	}
+	export class DayPlan {
+	    date: string;
+	    entry_id: number;
+	    blocks: PlanBlock[];
+	    unscheduled: Task[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DayPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.entry_id = source["entry_id"];
+	        this.blocks = this.convertValues(source["blocks"], PlanBlock);
+	        this.unscheduled = this.convertValues(source["unscheduled"], Task);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EntryRevision {
+	    content: string;
+	    // Go type: time
+	    edited_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new EntryRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.content = source["content"];
+	        this.edited_at = this.convertValues(source["edited_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EstimationStats {
+	    weeks: WeeklyEstimates[];
+	    recent: TaskEstimate[];
+	    ratio: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EstimationStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.weeks = this.convertValues(source["weeks"], WeeklyEstimates);
+	        this.recent = this.convertValues(source["recent"], TaskEstimate);
+	        this.ratio = source["ratio"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GuidedFlow {
+	    title: string;
+	    questions: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GuidedFlow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.questions = source["questions"];
+	    }
+	}
+	export class GuidedQuestion {
+	    flow: string;
+	    title: string;
+	    question: string;
+	    step: number;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GuidedQuestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flow = source["flow"];
+	        this.title = source["title"];
+	        this.question = source["question"];
+	        this.step = source["step"];
+	        this.total = source["total"];
+	    }
+	}
+	export class HealthStatus {
+	    status: string;
+	    database: string;
+	    disk_free_bytes: number;
+	    // Go type: time
+	    last_backup: any;
+	    sync: string;
+	    // Go type: time
+	    checked_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new HealthStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.database = source["database"];
+	        this.disk_free_bytes = source["disk_free_bytes"];
+	        this.last_backup = this.convertValues(source["last_backup"], null);
+	        this.sync = source["sync"];
+	        this.checked_at = this.convertValues(source["checked_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Heatmap {
+	    from: string;
+	    to: string;
+	    max: number;
+	    total: number;
+	    days: HeatmapDay[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Heatmap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.max = source["max"];
+	        this.total = source["total"];
+	        this.days = this.convertValues(source["days"], HeatmapDay);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HeatmapDay {
+	    date: string;
+	    weekday: number;
+	    count: number;
+	    level: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HeatmapDay(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.weekday = source["weekday"];
+	        this.count = source["count"];
+	        this.level = source["level"];
+	    }
+	}
+	export class HomeAssistantSettings {
+	    enabled: boolean;
+	    webhook_url: string;
+	    token: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HomeAssistantSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.webhook_url = source["webhook_url"];
+	        this.token = source["token"];
+	    }
+	}
+	export class HotkeyGestures {
+	    single: string;
+	    double: string;
+	    hold: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HotkeyGestures(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.single = source["single"];
+	        this.double = source["double"];
+	        this.hold = source["hold"];
+	    }
+	}
+	export class ImportReport {
+	    format: string;
+	    dry_run: boolean;
+	    records: number;
+	    created: number;
+	    duplicates: number;
+	    errors: BatchResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.dry_run = source["dry_run"];
+	        this.records = source["records"];
+	        this.created = source["created"];
+	        this.duplicates = source["duplicates"];
+	        this.errors = this.convertValues(source["errors"], BatchResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InboundHook {
+	    name: string;
+	    secret: string;
+	    template: string;
+	    tags: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new InboundHook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.secret = source["secret"];
+	        this.template = source["template"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class JSONExportReport {
+	    path: string;
+	    entries: number;
+	    tags: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JSONExportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.entries = source["entries"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class Journal {
+	    id: number;
+	    name: string;
+	    entries: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Journal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.entries = source["entries"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class LogEntry {
 	    id: number;
-	    content: string;
+	    content: string;
+	    // Go type: time
+	    created_at: any;
+	    source: string;
+	    repeat_count: number;
+	    entry_type: string;
+	    journal_id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.content = source["content"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.source = source["source"];
+	        this.repeat_count = source["repeat_count"];
+	        this.entry_type = source["entry_type"];
+	        this.journal_id = source["journal_id"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MarkdownExportReport {
+	    dir: string;
+	    files: number;
+	    entries: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MarkdownExportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.files = source["files"];
+	        this.entries = source["entries"];
+	    }
+	}
+	export class PendingNotification {
+	    title: string;
+	    message: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PendingNotification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.message = source["message"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Person {
+	    id: number;
+	    name: string;
+	    role: string;
+	    team: string;
+	    email: string;
+	    mentions: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Person(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.role = source["role"];
+	        this.team = source["team"];
+	        this.email = source["email"];
+	        this.mentions = source["mentions"];
+	    }
+	}
+	export class PersonReport {
+	    person: Person;
+	    // Go type: time
+	    since: any;
+	    entries: LogEntry[];
+	    decisions: LogEntry[];
+	    open_tasks: Task[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PersonReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.person = this.convertValues(source["person"], Person);
+	        this.since = this.convertValues(source["since"], null);
+	        this.entries = this.convertValues(source["entries"], LogEntry);
+	        this.decisions = this.convertValues(source["decisions"], LogEntry);
+	        this.open_tasks = this.convertValues(source["open_tasks"], Task);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PersonUpdate {
+	    role: string;
+	    team: string;
+	    email: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PersonUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.role = source["role"];
+	        this.team = source["team"];
+	        this.email = source["email"];
+	    }
+	}
+	export class PlanBlock {
+	    // Go type: time
+	    start: any;
+	    // Go type: time
+	    end: any;
+	    title: string;
+	    kind: string;
+	    task_id?: number;
+	    minutes: number;
+	    actual_minutes?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlanBlock(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.title = source["title"];
+	        this.kind = source["kind"];
+	        this.task_id = source["task_id"];
+	        this.minutes = source["minutes"];
+	        this.actual_minutes = source["actual_minutes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PluginInfo {
+	    manifest: PluginManifest;
+	    dir: string;
+	    enabled: boolean;
+	    running: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PluginInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.manifest = this.convertValues(source["manifest"], PluginManifest);
+	        this.dir = source["dir"];
+	        this.enabled = source["enabled"];
+	        this.running = source["running"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PluginManifest {
+	    name: string;
+	    description: string;
+	    version: string;
+	    command: string;
+	    args: string[];
+	    capabilities: string[];
+	    commands: string[];
+	    formats: string[];
+	    languages: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PluginManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.version = source["version"];
+	        this.command = source["command"];
+	        this.args = source["args"];
+	        this.capabilities = source["capabilities"];
+	        this.commands = source["commands"];
+	        this.formats = source["formats"];
+	        this.languages = source["languages"];
+	    }
+	}
+	export class Project {
+	    id: number;
+	    name: string;
+	    tag: string;
+	    client: string;
+	    hourly_rate: number;
+	    rounding_minutes: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Project(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.tag = source["tag"];
+	        this.client = source["client"];
+	        this.hourly_rate = source["hourly_rate"];
+	        this.rounding_minutes = source["rounding_minutes"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProjectReport {
+	    project: Project;
+	    entries: LogEntry[];
+	    decisions: LogEntry[];
+	    open_tasks: Task[];
+	    done_tasks: number;
+	    forecast?: TaskForecast;
+	    people: Person[];
+	    minutes: number;
+	    // Go type: time
+	    first_entry?: any;
+	    // Go type: time
+	    latest_entry?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project = this.convertValues(source["project"], Project);
+	        this.entries = this.convertValues(source["entries"], LogEntry);
+	        this.decisions = this.convertValues(source["decisions"], LogEntry);
+	        this.open_tasks = this.convertValues(source["open_tasks"], Task);
+	        this.done_tasks = source["done_tasks"];
+	        this.forecast = this.convertValues(source["forecast"], TaskForecast);
+	        this.people = this.convertValues(source["people"], Person);
+	        this.minutes = source["minutes"];
+	        this.first_entry = this.convertValues(source["first_entry"], null);
+	        this.latest_entry = this.convertValues(source["latest_entry"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class QueryResult {
+	    columns: string[];
+	    rows: string[][];
+	    truncated: boolean;
+	    elapsed: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = source["columns"];
+	        this.rows = source["rows"];
+	        this.truncated = source["truncated"];
+	        this.elapsed = source["elapsed"];
+	    }
+	}
+	export class QuickOpenItem {
+	    kind: string;
+	    title: string;
+	    subtitle: string;
+	    action: string;
+	    value: string;
+	    url: string;
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuickOpenItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.title = source["title"];
+	        this.subtitle = source["subtitle"];
+	        this.action = source["action"];
+	        this.value = source["value"];
+	        this.url = source["url"];
+	        this.score = source["score"];
+	    }
+	}
+	export class ReminderSchedule {
+	    name: string;
+	    enabled: boolean;
+	    weekdays: string[];
+	    start: string;
+	    end: string;
+	    interval_minutes: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReminderSchedule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.weekdays = source["weekdays"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.interval_minutes = source["interval_minutes"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ReminderStats {
+	    fired: number;
+	    snoozed: number;
+	    skipped: number;
+	    responded: number;
+	    response_rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReminderStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fired = source["fired"];
+	        this.snoozed = source["snoozed"];
+	        this.skipped = source["skipped"];
+	        this.responded = source["responded"];
+	        this.response_rate = source["response_rate"];
+	    }
+	}
+	export class ScriptHook {
+	    name: string;
+	    event: string;
+	    command: string;
+	    args: string[];
+	    timeout_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptHook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.event = source["event"];
+	        this.command = source["command"];
+	        this.args = source["args"];
+	        this.timeout_seconds = source["timeout_seconds"];
+	    }
+	}
+	export class SearchResult {
+	    id: number;
+	    snippet: string;
+	    // Go type: time
+	    created_at: any;
+	    rank: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.snippet = source["snippet"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.rank = source["rank"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SelfTestCheck {
+	    name: string;
+	    ok: boolean;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelfTestCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.ok = source["ok"];
+	        this.message = source["message"];
+	    }
+	}
+	export class SelfTestReport {
+	    passed: boolean;
+	    checks: SelfTestCheck[];
+	    // Go type: time
+	    ran_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelfTestReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.passed = source["passed"];
+	        this.checks = this.convertValues(source["checks"], SelfTestCheck);
+	        this.ran_at = this.convertValues(source["ran_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Settings {
+	    hotkey_modifiers: string[];
+	    hotkey_key: string;
+	    first_run: boolean;
+	    theme: string;
+	    dashboard_port: number;
+	    daily_goal: number;
+	    presets: Record<string, string>;
+	    inbound_hooks: InboundHook[];
+	    home_assistant: HomeAssistantSettings;
+	    ignore_focus_mode: boolean;
+	    hotkey_gestures: HotkeyGestures;
+	    marker_hotkey_modifiers: string[];
+	    marker_hotkey_key: string;
+	    screenshot_hotkey_modifiers: string[];
+	    screenshot_hotkey_key: string;
+	    track_active_window: boolean;
+	    reminders: ReminderSchedule[];
+	    auto_capture_window_seconds: number;
+	    templates: Record<string, string>;
+	    calendar_ics: string;
+	    script_hooks: ScriptHook[];
+	    enabled_plugins: string[];
+	    custom_commands: CustomCommand[];
+	    capture_selection: boolean;
+	    advanced_mode: boolean;
+	    backup_passphrase: string;
+	    backup_targets: BackupTarget[];
+	    sync: SyncSettings;
+	    slow_query_ms: number;
+	    storage: StorageSettings;
+	    day_start_hour: number;
+	    expand_hotkey_modifier: string;
+	    spellcheck_language: string;
+	    confirmations: ConfirmationSettings;
+	    tag_blacklist: string[];
+	    guided_flows: Record<string, GuidedFlow>;
+	    workday_start_hour: number;
+	    workday_end_hour: number;
+	    timesheet_currency: string;
+	    disable_nightly_backups: boolean;
+	    backup_keep: number;
+	    search_trigram: boolean;
+	    quote_style: string;
+	    expand_dashes: boolean;
+	    dashboard_theme: string;
+	    dashboard_font_scale: number;
+	    dashboard_density: string;
+	    preview_length: number;
+	    preview_first_line: boolean;
+	    capture_notify_sources: string[];
+	    capture_keep_focus: boolean;
+	    capture_position: string;
+	    capture_auto_hide_seconds: number;
+	    stay_open_after_log: boolean;
+	    keep_text_after_log: boolean;
+	    default_journal: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hotkey_modifiers = source["hotkey_modifiers"];
+	        this.hotkey_key = source["hotkey_key"];
+	        this.first_run = source["first_run"];
+	        this.theme = source["theme"];
+	        this.dashboard_port = source["dashboard_port"];
+	        this.daily_goal = source["daily_goal"];
+	        this.presets = source["presets"];
+	        this.inbound_hooks = this.convertValues(source["inbound_hooks"], InboundHook);
+	        this.home_assistant = this.convertValues(source["home_assistant"], HomeAssistantSettings);
+	        this.ignore_focus_mode = source["ignore_focus_mode"];
+	        this.hotkey_gestures = this.convertValues(source["hotkey_gestures"], HotkeyGestures);
+	        this.marker_hotkey_modifiers = source["marker_hotkey_modifiers"];
+	        this.marker_hotkey_key = source["marker_hotkey_key"];
+	        this.screenshot_hotkey_modifiers = source["screenshot_hotkey_modifiers"];
+	        this.screenshot_hotkey_key = source["screenshot_hotkey_key"];
+	        this.track_active_window = source["track_active_window"];
+	        this.reminders = this.convertValues(source["reminders"], ReminderSchedule);
+	        this.auto_capture_window_seconds = source["auto_capture_window_seconds"];
+	        this.templates = source["templates"];
+	        this.calendar_ics = source["calendar_ics"];
+	        this.script_hooks = this.convertValues(source["script_hooks"], ScriptHook);
+	        this.enabled_plugins = source["enabled_plugins"];
+	        this.custom_commands = this.convertValues(source["custom_commands"], CustomCommand);
+	        this.capture_selection = source["capture_selection"];
+	        this.advanced_mode = source["advanced_mode"];
+	        this.backup_passphrase = source["backup_passphrase"];
+	        this.backup_targets = this.convertValues(source["backup_targets"], BackupTarget);
+	        this.sync = this.convertValues(source["sync"], SyncSettings);
+	        this.slow_query_ms = source["slow_query_ms"];
+	        this.storage = this.convertValues(source["storage"], StorageSettings);
+	        this.day_start_hour = source["day_start_hour"];
+	        this.expand_hotkey_modifier = source["expand_hotkey_modifier"];
+	        this.spellcheck_language = source["spellcheck_language"];
+	        this.confirmations = this.convertValues(source["confirmations"], ConfirmationSettings);
+	        this.tag_blacklist = source["tag_blacklist"];
+	        this.guided_flows = this.convertValues(source["guided_flows"], GuidedFlow, true);
+	        this.workday_start_hour = source["workday_start_hour"];
+	        this.workday_end_hour = source["workday_end_hour"];
+	        this.timesheet_currency = source["timesheet_currency"];
+	        this.disable_nightly_backups = source["disable_nightly_backups"];
+	        this.backup_keep = source["backup_keep"];
+	        this.search_trigram = source["search_trigram"];
+	        this.quote_style = source["quote_style"];
+	        this.expand_dashes = source["expand_dashes"];
+	        this.dashboard_theme = source["dashboard_theme"];
+	        this.dashboard_font_scale = source["dashboard_font_scale"];
+	        this.dashboard_density = source["dashboard_density"];
+	        this.preview_length = source["preview_length"];
+	        this.preview_first_line = source["preview_first_line"];
+	        this.capture_notify_sources = source["capture_notify_sources"];
+	        this.capture_keep_focus = source["capture_keep_focus"];
+	        this.capture_position = source["capture_position"];
+	        this.capture_auto_hide_seconds = source["capture_auto_hide_seconds"];
+	        this.stay_open_after_log = source["stay_open_after_log"];
+	        this.keep_text_after_log = source["keep_text_after_log"];
+	        this.default_journal = source["default_journal"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Shortcut {
+	    id: number;
+	    trigger: string;
+	    expansion: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Shortcut(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.trigger = source["trigger"];
+	        this.expansion = source["expansion"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SlowOperation {
+	    kind: string;
+	    request_id?: string;
+	    operation: string;
+	    duration_ms: number;
+	    plan?: string[];
+	    error?: string;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SlowOperation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.request_id = source["request_id"];
+	        this.operation = source["operation"];
+	        this.duration_ms = source["duration_ms"];
+	        this.plan = source["plan"];
+	        this.error = source["error"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SpellcheckDictionary {
+	    language: string;
+	    enabled: boolean;
+	    words: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SpellcheckDictionary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.language = source["language"];
+	        this.enabled = source["enabled"];
+	        this.words = source["words"];
+	    }
+	}
+	export class StatusResponse {
+	    streak: number;
+	    today: number;
+	    daily_goal: number;
+	    goal_met: boolean;
+	    day_off: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StatusResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.streak = source["streak"];
+	        this.today = source["today"];
+	        this.daily_goal = source["daily_goal"];
+	        this.goal_met = source["goal_met"];
+	        this.day_off = source["day_off"];
+	    }
+	}
+	export class StorageSettings {
+	    max_entries: number;
+	    max_database_mb: number;
+	    max_attachments_mb: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.max_entries = source["max_entries"];
+	        this.max_database_mb = source["max_database_mb"];
+	        this.max_attachments_mb = source["max_attachments_mb"];
+	    }
+	}
+	export class StorageStats {
+	    entry_count: number;
+	    database_bytes: number;
+	    reclaimable_bytes: number;
+	    attachments_bytes: number;
+	    backups_bytes: number;
+	    warnings: StorageWarning[];
+	    // Go type: time
+	    checked_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entry_count = source["entry_count"];
+	        this.database_bytes = source["database_bytes"];
+	        this.reclaimable_bytes = source["reclaimable_bytes"];
+	        this.attachments_bytes = source["attachments_bytes"];
+	        this.backups_bytes = source["backups_bytes"];
+	        this.warnings = this.convertValues(source["warnings"], StorageWarning);
+	        this.checked_at = this.convertValues(source["checked_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StorageWarning {
+	    kind: string;
+	    message: string;
+	    value: number;
+	    limit: number;
+	    suggestions: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageWarning(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.message = source["message"];
+	        this.value = source["value"];
+	        this.limit = source["limit"];
+	        this.suggestions = source["suggestions"];
+	    }
+	}
+	export class StreakStats {
+	    current: number;
+	    longest: number;
+	    days_this_month: number;
+	    logged_today: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StreakStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = source["current"];
+	        this.longest = source["longest"];
+	        this.days_this_month = source["days_this_month"];
+	        this.logged_today = source["logged_today"];
+	    }
+	}
+	export class SubmitResult {
+	    id: number;
+	    hidden: boolean;
+	    clear_text: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubmitResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.hidden = source["hidden"];
+	        this.clear_text = source["clear_text"];
+	    }
+	}
+	export class SyncConflict {
+	    id: number;
+	    entry_id: number;
+	    local_content: string;
+	    remote_content: string;
+	    remote_deleted: boolean;
+	    remote_device: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entry_id = source["entry_id"];
+	        this.local_content = source["local_content"];
+	        this.remote_content = source["remote_content"];
+	        this.remote_deleted = source["remote_deleted"];
+	        this.remote_device = source["remote_device"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncDevice {
+	    id: string;
+	    name: string;
+	    fingerprint: string;
+	    // Go type: time
+	    last_seen: any;
+	    revoked: boolean;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncDevice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.fingerprint = source["fingerprint"];
+	        this.last_seen = this.convertValues(source["last_seen"], null);
+	        this.revoked = source["revoked"];
+	        this.current = source["current"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncSettings {
+	    enabled: boolean;
+	    interval_minutes: number;
+	    target: BackupTarget;
+	    passphrase: string;
+	    device_name: string;
+	    local_only_tags: string[];
+	    local_only_journals: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.interval_minutes = source["interval_minutes"];
+	        this.target = this.convertValues(source["target"], BackupTarget);
+	        this.passphrase = source["passphrase"];
+	        this.device_name = source["device_name"];
+	        this.local_only_tags = source["local_only_tags"];
+	        this.local_only_journals = source["local_only_journals"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncStatus {
+	    enabled: boolean;
+	    encrypted: boolean;
+	    device_id: string;
+	    // Go type: time
+	    last_sync: any;
+	    last_error: string;
+	    pending_ops: number;
+	    conflicts: number;
+	    peers: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.encrypted = source["encrypted"];
+	        this.device_id = source["device_id"];
+	        this.last_sync = this.convertValues(source["last_sync"], null);
+	        this.last_error = source["last_error"];
+	        this.pending_ops = source["pending_ops"];
+	        this.conflicts = source["conflicts"];
+	        this.peers = source["peers"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tag {
+	    id: number;
+	    name: string;
 	    // Go type: time
 	    created_at: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new LogEntry(source);
+	        return new Tag(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.content = source["content"];
+	        this.name = source["name"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	    }
 	
@@ -35,41 +1964,58 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class Settings {
-	    hotkey_modifiers: string[];
-	    hotkey_key: string;
-	    first_run: boolean;
-	    theme: string;
-	    dashboard_port: number;
+	export class TagAlias {
+	    alias: string;
+	    tag: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Settings(source);
+	        return new TagAlias(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.hotkey_modifiers = source["hotkey_modifiers"];
-	        this.hotkey_key = source["hotkey_key"];
-	        this.first_run = source["first_run"];
-	        this.theme = source["theme"];
-	        this.dashboard_port = source["dashboard_port"];
+	        this.alias = source["alias"];
+	        this.tag = source["tag"];
 	    }
 	}
-	export class Tag {
+	export class TagAliasReport {
+	    tags_merged: number;
+	    entries_moved: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagAliasReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tags_merged = source["tags_merged"];
+	        this.entries_moved = source["entries_moved"];
+	    }
+	}
+	export class Task {
 	    id: number;
-	    name: string;
+	    entry_id: number;
+	    position: number;
+	    text: string;
+	    done: boolean;
 	    // Go type: time
 	    created_at: any;
+	    // Go type: time
+	    completed_at?: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new Tag(source);
+	        return new Task(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.name = source["name"];
+	        this.entry_id = source["entry_id"];
+	        this.position = source["position"];
+	        this.text = source["text"];
+	        this.done = source["done"];
 	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.completed_at = this.convertValues(source["completed_at"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -90,6 +2036,336 @@ export namespace main {
 		    return a;
 		}
 	}
-
+	export class TaskEstimate {
+	    task_id: number;
+	    text: string;
+	    // Go type: time
+	    completed_at: any;
+	    estimate_minutes: number;
+	    actual_minutes: number;
+	    ratio: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.text = source["text"];
+	        this.completed_at = this.convertValues(source["completed_at"], null);
+	        this.estimate_minutes = source["estimate_minutes"];
+	        this.actual_minutes = source["actual_minutes"];
+	        this.ratio = source["ratio"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskForecast {
+	    tag: string;
+	    open: number;
+	    completed: number;
+	    weeks: number;
+	    per_week: number;
+	    clear_days?: number;
+	    // Go type: time
+	    clear_by?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskForecast(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.open = source["open"];
+	        this.completed = source["completed"];
+	        this.weeks = source["weeks"];
+	        this.per_week = source["per_week"];
+	        this.clear_days = source["clear_days"];
+	        this.clear_by = this.convertValues(source["clear_by"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskSnapshot {
+	    date: string;
+	    open: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.open = source["open"];
+	    }
+	}
+	export class TimesheetLine {
+	    client: string;
+	    project: string;
+	    date: string;
+	    minutes: number;
+	    billed_minutes: number;
+	    hours: number;
+	    rate: number;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimesheetLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.client = source["client"];
+	        this.project = source["project"];
+	        this.date = source["date"];
+	        this.minutes = source["minutes"];
+	        this.billed_minutes = source["billed_minutes"];
+	        this.hours = source["hours"];
+	        this.rate = source["rate"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class TimesheetReport {
+	    month: string;
+	    csv_path: string;
+	    pdf_path: string;
+	    lines: number;
+	    hours: number;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimesheetReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.csv_path = source["csv_path"];
+	        this.pdf_path = source["pdf_path"];
+	        this.lines = source["lines"];
+	        this.hours = source["hours"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class TopicCount {
+	    Term: string;
+	    Count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TopicCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Term = source["Term"];
+	        this.Count = source["Count"];
+	    }
+	}
+	export class TopicPeriod {
+	    Label: string;
+	    Entries: number;
+	    Top: TopicCount[];
+	    Rising: string[];
+	    Fading: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TopicPeriod(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Label = source["Label"];
+	        this.Entries = source["Entries"];
+	        this.Top = this.convertValues(source["Top"], TopicCount);
+	        this.Rising = source["Rising"];
+	        this.Fading = source["Fading"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TrashedEntry {
+	    id: number;
+	    content: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    deleted_at: any;
+	    source: string;
+	    entry_type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashedEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.content = source["content"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], null);
+	        this.source = source["source"];
+	        this.entry_type = source["entry_type"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WeekPlan {
+	    week_of: string;
+	    draft: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WeekPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.week_of = source["week_of"];
+	        this.draft = source["draft"];
+	    }
+	}
+	export class WeeklyEstimates {
+	    week_of: string;
+	    tasks: number;
+	    estimate_minutes: number;
+	    actual_minutes: number;
+	    ratio: number;
+	    on_target: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WeeklyEstimates(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.week_of = source["week_of"];
+	        this.tasks = source["tasks"];
+	        this.estimate_minutes = source["estimate_minutes"];
+	        this.actual_minutes = source["actual_minutes"];
+	        this.ratio = source["ratio"];
+	        this.on_target = source["on_target"];
+	    }
+	}
+	export class WeeklyWorkload {
+	    week_of: string;
+	    entries: number;
+	    late_night_ratio: number;
+	    weekend_ratio: number;
+	    meeting_ratio: number;
+	    longest_stretch_hours: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WeeklyWorkload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.week_of = source["week_of"];
+	        this.entries = source["entries"];
+	        this.late_night_ratio = source["late_night_ratio"];
+	        this.weekend_ratio = source["weekend_ratio"];
+	        this.meeting_ratio = source["meeting_ratio"];
+	        this.longest_stretch_hours = source["longest_stretch_hours"];
+	    }
+	}
+	export class WorkloadStats {
+	    weeks: WeeklyWorkload[];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkloadStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.weeks = this.convertValues(source["weeks"], WeeklyWorkload);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 }
 
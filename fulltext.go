@@ -0,0 +1,254 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultSearchLimit = 20
+	// searchTokenizer splits entries into words on Unicode rules and folds diacritics, so
+	// "uber" finds "über" and "resume" finds "résumé"
+	searchTokenizer = "unicode61 remove_diacritics 2"
+	// trigramTokenizer indexes every three characters, for matches inside words
+	trigramTokenizer = "trigram remove_diacritics 1"
+	// minTrigramWord is the shortest word the trigram index can find
+	minTrigramWord = 3
+)
+
+// likeEscaper escapes the wildcards of a LIKE pattern, for use with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchResult is one ranked full-text match, with the matching words of the snippet
+// wrapped in [ and ]
+type SearchResult struct {
+	ID        int       `json:"id"`
+	Snippet   string    `json:"snippet"`
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float64   `json:"rank"`
+}
+
+// SearchResults is the payload of the search-results event sent by /search
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
+
+// createSearchIndex creates the FTS5 index over log_entries. Triggers keep it in step with
+// every insert, update and delete; the first run indexes the existing entries, and so
+// does the first run after the tokenizer changes.
+func (a *App) createSearchIndex() error {
+	if err := a.createFTSIndex("log_entries_fts", searchTokenizer); err != nil {
+		return err
+	}
+	return a.updateTrigramIndex()
+}
+
+// updateTrigramIndex creates the trigram index when search_trigram is on and drops it,
+// which frees about as much space as the entries take, when it is off
+func (a *App) updateTrigramIndex() error {
+	if a.settings.SearchTrigram {
+		return a.createFTSIndex("log_entries_trigram", trigramTokenizer)
+	}
+	return a.dropFTSIndex("log_entries_trigram")
+}
+
+// createFTSIndex creates an FTS5 index named table over log_entries.content with its
+// triggers, rebuilding it when it is new or was created with another tokenizer
+func (a *App) createFTSIndex(table, tokenizer string) error {
+	var definition string
+	err := a.db.QueryRow(`SELECT sql FROM sqlite_master WHERE name = ?`, table).Scan(&definition)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check search index: %v", err)
+	}
+	if definition != "" && !strings.Contains(definition, tokenizer) {
+		if err := a.dropFTSIndex(table); err != nil {
+			return err
+		}
+		definition = ""
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS ` + table + ` USING fts5(
+			content, content='log_entries', content_rowid='id', tokenize='` + tokenizer + `'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_insert AFTER INSERT ON log_entries BEGIN
+			INSERT INTO ` + table + `(rowid, content) VALUES (new.id, new.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_delete AFTER DELETE ON log_entries BEGIN
+			INSERT INTO ` + table + `(` + table + `, rowid, content) VALUES ('delete', old.id, old.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_update AFTER UPDATE OF content ON log_entries BEGIN
+			INSERT INTO ` + table + `(` + table + `, rowid, content) VALUES ('delete', old.id, old.content);
+			INSERT INTO ` + table + `(rowid, content) VALUES (new.id, new.content);
+		END`,
+	}
+	for _, statement := range statements {
+		if _, err := a.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create search index: %v", err)
+		}
+	}
+
+	if definition == "" {
+		if _, err := a.db.Exec(`INSERT INTO ` + table + `(` + table + `) VALUES ('rebuild')`); err != nil {
+			return fmt.Errorf("failed to build search index: %v", err)
+		}
+		a.logf("Built full-text search index %s\n", table)
+	}
+	return nil
+}
+
+// dropFTSIndex removes an index created by createFTSIndex and its triggers
+func (a *App) dropFTSIndex(table string) error {
+	for _, statement := range []string{
+		`DROP TRIGGER IF EXISTS ` + table + `_insert`,
+		`DROP TRIGGER IF EXISTS ` + table + `_delete`,
+		`DROP TRIGGER IF EXISTS ` + table + `_update`,
+		`DROP TABLE IF EXISTS ` + table,
+	} {
+		if _, err := a.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to drop search index: %v", err)
+		}
+	}
+	return nil
+}
+
+// ftsQuery turns what the user typed into an FTS5 query matching entries that contain
+// every word. Words are quoted so punctuation can't become query syntax, and the last
+// one matches as a prefix since it may still be being typed.
+func ftsQuery(input string) string {
+	words := strings.Fields(input)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	if len(words) > 0 {
+		words[len(words)-1] += "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// ftsMatch picks the index a search runs against and builds its MATCH query. With the
+// trigram index words also match inside longer words, so "besprech" finds
+// "Teambesprechung" and Chinese or Japanese text, written without spaces, can be
+// searched at all. Words shorter than minTrigramWord are too short for it and are
+// returned as LIKE patterns instead; when every word is that short match is empty.
+func ftsMatch(input string, trigram bool) (table, match string, likes []string) {
+	if !trigram {
+		return "log_entries_fts", ftsQuery(input), nil
+	}
+
+	var phrases []string
+	for _, word := range strings.Fields(input) {
+		if utf8.RuneCountInString(word) < minTrigramWord {
+			likes = append(likes, "%"+likeEscaper.Replace(word)+"%")
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	return "log_entries_trigram", strings.Join(phrases, " "), likes
+}
+
+// ftsCondition is the SQL condition selecting the log_entries rows that match input, or
+// "" when input has no words
+func ftsCondition(input string, trigram bool) (string, []interface{}) {
+	table, match, likes := ftsMatch(input, trigram)
+	var conditions []string
+	var args []interface{}
+	if match != "" {
+		conditions = append(conditions, `id IN (SELECT rowid FROM `+table+` WHERE `+table+` MATCH ?)`)
+		args = append(args, match)
+	}
+	for _, like := range likes {
+		conditions = append(conditions, `content LIKE ? ESCAPE '\'`)
+		args = append(args, like)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// SearchEntries returns up to limit entries matching every word of query, best match first
+func (a *App) SearchEntries(query string, limit int) ([]SearchResult, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	table, match, likes := ftsMatch(query, a.settings.SearchTrigram)
+	if match == "" && len(likes) == 0 {
+		return []SearchResult{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+
+	var rows *sql.Rows
+	var err error
+	if match != "" {
+		args := []interface{}{match}
+		conditions := ""
+		for _, like := range likes {
+			conditions += ` AND e.content LIKE ? ESCAPE '\'`
+			args = append(args, like)
+		}
+		rows, err = a.db.Query(`SELECT e.id, snippet(`+table+`, 0, '[', ']', '…', 12), e.created_at, bm25(`+table+`)
+			FROM `+table+` JOIN log_entries e ON e.id = `+table+`.rowid
+			WHERE `+table+` MATCH ? AND e.deleted_at IS NULL`+conditions+`
+			ORDER BY bm25(`+table+`), e.created_at DESC
+			LIMIT ?`, append(args, limit)...)
+	} else {
+		// only words too short for the trigram index: scan for them, newest first
+		condition, args := ftsCondition(query, true)
+		rows, err = a.db.Query(`SELECT id, content, created_at, 0 FROM log_entries
+			WHERE `+condition+` AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ?`, append(args, limit)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entries: %v", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		if err := rows.Scan(&result.ID, &result.Snippet, &result.CreatedAt, &result.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		if match == "" {
+			result.Snippet = a.markSnippet(result.Snippet, strings.Fields(query)[0])
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// markSnippet cuts the text around the first occurrence of word and wraps it in [ and ],
+// like the snippets of the search index. Without the word it falls back to the preview.
+func (a *App) markSnippet(text, word string) string {
+	at := strings.Index(strings.ToLower(text), strings.ToLower(word))
+	if at < 0 || len(strings.ToLower(text)) != len(text) {
+		return a.entryPreview(text)
+	}
+	before, after := []rune(text[:at]), []rune(text[at+len(word):])
+	prefix, suffix := "", ""
+	if len(before) > 30 {
+		before, prefix = before[len(before)-30:], "…"
+	}
+	if len(after) > 60 {
+		after, suffix = after[:60], "…"
+	}
+	return prefix + string(before) + "[" + text[at:at+len(word)] + "]" + string(after) + suffix
+}
+
+// showSearchResults lists /search matches in the input window, where one can be picked
+// for /edit
+func (a *App) showSearchResults(query string, results []SearchResult) {
+	a.logf("Search found %d entries\n", len(results))
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "search-results", SearchResults{Query: query, Results: results})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// newSearchApp returns an App holding contents as entries, with the trigram index when
+// trigram is set
+func newSearchApp(t *testing.T, trigram bool, contents ...string) (*App, map[string]int) {
+	t.Helper()
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+	a.settings.SearchTrigram = trigram
+	if err := a.updateTrigramIndex(); err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]int, len(contents))
+	for _, content := range contents {
+		id, err := a.logEntry(content, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[content] = int(id)
+	}
+	return a, ids
+}
+
+// searchIDs returns the IDs SearchEntries finds for query
+func searchIDs(t *testing.T, a *App, query string) map[int]bool {
+	t.Helper()
+	results, err := a.SearchEntries(query, 0)
+	if err != nil {
+		t.Fatalf("search %q: %v", query, err)
+	}
+	found := make(map[int]bool, len(results))
+	for _, result := range results {
+		found[result.ID] = true
+	}
+	return found
+}
+
+func TestSearchIgnoresAccents(t *testing.T) {
+	for _, trigram := range []bool{false, true} {
+		a, ids := newSearchApp(t, trigram, "Sent my résumé to the café", "Über-planning the sprint", "resume the review tomorrow")
+
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"resume", []string{"Sent my résumé to the café", "resume the review tomorrow"}},
+			{"résumé", []string{"Sent my résumé to the café", "resume the review tomorrow"}},
+			{"cafe", []string{"Sent my résumé to the café"}},
+			{"uber", []string{"Über-planning the sprint"}},
+		}
+		for _, tt := range tests {
+			found := searchIDs(t, a, tt.query)
+			if len(found) != len(tt.want) {
+				t.Errorf("trigram %v: %q found %d entries, want %d", trigram, tt.query, len(found), len(tt.want))
+			}
+			for _, content := range tt.want {
+				if !found[ids[content]] {
+					t.Errorf("trigram %v: %q didn't find %q", trigram, tt.query, content)
+				}
+			}
+		}
+	}
+}
+
+func TestTrigramSearchMatchesInsideWords(t *testing.T) {
+	entry := "Teambesprechung about the Q3 roadmap"
+
+	a, _ := newSearchApp(t, false, entry)
+	if found := searchIDs(t, a, "besprech"); len(found) != 0 {
+		t.Fatalf("the word index found %q inside a word", "besprech")
+	}
+
+	a, ids := newSearchApp(t, true, entry)
+	for _, query := range []string{"besprech", "ROADM", "oad"} {
+		if found := searchIDs(t, a, query); len(found) != 1 || !found[ids[entry]] {
+			t.Errorf("%q found %v, want entry %d", query, found, ids[entry])
+		}
+	}
+}
+
+func TestTrigramSearchFindsCJK(t *testing.T) {
+	chinese, japanese := "今天的产品评审会议很顺利", "東京駅で田中さんと待ち合わせ"
+	a, ids := newSearchApp(t, true, chinese, japanese, "unrelated English entry")
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"评审会议", chinese},
+		{"会议", chinese}, // shorter than a trigram, matched by scanning
+		{"待ち合わせ", japanese},
+		{"東京駅", japanese},
+		{"田中 待ち合わせ", japanese},
+	}
+	for _, tt := range tests {
+		found := searchIDs(t, a, tt.query)
+		if len(found) != 1 || !found[ids[tt.want]] {
+			t.Errorf("%q found %v, want entry %d", tt.query, found, ids[tt.want])
+		}
+	}
+	if found := searchIDs(t, a, "会议 東京"); len(found) != 0 {
+		t.Errorf("words from different entries found %v", found)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Gesture actions that can be mapped in HotkeyGestures
+const (
+	gestureCapture   = "capture"
+	gestureDashboard = "dashboard"
+	gestureVoice     = "voice"
+	gestureExpand    = "expand"
+)
+
+const (
+	doublePressWindow = 350 * time.Millisecond
+	holdThreshold     = 600 * time.Millisecond
+)
+
+// HotkeyGestures maps press patterns on the global hotkey to actions.
+// An empty Double or Hold disables that gesture, which also removes the
+// short wait needed to tell a single press from a double press.
+type HotkeyGestures struct {
+	Single string `json:"single"`
+	Double string `json:"double"`
+	Hold   string `json:"hold"`
+}
+
+// handleHotkeyGestures reads keydown/keyup events and dispatches single, double and hold gestures.
+// It returns once the hotkey is unregistered and its channels are closed.
+func (a *App) handleHotkeyGestures(hk globalHotkey) {
+	keydown, keyup := hk.Keydown(), hk.Keyup()
+	gestures := a.settings.HotkeyGestures
+	if gestures.Single == "" {
+		gestures.Single = gestureCapture
+	}
+
+	for {
+		if _, ok := <-keydown; !ok {
+			return
+		}
+
+		var holdTimer <-chan time.Time
+		if gestures.Hold != "" {
+			holdTimer = time.After(holdThreshold)
+		}
+
+		select {
+		case _, ok := <-keyup:
+			if !ok {
+				return
+			}
+		case <-holdTimer:
+			a.logf("Hotkey hold gesture detected\n")
+			a.runHotkeyAction(gestures.Hold)
+			if _, ok := <-keyup; !ok {
+				return
+			}
+			a.endHotkeyAction(gestures.Hold)
+			continue
+		}
+
+		if gestures.Double == "" {
+			a.logf("Hotkey detected!\n")
+			a.runHotkeyAction(gestures.Single)
+			continue
+		}
+
+		select {
+		case _, ok := <-keydown:
+			if !ok {
+				return
+			}
+			a.logf("Hotkey double press detected\n")
+			a.runHotkeyAction(gestures.Double)
+			if _, ok := <-keyup; !ok {
+				return
+			}
+		case <-time.After(doublePressWindow):
+			a.logf("Hotkey detected!\n")
+			a.runHotkeyAction(gestures.Single)
+		}
+	}
+}
+
+func (a *App) runHotkeyAction(action string) {
+	switch action {
+	case gestureCapture:
+		a.restoreCaptureMode()
+		a.showCaptureWindow()
+	case gestureExpand:
+		a.showLongFormCapture()
+	case gestureDashboard:
+		if err := a.generateDashboard(); err != nil {
+			a.logf("Failed to open dashboard from hotkey: %v\n", err)
+		}
+	case gestureVoice:
+		a.ShowWindow()
+		wailsRuntime.EventsEmit(a.ctx, "start-voice-capture")
+	default:
+		a.logf("Unknown hotkey action: %s\n", action)
+	}
+}
+
+// endHotkeyAction runs when the hotkey is released after a hold gesture
+func (a *App) endHotkeyAction(action string) {
+	if action == gestureVoice {
+		wailsRuntime.EventsEmit(a.ctx, "stop-voice-capture")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const maxGuidedQuestions = 20
+
+// GuidedFlow is a sequence of questions /flow asks one at a time. The answers are logged
+// together as one entry, tagged with the flow's name.
+type GuidedFlow struct {
+	Title     string   `json:"title"`
+	Questions []string `json:"questions"`
+}
+
+// GuidedQuestion is the question the input window should ask next, sent with the
+// "guided:question" event (nil once the flow is over)
+type GuidedQuestion struct {
+	Flow     string `json:"flow"`
+	Title    string `json:"title"`
+	Question string `json:"question"`
+	Step     int    `json:"step"`
+	Total    int    `json:"total"`
+}
+
+// guidedFlowState is the flow in progress and the answers given so far. There is one
+// capture window, so only one flow runs at a time.
+type guidedFlowState struct {
+	mu      sync.Mutex
+	name    string
+	flow    GuidedFlow
+	answers []string
+}
+
+// defaultGuidedFlows are used while guided_flows is unset
+func defaultGuidedFlows() map[string]GuidedFlow {
+	return map[string]GuidedFlow{
+		"standup": {Title: "Standup", Questions: []string{"Yesterday?", "Today?", "Blockers?"}},
+		"interview": {Title: "Interview", Questions: []string{
+			"Candidate and role?", "Strengths?", "Concerns?", "Recommendation?",
+		}},
+	}
+}
+
+func (a *App) guidedFlows() map[string]GuidedFlow {
+	if a.settings.GuidedFlows == nil {
+		return defaultGuidedFlows()
+	}
+	return a.settings.GuidedFlows
+}
+
+// validateGuidedFlows rejects flows that can't be run or tagged
+func validateGuidedFlows(flows map[string]GuidedFlow) error {
+	for name, flow := range flows {
+		if !tagNamePattern.MatchString(name) {
+			return fmt.Errorf("guided flow name %q may only contain letters, digits, _ and -", name)
+		}
+		if len(flow.Questions) == 0 || len(flow.Questions) > maxGuidedQuestions {
+			return fmt.Errorf("guided flow %s needs between 1 and %d questions", name, maxGuidedQuestions)
+		}
+		for _, question := range flow.Questions {
+			if strings.TrimSpace(question) == "" {
+				return fmt.Errorf("guided flow %s has an empty question", name)
+			}
+		}
+	}
+	return nil
+}
+
+// GetGuidedFlowNames lists the configured guided flows
+func (a *App) GetGuidedFlowNames() []string {
+	var names []string
+	for name := range a.guidedFlows() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartGuidedFlow begins the named flow, replacing one left unfinished, and returns its
+// first question
+func (a *App) StartGuidedFlow(name string) (*GuidedQuestion, error) {
+	name = strings.TrimSpace(name)
+	flow, ok := a.guidedFlows()[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown guided flow %q: use one of %s", name, strings.Join(a.GetGuidedFlowNames(), ", "))
+	}
+	if flow.Title == "" {
+		flow.Title = name
+	}
+
+	a.guided.mu.Lock()
+	a.guided.name, a.guided.flow, a.guided.answers = name, flow, nil
+	question := a.guided.current()
+	a.guided.mu.Unlock()
+
+	a.emitGuidedQuestion(question)
+	return question, nil
+}
+
+// GetGuidedQuestion returns the question waiting for an answer, or nil when no flow runs
+func (a *App) GetGuidedQuestion() *GuidedQuestion {
+	a.guided.mu.Lock()
+	defer a.guided.mu.Unlock()
+	return a.guided.current()
+}
+
+// AnswerGuidedFlow records the answer to the current question and returns the next one.
+// After the last question the answers are logged as one entry and it returns nil. An
+// empty answer skips the question.
+func (a *App) AnswerGuidedFlow(answer string) (*GuidedQuestion, error) {
+	a.guided.mu.Lock()
+	if a.guided.name == "" {
+		a.guided.mu.Unlock()
+		return nil, newAPIError(codeInvalidRequest, "no guided flow is running")
+	}
+	a.guided.answers = append(a.guided.answers, strings.TrimSpace(answer))
+	question := a.guided.current()
+	name, flow, answers := a.guided.name, a.guided.flow, a.guided.answers
+	if question == nil {
+		a.guided.name, a.guided.answers = "", nil
+	}
+	a.guided.mu.Unlock()
+
+	if question != nil {
+		a.emitGuidedQuestion(question)
+		return question, nil
+	}
+
+	a.emitGuidedQuestion(nil)
+	if text := guidedEntryText(name, flow, answers); text != "" {
+		if _, err := a.logEntry(text, sourceHotkey); err != nil {
+			return nil, err
+		}
+		a.logf("Guided flow %s logged\n", name)
+	}
+	return nil, nil
+}
+
+// CancelGuidedFlow drops the flow in progress without logging anything
+func (a *App) CancelGuidedFlow() {
+	a.guided.mu.Lock()
+	running := a.guided.name != ""
+	a.guided.name, a.guided.answers = "", nil
+	a.guided.mu.Unlock()
+
+	if running {
+		a.emitGuidedQuestion(nil)
+	}
+}
+
+func (s *guidedFlowState) current() *GuidedQuestion {
+	if s.name == "" || len(s.answers) >= len(s.flow.Questions) {
+		return nil
+	}
+	return &GuidedQuestion{
+		Flow:     s.name,
+		Title:    s.flow.Title,
+		Question: s.flow.Questions[len(s.answers)],
+		Step:     len(s.answers) + 1,
+		Total:    len(s.flow.Questions),
+	}
+}
+
+// guidedEntryText composes the entry: a heading line with the flow's tag, then each
+// answered question in bold followed by its answer. It is empty if every question was
+// skipped.
+func guidedEntryText(name string, flow GuidedFlow, answers []string) string {
+	var sections []string
+	for i, answer := range answers {
+		if answer != "" {
+			sections = append(sections, fmt.Sprintf("**%s**\n%s", strings.TrimSpace(flow.Questions[i]), answer))
+		}
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s #%s\n\n%s", flow.Title, name, strings.Join(sections, "\n\n"))
+}
+
+func (a *App) emitGuidedQuestion(question *GuidedQuestion) {
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "guided:question", question)
+	}
+}
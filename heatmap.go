@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultHeatmapMonths = 12
+	maxHeatmapMonths     = 60
+)
+
+// HeatmapDay is one cell of the activity heatmap. Level runs from 0 (no entries) to 4
+// (the busiest days), relative to the busiest day in range.
+type HeatmapDay struct {
+	Date    string `json:"date"`
+	Weekday int    `json:"weekday"`
+	Count   int    `json:"count"`
+	Level   int    `json:"level"`
+}
+
+// Heatmap holds the entry counts of every day from From to To, oldest first, including
+// days without entries so the grid can be drawn as is
+type Heatmap struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Max   int          `json:"max"`
+	Total int          `json:"total"`
+	Days  []HeatmapDay `json:"days"`
+}
+
+// GetHeatmap counts entries per logical day over the last months, today included.
+// The counting is done by the daily_counts view so no entries are loaded.
+func (a *App) GetHeatmap(months int) (*Heatmap, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if months <= 0 {
+		months = defaultHeatmapMonths
+	}
+	if months > maxHeatmapMonths {
+		months = maxHeatmapMonths
+	}
+
+	today := a.today()
+	from := today.AddDate(0, -months, 1)
+	heatmap := &Heatmap{From: from.Format("2006-01-02"), To: today.Format("2006-01-02"), Days: []HeatmapDay{}}
+
+	rows, err := a.db.Query(`SELECT date, entries FROM daily_counts WHERE date >= ? AND date <= ?`, heatmap.From, heatmap.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily counts: %v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var date string
+		var count int
+		if err := rows.Scan(&date, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan daily count: %v", err)
+		}
+		counts[date] = count
+		heatmap.Total += count
+		if count > heatmap.Max {
+			heatmap.Max = count
+		}
+	}
+
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		heatmap.Days = append(heatmap.Days, HeatmapDay{
+			Date:    date,
+			Weekday: int(day.Weekday()),
+			Count:   counts[date],
+			Level:   heatmapLevel(counts[date], heatmap.Max),
+		})
+	}
+	return heatmap, nil
+}
+
+// heatmapLevel buckets count into quarters of max, so any logged day is at least level 1
+func heatmapLevel(count, max int) int {
+	if count == 0 || max == 0 {
+		return 0
+	}
+	return (count*4 + max - 1) / max
+}
+
+// handleHeatmapAPI serves GET /api/stats/heatmap?months=12
+func (a *App) handleHeatmapAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	months := defaultHeatmapMonths
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("invalid months: %s", value))
+			return
+		}
+		months = parsed
+	}
+
+	heatmap, err := a.GetHeatmap(months)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, heatmap)
+}
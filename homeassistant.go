@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HomeAssistantSettings configures the Home Assistant integration. Events are
+// POSTed to WebhookURL (a Home Assistant webhook trigger), and the capture and
+// sensor endpoints require Token as a bearer token when it is set.
+type HomeAssistantSettings struct {
+	Enabled    bool   `json:"enabled"`
+	WebhookURL string `json:"webhook_url"`
+	Token      string `json:"token"`
+}
+
+// HomeAssistantEvent is the payload sent to the Home Assistant webhook
+type HomeAssistantEvent struct {
+	Event     string    `json:"event"`
+	ID        int       `json:"id"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var haClient = &http.Client{Timeout: 10 * time.Second}
+
+func (a *App) publishHomeAssistantEvent(event string, entry *LogEntry) {
+	ha := a.settings.HomeAssistant
+	if !ha.Enabled || ha.WebhookURL == "" {
+		return
+	}
+
+	payload, err := json.Marshal(HomeAssistantEvent{
+		Event:     event,
+		ID:        entry.ID,
+		Content:   entry.Content,
+		Tags:      a.entryTags(entry.Content),
+		CreatedAt: entry.CreatedAt,
+	})
+	if err != nil {
+		a.logf("Warning: failed to encode Home Assistant event: %v\n", err)
+		return
+	}
+
+	resp, err := haClient.Post(ha.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		a.logf("Warning: failed to send Home Assistant event: %v\n", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		a.logf("Warning: Home Assistant webhook returned %s\n", resp.Status)
+	}
+}
+
+func (a *App) authorizeHomeAssistant(w http.ResponseWriter, r *http.Request) bool {
+	ha := a.settings.HomeAssistant
+	if !ha.Enabled {
+		writeError(w, http.StatusNotFound, codeDisabled, "Home Assistant integration is disabled")
+		return false
+	}
+	if ha.Token == "" {
+		return true
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(ha.Token)) != 1 {
+		writeError(w, http.StatusUnauthorized, codeUnauthorized, "Unauthorized")
+		return false
+	}
+	return true
+}
+
+// handleHomeAssistantCapture accepts capture commands from automations:
+// POST /api/ha/capture with {"text": "..."} or {"preset": "..."}
+func (a *App) handleHomeAssistantCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !a.authorizeHomeAssistant(w, r) {
+		return
+	}
+
+	var req struct {
+		Text   string `json:"text"`
+		Preset string `json:"preset"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if req.Preset != "" {
+		presetText, ok := a.presetText(req.Preset)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, fmt.Sprintf("Unknown preset: %s", req.Preset))
+			return
+		}
+		text = presetText
+	}
+	if text == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "Either text or preset is required")
+		return
+	}
+
+	_, merged, err := a.logAutoEntry("homeassistant", text)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to log entry: %v", err))
+		a.logf("Error logging entry from Home Assistant: %v\n", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"message":   "Entry logged successfully",
+		"coalesced": merged,
+	})
+
+	a.logf("Entry logged via Home Assistant (coalesced: %v)\n", merged)
+}
+
+// handleHomeAssistantSensor serves a RESTful sensor payload: GET /api/ha/sensor.
+// The state is the current streak; goal progress is exposed as attributes.
+func (a *App) handleHomeAssistantSensor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !a.authorizeHomeAssistant(w, r) {
+		return
+	}
+	if a.notModified(w, r) {
+		return
+	}
+
+	status, err := a.GetStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to get status: %v", err))
+		return
+	}
+
+	attributes := map[string]interface{}{
+		"today":               status.Today,
+		"daily_goal":          status.DailyGoal,
+		"goal_met":            status.GoalMet,
+		"unit_of_measurement": "days",
+		"friendly_name":       "SnapLog streak",
+	}
+	if entry, err := a.GetMostRecentEntry(); err == nil {
+		attributes["last_entry"] = entry.Content
+		attributes["last_entry_at"] = entry.CreatedAt
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"state":      status.Streak,
+		"attributes": attributes,
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// idempotencyRetention is how long a stored response is replayed for retries
+const idempotencyRetention = "-1 day"
+
+// idempotencyStore serializes keyed requests so two concurrent retries cannot both run
+type idempotencyStore struct {
+	mu sync.Mutex
+}
+
+func (a *App) createIdempotencyTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS api_idempotency (
+		idem_key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		content_type TEXT NOT NULL,
+		body BLOB NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create idempotency table: %v", err)
+	}
+	return nil
+}
+
+// recordingWriter passes a response through while keeping a copy for replays
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// idempotencyKey returns the Idempotency-Key header, or client_id and client_seq from the
+// query string or the top level of a JSON body
+func idempotencyKey(r *http.Request, body []byte) string {
+	if key := strings.TrimSpace(r.Header.Get("Idempotency-Key")); key != "" {
+		return key
+	}
+
+	clientID, clientSeq := r.URL.Query().Get("client_id"), r.URL.Query().Get("client_seq")
+	if clientID == "" {
+		var fields struct {
+			ClientID  string      `json:"client_id"`
+			ClientSeq json.Number `json:"client_seq"`
+		}
+		if json.Unmarshal(body, &fields) == nil {
+			clientID, clientSeq = fields.ClientID, fields.ClientSeq.String()
+		}
+	}
+	if clientID == "" || clientSeq == "" {
+		return ""
+	}
+	return "client:" + clientID + "/" + clientSeq
+}
+
+// idempotent wraps a POST handler that creates entries. A retry carrying the same key
+// gets the stored response instead of running the handler again.
+func (a *App) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || a.db == nil {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBatchPayload))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := idempotencyKey(r, body)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		requestHash := sha256Hex([]byte(r.URL.Path + "\n" + string(body)))
+
+		a.idempotency.mu.Lock()
+		defer a.idempotency.mu.Unlock()
+
+		var storedHash, contentType string
+		var status int
+		var stored []byte
+		err = a.db.QueryRowContext(r.Context(), `SELECT request_hash, status, content_type, body FROM api_idempotency
+			WHERE idem_key = ? AND created_at >= datetime('now', ?)`, key, idempotencyRetention).
+			Scan(&storedHash, &status, &contentType, &stored)
+		switch {
+		case err == nil && storedHash != requestHash:
+			writeError(w, http.StatusUnprocessableEntity, codeIdempotencyKeyReused, "Idempotency key was already used for a different request")
+			return
+		case err == nil:
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(status)
+			w.Write(stored)
+			return
+		case err != sql.ErrNoRows:
+			a.logf("Warning: failed to look up idempotency key: %v\n", err)
+		}
+
+		rec := &recordingWriter{ResponseWriter: w}
+		next(rec, r)
+
+		// server errors are not stored so the client can retry them
+		if rec.status == 0 || rec.status >= 500 {
+			return
+		}
+		if _, err := a.db.ExecContext(r.Context(), `DELETE FROM api_idempotency WHERE created_at < datetime('now', ?)`, idempotencyRetention); err != nil {
+			a.logf("Warning: failed to prune idempotency keys: %v\n", err)
+		}
+		if _, err := a.db.ExecContext(r.Context(), `INSERT OR REPLACE INTO api_idempotency (idem_key, request_hash, status, content_type, body) VALUES (?, ?, ?, ?, ?)`,
+			key, requestHash, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+			a.logf("Warning: failed to store idempotency key: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	importFormatJSON     = "json"
+	importFormatCSV      = "csv"
+	importFormatDayOne   = "dayone"
+	importFormatObsidian = "obsidian"
+)
+
+// importTimeLayouts are the timestamps accepted besides RFC 3339, read as local time
+var importTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02"}
+
+// ImportReport says what ImportEntries did, or would do in a dry run. Records that fail
+// validation are listed in Errors with their index in the file and nothing is imported
+// for them; records matching an existing entry's text and time are skipped as duplicates.
+type ImportReport struct {
+	Format     string        `json:"format"`
+	DryRun     bool          `json:"dry_run"`
+	Records    int           `json:"records"`
+	Created    int           `json:"created"`
+	Duplicates int           `json:"duplicates"`
+	Errors     []BatchResult `json:"errors"`
+}
+
+// importRecord is one entry read from an import file, before validation
+type importRecord struct {
+	Content   string   `json:"content"`
+	Text      string   `json:"text"`
+	CreatedAt string   `json:"created_at"`
+	Tags      []string `json:"tags"`
+}
+
+// ImportEntries reads entries from a JSON, CSV or Day One file or a folder of Obsidian
+// daily notes (format "" goes by the extension, a .zip is a Day One export and a folder
+// is read as daily notes), validates them and logs them with their original timestamps
+// and the import source. Tags are parsed from the text as usual, and tags listed
+// separately are appended to it. With dryRun nothing is written and the report says how
+// many entries would be created.
+func (a *App) ImportEntries(path string, format string, dryRun bool) (*ImportReport, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			format = importFormatObsidian
+		} else if format == "zip" {
+			format = importFormatDayOne
+		}
+	}
+
+	var records []importRecord
+	var err error
+	if format == importFormatObsidian {
+		records, err = a.parseImportObsidian(path)
+	} else {
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read import file: %v", readErr)
+		}
+		switch format {
+		case importFormatJSON:
+			records, err = parseImportJSON(data)
+		case importFormatCSV:
+			records, err = parseImportCSV(data)
+		case importFormatDayOne:
+			records, err = parseImportDayOne(data)
+		default:
+			return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("unsupported import format %q: use json, csv, dayone or obsidian", format))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Format: format, DryRun: dryRun, Records: len(records), Errors: []BatchResult{}}
+	var batch []BatchEntry
+	for i, record := range records {
+		entry := record.batchEntry()
+		text, _, createdAt, problem := a.checkBatchEntry(entry)
+		if problem != "" {
+			report.Errors = append(report.Errors, BatchResult{Index: i, Error: problem})
+			continue
+		}
+		var exists int
+		err := a.db.QueryRow(`SELECT COUNT(*) FROM log_entries WHERE content = ? AND created_at = ?`, text, sqlTime(createdAt)).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %v", err)
+		}
+		if exists > 0 {
+			report.Duplicates++
+			continue
+		}
+		batch = append(batch, entry)
+	}
+
+	if dryRun || len(batch) == 0 {
+		report.Created = len(batch)
+		a.logf("Import of %s checked: %d of %d records would be created\n", path, report.Created, report.Records)
+		return report, nil
+	}
+
+	results, err := a.logEntryBatch(context.Background(), batch)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.OK {
+			report.Created++
+		}
+	}
+	a.logf("Imported %d of %d records from %s\n", report.Created, report.Records, path)
+	return report, nil
+}
+
+// batchEntry turns a record into an import BatchEntry, appending the tags its text lacks
+// and normalizing its timestamp to RFC 3339
+func (r importRecord) batchEntry() BatchEntry {
+	content := r.Content
+	if content == "" {
+		content = r.Text
+	}
+	present := make(map[string]bool)
+	for _, name := range extractTagNames(content) {
+		present[strings.ToLower(name)] = true
+	}
+	for _, tag := range r.Tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if strings.TrimSpace(content) != "" && tag != "" && !present[strings.ToLower(tag)] {
+			present[strings.ToLower(tag)] = true
+			content = strings.TrimRight(content, " ") + " #" + tag
+		}
+	}
+
+	createdAt := strings.TrimSpace(r.CreatedAt)
+	if _, err := time.Parse(time.RFC3339, createdAt); err != nil {
+		for _, layout := range importTimeLayouts {
+			if t, err := time.ParseInLocation(layout, createdAt, time.Local); err == nil {
+				createdAt = t.Format(time.RFC3339)
+				break
+			}
+		}
+	}
+	return BatchEntry{Content: content, CreatedAt: createdAt, Source: sourceImport}
+}
+
+// parseImportJSON reads an ExportJSON file, a Day One journal or a plain array of
+// entries with content (or text), created_at and optional tags
+func parseImportJSON(data []byte) ([]importRecord, error) {
+	var records []importRecord
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, newAPIError(codeInvalidPayload, fmt.Sprintf("invalid JSON import: %v", err))
+		}
+		return records, nil
+	}
+
+	var export struct {
+		Format   string          `json:"format"`
+		Version  int             `json:"version"`
+		Metadata json.RawMessage `json:"metadata"`
+		Entries  []importRecord  `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, newAPIError(codeInvalidPayload, fmt.Sprintf("invalid JSON import: %v", err))
+	}
+	if export.Format == "" && export.Metadata != nil {
+		return parseDayOneJournal(data)
+	}
+	if export.Format != "snaplog" || export.Version > jsonExportVersion {
+		return nil, newAPIError(codeInvalidPayload, "the JSON import must be a SnapLog export or an array of entries")
+	}
+	// an export's tags were parsed from the text, which already carries them
+	for i := range export.Entries {
+		export.Entries[i].Tags = nil
+	}
+	return export.Entries, nil
+}
+
+// parseImportCSV reads a CSV file with a header row naming a content (or text) column,
+// a created_at (or date) column and optionally a tags column of space or comma
+// separated tags
+func parseImportCSV(data []byte) ([]importRecord, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, newAPIError(codeInvalidPayload, fmt.Sprintf("invalid CSV import: %v", err))
+	}
+	if len(rows) == 0 {
+		return []importRecord{}, nil
+	}
+
+	columns := map[string]int{"content": -1, "created_at": -1, "tags": -1}
+	for i, name := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "content", "text":
+			columns["content"] = i
+		case "created_at", "date", "timestamp":
+			columns["created_at"] = i
+		case "tags":
+			columns["tags"] = i
+		}
+	}
+	if columns["content"] < 0 {
+		return nil, newAPIError(codeInvalidPayload, "the CSV header needs a content or text column")
+	}
+
+	cell := func(row []string, column string) string {
+		if i := columns[column]; i >= 0 && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	records := make([]importRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, importRecord{
+			Content:   cell(row, "content"),
+			CreatedAt: cell(row, "created_at"),
+			Tags: strings.FieldsFunc(cell(row, "tags"), func(r rune) bool {
+				return r == ',' || r == ' '
+			}),
+		})
+	}
+	return records, nil
+}
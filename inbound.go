@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const maxInboundPayload = 1 << 20
+
+// inboundFuncs are the functions of inbound templates. blank ends every action that
+// prints, so a field missing from the payload (or null) prints nothing.
+var inboundFuncs = template.FuncMap{"blank": blankIfMissing}
+
+func blankIfMissing(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// blankMissingFields adds blank to the end of the printing actions under node
+func blankMissingFields(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			blankMissingFields(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("blank").SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		blankMissingFields(n.List)
+		blankMissingFields(n.ElseList)
+	case *parse.RangeNode:
+		blankMissingFields(n.List)
+		blankMissingFields(n.ElseList)
+	case *parse.WithNode:
+		blankMissingFields(n.List)
+		blankMissingFields(n.ElseList)
+	}
+}
+
+// InboundHook maps a JSON payload posted by a no-code automation into an entry.
+// Template is a Go text/template executed against the decoded payload, e.g.
+// "Completed {{.task_name}} in {{.project}}".
+type InboundHook struct {
+	Name     string   `json:"name"`
+	Secret   string   `json:"secret"`
+	Template string   `json:"template"`
+	Tags     []string `json:"tags"`
+}
+
+func (a *App) findInboundHook(secret string) *InboundHook {
+	for i := range a.settings.InboundHooks {
+		hook := &a.settings.InboundHooks[i]
+		if hook.Secret == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hook.Secret), []byte(secret)) == 1 {
+			return hook
+		}
+	}
+	return nil
+}
+
+// renderInboundEntry turns a payload into entry text using the hook's template.
+// Without a template the payload's "text" or "content" field is used as-is.
+func renderInboundEntry(hook *InboundHook, payload map[string]interface{}) (string, error) {
+	var text string
+	if hook.Template == "" {
+		for _, key := range []string{"text", "content"} {
+			if value, ok := payload[key].(string); ok {
+				text = value
+				break
+			}
+		}
+	} else {
+		tmpl, err := template.New(hook.Name).Option("missingkey=zero").Funcs(inboundFuncs).Parse(hook.Template)
+		if err != nil {
+			return "", fmt.Errorf("invalid template for hook %s: %v", hook.Name, err)
+		}
+		for _, t := range tmpl.Templates() {
+			blankMissingFields(t.Tree.Root)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, payload); err != nil {
+			return "", fmt.Errorf("failed to execute template for hook %s: %v", hook.Name, err)
+		}
+		text = buf.String()
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("payload produced an empty entry")
+	}
+
+	present := make(map[string]bool)
+	for _, name := range extractTagNames(text) {
+		present[canonicalTag(name)] = true
+	}
+	for _, tag := range hook.Tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag != "" && !present[canonicalTag(tag)] {
+			present[canonicalTag(tag)] = true
+			text += " #" + tag
+		}
+	}
+
+	return text, nil
+}
+
+// handleInboundAPI accepts webhook payloads: POST /api/inbound/{secret}
+func (a *App) handleInboundAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	secret := strings.TrimPrefix(r.URL.Path, "/api/inbound/")
+	hook := a.findInboundHook(secret)
+	if hook == nil {
+		writeError(w, http.StatusNotFound, codeNotFound, "Unknown hook")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundPayload))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "Failed to read request body")
+		return
+	}
+
+	payload := make(map[string]interface{})
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+	}
+
+	text, err := renderInboundEntry(hook, payload)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, codeInvalidPayload, err.Error())
+		a.logf("Inbound hook %s rejected payload: %v\n", hook.Name, err)
+		return
+	}
+
+	_, merged, err := a.logAutoEntry("inbound:"+hook.Name, text)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, fmt.Sprintf("Failed to log entry: %v", err))
+		a.logf("Error logging entry from hook %s: %v\n", hook.Name, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"message":   "Entry logged successfully",
+		"coalesced": merged,
+	})
+
+	a.logf("Entry logged via inbound hook %s (coalesced: %v)\n", hook.Name, merged)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// The main journal holds every entry until other journals are made, and the entries
+// logged without one, such as those synced from a device that predates journals
+const (
+	mainJournalID   = 1
+	mainJournalName = "main"
+)
+
+const maxJournalNameLength = 50
+
+// journalCondition selects the entries of the journal named by its argument
+const journalCondition = `journal_id = (SELECT id FROM journals WHERE name = ?)`
+
+// Journal keeps a set of entries apart from the others, e.g. work and personal
+type Journal struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Entries   int       `json:"entries"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// journalState is the journal new entries go to, switched with /journal. Empty means
+// default_journal.
+type journalState struct {
+	mu     sync.Mutex
+	active string
+}
+
+func (a *App) createJournalTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS journals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create journals table: %v", err)
+	}
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO journals (id, name) VALUES (?, ?)`, mainJournalID, mainJournalName); err != nil {
+		return fmt.Errorf("failed to create the main journal: %v", err)
+	}
+	// existing entries and inserts that don't name a journal fall in the main journal
+	if _, err := a.addColumnIfMissing("log_entries", "journal_id", fmt.Sprintf("INTEGER NOT NULL DEFAULT %d", mainJournalID)); err != nil {
+		return err
+	}
+	if _, err := a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_log_entries_journal ON log_entries(journal_id)`); err != nil {
+		return fmt.Errorf("failed to create journal index: %v", err)
+	}
+	return nil
+}
+
+// normalizeJournalName lowercases a journal name and checks it is made of letters,
+// digits, _ and - like a tag
+func normalizeJournalName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !tagNamePattern.MatchString(name) || len(name) > maxJournalNameLength {
+		return "", newAPIError(codeInvalidRequest, fmt.Sprintf("invalid journal name %q: use up to %d letters, digits, _ and -", name, maxJournalNameLength))
+	}
+	return name, nil
+}
+
+func validateDefaultJournal(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := normalizeJournalName(name)
+	return err
+}
+
+// GetJournals returns every journal with its number of entries, oldest first
+func (a *App) GetJournals() ([]Journal, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT j.id, j.name, j.created_at, COUNT(e.id) FROM journals j
+		LEFT JOIN log_entries e ON e.journal_id = j.id AND e.deleted_at IS NULL
+		GROUP BY j.id ORDER BY j.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journals: %v", err)
+	}
+	defer rows.Close()
+
+	journals := []Journal{}
+	for rows.Next() {
+		var journal Journal
+		if err := rows.Scan(&journal.ID, &journal.Name, &journal.CreatedAt, &journal.Entries); err != nil {
+			return nil, fmt.Errorf("failed to scan journal: %v", err)
+		}
+		journals = append(journals, journal)
+	}
+	return journals, nil
+}
+
+// journalID looks up a journal by name
+func (a *App) journalID(name string) (int64, error) {
+	name, err := normalizeJournalName(name)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = a.db.QueryRow(`SELECT id FROM journals WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, newAPIError(codeNotFound, fmt.Sprintf("journal not found: %s", name))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query journal: %v", err)
+	}
+	return id, nil
+}
+
+// getOrCreateJournal returns the ID of the journal with name, creating it if needed
+func (a *App) getOrCreateJournal(name string) (int64, error) {
+	name, err := normalizeJournalName(name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO journals (name) VALUES (?)`, name); err != nil {
+		return 0, fmt.Errorf("failed to create journal: %v", err)
+	}
+	return a.journalID(name)
+}
+
+// GetActiveJournal returns the name of the journal new entries go to
+func (a *App) GetActiveJournal() string {
+	a.journal.mu.Lock()
+	active := a.journal.active
+	a.journal.mu.Unlock()
+	if active != "" {
+		return active
+	}
+	if name, err := normalizeJournalName(a.settings.DefaultJournal); err == nil {
+		return name
+	}
+	return mainJournalName
+}
+
+// activeJournalID returns the ID of the journal new entries go to, creating it when
+// default_journal names one that doesn't exist yet
+func (a *App) activeJournalID() (int64, error) {
+	return a.getOrCreateJournal(a.GetActiveJournal())
+}
+
+// SwitchJournal makes new entries go to the journal with name, creating it if needed,
+// until the app restarts in default_journal or another journal is picked
+func (a *App) SwitchJournal(name string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	if _, err := a.getOrCreateJournal(name); err != nil {
+		return err
+	}
+	name, _ = normalizeJournalName(name)
+
+	a.journal.mu.Lock()
+	a.journal.active = name
+	a.journal.mu.Unlock()
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "journal", name)
+	}
+	a.logf("Switched to journal %s\n", name)
+	return nil
+}
+
+// runJournalCommand switches journal with "/journal <name>"
+func (a *App) runJournalCommand(args string) error {
+	if err := a.SwitchJournal(args); err != nil {
+		return err
+	}
+	a.notify("SnapLog", fmt.Sprintf("New entries go to the %s journal", a.GetActiveJournal()), priorityNormal)
+	return nil
+}
+
+// showJournals lists the journals for a bare "/journal"
+func (a *App) showJournals() error {
+	journals, err := a.GetJournals()
+	if err != nil {
+		return err
+	}
+	active := a.GetActiveJournal()
+	names := make([]string, len(journals))
+	for i, journal := range journals {
+		names[i] = fmt.Sprintf("%s (%d)", journal.Name, journal.Entries)
+		if journal.Name == active {
+			names[i] = "▸ " + names[i]
+		}
+	}
+	a.notify("SnapLog journals", strings.Join(names, ", "), priorityNormal)
+	return nil
+}
+
+// MoveEntry files a misfiled entry under another journal, which must exist. Tags, tasks,
+// people and attachments are shared by all journals and keyed by the entry's ID, which
+// doesn't change, so they follow the entry, and [[id]] links and permalinks to it from
+// either journal keep working.
+func (a *App) MoveEntry(id int, journal string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	entry, err := a.GetEntryByID(id)
+	if err != nil {
+		return newAPIError(codeNotFound, fmt.Sprintf("entry %d not found", id))
+	}
+	journalID, err := a.journalID(journal)
+	if err != nil {
+		return err
+	}
+	if entry.JournalID == journalID {
+		return nil
+	}
+
+	if _, err := a.db.Exec(`UPDATE log_entries SET journal_id = ? WHERE id = ?`, journalID, id); err != nil {
+		return fmt.Errorf("failed to move entry: %v", err)
+	}
+	a.logf("Moved entry %d to journal %d\n", id, journalID)
+	return nil
+}
+
+// runMoveCommand moves an entry with "/move <id> <journal>"
+func (a *App) runMoveCommand(args string) error {
+	parts := strings.Fields(args)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid command. Usage: /move <entry-id> <journal>")
+	}
+	entryID, err := parseCommandEntryID(parts[0], "/move <entry-id> <journal>")
+	if err != nil {
+		return err
+	}
+	if err := a.MoveEntry(entryID, parts[1]); err != nil {
+		return err
+	}
+	a.notify("SnapLog", fmt.Sprintf("Moved entry %d to the %s journal", entryID, strings.ToLower(parts[1])), priorityNormal)
+	return nil
+}
+
+// handleMoveEntry serves POST /api/entries/{id}/move with {"journal": "personal"}
+func (a *App) handleMoveEntry(w http.ResponseWriter, r *http.Request, id int) {
+	var req struct {
+		Journal string `json:"journal"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	if err := a.MoveEntry(id, req.Journal); err != nil {
+		writeEntryEditError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "id": id})
+}
+
+// setSyncedJournal files a synced entry under the journal it has on the device that sent
+// it, creating the journal here if needed
+func (a *App) setSyncedJournal(uid, name string) error {
+	journalID, err := a.getOrCreateJournal(name)
+	if err != nil {
+		a.logf("Warning: ignoring the journal of synced entry %s: %v\n", uid, err)
+		return nil
+	}
+	if _, err := a.db.Exec(`UPDATE log_entries SET journal_id = ? WHERE uid = ? AND journal_id != ?`, journalID, uid, journalID); err != nil {
+		return fmt.Errorf("failed to set journal of synced entry: %v", err)
+	}
+	return nil
+}
+
+// handleJournalsAPI serves GET /api/journals and POST /api/journals/active with
+// {"name": "work"}, which switches the journal new entries go to
+func (a *App) handleJournalsAPI(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/journals"), "/")
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		journals, err := a.GetJournals()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"active": a.GetActiveJournal(), "journals": journals})
+	case r.Method == http.MethodPost && path == "active":
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		if err := a.SwitchJournal(req.Name); err != nil {
+			writeError(w, http.StatusBadRequest, asAPIError(err).Code, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "active": a.GetActiveJournal()})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
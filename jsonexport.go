@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// jsonExportVersion is bumped whenever a field of the JSON export changes meaning or
+// is removed; adding fields keeps the version
+const jsonExportVersion = 1
+
+// JSONExport is the portable backup format written by ExportJSON. It has no export
+// time, so exporting an unchanged database gives an identical file.
+type JSONExport struct {
+	Format  string             `json:"format"`
+	Version int                `json:"version"`
+	Entries []JSONExportEntry  `json:"entries"`
+	Tags    []JSONExportTag    `json:"tags"`
+	People  []JSONExportPerson `json:"people"`
+}
+
+// JSONExportEntry is an entry with its tags, tag positions and @mentions
+type JSONExportEntry struct {
+	ID          int       `json:"id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	Source      string    `json:"source"`
+	RepeatCount int       `json:"repeat_count"`
+	EntryType   string    `json:"entry_type"`
+	Tags        []string  `json:"tags"`
+	TagSpans    []TagSpan `json:"tag_spans"`
+	People      []string  `json:"people"`
+}
+
+// JSONExportTag is a tag with the time it was first used
+type JSONExportTag struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JSONExportPerson is a people directory entry
+type JSONExportPerson struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Team  string `json:"team"`
+	Email string `json:"email"`
+}
+
+// JSONExportReport says where ExportJSON wrote and how much
+type JSONExportReport struct {
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+	Tags    int    `json:"tags"`
+}
+
+// ExportJSON writes every entry, tag and person, with the tags and people of each
+// entry, to path as versioned JSON. An empty path writes exports/snaplog.json in the
+// SnapLog folder. The file is replaced only once it is fully written.
+func (a *App) ExportJSON(path string) (*JSONExportReport, error) {
+	if path == "" {
+		dir, err := snaplogDataDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "exports", "snaplog.json")
+	}
+
+	entries, err := a.entriesBetween(time.Time{}, a.clock.Now().AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	export, err := a.buildJSONExport(entries)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write export: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write export: %v", err)
+	}
+
+	a.logf("Exported %d entries as JSON to %s\n", len(export.Entries), path)
+	return &JSONExportReport{Path: path, Entries: len(export.Entries), Tags: len(export.Tags)}, nil
+}
+
+// buildJSONExport gathers the tags, people and associations of entries. Entries keep
+// the order they were logged in and tags and people are sorted by name, so exports
+// diff cleanly.
+func (a *App) buildJSONExport(entries []LogEntry) (*JSONExport, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	export := &JSONExport{
+		Format:  "snaplog",
+		Version: jsonExportVersion,
+		Entries: make([]JSONExportEntry, 0, len(entries)),
+		Tags:    []JSONExportTag{},
+		People:  []JSONExportPerson{},
+	}
+
+	entryTags, err := a.entryNames(`SELECT lt.log_entry_id, t.name FROM log_entries_tags lt
+		JOIN tags t ON t.id = lt.tag_id ORDER BY lt.log_entry_id, t.name`)
+	if err != nil {
+		return nil, err
+	}
+	entryPeople, err := a.entryNames(`SELECT lp.log_entry_id, p.name FROM log_entries_people lp
+		JOIN people p ON p.id = lp.person_id ORDER BY lp.log_entry_id, p.name`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		item := JSONExportEntry{
+			ID:          entry.ID,
+			Content:     entry.Content,
+			CreatedAt:   entry.CreatedAt.UTC(),
+			Source:      entry.Source,
+			RepeatCount: entry.RepeatCount,
+			EntryType:   entry.EntryType,
+			Tags:        entryTags[entry.ID],
+			TagSpans:    a.entryTagSpans(entry.ID),
+			People:      entryPeople[entry.ID],
+		}
+		if item.Tags == nil {
+			item.Tags = []string{}
+		}
+		if item.TagSpans == nil {
+			item.TagSpans = []TagSpan{}
+		}
+		if item.People == nil {
+			item.People = []string{}
+		}
+		export.Entries = append(export.Entries, item)
+	}
+
+	rows, err := a.db.Query(`SELECT name, created_at FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag JSONExportTag
+		if err := rows.Scan(&tag.Name, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		tag.CreatedAt = tag.CreatedAt.UTC()
+		export.Tags = append(export.Tags, tag)
+	}
+
+	people, err := a.GetPeopleDirectory()
+	if err != nil {
+		return nil, err
+	}
+	for _, person := range people {
+		export.People = append(export.People, JSONExportPerson{Name: person.Name, Role: person.Role, Team: person.Team, Email: person.Email})
+	}
+	return export, nil
+}
+
+// entryNames runs a query returning (entry id, name) rows and groups the names by entry
+func (a *App) entryNames(query string) (map[int][]string, error) {
+	rows, err := a.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entry associations: %v", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan entry association: %v", err)
+		}
+		names[id] = append(names[id], name)
+	}
+	return names, nil
+}
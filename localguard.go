@@ -0,0 +1,82 @@
+package main
+
+import (
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// selfAuthenticatedPaths check a secret or token themselves, and are often reached
+// through a tunnel or reverse proxy, so they may be called under another host name
+var selfAuthenticatedPaths = []string{"/api/inbound/", "/api/ha/"}
+
+// guardLocalRequests protects the dashboard server from the web pages open in the
+// user's browser. The server only listens on localhost but has no login, so:
+//   - a Host other than localhost is refused, which stops DNS rebinding from reading it
+//   - a write from a page on another origin is refused (CSRF)
+//   - a write with a body must be JSON, which a cross-site form or simple request can't
+//     send without a preflight
+//
+// Clients such as curl, scripts and macro pads send no Origin and are not affected.
+func guardLocalRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selfAuthenticated := false
+		for _, prefix := range selfAuthenticatedPaths {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				selfAuthenticated = true
+			}
+		}
+
+		if !selfAuthenticated && !isLocalHost(r.Host) {
+			writeError(w, http.StatusForbidden, codeForbidden, "Requests must be made to localhost")
+			return
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" && !sameLocalOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, codeForbidden, "Cross-origin requests are not allowed")
+			return
+		}
+		if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+			writeError(w, http.StatusForbidden, codeForbidden, "Cross-site requests are not allowed")
+			return
+		}
+		if r.ContentLength != 0 && !strings.HasPrefix(r.URL.Path, "/api/inbound/") {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, codeInvalidPayload, "Request bodies must be sent as application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLocalHost reports whether a Host header names this machine's loopback interface
+func isLocalHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameLocalOrigin reports whether origin is the dashboard itself: a loopback origin on
+// the port the request was made to
+func sameLocalOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !isLocalHost(u.Host) {
+		return false
+	}
+	_, originPort, _ := net.SplitHostPort(u.Host)
+	_, hostPort, _ := net.SplitHostPort(host)
+	return originPort == hostPort
+}
@@ -40,6 +40,11 @@ func main() {
 		Title:  "SnapLog CLI",
 		Width:  800,
 		Height: 300,
+		// the quick capture profile; SetCaptureMode lifts the limits for long-form writing
+		MinWidth:  800,
+		MinHeight: 300,
+		MaxWidth:  800,
+		MaxHeight: 300,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
@@ -49,8 +54,8 @@ func main() {
 		Bind: []interface{}{
 			app,
 		},
-		// Keep the app running in background even when window is closed
-		DisableResize: true,
+		// bound methods reject with the {code, message, details} envelope
+		ErrorFormatter: formatBoundError,
 	})
 
 	if err != nil {
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MarkdownExportReport says where ExportMarkdown wrote and how much
+type MarkdownExportReport struct {
+	Dir     string `json:"dir"`
+	Files   int    `json:"files"`
+	Entries int    `json:"entries"`
+}
+
+func markdownExportDir() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "exports", "markdown"), nil
+}
+
+// ExportMarkdown writes the entries created in [from, to) to one Markdown file per
+// logical day, named 2006-01-02.md, for journal apps such as Obsidian and Logseq. Files
+// of exported days are replaced, so exporting again refreshes them. An empty dir uses
+// exports/markdown in the SnapLog folder and a zero to means up to now.
+func (a *App) ExportMarkdown(dir string, from, to time.Time) (*MarkdownExportReport, error) {
+	if dir == "" {
+		var err error
+		if dir, err = markdownExportDir(); err != nil {
+			return nil, err
+		}
+	}
+	if to.IsZero() {
+		to = a.clock.Now().Add(time.Minute)
+	}
+	if !from.Before(to) {
+		return nil, newAPIError(codeInvalidRequest, "the export range ends before it starts")
+	}
+
+	entries, err := a.entriesBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+
+	report := &MarkdownExportReport{Dir: dir, Entries: len(entries)}
+	for start := 0; start < len(entries); {
+		day := a.dayKey(entries[start].CreatedAt)
+		end := start
+		for end < len(entries) && a.dayKey(entries[end].CreatedAt) == day {
+			end++
+		}
+		if err := os.WriteFile(filepath.Join(dir, day+".md"), []byte(a.markdownDay(entries[start:end])), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s.md: %v", day, err)
+		}
+		report.Files++
+		start = end
+	}
+
+	a.logf("Exported %d entries to %d Markdown files in %s\n", report.Entries, report.Files, dir)
+	return report, nil
+}
+
+// markdownDay renders one day's entries: front matter with the date and the day's tags,
+// then each entry under its local time
+func (a *App) markdownDay(entries []LogEntry) string {
+	day := a.dayOf(entries[0].CreatedAt)
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		for _, tag := range spanTags(validSpans(entry.Content, a.entryTagSpans(entry.ID))) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ndate: %s\n", day.Format("2006-01-02"))
+	if len(tags) > 0 {
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(tags, ", "))
+	}
+	fmt.Fprintf(&b, "---\n\n# %s\n", day.Format("Monday, 2 January 2006"))
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", entry.CreatedAt.Local().Format("15:04"), strings.TrimSpace(entry.Content))
+	}
+	return b.String()
+}
+
+// parseExportRange reads the /export arguments: nothing for every entry, or a first and
+// optional last day, both included. Days are ISO dates or natural ones; a natural range
+// is written "<from> to <to>", as in "last monday to yesterday".
+func (a *App) parseExportRange(args string) (time.Time, time.Time, error) {
+	var days []string
+	if from, to, found := strings.Cut(args, " to "); found {
+		days = []string{from, to}
+	} else if _, ok := parseFilterDay(strings.TrimSpace(args), a.today()); ok {
+		days = []string{args}
+	} else {
+		days = strings.Fields(args)
+	}
+	if len(days) > 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid command. Usage: /export [<from> [<to>]]")
+	}
+	var bounds [2]time.Time
+	for i, value := range days {
+		day, ok := parseFilterDay(strings.TrimSpace(value), a.today())
+		if !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or a date like \"last monday\"", strings.TrimSpace(value))
+		}
+		bounds[i] = a.dayStart(day)
+	}
+	if len(days) == 2 {
+		bounds[1] = bounds[1].AddDate(0, 0, 1)
+	}
+	return bounds[0], bounds[1], nil
+}
+
+func (a *App) runExportCommand(args string) error {
+	from, to, err := a.parseExportRange(args)
+	if err != nil {
+		return err
+	}
+	report, err := a.ExportMarkdown("", from, to)
+	if err != nil {
+		return err
+	}
+	a.notify("SnapLog export", fmt.Sprintf("%d entries exported to %d files", report.Entries, report.Files), priorityNormal)
+	return a.openInBrowser(report.Dir)
+}
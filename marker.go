@@ -0,0 +1,41 @@
+package main
+
+// markerText is the bare entry logged by the marker hotkey. Markers bracket
+// interruptions and are annotated afterwards with /editprev.
+const markerText = "— marker —"
+
+// startMarkerHotkey registers the optional marker hotkey; an empty key disables it
+func (a *App) startMarkerHotkey() {
+	if a.settings.MarkerHotkeyKey == "" {
+		return
+	}
+	if _, ok := parseKey(a.settings.MarkerHotkeyKey); !ok {
+		a.logf("Unknown marker hotkey key: %s\n", a.settings.MarkerHotkeyKey)
+		return
+	}
+
+	hk, err := a.registerHotkey(a.settings.MarkerHotkeyModifiers, a.settings.MarkerHotkeyKey)
+	if err != nil {
+		a.logf("Failed to register marker hotkey: %v\n", err)
+		return
+	}
+
+	a.logf("Marker hotkey registered: %v+%v\n", a.settings.MarkerHotkeyModifiers, a.settings.MarkerHotkeyKey)
+	a.extraHotkeys = append(a.extraHotkeys, hk)
+
+	go func() {
+		keydown := hk.Keydown()
+		for range keydown {
+			a.LogMarker()
+		}
+	}()
+}
+
+// LogMarker logs a bare timestamped marker entry
+func (a *App) LogMarker() error {
+	if _, err := a.logEntry(markerText, sourceHotkey); err != nil {
+		a.logf("Failed to log marker: %v\n", err)
+		return err
+	}
+	return nil
+}
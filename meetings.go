@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ActiveMeeting is the meeting started with /meeting and not yet ended
+type ActiveMeeting struct {
+	ID        int64     `json:"id"`
+	EntryID   int64     `json:"entry_id"`
+	Title     string    `json:"title"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (a *App) createMeetingTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS meetings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		log_entry_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create meetings table: %v", err)
+	}
+	return nil
+}
+
+// parseMeetingArgs splits `"Design review" @alice @bob` into the title and the words
+// after it. The title can also be written without quotes, up to the first @mention.
+func parseMeetingArgs(args string) (string, []string) {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, `"`) {
+		if end := strings.Index(args[1:], `"`); end >= 0 {
+			return strings.TrimSpace(args[1 : end+1]), strings.Fields(args[end+2:])
+		}
+	}
+	words := strings.Fields(args)
+	i := 0
+	for i < len(words) && !strings.HasPrefix(words[i], "@") && !strings.HasPrefix(words[i], "#") {
+		i++
+	}
+	return strings.Trim(strings.Join(words[:i], " "), `"`), words[i:]
+}
+
+// meetingText is the entry logged when a meeting starts
+func meetingText(title string, extra []string) string {
+	var attendees, other []string
+	for _, word := range extra {
+		if strings.HasPrefix(word, "@") {
+			attendees = append(attendees, word)
+		} else {
+			other = append(other, word)
+		}
+	}
+
+	text := "Meeting: " + title
+	if len(attendees) > 0 {
+		text += "\nAttendees: " + strings.Join(attendees, " ")
+	}
+	if len(other) > 0 {
+		text += "\n" + strings.Join(other, " ")
+	}
+	if !strings.Contains(strings.ToLower(text), "#meeting") {
+		text += " #meeting"
+	}
+	return text
+}
+
+// StartMeeting logs a meeting entry with its attendees and starts timing it
+func (a *App) StartMeeting(args string) (*ActiveMeeting, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	title, extra := parseMeetingArgs(args)
+	if title == "" {
+		return nil, fmt.Errorf(`invalid command. Usage: /meeting "<title>" @attendee ...`)
+	}
+	if active, err := a.GetActiveMeeting(); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf("%q is still running: /endmeeting first", active.Title)
+	}
+
+	entryID, err := a.logEntry(meetingText(title, extra), sourceHotkey)
+	if err != nil {
+		return nil, err
+	}
+	meeting := &ActiveMeeting{EntryID: entryID, Title: title, StartedAt: a.clock.Now()}
+	result, err := a.db.Exec(`INSERT INTO meetings (log_entry_id, title, started_at) VALUES (?, ?, ?)`,
+		entryID, title, sqlTime(meeting.StartedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start meeting: %v", err)
+	}
+	meeting.ID, _ = result.LastInsertId()
+
+	a.logf("Meeting started (entry %d)\n", entryID)
+	a.emitMeeting(meeting)
+	return meeting, nil
+}
+
+// GetActiveMeeting returns the running meeting, or nil when there is none
+func (a *App) GetActiveMeeting() (*ActiveMeeting, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	var meeting ActiveMeeting
+	err := a.db.QueryRow(`SELECT id, log_entry_id, title, started_at FROM meetings
+		WHERE ended_at IS NULL AND log_entry_id IN (SELECT id FROM log_entries WHERE deleted_at IS NULL)
+		ORDER BY started_at DESC LIMIT 1`).Scan(&meeting.ID, &meeting.EntryID, &meeting.Title, &meeting.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active meeting: %v", err)
+	}
+	return &meeting, nil
+}
+
+// formatMeetingDuration rounds to the minute: "45 min", "1 h 20 min"
+func formatMeetingDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d h", minutes/60)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
+
+// EndMeeting stops the running meeting and appends its duration to the meeting entry.
+// It returns the action item draft for the input, whose checkboxes become tasks.
+func (a *App) EndMeeting() (string, error) {
+	meeting, err := a.GetActiveMeeting()
+	if err != nil {
+		return "", err
+	}
+	if meeting == nil {
+		return "", fmt.Errorf("no meeting is running: start one with /meeting")
+	}
+
+	now := a.clock.Now()
+	if _, err := a.db.Exec(`UPDATE meetings SET ended_at = ? WHERE id = ?`, sqlTime(now), meeting.ID); err != nil {
+		return "", fmt.Errorf("failed to end meeting: %v", err)
+	}
+	duration := formatMeetingDuration(now.Sub(meeting.StartedAt))
+	if entry, err := a.GetEntryByID(int(meeting.EntryID)); err == nil {
+		if err := a.UpdateEntry(entry.ID, entry.Content+"\nDuration: "+duration); err != nil {
+			a.logf("Warning: failed to add duration to meeting entry %d: %v\n", entry.ID, err)
+		}
+	}
+
+	a.logf("Meeting ended (entry %d) after %s\n", meeting.EntryID, duration)
+	a.emitMeeting(nil)
+	return fmt.Sprintf("Action items from %s [[%d]]:\n- [ ] ", meeting.Title, meeting.EntryID), nil
+}
+
+// emitMeeting tells the input window a meeting started or ended (nil)
+func (a *App) emitMeeting(meeting *ActiveMeeting) {
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "meeting", meeting)
+	}
+}
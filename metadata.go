@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Entry types, derived from content whenever an entry is logged or edited
+const (
+	entryTypeNote      = "note"
+	entryTypeTask      = "task"
+	entryTypeDecision  = "decision"
+	entryTypeMarker    = "marker"
+	entryTypeMeeting   = "meeting"
+	entryTypeMilestone = "milestone"
+	entryTypeTimer     = "timer"
+)
+
+// personPattern matches @name mentions, ignoring email addresses
+var personPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z][A-Za-z0-9_.-]*)`)
+
+var decisionPattern = regexp.MustCompile(`(?im)^\s*decision:|#decision\b`)
+
+var meetingPattern = regexp.MustCompile(`(?i)^\s*meeting:`)
+
+// Person is someone mentioned with @name in entries. Role, team and email are optional
+// directory fields set through /api/people.
+type Person struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Team     string `json:"team"`
+	Email    string `json:"email"`
+	Mentions int    `json:"mentions"`
+}
+
+func (a *App) createPeopleTables() error {
+	createPeopleSQL := `
+	CREATE TABLE IF NOT EXISTS people (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE
+	);`
+
+	createJunctionSQL := `
+	CREATE TABLE IF NOT EXISTS log_entries_people (
+		log_entry_id INTEGER NOT NULL,
+		person_id INTEGER NOT NULL,
+		PRIMARY KEY (log_entry_id, person_id),
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE,
+		FOREIGN KEY (person_id) REFERENCES people(id) ON DELETE CASCADE
+	);`
+
+	if _, err := a.db.Exec(createPeopleSQL); err != nil {
+		return fmt.Errorf("failed to create people table: %v", err)
+	}
+	if _, err := a.db.Exec(createJunctionSQL); err != nil {
+		return fmt.Errorf("failed to create log_entries_people table: %v", err)
+	}
+	return a.migratePeopleDirectory()
+}
+
+// classifyEntry derives the entry type from its content
+func classifyEntry(text string) string {
+	switch {
+	case strings.TrimSpace(text) == markerText:
+		return entryTypeMarker
+	case milestonePattern.MatchString(text):
+		return entryTypeMilestone
+	case taskPattern.MatchString(text):
+		return entryTypeTask
+	case decisionPattern.MatchString(text):
+		return entryTypeDecision
+	case meetingPattern.MatchString(text):
+		return entryTypeMeeting
+	case timerPattern.MatchString(text):
+		return entryTypeTimer
+	default:
+		return entryTypeNote
+	}
+}
+
+// extractPeople returns the unique @mentions in text, in order of appearance
+func extractPeople(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range personPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		key := strings.ToLower(name)
+		if name != "" && !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// updateEntryMetadata stores the entry type and people mentioned for an entry,
+// replacing whatever was derived from its previous content
+func (a *App) updateEntryMetadata(ctx context.Context, entryID int64, text string) error {
+	if _, err := a.db.ExecContext(ctx, `UPDATE log_entries SET entry_type = ? WHERE id = ?`, classifyEntry(text), entryID); err != nil {
+		return fmt.Errorf("failed to set entry type: %v", err)
+	}
+
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_people WHERE log_entry_id = ?`, entryID); err != nil {
+		return fmt.Errorf("failed to clear people links: %v", err)
+	}
+	for _, name := range extractPeople(text) {
+		if _, err := a.db.ExecContext(ctx, `INSERT OR IGNORE INTO people (name) VALUES (?)`, name); err != nil {
+			return fmt.Errorf("failed to create person: %v", err)
+		}
+		linkSQL := `INSERT OR IGNORE INTO log_entries_people (log_entry_id, person_id)
+			SELECT ?, id FROM people WHERE name = ?`
+		if _, err := a.db.ExecContext(ctx, linkSQL, entryID, name); err != nil {
+			return fmt.Errorf("failed to link person: %v", err)
+		}
+	}
+	return nil
+}
+
+// backfillEntryMetadata derives types and people for entries logged before they were tracked
+func (a *App) backfillEntryMetadata() error {
+	rows, err := a.db.Query(`SELECT id, content FROM log_entries WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query entries for backfill: %v", err)
+	}
+
+	type pending struct {
+		id      int64
+		content string
+	}
+	var entries []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.content); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan entry for backfill: %v", err)
+		}
+		entries = append(entries, p)
+	}
+	rows.Close()
+
+	for _, p := range entries {
+		if err := a.updateEntryMetadata(context.Background(), p.id, p.content); err != nil {
+			return err
+		}
+	}
+	a.logf("Backfilled metadata for %d entries\n", len(entries))
+	return nil
+}
+
+// GetPeople returns everyone mentioned in at least one entry
+func (a *App) GetPeople() ([]Person, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	query := `SELECT ` + personColumns + ` FROM people p
+		WHERE EXISTS (SELECT 1 FROM log_entries_people lp WHERE lp.person_id = p.id)
+		ORDER BY p.name`
+	rows, err := a.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query people: %v", err)
+	}
+	defer rows.Close()
+
+	var people []Person
+	for rows.Next() {
+		p, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		people = append(people, p)
+	}
+	return people, nil
+}
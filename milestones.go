@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var milestonePattern = regexp.MustCompile(`(?i)^\s*milestone:\s*`)
+
+// LogMilestone logs a milestone entry, e.g. "Shipped v2 #redesign". Milestones show as
+// separators on the dashboard and in the history strip of their projects.
+func (a *App) LogMilestone(text string) (int64, error) {
+	text = strings.TrimSpace(milestonePattern.ReplaceAllString(text, ""))
+	if text == "" {
+		return 0, fmt.Errorf("invalid command. Usage: /milestone <what happened> [#tag]")
+	}
+	return a.logEntry("Milestone: "+text, sourceHotkey)
+}
+
+// GetMilestones returns the milestones of a project, oldest first, or every milestone
+// when project is empty
+func (a *App) GetMilestones(project string) ([]LogEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	condition, args := `entry_type = ?`, []interface{}{entryTypeMilestone}
+	if project != "" {
+		p, err := a.GetProject(project)
+		if err != nil {
+			return nil, err
+		}
+		condition, args = condition+` AND `+taggedEntryCondition, append(args, p.Tag)
+	}
+
+	milestones, err := a.queryEntriesWhere(context.Background(), condition+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	if milestones == nil {
+		milestones = []LogEntry{}
+	}
+	return milestones, nil
+}
+
+// milestoneTitle is the first line of a milestone without its "Milestone:" prefix and tags
+func milestoneTitle(content string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(milestonePattern.ReplaceAllString(content, "")), "\n")
+	return stripTags(title)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// startMultiCapture opens the long-form editor prefilled with "/multi" and whatever was
+// typed after it, so the lines to log can be pasted or typed below it
+func (a *App) startMultiCapture(shared string) error {
+	if err := a.SetCaptureMode(captureModeLong); err != nil {
+		return err
+	}
+	return a.requestPrefill(strings.TrimSpace("/multi "+shared) + "\n")
+}
+
+// runMultiCommand logs each non-empty line below "/multi" as its own entry. Text on the
+// /multi line itself, such as "#standup @bob", is added to every entry, and an @@when
+// there backdates the entries that don't have their own.
+func (a *App) runMultiCommand(args string) error {
+	shared, body, found := strings.Cut(args, "\n")
+	if !found || strings.TrimSpace(body) == "" {
+		return a.startMultiCapture(shared)
+	}
+
+	now := a.clock.Now()
+	shared, sharedAt, err := splitBackdate(strings.TrimSpace(shared), now)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for i, line := range lines {
+		text, at, err := splitBackdate(line, now)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if at.IsZero() {
+			at = sharedAt
+		}
+		if shared != "" {
+			text += " " + shared
+		}
+		if _, err := a.logEntryAt(text, sourceHotkey, at); err != nil {
+			return fmt.Errorf("failed to log line %d: %v", i+1, err)
+		}
+	}
+	a.logf("Logged %d entries with /multi\n", len(lines))
+	return nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Natural-language dates, used to backdate entries ("/log yesterday evening ...") and to
+// filter them ("last tuesday", "3 days ago"). Days are calendar days; grouping by the
+// logical day is left to the callers.
+
+// Times of day: 14:30, 3pm, 3:30pm
+var (
+	clockTimePattern    = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	meridiemTimePattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	ordinalDayPattern   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// dayPeriods are the hours parts of the day stand for, e.g. "yesterday evening"
+var dayPeriods = map[string]int{
+	"midnight":  0,
+	"morning":   9,
+	"noon":      12,
+	"midday":    12,
+	"lunch":     12,
+	"afternoon": 15,
+	"evening":   19,
+	"night":     21,
+}
+
+// naturalDefaultHour is the hour a day without a time of day stands for
+const naturalDefaultHour = 12
+
+// naturalFillers are words that add nothing to a date, as in "on the 3rd at 5pm"
+var naturalFillers = map[string]bool{"at": true, "on": true, "the": true, "of": true, "in": true, "this": true}
+
+// naturalWords lowercases text and splits it into words, dropping commas and fillers
+func naturalWords(text string) []string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(text, ",", " "))) {
+		if !naturalFillers[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// parseNaturalTime reads when something happened: "yesterday evening", "last tuesday 3pm",
+// "2 hours ago", "tonight", "may 1 14:30" or "2024-05-01 14:30". A day alone means noon,
+// and a time alone that hasn't come yet today means yesterday.
+func parseNaturalTime(text string, now time.Time) (time.Time, bool) {
+	now = now.Local()
+	words := naturalWords(text)
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	if ago, ok := parseAgo(words); ok {
+		return now.Add(-ago), true
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	day, rest, hasDay := parseDayWords(words, today)
+	if !hasDay {
+		rest = words
+		// the day may also come last, as in "3pm yesterday"
+		for i := 1; i < len(words) && !hasDay; i++ {
+			if tail, remaining, ok := parseDayWords(words[i:], today); ok && len(remaining) == 0 {
+				day, rest, hasDay = tail, words[:i], true
+			}
+		}
+	}
+
+	hour, minute := naturalDefaultHour, 0
+	if len(rest) > 0 {
+		var ok bool
+		if hour, minute, ok = parseTimeOfDay(strings.Join(rest, "")); !ok {
+			return time.Time{}, false
+		}
+	} else if !hasDay {
+		return time.Time{}, false
+	}
+
+	if !hasDay {
+		at := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, time.Local)
+		if at.After(now) {
+			at = at.AddDate(0, 0, -1)
+		}
+		return at, true
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local), true
+}
+
+// parseNaturalDay reads a day to filter by: "today", "last tuesday", "3 days ago",
+// "may 1" or "2024-05-01". It returns local midnight of that day.
+func parseNaturalDay(text string, today time.Time) (time.Time, bool) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	day, rest, ok := parseDayWords(naturalWords(text), today)
+	if !ok || len(rest) > 0 {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// parseDayWords reads a day from the start of words and returns it with the words left
+// over, e.g. "night" after "last night". Weekdays and dates without a year are the most
+// recent ones that aren't after today.
+func parseDayWords(words []string, today time.Time) (time.Time, []string, bool) {
+	if len(words) == 0 {
+		return time.Time{}, nil, false
+	}
+	switch words[0] {
+	case "today":
+		return today, words[1:], true
+	case "tonight":
+		return today, append([]string{"night"}, words[1:]...), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), words[1:], true
+	}
+	if len(words) >= 3 && words[0] == "day" && words[1] == "before" && words[2] == "yesterday" {
+		return today.AddDate(0, 0, -2), words[3:], true
+	}
+	if len(words) >= 3 && words[2] == "ago" {
+		if count, ok := naturalCount(words[0]); ok {
+			switch strings.TrimSuffix(words[1], "s") {
+			case "day":
+				return today.AddDate(0, 0, -count), words[3:], true
+			case "week":
+				return today.AddDate(0, 0, -7*count), words[3:], true
+			case "month":
+				return today.AddDate(0, -count, 0), words[3:], true
+			}
+		}
+	}
+	if words[0] == "last" && len(words) >= 2 {
+		switch words[1] {
+		case "night":
+			return today.AddDate(0, 0, -1), words[1:], true
+		case "week":
+			return today.AddDate(0, 0, -7), words[2:], true
+		case "month":
+			return today.AddDate(0, -1, 0), words[2:], true
+		}
+		if weekday, ok := parseWeekday(words[1]); ok {
+			return mostRecentWeekday(today, weekday), words[2:], true
+		}
+		return time.Time{}, nil, false
+	}
+	if weekday, ok := parseWeekday(words[0]); ok {
+		return mostRecentWeekday(today, weekday), words[1:], true
+	}
+	if day, err := time.ParseInLocation("2006-01-02", words[0], time.Local); err == nil {
+		return day, words[1:], true
+	}
+	return parseMonthDay(words, today)
+}
+
+// parseMonthDay reads "may 1", "1st may", "may 1 2024" or "1 may 2024"
+func parseMonthDay(words []string, today time.Time) (time.Time, []string, bool) {
+	if len(words) < 2 {
+		return time.Time{}, nil, false
+	}
+	month, ok := parseMonth(words[0])
+	dayWord := words[1]
+	if !ok {
+		if month, ok = parseMonth(words[1]); !ok {
+			return time.Time{}, nil, false
+		}
+		dayWord = words[0]
+	}
+	match := ordinalDayPattern.FindStringSubmatch(dayWord)
+	if match == nil {
+		return time.Time{}, nil, false
+	}
+	dayOfMonth, _ := strconv.Atoi(match[1])
+	if dayOfMonth < 1 || dayOfMonth > 31 {
+		return time.Time{}, nil, false
+	}
+
+	rest := words[2:]
+	if len(rest) > 0 && len(rest[0]) == 4 {
+		if year, err := strconv.Atoi(rest[0]); err == nil {
+			day, ok := dateOf(year, month, dayOfMonth)
+			return day, rest[1:], ok
+		}
+	}
+	day, ok := dateOf(today.Year(), month, dayOfMonth)
+	if !ok || day.After(today) {
+		day, ok = dateOf(today.Year()-1, month, dayOfMonth)
+	}
+	return day, rest, ok
+}
+
+// dateOf returns the day, or false when the month doesn't have it, as for "feb 30"
+func dateOf(year int, month time.Month, dayOfMonth int) (time.Time, bool) {
+	day := time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.Local)
+	if day.Month() != month {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// parseAgo reads "2 hours ago", "an hour ago", "45 minutes ago" or "3 days ago" as how long
+// ago that was
+func parseAgo(words []string) (time.Duration, bool) {
+	if len(words) != 3 || words[2] != "ago" {
+		return 0, false
+	}
+	count, ok := naturalCount(words[0])
+	if !ok {
+		return 0, false
+	}
+	switch strings.TrimSuffix(words[1], "s") {
+	case "minute", "min":
+		return time.Duration(count) * time.Minute, true
+	case "hour", "hr":
+		return time.Duration(count) * time.Hour, true
+	case "day":
+		return time.Duration(count) * 24 * time.Hour, true
+	case "week":
+		return time.Duration(count) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// naturalCount reads a count such as 3, "a" or "an"
+func naturalCount(word string) (int, bool) {
+	if word == "a" || word == "an" {
+		return 1, true
+	}
+	count, err := strconv.Atoi(word)
+	return count, err == nil && count >= 0
+}
+
+// parseTimeOfDay reads 14:30, 3pm, 3:30pm or a part of the day such as "evening"
+func parseTimeOfDay(text string) (int, int, bool) {
+	if hour, ok := dayPeriods[text]; ok {
+		return hour, 0, true
+	}
+	if match := clockTimePattern.FindStringSubmatch(text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		return hour, minute, hour < 24 && minute < 60
+	}
+	if match := meridiemTimePattern.FindStringSubmatch(text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute := 0
+		if match[2] != "" {
+			minute, _ = strconv.Atoi(match[2])
+		}
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+		return hour, minute, true
+	}
+	return 0, 0, false
+}
+
+// parseWeekday reads a weekday from its name or the first three letters or more of it
+func parseWeekday(word string) (time.Weekday, bool) {
+	if len(word) < 3 {
+		return 0, false
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.HasPrefix(strings.ToLower(weekday.String()), word) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonth reads a month from its name or the first three letters or more of it
+func parseMonth(word string) (time.Month, bool) {
+	if len(word) < 3 {
+		return 0, false
+	}
+	for month := time.January; month <= time.December; month++ {
+		if strings.HasPrefix(strings.ToLower(month.String()), word) {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// mostRecentWeekday is the last weekday before today, a week ago when today is one
+func mostRecentWeekday(today time.Time, weekday time.Weekday) time.Time {
+	back := (int(today.Weekday()) - int(weekday) + 7) % 7
+	if back == 0 {
+		back = 7
+	}
+	return today.AddDate(0, 0, -back)
+}
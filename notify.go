@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+type notificationPriority int
+
+const (
+	// priorityLow notifications (e.g. reminders) are dropped while Focus is on
+	priorityLow notificationPriority = iota
+	// priorityNormal notifications are queued while Focus is on
+	priorityNormal
+	// priorityUrgent notifications are always delivered
+	priorityUrgent
+)
+
+const focusPollInterval = 30 * time.Second
+
+// PendingNotification is a notification deferred until Focus/DND ends
+type PendingNotification struct {
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type notificationQueue struct {
+	mu          sync.Mutex
+	pending     []PendingNotification
+	focusActive bool
+	dndUntil    time.Time
+}
+
+// notify shows a system notification, deferring or suppressing it while the OS Focus/DND mode is on
+func (a *App) notify(title, message string, priority notificationPriority) {
+	if priority != priorityUrgent && (a.doNotDisturbActive() || !a.settings.IgnoreFocusMode && a.checkFocusMode()) {
+		if priority == priorityLow {
+			a.logf("Focus mode active - suppressed notification: %s\n", title)
+			return
+		}
+		a.notifications.mu.Lock()
+		a.notifications.pending = append(a.notifications.pending, PendingNotification{
+			Title:     title,
+			Message:   message,
+			CreatedAt: a.clock.Now(),
+		})
+		a.notifications.mu.Unlock()
+		a.logf("Focus mode active - deferred notification: %s\n", title)
+		return
+	}
+
+	if err := a.notifier.Notify(title, message); err != nil {
+		a.logf("Warning: failed to show notification: %v\n", err)
+	}
+}
+
+// notificationAction is a button on a notification
+type notificationAction struct {
+	Key   string
+	Label string
+}
+
+// notifyWithActions shows a low priority notification with buttons and returns the key of
+// the one clicked, or "" when it was dismissed, suppressed by Focus/DND, or the platform
+// can't show buttons. It blocks until then, so callers run it in a goroutine.
+func (a *App) notifyWithActions(title, message string, actions []notificationAction) string {
+	if a.doNotDisturbActive() || !a.settings.IgnoreFocusMode && a.checkFocusMode() {
+		a.logf("Focus mode active - suppressed notification: %s\n", title)
+		return ""
+	}
+	withActions, ok := a.notifier.(actionNotifier)
+	if !ok {
+		a.notify(title, message, priorityUrgent)
+		return ""
+	}
+	key, err := withActions.NotifyActions(title, message, actions)
+	if err != nil {
+		a.logf("Warning: failed to show notification: %v\n", err)
+		return ""
+	}
+	return key
+}
+
+func (a *App) checkFocusMode() bool {
+	active := isFocusModeActive()
+	a.notifications.mu.Lock()
+	a.notifications.focusActive = active
+	a.notifications.mu.Unlock()
+	return active
+}
+
+func (a *App) doNotDisturbActive() bool {
+	a.notifications.mu.Lock()
+	defer a.notifications.mu.Unlock()
+	return a.clock.Now().Before(a.notifications.dndUntil)
+}
+
+// SetDoNotDisturb holds SnapLog's own notifications and reminders for the given minutes; 0 ends it
+func (a *App) SetDoNotDisturb(minutes int) {
+	a.notifications.mu.Lock()
+	a.notifications.dndUntil = a.clock.Now().Add(time.Duration(minutes) * time.Minute)
+	a.notifications.mu.Unlock()
+	a.logf("Do Not Disturb set for %d minutes\n", minutes)
+}
+
+// watchFocusMode delivers queued notifications once Focus/DND is switched off
+func (a *App) watchFocusMode() {
+	ticks, stop := a.clock.Ticker(focusPollInterval)
+	defer stop()
+
+	for range ticks {
+		if a.checkFocusMode() || a.doNotDisturbActive() {
+			continue
+		}
+		a.flushPendingNotifications()
+	}
+}
+
+func (a *App) flushPendingNotifications() {
+	a.notifications.mu.Lock()
+	pending := a.notifications.pending
+	a.notifications.pending = nil
+	a.notifications.mu.Unlock()
+
+	switch len(pending) {
+	case 0:
+		return
+	case 1:
+		a.notify(pending[0].Title, pending[0].Message, priorityUrgent)
+	default:
+		titles := make([]string, len(pending))
+		for i, n := range pending {
+			titles[i] = n.Title
+		}
+		a.notify(fmt.Sprintf("%d notifications while Focus was on", len(pending)), strings.Join(titles, ", "), priorityUrgent)
+	}
+}
+
+// IsFocusModeActive reports whether the OS Focus/Do Not Disturb mode was on at the last check,
+// or SnapLog's own Do Not Disturb is set
+func (a *App) IsFocusModeActive() bool {
+	a.notifications.mu.Lock()
+	defer a.notifications.mu.Unlock()
+	return a.notifications.focusActive || a.clock.Now().Before(a.notifications.dndUntil)
+}
+
+func (a *App) GetPendingNotifications() []PendingNotification {
+	a.notifications.mu.Lock()
+	defer a.notifications.mu.Unlock()
+	pending := make([]PendingNotification, len(a.notifications.pending))
+	copy(pending, a.notifications.pending)
+	return pending
+}
+
+// sendSystemNotification shows a native notification using the tooling available on each platform
+func sendSystemNotification(title, message string) error {
+	switch runtime.GOOS {
+	case "windows":
+		escapedTitle := strings.ReplaceAll(title, "'", "''")
+		escapedMsg := strings.ReplaceAll(message, "'", "''")
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+			`$n.ShowBalloonTip(5000, '%s', '%s', 'Info'); Start-Sleep -Seconds 6; $n.Dispose()`,
+			escapedTitle, escapedMsg)
+		// the balloon stays up for a few seconds; wait for PowerShell in the background so
+		// the finished process is reaped
+		cmd := exec.Command("powershell", "-NoProfile", "-WindowStyle", "Hidden", "-Command", script)
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		go cmd.Wait()
+		return nil
+	case "darwin":
+		// title and message are passed as arguments rather than written into the script, so
+		// quotes and backslashes in them need no escaping
+		return exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message).Run()
+	case "linux":
+		return exec.Command("notify-send", title, message).Run()
+	default:
+		return fmt.Errorf("notifications are not supported on %s", runtime.GOOS)
+	}
+}
+
+// sendActionNotification shows a native notification with buttons where the platform has
+// a tool that reports the click: notify-send 0.7.9+ on Linux and terminal-notifier on macOS.
+// Elsewhere it shows a plain notification and returns "".
+func sendActionNotification(title, message string, actions []notificationAction) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		args := []string{"--app-name=SnapLog", "--expire-time=8000", "--wait"}
+		for _, action := range actions {
+			args = append(args, "--action="+action.Key+"="+action.Label)
+		}
+		cmd = exec.Command("notify-send", append(args, title, message)...)
+	case "darwin":
+		if _, err := exec.LookPath("terminal-notifier"); err != nil {
+			return "", sendSystemNotification(title, message)
+		}
+		labels := make([]string, len(actions))
+		for i, action := range actions {
+			labels[i] = action.Label
+		}
+		cmd = exec.Command("terminal-notifier", "-title", title, "-message", message,
+			"-actions", strings.Join(labels, ","), "-timeout", "8")
+	default:
+		return "", sendSystemNotification(title, message)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		// an older notify-send without --action: fall back to a plain notification
+		return "", sendSystemNotification(title, message)
+	}
+	answer := strings.TrimSpace(string(output))
+	for _, action := range actions {
+		if answer == action.Key || answer == action.Label {
+			return action.Key, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dailyNotePattern matches the file names of Obsidian daily notes, 2006-01-02.md
+var dailyNotePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.md$`)
+
+// timedBulletPattern matches a list item that starts with a time, "- 14:32 did X"
+var timedBulletPattern = regexp.MustCompile(`^[-*+]\s+(?:\[[ xX]\]\s+)?([01]?\d|2[0-3]):([0-5]\d)\s*(?:[-–—:]\s*)?(.+)$`)
+
+// wikiLinkPattern matches [[Page]] and [[Page|label]] links, but not [[123]] entry links
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\]|]*[^\]|\d][^\]|]*)(?:\|([^\]]+))?\]\]`)
+
+// embedPattern matches ![[file]] embeds of images and other notes
+var embedPattern = regexp.MustCompile(`!\[\[[^\]]*\]\]`)
+
+// nestedTagPattern matches Obsidian's nested tags, #project/alpha
+var nestedTagPattern = regexp.MustCompile(`#[\p{L}\p{M}\p{N}_-]+(?:/[\p{L}\p{M}\p{N}_-]+)+`)
+
+// parseImportObsidian walks a vault folder for daily notes and turns each one into
+// entries: every "- 14:32 did X" item becomes an entry at that time, with the lines
+// indented under it, and the rest of the note becomes one entry at the start of the day.
+// Tags in the front matter are added to every entry of the note.
+func (a *App) parseImportObsidian(dir string) ([]importRecord, error) {
+	records := []importRecord{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		match := dailyNotePattern.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		day, err := time.ParseInLocation("2006-01-02", match[1], time.Local)
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", d.Name(), err)
+		}
+		records = append(records, a.dailyNoteRecords(day, string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daily notes: %v", err)
+	}
+	return records, nil
+}
+
+// dailyNoteRecords splits one daily note into import records
+func (a *App) dailyNoteRecords(day time.Time, note string) []importRecord {
+	tags, body := splitFrontMatter(strings.ReplaceAll(note, "\r\n", "\n"))
+
+	var records []importRecord
+	var rest []string
+	var timed *importRecord
+	for _, line := range strings.Split(body, "\n") {
+		if match := timedBulletPattern.FindStringSubmatch(strings.TrimRight(line, " \t")); match != nil {
+			hour, minute := 0, 0
+			fmt.Sscanf(match[1]+" "+match[2], "%d %d", &hour, &minute)
+			at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+			records = append(records, importRecord{Content: match[3], CreatedAt: at.Format(time.RFC3339), Tags: tags})
+			timed = &records[len(records)-1]
+			continue
+		}
+		if timed != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && strings.TrimSpace(line) != "" {
+			timed.Content += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		timed = nil
+		rest = append(rest, line)
+	}
+
+	if text := strings.TrimSpace(strings.Join(rest, "\n")); text != "" {
+		records = append(records, importRecord{Content: text, CreatedAt: a.dayStart(day).Format(time.RFC3339), Tags: tags})
+	}
+	for i := range records {
+		records[i].Content = obsidianMarkdown(records[i].Content)
+	}
+	return records
+}
+
+// splitFrontMatter separates a note's YAML front matter from its body and returns the
+// tags it lists, as `tags: [a, b]`, `tags: a, b` or a list of `- a` lines
+func splitFrontMatter(note string) ([]string, string) {
+	if !strings.HasPrefix(note, "---\n") {
+		return nil, note
+	}
+	end := strings.Index(note[4:], "\n---")
+	if end < 0 {
+		return nil, note
+	}
+	header, body := note[4:4+end], note[4+end+4:]
+
+	var tags []string
+	inTags := false
+	for _, line := range strings.Split(header, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		switch {
+		case found && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-"):
+			inTags = strings.TrimSpace(key) == "tags" || strings.TrimSpace(key) == "tag"
+			if inTags {
+				tags = append(tags, strings.FieldsFunc(strings.Trim(strings.TrimSpace(value), "[]"), func(r rune) bool {
+					return r == ',' || r == ' '
+				})...)
+			}
+		case inTags && strings.HasPrefix(strings.TrimSpace(line), "- "):
+			tags = append(tags, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- ")))
+		}
+	}
+	for i, tag := range tags {
+		tags[i] = strings.ReplaceAll(strings.Trim(tag, `"'#`), "/", "-")
+	}
+	return tags, body
+}
+
+// obsidianMarkdown turns Obsidian syntax SnapLog doesn't know into plain text: embeds
+// are dropped, wiki links become their label and nested tags become #project-alpha
+func obsidianMarkdown(text string) string {
+	text = strings.TrimSpace(embedPattern.ReplaceAllString(text, ""))
+	text = wikiLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := wikiLinkPattern.FindStringSubmatch(link)
+		if match[2] != "" {
+			return match[2]
+		}
+		return match[1]
+	})
+	return nestedTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		return strings.ReplaceAll(tag, "/", "-")
+	})
+}
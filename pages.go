@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// PageData is the common envelope for dashboard sub-pages rendered with templates/layout.html
+type PageData struct {
+	Title     string
+	LogoData  template.URL
+	Generated string
+	Theme     string
+	FontScale int
+	Density   string
+	Data      interface{}
+}
+
+func readTemplate(name string) ([]byte, error) {
+	content, err := templates.ReadFile("templates/" + name)
+	if err != nil {
+		content, err = os.ReadFile(filepath.Join(".", "templates", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %v", name, err)
+		}
+	}
+	return content, nil
+}
+
+// renderPage executes a page template inside the shared layout
+func (a *App) renderPage(w http.ResponseWriter, page, title string, data interface{}) {
+	layout, err := readTemplate("layout.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	content, err := readTemplate(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := template.New(page).Funcs(pageFuncs).Parse(string(layout))
+	if err == nil {
+		_, err = tmpl.Parse(string(content))
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse template: %v", err), http.StatusInternalServerError)
+		a.logf("Error parsing template %s: %v\n", page, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	pageData := PageData{
+		Title:     title,
+		LogoData:  logoURL(),
+		Generated: a.clock.Now().Local().Format("2006-01-02 15:04:05"),
+		Theme:     a.settings.DashboardTheme,
+		FontScale: a.dashboardFontScale(),
+		Density:   a.dashboardDensity(),
+		Data:      data,
+	}
+	if err := tmpl.Execute(&buf, pageData); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate HTML: %v", err), http.StatusInternalServerError)
+		a.logf("Error executing template %s: %v\n", page, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+var pageFuncs = template.FuncMap{
+	"asset": assetURL,
+	"percent": func(ratio float64) string {
+		return fmt.Sprintf("%.0f%%", ratio*100)
+	},
+	"dict": func(pairs ...interface{}) map[string]interface{} {
+		m := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			m[fmt.Sprint(pairs[i])] = pairs[i+1]
+		}
+		return m
+	},
+}
+
+// StatsPageData holds everything rendered on /dash/stats
+type StatsPageData struct {
+	SwitchesToday   int
+	ContextSwitches []DailyContextSwitches
+	Reminders       *ReminderStats
+	Workload        *WorkloadStats
+	Burndown        []HistogramBucket
+	Estimates       *EstimationStats
+}
+
+func (a *App) serveStatsPage(w http.ResponseWriter, r *http.Request) {
+	switches, err := a.GetContextSwitches(14)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get stats: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting stats: %v\n", err)
+		return
+	}
+
+	reminderStats, err := a.GetReminderStats(30)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get reminder stats: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting reminder stats: %v\n", err)
+		return
+	}
+
+	workload, err := a.GetWorkloadStats(8)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get workload stats: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting workload stats: %v\n", err)
+		return
+	}
+
+	burndown, err := a.GetTaskBurndown("", burndownDays)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get task burndown: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting task burndown: %v\n", err)
+		return
+	}
+
+	estimates, err := a.GetEstimationStats(estimateWeeks)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get estimation stats: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting estimation stats: %v\n", err)
+		return
+	}
+
+	data := StatsPageData{ContextSwitches: switches, Reminders: reminderStats, Workload: workload, Burndown: burndownBars(burndown),
+		Estimates: estimates}
+	if len(switches) > 0 {
+		data.SwitchesToday = switches[0].Total
+	}
+
+	a.renderPage(w, "stats.html", "Stats", data)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A small writer for text-only PDF reports, such as the timesheet, using the standard
+// Helvetica fonts every PDF reader has. Pages are A4 and filled top to bottom.
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// pdfCell is text placed at x on the current row, or ending at x when right is set
+type pdfCell struct {
+	x     float64
+	text  string
+	right bool
+}
+
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	y     float64
+	// scale multiplies every font size and gap; leading is the line height as a
+	// multiple of the font size
+	scale   float64
+	leading float64
+}
+
+func newPDFWriter() *pdfWriter {
+	p := &pdfWriter{scale: 1, leading: 1.4}
+	p.newPage()
+	return p
+}
+
+func (p *pdfWriter) newPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// row writes cells on the next line, starting a new page when this one is full
+func (p *pdfWriter) row(size float64, bold bool, cells ...pdfCell) {
+	size *= p.scale
+	if p.y-size < pdfMargin {
+		p.newPage()
+	}
+	p.y -= size * p.leading
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	page := p.pages[len(p.pages)-1]
+	for _, cell := range cells {
+		x := cell.x
+		if cell.right {
+			x -= pdfTextWidth(cell.text, size)
+		}
+		fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, p.y, pdfEscape(cell.text))
+	}
+}
+
+// rule draws a horizontal line under the current row
+func (p *pdfWriter) rule() {
+	p.y -= 4
+	fmt.Fprintf(p.pages[len(p.pages)-1], "%.2f %.2f m %.2f %.2f l 0.5 w S\n", pdfMargin, p.y, pdfPageWidth-pdfMargin, p.y)
+}
+
+// space leaves a gap of height points
+func (p *pdfWriter) space(height float64) {
+	p.y -= height * p.scale
+}
+
+// bytes renders the document
+func (p *pdfWriter) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// pdfWinAnsi maps the characters outside Latin-1 that WinAnsiEncoding has
+var pdfWinAnsi = map[rune]byte{'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97}
+
+// pdfEscape encodes text as a PDF string in WinAnsiEncoding; other characters become ?
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case pdfWinAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", pdfWinAnsi[r])
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfTextWidth estimates the width of Helvetica text, exactly for digits and the
+// punctuation of numbers, which is what gets right-aligned
+func pdfTextWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		switch {
+		case r == '.' || r == ',' || r == ' ' || r == ':':
+			width += 278
+		case r == '-':
+			width += 333
+		default:
+			width += 556
+		}
+	}
+	return width * size / 1000
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+const maxPersonFieldLength = 200
+
+var personNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// PersonUpdate changes a person's directory fields; fields left out are kept
+type PersonUpdate struct {
+	Role  *string `json:"role"`
+	Team  *string `json:"team"`
+	Email *string `json:"email"`
+}
+
+// Context describes the person in a line, e.g. "Designer · Payments"
+func (p Person) Context() string {
+	var parts []string
+	for _, part := range []string{p.Role, p.Team, p.Email} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// migratePeopleDirectory adds the directory fields to people tables created before them
+func (a *App) migratePeopleDirectory() error {
+	for _, column := range []string{"role", "team", "email"} {
+		if _, err := a.addColumnIfMissing("people", column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPeopleDirectory returns everyone in the people table, including people added to the
+// directory before they were mentioned
+func (a *App) GetPeopleDirectory() ([]Person, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT ` + personColumns + ` FROM people p ORDER BY p.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query people: %v", err)
+	}
+	defer rows.Close()
+
+	people := []Person{}
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		people = append(people, person)
+	}
+	return people, nil
+}
+
+// GetPerson looks up someone by name, ignoring case
+func (a *App) GetPerson(name string) (*Person, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	row := a.db.QueryRow(`SELECT `+personColumns+` FROM people p WHERE p.name = ?`, strings.TrimPrefix(name, "@"))
+	person, err := scanPerson(row)
+	if err == sql.ErrNoRows {
+		return nil, newAPIError(codeNotFound, fmt.Sprintf("person not found: %s", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// UpdatePerson sets a person's role, team and email, adding them to the directory if
+// they haven't been mentioned yet
+func (a *App) UpdatePerson(name string, update PersonUpdate) (*Person, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if !personNamePattern.MatchString(name) {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid name %q: write it as it is mentioned, without spaces", name))
+	}
+	fields := map[string]*string{"role": update.Role, "team": update.Team, "email": update.Email}
+	for field, value := range fields {
+		if value == nil {
+			continue
+		}
+		*value = strings.TrimSpace(*value)
+		if len(*value) > maxPersonFieldLength {
+			return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("%s is longer than %d characters", field, maxPersonFieldLength))
+		}
+	}
+	if update.Email != nil && *update.Email != "" {
+		address, err := mail.ParseAddress(*update.Email)
+		if err != nil || address.Name != "" {
+			return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid email address: %s", *update.Email))
+		}
+	}
+
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO people (name) VALUES (?)`, name); err != nil {
+		return nil, fmt.Errorf("failed to create person: %v", err)
+	}
+	for field, value := range fields {
+		if value == nil {
+			continue
+		}
+		if _, err := a.db.Exec(`UPDATE people SET `+field+` = ? WHERE name = ?`, *value, name); err != nil {
+			return nil, fmt.Errorf("failed to update person: %v", err)
+		}
+	}
+	a.logf("Updated directory entry for @%s\n", name)
+	return a.GetPerson(name)
+}
+
+// peopleDetails returns the directory entries for names, in the same order. Names not in
+// the directory get an entry with just the name.
+func (a *App) peopleDetails(names []string) []Person {
+	people := make([]Person, len(names))
+	for i, name := range names {
+		if person, err := a.GetPerson(name); err == nil {
+			people[i] = *person
+		} else {
+			people[i] = Person{Name: name}
+		}
+	}
+	return people
+}
+
+const personColumns = `p.id, p.name, p.role, p.team, p.email,
+	(SELECT COUNT(*) FROM log_entries_people lp WHERE lp.person_id = p.id)`
+
+func scanPerson(row rowScanner) (Person, error) {
+	var p Person
+	err := row.Scan(&p.ID, &p.Name, &p.Role, &p.Team, &p.Email, &p.Mentions)
+	if err != nil && err != sql.ErrNoRows {
+		return p, fmt.Errorf("failed to scan person: %v", err)
+	}
+	return p, err
+}
+
+// handlePeopleAPI serves the people directory: GET /api/people, GET /api/people/{name}
+// and PATCH (or PUT) /api/people/{name} with {"role", "team", "email"}. The 1:1 report is
+// at /api/people/{name}/report.
+func (a *App) handlePeopleAPI(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/people"), "/")
+	if person, ok := strings.CutSuffix(name, "/report"); ok {
+		a.handlePersonReportAPI(w, r, person)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		people, err := a.GetPeopleDirectory()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, people)
+	case r.Method == http.MethodGet:
+		person, err := a.GetPerson(name)
+		if err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, person)
+	case (r.Method == http.MethodPatch || r.Method == http.MethodPut) && name != "":
+		var update PersonUpdate
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		person, err := a.UpdatePerson(name, update)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, person)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// personReportDays is how far back a 1:1 report looks when no date is given
+const personReportDays = 7
+
+// PersonReport gathers what to bring to a 1:1: the entries mentioning someone since a
+// date, the decisions among them, and every open task from an entry that mentions them
+type PersonReport struct {
+	Person    Person     `json:"person"`
+	Since     time.Time  `json:"since"`
+	Entries   []LogEntry `json:"entries"`
+	Decisions []LogEntry `json:"decisions"`
+	OpenTasks []Task     `json:"open_tasks"`
+}
+
+// PersonReportPageData holds everything rendered on /dash/person/{name}
+type PersonReportPageData struct {
+	Report    *PersonReport
+	Since     string
+	Entries   []DisplayEntry
+	Decisions []DisplayEntry
+	Ranges    []PersonReportRange
+}
+
+// PersonReportRange is one of the "since" shortcuts on the report page
+type PersonReportRange struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// GetPersonReport returns the 1:1 report for name covering entries since the given time.
+// Decisions are listed apart from the other entries; open tasks are included however old
+// they are, since they carry over from one 1:1 to the next.
+func (a *App) GetPersonReport(name string, since time.Time) (*PersonReport, error) {
+	person, err := a.GetPerson(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.queryEntriesWhere(context.Background(), `created_at >= ?
+		AND id IN (SELECT log_entry_id FROM log_entries_people WHERE person_id = ?)
+		ORDER BY created_at DESC, id DESC`, sqlTime(since), person.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PersonReport{Person: *person, Since: since, Entries: []LogEntry{}, Decisions: []LogEntry{}}
+	for _, entry := range entries {
+		if entry.EntryType == entryTypeDecision {
+			report.Decisions = append(report.Decisions, entry)
+		} else {
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	report.OpenTasks, err = a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE done = 0
+		AND log_entry_id IN (SELECT log_entry_id FROM log_entries_people WHERE person_id = ?)
+		ORDER BY created_at, log_entry_id, position`, person.ID)
+	if err != nil {
+		return nil, err
+	}
+	if report.OpenTasks == nil {
+		report.OpenTasks = []Task{}
+	}
+	return report, nil
+}
+
+// personReportSince reads ?since=2006-01-02 or a natural date such as "last monday", defaulting to personReportDays ago
+func (a *App) personReportSince(r *http.Request) (time.Time, error) {
+	value := r.URL.Query().Get("since")
+	if value == "" {
+		return a.dayStart(a.today().AddDate(0, 0, -personReportDays)), nil
+	}
+	day, ok := parseFilterDay(value, a.today())
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid since date: %s", value)
+	}
+	return a.dayStart(day), nil
+}
+
+// personReportMarkdown writes the report as notes to paste into a 1:1 doc
+func (a *App) personReportMarkdown(report *PersonReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 1:1 with @%s\n\n", report.Person.Name)
+	if details := report.Person.Context(); details != "" {
+		fmt.Fprintf(&b, "%s\n\n", details)
+	}
+	fmt.Fprintf(&b, "Since %s\n", a.dayKey(report.Since))
+
+	writeEntries := func(title string, entries []LogEntry) {
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		if len(entries) == 0 {
+			b.WriteString("None.\n")
+			return
+		}
+		for _, entry := range entries {
+			lines := strings.Split(strings.TrimSpace(entry.Content), "\n")
+			fmt.Fprintf(&b, "- %s %s: %s\n", a.dayKey(entry.CreatedAt), entry.CreatedAt.Local().Format("15:04"), lines[0])
+			for _, line := range lines[1:] {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Open tasks\n\n")
+	if len(report.OpenTasks) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString(formatTaskList(report.OpenTasks) + "\n")
+	}
+	writeEntries("Decisions", report.Decisions)
+	writeEntries("Notes", report.Entries)
+	return b.String()
+}
+
+// writePersonReportMarkdown downloads the report as a markdown file
+func (a *App) writePersonReportMarkdown(w http.ResponseWriter, report *PersonReport) {
+	filename := fmt.Sprintf("1on1-%s-%s.md", report.Person.Name, a.dayKey(a.clock.Now()))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	fmt.Fprint(w, a.personReportMarkdown(report))
+}
+
+// handlePersonReportAPI serves GET /api/people/{name}/report?since=2006-01-02 as JSON,
+// or as markdown with format=md
+func (a *App) handlePersonReportAPI(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	since, err := a.personReportSince(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	report, err := a.GetPersonReport(name, since)
+	if err != nil {
+		writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == "md" {
+		a.writePersonReportMarkdown(w, report)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (a *App) servePersonReportPage(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(strings.Trim(strings.TrimPrefix(r.URL.Path, "/dash/person"), "/"), "@")
+	since, err := a.personReportSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := a.GetPersonReport(name, since)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Person not found: %s", name), http.StatusNotFound)
+		return
+	}
+
+	data := PersonReportPageData{Report: report, Since: a.dayKey(since)}
+	for _, entry := range report.Entries {
+		data.Entries = append(data.Entries, a.toDisplayEntry(entry))
+	}
+	for _, entry := range report.Decisions {
+		data.Decisions = append(data.Decisions, a.toDisplayEntry(entry))
+	}
+	for _, weeks := range []int{1, 2, 4} {
+		day := a.dayKey(a.dayStart(a.today().AddDate(0, 0, -7*weeks)))
+		label := fmt.Sprintf("%d weeks", weeks)
+		if weeks == 1 {
+			label = "1 week"
+		}
+		data.Ranges = append(data.Ranges, PersonReportRange{
+			Label:  label,
+			URL:    "/dash/person/" + url.PathEscape(report.Person.Name) + "?since=" + day,
+			Active: day == data.Since,
+		})
+	}
+
+	a.renderPage(w, "person.html", "1:1 with @"+report.Person.Name, data)
+}
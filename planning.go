@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxPlanTasks     = 30
+	goalLookbackDays = 28
+	weekTemplateName = "week"
+	goalTag          = "goal"
+	doneTag          = "done"
+)
+
+// WeekPlan is a draft plan entry for the week starting on Monday
+type WeekPlan struct {
+	WeekOf string `json:"week_of"`
+	Draft  string `json:"draft"`
+}
+
+// weekStart returns local midnight on the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// planWeekStart is the Monday being planned: this week's, or next week's from Saturday on
+func planWeekStart(now time.Time) time.Time {
+	monday := weekStart(now)
+	if day := now.Weekday(); day == time.Saturday || day == time.Sunday {
+		return monday.AddDate(0, 0, 7)
+	}
+	return monday
+}
+
+// GetWeekPlanDraft assembles last week's open tasks, unfinished #goal entries and the
+// items of the "week" template into a plan entry for the user to edit before saving
+func (a *App) GetWeekPlanDraft() (*WeekPlan, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	monday := planWeekStart(a.clock.Now())
+
+	tasks, err := a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE done = 0 AND created_at < ?
+		ORDER BY created_at, log_entry_id, position
+		LIMIT ?`, sqlTime(monday), maxPlanTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := a.unfinishedGoals(monday.AddDate(0, 0, -goalLookbackDays), monday)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Week plan %s #plan\n", monday.Format("2006-01-02"))
+	if len(tasks) > 0 {
+		b.WriteString("\nCarried over:\n" + formatTaskList(tasks) + "\n")
+	}
+	if len(goals) > 0 {
+		b.WriteString("\nGoals:\n")
+		for _, goal := range goals {
+			fmt.Fprintf(&b, "- %s [[%d]]\n", entryTitle(tagPattern.ReplaceAllString(goal.Content, "")), goal.ID)
+		}
+	}
+	if text, ok := a.templateText(weekTemplateName); ok {
+		b.WriteString("\n" + strings.TrimSpace(a.expandPlaceholders(text)) + "\n")
+	}
+
+	return &WeekPlan{WeekOf: monday.Format("2006-01-02"), Draft: strings.TrimSpace(b.String())}, nil
+}
+
+// unfinishedGoals returns entries tagged #goal in [from, to) that are not tagged #done
+// and still have an open task, or have no tasks at all
+func (a *App) unfinishedGoals(from, to time.Time) ([]LogEntry, error) {
+	query := `SELECT ` + entryColumns + ` FROM log_entries e
+		WHERE created_at >= ? AND created_at < ? AND deleted_at IS NULL
+		AND id IN (SELECT lt.log_entry_id FROM log_entries_tags lt JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)
+		AND id NOT IN (SELECT lt.log_entry_id FROM log_entries_tags lt JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)
+		AND (NOT EXISTS (SELECT 1 FROM tasks WHERE log_entry_id = e.id)
+			OR EXISTS (SELECT 1 FROM tasks WHERE log_entry_id = e.id AND done = 0))
+		ORDER BY created_at`
+	rows, err := a.db.Query(query, sqlTime(from), sqlTime(to), goalTag, doneTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %v", err)
+	}
+	defer rows.Close()
+
+	var goals []LogEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %v", err)
+		}
+		goals = append(goals, entry)
+	}
+	return goals, nil
+}
+
+// handleWeekPlanAPI returns the draft week plan: GET /api/plan/week
+func (a *App) handleWeekPlanAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	plan, err := a.GetWeekPlanDraft()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
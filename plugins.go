@@ -0,0 +1,601 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Plugins are external processes that speak newline-delimited JSON over stdin/stdout.
+// The host sends {"id", "method", "params"} requests and the plugin answers with
+// {"id", "result"} or {"id", "error"}. A plugin may also send notifications (no id),
+// e.g. {"method": "capture", "params": {"text": "..."}} from a capture source.
+
+const (
+	pluginProtocolVersion = 1
+	pluginManifestName    = "plugin.json"
+	pluginCallTimeout     = 10 * time.Second
+	pluginRenderTimeout   = 2 * time.Second
+)
+
+// Plugin capabilities
+const (
+	capabilityCapture  = "capture"
+	capabilityExporter = "exporter"
+	capabilityRenderer = "renderer"
+	capabilityCommand  = "command"
+)
+
+// PluginManifest is read from plugins/<name>/plugin.json
+type PluginManifest struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Version      string   `json:"version"`
+	Command      string   `json:"command"`
+	Args         []string `json:"args"`
+	Capabilities []string `json:"capabilities"`
+	// Commands are slash commands handled by the plugin, e.g. "/jira"
+	Commands []string `json:"commands"`
+	// Formats are export formats provided by an exporter, e.g. "csv"
+	Formats []string `json:"formats"`
+	// Languages are fenced code block languages rendered by a renderer, e.g. "mermaid"
+	Languages []string `json:"languages"`
+}
+
+// PluginInfo describes an installed plugin for the settings UI
+type PluginInfo struct {
+	Manifest PluginManifest `json:"manifest"`
+	Dir      string         `json:"dir"`
+	Enabled  bool           `json:"enabled"`
+	Running  bool           `json:"running"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type pluginMessage struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params interface{}     `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type pluginProcess struct {
+	manifest PluginManifest
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan pluginMessage
+	done    chan struct{}
+}
+
+type pluginHost struct {
+	mu     sync.Mutex
+	procs  map[string]*pluginProcess
+	errors map[string]string
+}
+
+func pluginsDir() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
+func (m PluginManifest) has(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverPlugins reads every manifest in the plugins directory
+func discoverPlugins() ([]PluginInfo, error) {
+	root, err := pluginsDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %v", err)
+	}
+
+	var plugins []PluginInfo
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, d.Name())
+		info := PluginInfo{Dir: dir}
+		data, err := os.ReadFile(filepath.Join(dir, pluginManifestName))
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(data, &info.Manifest); err != nil {
+			info.Manifest.Name = d.Name()
+			info.Error = fmt.Sprintf("invalid manifest: %v", err)
+		}
+		if info.Manifest.Name == "" {
+			info.Manifest.Name = d.Name()
+		}
+		plugins = append(plugins, info)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Manifest.Name < plugins[j].Manifest.Name
+	})
+	return plugins, nil
+}
+
+func (a *App) isPluginEnabled(name string) bool {
+	for _, enabled := range a.settings.EnabledPlugins {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPlugins returns the installed plugins and whether they are enabled and running
+func (a *App) ListPlugins() ([]PluginInfo, error) {
+	plugins, err := discoverPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	for i := range plugins {
+		plugins[i].Enabled = a.isPluginEnabled(plugins[i].Manifest.Name)
+		_, plugins[i].Running = a.plugins.procs[plugins[i].Manifest.Name]
+		if msg, ok := a.plugins.errors[plugins[i].Manifest.Name]; ok && plugins[i].Error == "" {
+			plugins[i].Error = msg
+		}
+	}
+	return plugins, nil
+}
+
+// EnablePlugin starts a plugin and remembers it for the next launch
+func (a *App) EnablePlugin(name string) error {
+	plugins, err := discoverPlugins()
+	if err != nil {
+		return err
+	}
+	for _, info := range plugins {
+		if info.Manifest.Name != name {
+			continue
+		}
+		if info.Error != "" {
+			return fmt.Errorf("plugin %s cannot be enabled: %s", name, info.Error)
+		}
+		if err := a.startPlugin(info); err != nil {
+			return err
+		}
+		if !a.isPluginEnabled(name) {
+			a.settings.EnabledPlugins = append(a.settings.EnabledPlugins, name)
+		}
+		return a.saveSettings()
+	}
+	return fmt.Errorf("plugin not found: %s", name)
+}
+
+// DisablePlugin stops a plugin and keeps it from starting on the next launch
+func (a *App) DisablePlugin(name string) error {
+	a.stopPlugin(name)
+
+	enabled := a.settings.EnabledPlugins[:0]
+	for _, n := range a.settings.EnabledPlugins {
+		if n != name {
+			enabled = append(enabled, n)
+		}
+	}
+	a.settings.EnabledPlugins = enabled
+	return a.saveSettings()
+}
+
+// startPlugins launches every enabled plugin
+func (a *App) startPlugins() {
+	plugins, err := discoverPlugins()
+	if err != nil {
+		a.logf("Failed to discover plugins: %v\n", err)
+		return
+	}
+	for _, info := range plugins {
+		if !a.isPluginEnabled(info.Manifest.Name) || info.Error != "" {
+			continue
+		}
+		if err := a.startPlugin(info); err != nil {
+			a.logf("Failed to start plugin %s: %v\n", info.Manifest.Name, err)
+		}
+	}
+}
+
+func (a *App) startPlugin(info PluginInfo) error {
+	a.plugins.mu.Lock()
+	if a.plugins.procs == nil {
+		a.plugins.procs = make(map[string]*pluginProcess)
+		a.plugins.errors = make(map[string]string)
+	}
+	if _, running := a.plugins.procs[info.Manifest.Name]; running {
+		a.plugins.mu.Unlock()
+		return nil
+	}
+	a.plugins.mu.Unlock()
+
+	command := info.Manifest.Command
+	if command == "" {
+		return fmt.Errorf("plugin %s has no command", info.Manifest.Name)
+	}
+	if !filepath.IsAbs(command) && strings.ContainsAny(command, `/\`) {
+		command = filepath.Join(info.Dir, command)
+	}
+
+	cmd := exec.Command(command, info.Manifest.Args...)
+	cmd.Dir = info.Dir
+	cmd.Env = append(os.Environ(), fmt.Sprintf("SNAPLOG_PLUGIN_PROTOCOL=%d", pluginProtocolVersion))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdin: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdout: %v", err)
+	}
+	cmd.Stderr = &pluginLogWriter{app: a, name: info.Manifest.Name}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %v", info.Manifest.Name, err)
+	}
+
+	proc := &pluginProcess{
+		manifest: info.Manifest,
+		cmd:      cmd,
+		stdin:    stdin,
+		pending:  make(map[int64]chan pluginMessage),
+		done:     make(chan struct{}),
+	}
+	go a.readPlugin(proc, stdout)
+
+	if _, err := proc.call("initialize", map[string]interface{}{"protocol_version": pluginProtocolVersion}, pluginCallTimeout); err != nil {
+		proc.kill()
+		a.setPluginError(info.Manifest.Name, err.Error())
+		return fmt.Errorf("plugin %s failed to initialize: %v", info.Manifest.Name, err)
+	}
+
+	a.plugins.mu.Lock()
+	a.plugins.procs[info.Manifest.Name] = proc
+	delete(a.plugins.errors, info.Manifest.Name)
+	a.plugins.mu.Unlock()
+
+	a.logf("Plugin %s started\n", info.Manifest.Name)
+	return nil
+}
+
+func (a *App) setPluginError(name, msg string) {
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	if a.plugins.errors != nil {
+		a.plugins.errors[name] = msg
+	}
+}
+
+func (a *App) stopPlugin(name string) {
+	a.plugins.mu.Lock()
+	proc, ok := a.plugins.procs[name]
+	delete(a.plugins.procs, name)
+	a.plugins.mu.Unlock()
+
+	if ok {
+		proc.kill()
+		a.logf("Plugin %s stopped\n", name)
+	}
+}
+
+func (a *App) stopPlugins() {
+	a.plugins.mu.Lock()
+	names := make([]string, 0, len(a.plugins.procs))
+	for name := range a.plugins.procs {
+		names = append(names, name)
+	}
+	a.plugins.mu.Unlock()
+
+	for _, name := range names {
+		a.stopPlugin(name)
+	}
+}
+
+// readPlugin dispatches responses and notifications until the plugin exits
+func (a *App) readPlugin(proc *pluginProcess, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxExportPayload)
+	for scanner.Scan() {
+		var msg pluginMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			a.logf("Plugin %s sent invalid message: %v\n", proc.manifest.Name, err)
+			continue
+		}
+		if msg.ID != 0 && msg.Method == "" {
+			proc.mu.Lock()
+			ch, ok := proc.pending[msg.ID]
+			delete(proc.pending, msg.ID)
+			proc.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+			continue
+		}
+		a.handlePluginNotification(proc, msg)
+	}
+
+	close(proc.done)
+	proc.cmd.Wait()
+
+	a.plugins.mu.Lock()
+	if a.plugins.procs[proc.manifest.Name] == proc {
+		delete(a.plugins.procs, proc.manifest.Name)
+		a.plugins.errors[proc.manifest.Name] = "plugin exited"
+		a.logf("Plugin %s exited\n", proc.manifest.Name)
+	}
+	a.plugins.mu.Unlock()
+}
+
+func (a *App) handlePluginNotification(proc *pluginProcess, msg pluginMessage) {
+	switch msg.Method {
+	case "capture":
+		if !proc.manifest.has(capabilityCapture) {
+			return
+		}
+		var params struct {
+			Text string `json:"text"`
+		}
+		raw, _ := json.Marshal(msg.Params)
+		if err := json.Unmarshal(raw, &params); err != nil || strings.TrimSpace(params.Text) == "" {
+			return
+		}
+		if _, _, err := a.logPluginEntry(proc.manifest.Name, params.Text); err != nil {
+			a.logf("Error logging entry from plugin %s: %v\n", proc.manifest.Name, err)
+		}
+	case "log":
+		a.logf("Plugin %s: %v\n", proc.manifest.Name, msg.Params)
+	}
+}
+
+// logPluginEntry coalesces captures like other automated sources but attributes them to the plugin source
+func (a *App) logPluginEntry(name, text string) (int64, bool, error) {
+	return a.logCoalescedEntry("plugin:"+name, text, sourcePlugin)
+}
+
+func (p *pluginProcess) call(method string, params interface{}, timeout time.Duration) (json.RawMessage, error) {
+	p.mu.Lock()
+	p.nextID++
+	id := p.nextID
+	ch := make(chan pluginMessage, 1)
+	p.pending[id] = ch
+	data, err := json.Marshal(pluginMessage{ID: id, Method: method, Params: params})
+	if err == nil {
+		_, err = p.stdin.Write(append(data, '\n'))
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		p.forget(id)
+		return nil, fmt.Errorf("failed to send %s: %v", method, err)
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != "" {
+			return nil, fmt.Errorf("%s", msg.Error)
+		}
+		return msg.Result, nil
+	case <-p.done:
+		p.forget(id)
+		return nil, fmt.Errorf("plugin exited")
+	case <-time.After(timeout):
+		p.forget(id)
+		return nil, fmt.Errorf("%s timed out after %s", method, timeout)
+	}
+}
+
+func (p *pluginProcess) forget(id int64) {
+	p.mu.Lock()
+	delete(p.pending, id)
+	p.mu.Unlock()
+}
+
+func (p *pluginProcess) kill() {
+	p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(2 * time.Second):
+		p.cmd.Process.Kill()
+	}
+}
+
+// pluginLogWriter forwards plugin stderr to the SnapLog log file
+type pluginLogWriter struct {
+	app  *App
+	name string
+}
+
+func (w *pluginLogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.app.logf("Plugin %s: %s\n", w.name, line)
+	}
+	return len(p), nil
+}
+
+// findPlugin returns the running plugin with the capability that claims value via claims
+func (a *App) findPlugin(capability string, claims func(PluginManifest) []string, value string) *pluginProcess {
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	for _, proc := range a.plugins.procs {
+		if !proc.manifest.has(capability) {
+			continue
+		}
+		for _, claimed := range claims(proc.manifest) {
+			if strings.EqualFold(claimed, value) {
+				return proc
+			}
+		}
+	}
+	return nil
+}
+
+// pluginCommands lists the slash commands provided by running plugins
+func (a *App) pluginCommands() []string {
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	var commands []string
+	for _, proc := range a.plugins.procs {
+		if proc.manifest.has(capabilityCommand) {
+			commands = append(commands, proc.manifest.Commands...)
+		}
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// runPluginCommand passes a slash command to the plugin that registered it.
+// The plugin may ask to log an entry, prefill the input, or both.
+func (a *App) runPluginCommand(command string) (bool, error) {
+	name, args, _ := strings.Cut(command, " ")
+	proc := a.findPlugin(capabilityCommand, func(m PluginManifest) []string { return m.Commands }, name)
+	if proc == nil {
+		return false, nil
+	}
+
+	raw, err := proc.call("command", map[string]string{"command": name, "args": strings.TrimSpace(args)}, pluginCallTimeout)
+	if err != nil {
+		return true, fmt.Errorf("plugin %s failed: %v", proc.manifest.Name, err)
+	}
+	var result struct {
+		Log     string `json:"log"`
+		Prefill string `json:"prefill"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return true, fmt.Errorf("plugin %s returned an invalid result: %v", proc.manifest.Name, err)
+	}
+	if result.Log != "" {
+		if _, err := a.logEntry(result.Log, sourcePlugin); err != nil {
+			return true, err
+		}
+	}
+	if result.Prefill != "" {
+		return true, a.requestPrefill(result.Prefill)
+	}
+	return true, nil
+}
+
+// exportWithPlugin renders entries in a plugin-provided format
+func (a *App) exportWithPlugin(format string, entries []LogEntry) (content, filename string, err error) {
+	proc := a.findPlugin(capabilityExporter, func(m PluginManifest) []string { return m.Formats }, format)
+	if proc == nil {
+		return "", "", fmt.Errorf("no plugin exports %s", format)
+	}
+
+	raw, err := proc.call("export", map[string]interface{}{"format": format, "entries": entries}, pluginCallTimeout)
+	if err != nil {
+		return "", "", fmt.Errorf("plugin %s failed: %v", proc.manifest.Name, err)
+	}
+	var result struct {
+		Content  string `json:"content"`
+		Filename string `json:"filename"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", "", fmt.Errorf("plugin %s returned an invalid result: %v", proc.manifest.Name, err)
+	}
+	if result.Filename == "" {
+		result.Filename = fmt.Sprintf("snaplog-export-%s.%s", a.clock.Now().Format("2006-01-02"), format)
+	}
+	return result.Content, result.Filename, nil
+}
+
+// renderWithPlugin renders a fenced code block through the renderer that claims its language
+func (a *App) renderWithPlugin(language, source string) (string, bool) {
+	proc := a.findPlugin(capabilityRenderer, func(m PluginManifest) []string { return m.Languages }, language)
+	if proc == nil {
+		return "", false
+	}
+
+	raw, err := proc.call("render", map[string]string{"language": language, "source": source}, pluginRenderTimeout)
+	if err != nil {
+		a.logf("Plugin %s failed to render %s: %v\n", proc.manifest.Name, language, err)
+		return "", false
+	}
+	var result struct {
+		HTML string `json:"html"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || result.HTML == "" {
+		return "", false
+	}
+	return result.HTML, true
+}
+
+var fencedBlockPattern = regexp.MustCompile("(?ms)^```([A-Za-z0-9_+-]+)[ \t]*\n(.*?)\n```[ \t]*$")
+
+// renderPluginBlocks swaps fenced code blocks claimed by renderer plugins for
+// placeholders, so the plugin HTML can be spliced in after markdown rendering.
+func (a *App) renderPluginBlocks(markdown string) (string, []string) {
+	if !strings.Contains(markdown, "```") || !a.hasPluginCapability(capabilityRenderer) {
+		return markdown, nil
+	}
+
+	var rendered []string
+	markdown = fencedBlockPattern.ReplaceAllStringFunc(markdown, func(block string) string {
+		match := fencedBlockPattern.FindStringSubmatch(block)
+		html, ok := a.renderWithPlugin(match[1], match[2])
+		if !ok {
+			return block
+		}
+		rendered = append(rendered, html)
+		return fmt.Sprintf("\n\nSNAPLOGPLUGINBLOCK%d\n\n", len(rendered)-1)
+	})
+	return markdown, rendered
+}
+
+func replacePluginBlocks(html string, rendered []string) string {
+	for i, block := range rendered {
+		html = strings.Replace(html, fmt.Sprintf("<p>SNAPLOGPLUGINBLOCK%d</p>", i), block, 1)
+	}
+	return html
+}
+
+func (a *App) hasPluginCapability(capability string) bool {
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	for _, proc := range a.plugins.procs {
+		if proc.manifest.has(capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginExportFormats lists the export formats provided by running plugins
+func (a *App) pluginExportFormats() []string {
+	a.plugins.mu.Lock()
+	defer a.plugins.mu.Unlock()
+	var formats []string
+	for _, proc := range a.plugins.procs {
+		if proc.manifest.has(capabilityExporter) {
+			formats = append(formats, proc.manifest.Formats...)
+		}
+	}
+	sort.Strings(formats)
+	return formats
+}
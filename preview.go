@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+)
+
+// previewMaxBytes caps how much of the text the live preview renders, so a pasted wall of
+// text can't stall every keystroke. The entry itself is saved in full.
+const previewMaxBytes = 32 << 10
+
+// previewRenderer is reused across calls. Its goldmark defaults omit raw HTML and drop
+// javascript: and similar links, which is what keeps the preview sanitized.
+var previewRenderer = goldmark.New()
+
+// previewCache holds the last rendered text, since a debounced editor often asks for the
+// same text again, e.g. after a mode switch
+type previewCache struct {
+	mu   sync.Mutex
+	text string
+	html string
+}
+
+// RenderMarkdownPreview renders markdown for the long-form editor's preview pane. Unlike
+// RenderMarkdown it skips plugin blocks and renders at most previewMaxBytes.
+func (a *App) RenderMarkdownPreview(text string) (string, error) {
+	a.preview.mu.Lock()
+	if text == a.preview.text && a.preview.html != "" {
+		html := a.preview.html
+		a.preview.mu.Unlock()
+		return html, nil
+	}
+	a.preview.mu.Unlock()
+
+	source, truncated := text, false
+	if len(source) > previewMaxBytes {
+		cut := previewMaxBytes
+		for cut > 0 && !utf8.RuneStart(source[cut]) {
+			cut--
+		}
+		source, truncated = source[:cut], true
+	}
+
+	var buf bytes.Buffer
+	if err := previewRenderer.Convert([]byte(linkEntryRefs(source)), &buf); err != nil {
+		return "", fmt.Errorf("failed to render preview: %v", err)
+	}
+	if truncated {
+		fmt.Fprintf(&buf, `<p class="preview-truncated"><em>Preview shows the first %d KB</em></p>`, previewMaxBytes>>10)
+	}
+
+	html := buf.String()
+	a.preview.mu.Lock()
+	a.preview.text, a.preview.html = text, html
+	a.preview.mu.Unlock()
+	return html, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// projectPageEntries is how many of the newest entries the project page lists
+const projectPageEntries = 50
+
+// ProjectReport is everything logged for one project: its entries newest first, the
+// decisions among them, its tasks and when they should clear, who was involved and the
+// time spent on it
+type ProjectReport struct {
+	Project     Project       `json:"project"`
+	Entries     []LogEntry    `json:"entries"`
+	Decisions   []LogEntry    `json:"decisions"`
+	OpenTasks   []Task        `json:"open_tasks"`
+	DoneTasks   int           `json:"done_tasks"`
+	Forecast    *TaskForecast `json:"forecast"`
+	People      []Person      `json:"people"`
+	Minutes     float64       `json:"minutes"`
+	FirstEntry  *time.Time    `json:"first_entry,omitempty"`
+	LatestEntry *time.Time    `json:"latest_entry,omitempty"`
+}
+
+// ProjectPageData holds everything rendered on /dash/project/{name}
+type ProjectPageData struct {
+	Report     *ProjectReport
+	Hours      string
+	Milestones []MilestoneMark
+	Burndown   []HistogramBucket
+	Entries    []DisplayEntry
+	Decisions  []DisplayEntry
+	More       int
+}
+
+// MilestoneMark is one stop on a project's history strip
+type MilestoneMark struct {
+	ID    int
+	Title string
+	Date  string
+}
+
+// ProjectsPageData holds the project list rendered on /dash/project
+type ProjectsPageData struct {
+	Projects []Project
+}
+
+// GetProjectReport gathers the entries tagged with the project's tag. Time is counted
+// the way the weekly_time_by_tag view counts it, and People holds the mentions within
+// the project.
+func (a *App) GetProjectReport(name string) (*ProjectReport, error) {
+	project, err := a.GetProject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.queryEntriesWhere(context.Background(), taggedEntryCondition+` ORDER BY created_at DESC, id DESC`, project.Tag)
+	if err != nil {
+		return nil, err
+	}
+	report := &ProjectReport{Project: *project, Entries: []LogEntry{}, Decisions: []LogEntry{}, OpenTasks: []Task{}, People: []Person{}}
+	for _, entry := range entries {
+		report.Entries = append(report.Entries, entry)
+		if entry.EntryType == entryTypeDecision {
+			report.Decisions = append(report.Decisions, entry)
+		}
+	}
+	if len(entries) > 0 {
+		report.LatestEntry = &entries[0].CreatedAt
+		report.FirstEntry = &entries[len(entries)-1].CreatedAt
+	}
+
+	tasks, err := a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE log_entry_id IN (SELECT lt.log_entry_id FROM log_entries_tags lt
+			JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)
+		ORDER BY created_at, log_entry_id, position`, project.Tag)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.Done {
+			report.DoneTasks++
+		} else {
+			report.OpenTasks = append(report.OpenTasks, task)
+		}
+	}
+	if report.Forecast, err = a.GetTaskForecast(project.Tag); err != nil {
+		return nil, err
+	}
+
+	rows, err := a.db.Query(`SELECT p.name, p.role, p.team, COUNT(*) FROM log_entries_people lp
+		JOIN people p ON p.id = lp.person_id
+		WHERE lp.log_entry_id IN (SELECT lt.log_entry_id FROM log_entries_tags lt
+			JOIN tags t ON t.id = lt.tag_id WHERE t.name = ?)
+		GROUP BY p.id ORDER BY COUNT(*) DESC, p.name`, project.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project people: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var person Person
+		if err := rows.Scan(&person.Name, &person.Role, &person.Team, &person.Mentions); err != nil {
+			return nil, fmt.Errorf("failed to scan project person: %v", err)
+		}
+		report.People = append(report.People, person)
+	}
+
+	if err := a.db.QueryRow(`SELECT COALESCE(SUM(minutes), 0) FROM weekly_time_by_tag WHERE tag = ?`, project.Tag).Scan(&report.Minutes); err != nil {
+		return nil, fmt.Errorf("failed to query project time: %v", err)
+	}
+	return report, nil
+}
+
+// formatHours renders minutes as "12.5 h"
+func formatHours(minutes float64) string {
+	return fmt.Sprintf("%.1f h", minutes/60)
+}
+
+// projectReportMarkdown writes the report as a status update to send to stakeholders
+func (a *App) projectReportMarkdown(report *ProjectReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", report.Project.Name)
+	fmt.Fprintf(&b, "Entries: %d · Time: %s · Open tasks: %d · Done: %d", len(report.Entries), formatHours(report.Minutes), len(report.OpenTasks), report.DoneTasks)
+	if report.FirstEntry != nil {
+		fmt.Fprintf(&b, " · %s to %s", a.dayKey(*report.FirstEntry), a.dayKey(*report.LatestEntry))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("\n## Decisions\n\n")
+	if len(report.Decisions) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, entry := range report.Decisions {
+		fmt.Fprintf(&b, "- %s: %s\n", a.dayKey(entry.CreatedAt), strings.ReplaceAll(strings.TrimSpace(entry.Content), "\n", "\n  "))
+	}
+
+	b.WriteString("\n## Open tasks\n\n")
+	if len(report.OpenTasks) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString(formatTaskList(report.OpenTasks) + "\n")
+		fmt.Fprintf(&b, "\nThroughput: %s\n", report.Forecast.Summary())
+	}
+
+	if len(report.People) > 0 {
+		b.WriteString("\n## People (mentions)\n\n")
+		for _, person := range report.People {
+			fmt.Fprintf(&b, "- @%s", person.Name)
+			if details := person.Context(); details != "" {
+				fmt.Fprintf(&b, " (%s)", details)
+			}
+			fmt.Fprintf(&b, " · %d\n", person.Mentions)
+		}
+	}
+	return b.String()
+}
+
+// handleProjectReportAPI serves GET /api/projects/{name}/report as JSON, or as markdown
+// with format=md
+func (a *App) handleProjectReportAPI(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	report, err := a.GetProjectReport(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == "md" {
+		filename := fmt.Sprintf("%s-%s.md", report.Project.Tag, a.dayKey(a.clock.Now()))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprint(w, a.projectReportMarkdown(report))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// serveProjectPage renders /dash/project/{name}, or the list of projects at /dash/project
+func (a *App) serveProjectPage(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/dash/project"), "/")
+	if name == "" {
+		projects, err := a.GetProjects()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load projects: %v", err), http.StatusInternalServerError)
+			return
+		}
+		a.renderPage(w, "projects.html", "Projects", ProjectsPageData{Projects: projects})
+		return
+	}
+
+	report, err := a.GetProjectReport(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Project not found: %s", name), http.StatusNotFound)
+		return
+	}
+
+	milestones, err := a.GetMilestones(report.Project.Name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load milestones: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	burndown, err := a.GetTaskBurndown(report.Project.Tag, burndownDays)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load task burndown: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data := ProjectPageData{Report: report, Hours: formatHours(report.Minutes), Burndown: burndownBars(burndown)}
+	for _, milestone := range milestones {
+		data.Milestones = append(data.Milestones, MilestoneMark{ID: milestone.ID, Title: milestoneTitle(milestone.Content), Date: a.dayKey(milestone.CreatedAt)})
+	}
+	for i, entry := range report.Entries {
+		if i == projectPageEntries {
+			data.More = len(report.Entries) - projectPageEntries
+			break
+		}
+		data.Entries = append(data.Entries, a.toDisplayEntry(entry))
+	}
+	for _, entry := range report.Decisions {
+		data.Decisions = append(data.Decisions, a.toDisplayEntry(entry))
+	}
+
+	a.renderPage(w, "project.html", report.Project.Name, data)
+}
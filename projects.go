@@ -0,0 +1,246 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxProjectNameLength = 100
+
+// maxRoundingMinutes caps the increment timesheet hours are rounded up to
+const maxRoundingMinutes = 240
+
+// Project groups the entries filed under a tag, e.g. "Website Redesign" → #redesign.
+// Client, HourlyRate and RoundingMinutes are set with SetProjectBilling for timesheets.
+type Project struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Tag             string    `json:"tag"`
+	Client          string    `json:"client"`
+	HourlyRate      float64   `json:"hourly_rate"`
+	RoundingMinutes int       `json:"rounding_minutes"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// projectColumns are the columns scanProject reads, in order
+const projectColumns = `id, name, tag, client, hourly_rate, rounding_minutes, created_at`
+
+func scanProject(row interface{ Scan(...interface{}) error }, project *Project) error {
+	return row.Scan(&project.ID, &project.Name, &project.Tag, &project.Client, &project.HourlyRate, &project.RoundingMinutes, &project.CreatedAt)
+}
+
+func (a *App) createProjectTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS projects (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		tag TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create projects table: %v", err)
+	}
+	for column, definition := range map[string]string{
+		"client":           "TEXT NOT NULL DEFAULT ''",
+		"hourly_rate":      "REAL NOT NULL DEFAULT 0",
+		"rounding_minutes": "INTEGER NOT NULL DEFAULT 0",
+	} {
+		if _, err := a.addColumnIfMissing("projects", column, definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProjects returns every project ordered by name
+func (a *App) GetProjects() ([]Project, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT ` + projectColumns + ` FROM projects ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %v", err)
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var project Project
+		if err := scanProject(rows, &project); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %v", err)
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// GetProject looks up a project by name, ignoring case
+func (a *App) GetProject(name string) (*Project, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	var project Project
+	err := scanProject(a.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE name = ?`, strings.TrimSpace(name)), &project)
+	if err == sql.ErrNoRows {
+		return nil, newAPIError(codeNotFound, fmt.Sprintf("project not found: %s", name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project: %v", err)
+	}
+	return &project, nil
+}
+
+// SetProject creates a project or changes its tag. The tag defaults to the name when
+// the name is a valid tag.
+func (a *App) SetProject(name, tag string) (*Project, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	name = strings.TrimSpace(name)
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	if name == "" || len(name) > maxProjectNameLength || strings.Contains(name, "/") {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("project names need 1 to %d characters and no /", maxProjectNameLength))
+	}
+	if tag == "" {
+		tag = name
+	}
+	if !tagNamePattern.MatchString(tag) {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid tag %q: give the project a tag of letters, digits, _ and -", tag))
+	}
+	tag = canonicalTag(tag)
+
+	_, err := a.db.Exec(`INSERT INTO projects (name, tag) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET tag = excluded.tag`, name, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save project: %v", err)
+	}
+	a.logf("Project %s files entries tagged #%s\n", name, tag)
+	return a.GetProject(name)
+}
+
+// SetProjectBilling sets who a project's hours are billed to, the hourly rate and the
+// increment each day's hours are rounded up to on timesheets (0 for exact minutes)
+func (a *App) SetProjectBilling(name, client string, hourlyRate float64, roundingMinutes int) (*Project, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if hourlyRate < 0 {
+		return nil, newAPIError(codeInvalidRequest, "the hourly rate cannot be negative")
+	}
+	if roundingMinutes < 0 || roundingMinutes > maxRoundingMinutes {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("rounding must be 0 to %d minutes", maxRoundingMinutes))
+	}
+
+	result, err := a.db.Exec(`UPDATE projects SET client = ?, hourly_rate = ?, rounding_minutes = ? WHERE name = ?`,
+		strings.TrimSpace(client), hourlyRate, roundingMinutes, strings.TrimSpace(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save project billing: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, newAPIError(codeNotFound, fmt.Sprintf("project not found: %s", name))
+	}
+	return a.GetProject(name)
+}
+
+// RemoveProject deletes a project; its entries and tag are kept
+func (a *App) RemoveProject(name string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	result, err := a.db.Exec(`DELETE FROM projects WHERE name = ?`, strings.TrimSpace(name))
+	if err != nil {
+		return fmt.Errorf("failed to remove project: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return newAPIError(codeNotFound, fmt.Sprintf("project not found: %s", name))
+	}
+	return nil
+}
+
+// parseProjectArgs splits `/project Website Redesign #redesign` into name and tag
+func parseProjectArgs(args string) (string, string) {
+	words := strings.Fields(args)
+	if len(words) > 1 && strings.HasPrefix(words[len(words)-1], "#") {
+		return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+	}
+	return strings.Join(words, " "), ""
+}
+
+// handleProjectsAPI serves GET /api/projects, POST /api/projects with {"name", "tag"},
+// DELETE /api/projects/{name}, the project report at /api/projects/{name}/report, its
+// milestones at /api/projects/{name}/milestones and PUT /api/projects/{name}/billing
+// with {"client", "hourly_rate", "rounding_minutes"}
+func (a *App) handleProjectsAPI(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/projects"), "/")
+	if project, ok := strings.CutSuffix(name, "/report"); ok {
+		a.handleProjectReportAPI(w, r, project)
+		return
+	}
+	if project, ok := strings.CutSuffix(name, "/billing"); ok && r.Method == http.MethodPut {
+		var req Project
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		updated, err := a.SetProjectBilling(project, req.Client, req.HourlyRate, req.RoundingMinutes)
+		if err != nil {
+			status := http.StatusBadRequest
+			if asAPIError(err).Code == codeNotFound {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, asAPIError(err).Code, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+		return
+	}
+	if project, ok := strings.CutSuffix(name, "/milestones"); ok && r.Method == http.MethodGet {
+		milestones, err := a.GetMilestones(project)
+		if err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, milestones)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		projects, err := a.GetProjects()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, projects)
+	case r.Method == http.MethodPost && name == "":
+		var req Project
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		project, err := a.SetProject(req.Name, req.Tag)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, project)
+	case r.Method == http.MethodDelete && name != "":
+		if err := a.RemoveProject(name); err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Quote styles for quote_style; empty leaves quotes as typed or pasted
+const (
+	quoteStyleStraight = "straight"
+	quoteStyleSmart    = "smart"
+)
+
+// codeSpanPattern matches fenced code blocks and inline code, which are saved as typed
+var codeSpanPattern = regexp.MustCompile("(?s)```.*?(?:```|$)|`[^`\n]*`")
+
+// Double hyphens become em dashes between words or between spaces, but not in a --flag
+// or a --- rule
+var (
+	joinedHyphenPattern = regexp.MustCompile(`\b--\b`)
+	spacedHyphenPattern = regexp.MustCompile(`(\s)--(\s)`)
+)
+
+var straightQuotes = strings.NewReplacer("‘", "'", "’", "'", "‚", "'", "‛", "'", "“", `"`, "”", `"`, "„", `"`, "‟", `"`)
+
+func validateQuoteStyle(style string) error {
+	switch style {
+	case "", quoteStyleStraight, quoteStyleSmart:
+		return nil
+	default:
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("unknown quote style %q: use %s or %s", style, quoteStyleStraight, quoteStyleSmart))
+	}
+}
+
+// normalizePunctuation applies quote_style and expand_dashes to text being saved, so a
+// phrase pasted from different apps is stored, and found, the same way. Code is left
+// alone.
+func (a *App) normalizePunctuation(text string) string {
+	style, dashes := a.settings.QuoteStyle, a.settings.ExpandDashes
+	if style == "" && !dashes {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		b.WriteString(normalizeProse(text[last:span[0]], style, dashes))
+		b.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(normalizeProse(text[last:], style, dashes))
+	return b.String()
+}
+
+// normalizeProse rewrites the quotes and dashes of text outside code
+func normalizeProse(text, style string, dashes bool) string {
+	if dashes {
+		text = joinedHyphenPattern.ReplaceAllString(text, "—")
+		text = spacedHyphenPattern.ReplaceAllString(text, "$1—$2")
+	}
+	switch style {
+	case quoteStyleStraight:
+		return straightQuotes.Replace(text)
+	case quoteStyleSmart:
+		return smartQuotes(straightQuotes.Replace(text))
+	}
+	return text
+}
+
+// smartQuotes curls straight quotes: a quote opens at the start of the text or after a
+// space or opening bracket and closes everywhere else, which also turns the apostrophe
+// in "don't" into ’
+func smartQuotes(text string) string {
+	var b strings.Builder
+	previous := ' '
+	for _, r := range text {
+		opening := unicode.IsSpace(previous) || strings.ContainsRune("([{—–", previous)
+		switch {
+		case r == '"' && opening:
+			b.WriteRune('“')
+		case r == '"':
+			b.WriteRune('”')
+		case r == '\'' && opening:
+			b.WriteRune('‘')
+		case r == '\'':
+			b.WriteRune('’')
+		default:
+			b.WriteRune(r)
+		}
+		previous = r
+	}
+	return b.String()
+}
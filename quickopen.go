@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Quick open result kinds
+const (
+	quickKindCommand = "command"
+	quickKindEntry   = "entry"
+	quickKindTag     = "tag"
+	quickKindDate    = "date"
+)
+
+// Quick open actions tell the capture window what to do with a chosen result
+const (
+	quickActionRun    = "run"    // run Value as a command
+	quickActionInsert = "insert" // put Value in the input for the user to finish
+	quickActionOpen   = "open"   // open URL in the browser
+)
+
+const (
+	maxQuickOpenResults = 20
+	quickOpenEntryScan  = 300
+)
+
+// QuickOpenItem is one ranked result of QuickOpen
+type QuickOpenItem struct {
+	Kind     string  `json:"kind"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Action   string  `json:"action"`
+	Value    string  `json:"value"`
+	URL      string  `json:"url"`
+	Score    float64 `json:"score"`
+}
+
+// QuickOpen returns commands, recent entries, tags and dates matching query, best first.
+// An empty query lists the commands and the most recent entries.
+func (a *App) QuickOpen(query string) ([]QuickOpenItem, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var items []QuickOpenItem
+	add := func(item QuickOpenItem, query, text string, bonus float64) {
+		score, ok := fuzzyScore(query, text)
+		if !ok {
+			return
+		}
+		item.Score = score + bonus
+		items = append(items, item)
+	}
+
+	for _, cmd := range a.GetCommands() {
+		item := QuickOpenItem{Kind: quickKindCommand, Title: cmd.Usage, Subtitle: cmd.Description, Value: cmd.Name, Action: quickActionRun}
+		if cmd.Usage != cmd.Name {
+			item.Action = quickActionInsert
+			item.Value = cmd.Name + " "
+		}
+		add(item, strings.TrimPrefix(query, "/"), strings.TrimPrefix(cmd.Name, "/")+" "+cmd.Description, 5)
+	}
+
+	entries, err := a.GetLogEntries(quickOpenEntryScan)
+	if err != nil {
+		return nil, err
+	}
+	now := a.clock.Now()
+	for i, entry := range entries {
+		if query == "" && i >= 5 {
+			break
+		}
+		// Newer entries win ties; the boost fades over a couple of weeks
+		age := now.Sub(entry.CreatedAt).Hours() / 24
+		recency := 10 / (1 + age/3)
+		add(QuickOpenItem{
+			Kind:     quickKindEntry,
+			Title:    a.entryPreview(entry.Content),
+			Subtitle: fmt.Sprintf("#%d · %s", entry.ID, entry.CreatedAt.Local().Format("Jan 2 15:04")),
+			Action:   quickActionOpen,
+			Value:    fmt.Sprint(entry.ID),
+			URL:      a.EntryURL(entry.ID),
+		}, query, entry.Content, recency)
+	}
+
+	if query != "" {
+		tags, err := a.tagUsage()
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			add(QuickOpenItem{
+				Kind:     quickKindTag,
+				Title:    "#" + tag.name,
+				Subtitle: fmt.Sprintf("%d entries", tag.count),
+				Action:   quickActionOpen,
+				Value:    tag.name,
+				URL:      a.searchURL(url.Values{"tag": {tag.name}}),
+			}, strings.TrimPrefix(query, "#"), tag.name, float64(tag.count)/(float64(tag.count)+10)*5)
+		}
+
+		if day, label, ok := parseQuickDate(query, a.today()); ok {
+			date := day.Format("2006-01-02")
+			items = append(items, QuickOpenItem{
+				Kind:     quickKindDate,
+				Title:    label,
+				Subtitle: day.Format("Monday, January 2, 2006"),
+				Action:   quickActionOpen,
+				Value:    date,
+				URL:      a.searchURL(url.Values{"from": {date}, "to": {date}}),
+				Score:    110,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Score > items[j].Score
+	})
+	if len(items) > maxQuickOpenResults {
+		items = items[:maxQuickOpenResults]
+	}
+	return items, nil
+}
+
+func (a *App) searchURL(values url.Values) string {
+	return fmt.Sprintf("http://localhost:%d/dash/search?%s", a.dashboardPort, values.Encode())
+}
+
+type tagCount struct {
+	name  string
+	count int
+}
+
+func (a *App) tagUsage() ([]tagCount, error) {
+	rows, err := a.db.Query(`SELECT t.name, COUNT(lt.log_entry_id) FROM tags t
+		JOIN log_entries_tags lt ON lt.tag_id = t.id
+		GROUP BY t.id ORDER BY COUNT(lt.log_entry_id) DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag usage: %v", err)
+	}
+	defer rows.Close()
+
+	var tags []tagCount
+	for rows.Next() {
+		var tag tagCount
+		if err := rows.Scan(&tag.name, &tag.count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag usage: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// entryTitle is the first non-empty line of an entry, shortened for a result list
+func entryTitle(content string) string {
+	return previewText(content, 80, true)
+}
+
+// fuzzyScore rates how well query matches text, from 100 for a prefix match down to a
+// few points for a scattered subsequence. An empty query matches everything equally.
+func fuzzyScore(query, text string) (float64, bool) {
+	if query == "" {
+		return 1, true
+	}
+	text = strings.ToLower(text)
+
+	if strings.HasPrefix(text, query) {
+		return 100, true
+	}
+	if i := strings.Index(text, query); i >= 0 {
+		score := 70.0
+		if i > 0 && !isWordChar(text[i-1]) {
+			score = 85
+		}
+		return score - float64(i)/float64(len(text))*10, true
+	}
+
+	// Scattered matches are noise in long text such as entry bodies
+	if len(text) > 64 {
+		return 0, false
+	}
+
+	// Every query character must appear in order; tight, word-aligned runs score higher
+	score, last := 0.0, -1
+	for i := 0; i < len(query); i++ {
+		j := strings.IndexByte(text[last+1:], query[i])
+		if j < 0 {
+			return 0, false
+		}
+		pos := last + 1 + j
+		switch {
+		case pos == last+1:
+			score += 3
+		case pos == 0 || !isWordChar(text[pos-1]):
+			score += 2
+		default:
+			score += 0.5
+		}
+		last = pos
+	}
+	return score / float64(len(query)) * 15, true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// parseQuickDate understands "today", "yesterday", weekday names (the most recent one),
+// ISO dates and the rest of parseNaturalDay, such as "3 days ago" or "may 1"
+func parseQuickDate(query string, now time.Time) (time.Time, string, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch query {
+	case "today":
+		return today, "Today", true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), "Yesterday", true
+	}
+
+	if wd, ok := parseWeekday(query); ok {
+		return mostRecentWeekday(today, wd), "Last " + wd.String(), true
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", query, time.Local); err == nil {
+		return day, day.Format("2006-01-02"), true
+	}
+	if day, ok := parseNaturalDay(query, today); ok {
+		return day, day.Format("Mon, Jan 2"), true
+	}
+	return time.Time{}, "", false
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const defaultReminderMessage = "What are you working on? You have logged {{.TodayCount}} entries today."
+
+// an entry logged this soon after a reminder counts as a response to it
+const reminderResponseWindow = 15 * time.Minute
+
+const maxSnoozeMinutes = 24 * 60
+
+// Keys of the buttons on a reminder notification
+const (
+	reminderActionSnooze10 = "snooze-10"
+	reminderActionSnooze30 = "snooze-30"
+	reminderActionSnooze60 = "snooze-60"
+	reminderActionSkip     = "skip-today"
+)
+
+var reminderNotificationActions = []notificationAction{
+	{Key: reminderActionSnooze10, Label: "Snooze 10 min"},
+	{Key: reminderActionSnooze30, Label: "Snooze 30 min"},
+	{Key: reminderActionSnooze60, Label: "Snooze 1 hour"},
+	{Key: reminderActionSkip, Label: "Skip today"},
+}
+
+var reminderSnoozeMinutes = map[string]int{
+	reminderActionSnooze10: 10,
+	reminderActionSnooze30: 30,
+	reminderActionSnooze60: 60,
+}
+
+// reminderState tracks snoozes and skips between scheduler ticks
+type reminderState struct {
+	mu           sync.Mutex
+	snoozedUntil time.Time
+	snoozed      *ReminderSchedule
+	last         *ReminderSchedule
+	skipDate     string
+	lastFiredAt  time.Time
+	responded    bool
+}
+
+// ReminderStats summarises how reminders were handled over a period
+type ReminderStats struct {
+	Fired        int     `json:"fired"`
+	Snoozed      int     `json:"snoozed"`
+	Skipped      int     `json:"skipped"`
+	Responded    int     `json:"responded"`
+	ResponseRate float64 `json:"response_rate"`
+}
+
+func (a *App) createReminderTables() error {
+	createReminderEventsSQL := `
+	CREATE TABLE IF NOT EXISTS reminder_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		reminder_name TEXT,
+		action TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_reminder_events_created ON reminder_events(created_at);`
+
+	if _, err := a.db.Exec(createReminderEventsSQL); err != nil {
+		return fmt.Errorf("failed to create reminder_events table: %v", err)
+	}
+	return nil
+}
+
+func (a *App) recordReminderEvent(name, action string) {
+	if a.db == nil {
+		return
+	}
+	query := `INSERT INTO reminder_events (reminder_name, action) VALUES (?, ?)`
+	if _, err := a.db.Exec(query, name, action); err != nil {
+		a.logf("Warning: failed to record reminder event: %v\n", err)
+	}
+}
+
+// ReminderSchedule describes when a reminder fires. Weekdays use three letter
+// names ("mon".."sun"), Start/End are "HH:MM" in local time, and Message is a
+// text/template with .Name, .Time, .Weekday, .TodayCount and .Streak available.
+type ReminderSchedule struct {
+	Name            string   `json:"name"`
+	Enabled         bool     `json:"enabled"`
+	Weekdays        []string `json:"weekdays"`
+	Start           string   `json:"start"`
+	End             string   `json:"end"`
+	IntervalMinutes int      `json:"interval_minutes"`
+	Message         string   `json:"message"`
+}
+
+// reminderContext is the data available to reminder message templates
+type reminderContext struct {
+	Name         string
+	Time         string
+	Weekday      string
+	TodayCount   int
+	Streak       int
+	LastEntry    string
+	LastEntryURL string
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// isDue reports whether the schedule fires at the given minute
+func (r ReminderSchedule) isDue(now time.Time) bool {
+	if !r.Enabled || r.IntervalMinutes <= 0 {
+		return false
+	}
+
+	weekday := strings.ToLower(now.Weekday().String()[:3])
+	matched := len(r.Weekdays) == 0
+	for _, day := range r.Weekdays {
+		if strings.ToLower(strings.TrimSpace(day)) == weekday {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	start, err := parseClock(r.Start)
+	if err != nil {
+		return false
+	}
+	end := 24 * 60
+	if r.End != "" {
+		if end, err = parseClock(r.End); err != nil {
+			return false
+		}
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if minute < start || minute >= end {
+		return false
+	}
+	return (minute-start)%r.IntervalMinutes == 0
+}
+
+func (a *App) renderReminderMessage(r ReminderSchedule, now time.Time) string {
+	message := r.Message
+	if message == "" {
+		message = defaultReminderMessage
+	}
+
+	data := reminderContext{
+		Name:    r.Name,
+		Time:    now.Format("15:04"),
+		Weekday: now.Weekday().String(),
+	}
+	if count, err := a.countEntriesToday(); err == nil {
+		data.TodayCount = count
+	}
+	if streak, err := a.calculateCurrentStreak(); err == nil {
+		data.Streak = streak
+	}
+	if entry, err := a.GetMostRecentEntry(); err == nil {
+		data.LastEntry = a.entryPreview(entry.Content)
+		data.LastEntryURL = a.EntryURL(entry.ID)
+	}
+
+	tmpl, err := template.New(r.Name).Parse(message)
+	if err != nil {
+		return message
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return message
+	}
+	return buf.String()
+}
+
+// runReminderScheduler checks every minute whether a reminder schedule is due
+func (a *App) runReminderScheduler() {
+	ticks, stop := a.clock.Ticker(time.Minute)
+	defer stop()
+
+	lastFired := make(map[int]time.Time)
+	for now := range ticks {
+		now = now.Truncate(time.Minute)
+
+		a.reminders.mu.Lock()
+		skipped := a.reminders.skipDate == a.dayKey(now)
+		snoozed := now.Before(a.reminders.snoozedUntil)
+		var wakeUp *ReminderSchedule
+		if !snoozed && a.reminders.snoozed != nil {
+			wakeUp = a.reminders.snoozed
+			a.reminders.snoozed = nil
+		}
+		a.reminders.mu.Unlock()
+
+		if skipped || a.isDayOff(now) {
+			continue
+		}
+		if wakeUp != nil {
+			a.fireReminder(*wakeUp, now)
+			continue
+		}
+		if snoozed {
+			continue
+		}
+
+		for i, reminder := range a.settings.Reminders {
+			if !reminder.isDue(now) || lastFired[i].Equal(now) {
+				continue
+			}
+			lastFired[i] = now
+			a.fireReminder(reminder, now)
+		}
+	}
+}
+
+func (a *App) fireReminder(reminder ReminderSchedule, now time.Time) {
+	title := "SnapLog"
+	if reminder.Name != "" {
+		title = "SnapLog - " + reminder.Name
+	}
+	a.logf("Reminder due: %s\n", reminder.Name)
+
+	a.reminders.mu.Lock()
+	a.reminders.lastFiredAt = now
+	a.reminders.responded = false
+	a.reminders.last = &reminder
+	a.reminders.mu.Unlock()
+
+	a.recordReminderEvent(reminder.Name, "fired")
+	go a.notifyReminder(title, a.renderReminderMessage(reminder, now))
+}
+
+// notifyReminder shows a reminder with Snooze and Skip today buttons and carries out
+// the one clicked
+func (a *App) notifyReminder(title, message string) {
+	key := a.notifyWithActions(title, message, reminderNotificationActions)
+	if key == reminderActionSkip {
+		if err := a.SkipRemindersToday(); err != nil {
+			a.logf("Warning: failed to skip reminders: %v\n", err)
+		}
+	} else if minutes, ok := reminderSnoozeMinutes[key]; ok {
+		if err := a.SnoozeReminder(minutes); err != nil {
+			a.logf("Warning: failed to snooze reminders: %v\n", err)
+		}
+	}
+}
+
+// recordReminderResponse marks the latest reminder as answered when an entry follows it closely
+func (a *App) recordReminderResponse() {
+	a.reminders.mu.Lock()
+	if a.reminders.lastFiredAt.IsZero() || a.reminders.responded || a.clock.Now().Sub(a.reminders.lastFiredAt) > reminderResponseWindow {
+		a.reminders.mu.Unlock()
+		return
+	}
+	a.reminders.responded = true
+	name := ""
+	if a.reminders.last != nil {
+		name = a.reminders.last.Name
+	}
+	a.reminders.snoozed = nil
+	a.reminders.mu.Unlock()
+
+	a.recordReminderEvent(name, "responded")
+}
+
+// SnoozeReminder postpones reminders for the given number of minutes (e.g. 10, 30 or 60).
+// The most recent reminder fires again once the snooze ends.
+func (a *App) SnoozeReminder(minutes int) error {
+	if minutes <= 0 || minutes > maxSnoozeMinutes {
+		return fmt.Errorf("snooze must be between 1 and %d minutes", maxSnoozeMinutes)
+	}
+
+	a.reminders.mu.Lock()
+	a.reminders.snoozedUntil = a.clock.Now().Add(time.Duration(minutes) * time.Minute)
+	a.reminders.snoozed = a.reminders.last
+	name := ""
+	if a.reminders.last != nil {
+		name = a.reminders.last.Name
+	}
+	a.reminders.mu.Unlock()
+
+	a.recordReminderEvent(name, "snoozed")
+	a.logf("Reminders snoozed for %d minutes\n", minutes)
+	return nil
+}
+
+// SkipRemindersToday silences all reminders until tomorrow
+func (a *App) SkipRemindersToday() error {
+	a.reminders.mu.Lock()
+	a.reminders.skipDate = a.dayKey(a.clock.Now())
+	a.reminders.snoozed = nil
+	a.reminders.mu.Unlock()
+
+	a.recordReminderEvent("", "skipped")
+	a.logf("Reminders skipped for today\n")
+	return nil
+}
+
+// GetReminderStats returns prompt-response figures for the last N days
+func (a *App) GetReminderStats(days int) (*ReminderStats, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if days <= 0 {
+		days = 30
+	}
+
+	since := a.clock.Now().AddDate(0, 0, -days)
+	rows, err := a.db.Query(`SELECT action, COUNT(*) FROM reminder_events WHERE created_at >= ? GROUP BY action`, sqlTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminder events: %v", err)
+	}
+	defer rows.Close()
+
+	stats := &ReminderStats{}
+	for rows.Next() {
+		var action string
+		var count int
+		if err := rows.Scan(&action, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder events: %v", err)
+		}
+		switch action {
+		case "fired":
+			stats.Fired = count
+		case "snoozed":
+			stats.Snoozed = count
+		case "skipped":
+			stats.Skipped = count
+		case "responded":
+			stats.Responded = count
+		}
+	}
+
+	if stats.Fired > 0 {
+		stats.ResponseRate = float64(stats.Responded) / float64(stats.Fired)
+	}
+	return stats, nil
+}
+
+// handleReminderAPI exposes snooze and skip for integrations:
+// POST /api/reminders/snooze?minutes=30 and POST /api/reminders/skip
+func (a *App) handleReminderAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var err error
+	switch strings.TrimPrefix(r.URL.Path, "/api/reminders/") {
+	case "snooze":
+		minutes, convErr := strconv.Atoi(r.URL.Query().Get("minutes"))
+		if convErr != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "Invalid minutes")
+			return
+		}
+		err = a.SnoozeReminder(minutes)
+	case "skip":
+		err = a.SkipRemindersToday()
+	default:
+		writeError(w, http.StatusNotFound, codeNotFound, "Not found")
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+	})
+}
+
+func validateReminders(reminders []ReminderSchedule) error {
+	for _, r := range reminders {
+		if _, err := parseClock(r.Start); err != nil {
+			return fmt.Errorf("reminder %q: %v", r.Name, err)
+		}
+		if r.End != "" {
+			if _, err := parseClock(r.End); err != nil {
+				return fmt.Errorf("reminder %q: %v", r.Name, err)
+			}
+		}
+		if r.IntervalMinutes <= 0 {
+			return fmt.Errorf("reminder %q: interval must be positive", r.Name)
+		}
+		if _, err := template.New(r.Name).Parse(r.Message); err != nil {
+			return fmt.Errorf("reminder %q: invalid message template: %v", r.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderIsDue(t *testing.T) {
+	reminder := ReminderSchedule{Enabled: true, Weekdays: []string{"mon", "Wed"}, Start: "09:00", End: "17:00", IntervalMinutes: 90}
+	monday := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		due  bool
+	}{
+		{"start", monday.Add(9 * time.Hour), true},
+		{"between intervals", monday.Add(9*time.Hour + 45*time.Minute), false},
+		{"one interval in", monday.Add(10*time.Hour + 30*time.Minute), true},
+		{"before start", monday.Add(7*time.Hour + 30*time.Minute), false},
+		{"end is excluded", monday.Add(17 * time.Hour), false},
+		{"weekday in another case", monday.AddDate(0, 0, 2).Add(9 * time.Hour), true},
+		{"other weekday", monday.AddDate(0, 0, 1).Add(9 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := reminder.isDue(tt.at); got != tt.due {
+			t.Errorf("%s: isDue(%s) = %v, want %v", tt.name, tt.at.Format("Mon 15:04"), got, tt.due)
+		}
+	}
+
+	disabled := reminder
+	disabled.Enabled = false
+	if disabled.isDue(monday.Add(9 * time.Hour)) {
+		t.Error("a disabled reminder is due")
+	}
+}
+
+// startReminders runs the reminder scheduler on a fake clock standing at 08:59 on a
+// Monday, with one reminder every 30 minutes from 09:00 to 11:00
+func startReminders(t *testing.T) (*App, *fakeClock, *fakeNotifier) {
+	t.Helper()
+	a, clock, notifier := newFakeApp(t, time.Date(2026, time.March, 2, 8, 59, 0, 0, time.Local))
+	a.settings.Reminders = []ReminderSchedule{{Name: "Check-in", Enabled: true, Start: "09:00", End: "11:00", IntervalMinutes: 30}}
+	go a.runReminderScheduler()
+	clock.WaitForTickers(1)
+	return a, clock, notifier
+}
+
+// advanceTo moves the clock to a minute past hh:mm on its current day. The scheduler
+// has then finished with the tick at hh:mm, and has only just received the next one, so
+// tests look at the results for hh:mm when nothing is due a minute later.
+func advanceTo(clock *fakeClock, hour, minute int) {
+	now := clock.Now()
+	target := time.Date(now.Year(), now.Month(), now.Day(), hour, minute+1, 0, 0, time.Local)
+	clock.Advance(target.Sub(now))
+}
+
+// fired waits for the count reminders expected so far, which are shown from a goroutine,
+// and returns how many were sent
+func fired(notifier *fakeNotifier, count int) int {
+	notifier.WaitForSent(count)
+	return len(notifier.Sent())
+}
+
+func TestReminderSchedulerFiresOnSchedule(t *testing.T) {
+	_, clock, notifier := startReminders(t)
+
+	advanceTo(clock, 9, 28)
+	if n := fired(notifier, 1); n != 1 {
+		t.Fatalf("got %d reminders by 09:28, want 1", n)
+	}
+	advanceTo(clock, 12, 0)
+	notifier.WaitForSent(4)
+	sent := notifier.Sent()
+	if len(sent) != 4 {
+		t.Fatalf("got %d reminders by noon, want 4 (09:00 to 10:30)", len(sent))
+	}
+	if sent[0].Title != "SnapLog - Check-in" {
+		t.Errorf("title = %q", sent[0].Title)
+	}
+	if want := "What are you working on? You have logged 0 entries today."; sent[0].Message != want {
+		t.Errorf("message = %q, want %q", sent[0].Message, want)
+	}
+}
+
+func TestSnoozeReminderFiresItAgain(t *testing.T) {
+	a, clock, notifier := startReminders(t)
+
+	// snoozed at 09:01, until 09:11
+	advanceTo(clock, 9, 0)
+	if err := a.SnoozeReminder(10); err != nil {
+		t.Fatal(err)
+	}
+	advanceTo(clock, 9, 9)
+	if n := fired(notifier, 1); n != 1 {
+		t.Fatalf("got %d reminders while snoozed, want 1", n)
+	}
+	advanceTo(clock, 9, 11)
+	if n := fired(notifier, 2); n != 2 {
+		t.Fatalf("got %d reminders once the snooze ended, want 2", n)
+	}
+	advanceTo(clock, 9, 30)
+	if n := fired(notifier, 3); n != 3 {
+		t.Fatalf("got %d reminders after the next one due, want 3", n)
+	}
+}
+
+func TestSkipRemindersUntilTomorrow(t *testing.T) {
+	a, clock, notifier := startReminders(t)
+
+	advanceTo(clock, 9, 0)
+	if err := a.SkipRemindersToday(); err != nil {
+		t.Fatal(err)
+	}
+	advanceTo(clock, 23, 0)
+	if n := fired(notifier, 1); n != 1 {
+		t.Fatalf("got %d reminders on the skipped day, want 1", n)
+	}
+
+	clock.Advance(9 * time.Hour)
+	advanceTo(clock, 9, 0)
+	if n := fired(notifier, 2); n != 2 {
+		t.Fatalf("got %d reminders the next morning, want 2", n)
+	}
+}
+
+func TestRemindersRestOnDaysOff(t *testing.T) {
+	a, clock, notifier := startReminders(t)
+	if _, err := a.AddDaysOff("2026-03-02", "", "Holiday"); err != nil {
+		t.Fatal(err)
+	}
+
+	advanceTo(clock, 12, 0)
+	if n := fired(notifier, 0); n != 0 {
+		t.Fatalf("got %d reminders on a day off, want 0", n)
+	}
+}
+
+func TestReminderButtons(t *testing.T) {
+	a, clock, notifier := startReminders(t)
+	notifier.Answer(reminderActionSnooze30)
+
+	advanceTo(clock, 9, 0)
+	waitForReminders(a, func(r *reminderState) bool { return !r.snoozedUntil.IsZero() })
+	if sent := notifier.Sent(); len(sent) != 1 || sent[0].Title != "SnapLog - Check-in" {
+		t.Fatalf("sent %+v, want the 09:00 reminder", sent)
+	}
+	// snoozed at 09:00 or 09:01, depending on when the button was handled
+	advanceTo(clock, 9, 28)
+	if n := fired(notifier, 1); n != 1 {
+		t.Fatalf("got %d reminders while snoozed, want 1", n)
+	}
+
+	notifier.Answer(reminderActionSkip)
+	advanceTo(clock, 9, 31)
+	waitForReminders(a, func(r *reminderState) bool { return r.skipDate == "2026-03-02" })
+	advanceTo(clock, 23, 0)
+	if n := fired(notifier, 2); n != 2 {
+		t.Fatalf("got %d reminders after skipping today, want 2", n)
+	}
+}
+
+// waitForReminders blocks until the reminder state satisfies done, for the buttons of a
+// reminder that are answered from a goroutine
+func waitForReminders(a *App, done func(*reminderState) bool) {
+	for {
+		a.reminders.mu.Lock()
+		ok := done(a.reminders)
+		a.reminders.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
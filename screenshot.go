@@ -0,0 +1,60 @@
+package main
+
+import (
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// startScreenshotHotkey registers the optional screenshot hotkey; an empty key disables it
+func (a *App) startScreenshotHotkey() {
+	if a.settings.ScreenshotHotkeyKey == "" {
+		return
+	}
+	if _, ok := parseKey(a.settings.ScreenshotHotkeyKey); !ok {
+		a.logf("Unknown screenshot hotkey key: %s\n", a.settings.ScreenshotHotkeyKey)
+		return
+	}
+
+	hk, err := a.registerHotkey(a.settings.ScreenshotHotkeyModifiers, a.settings.ScreenshotHotkeyKey)
+	if err != nil {
+		a.logf("Failed to register screenshot hotkey: %v\n", err)
+		return
+	}
+
+	a.logf("Screenshot hotkey registered: %v+%v\n", a.settings.ScreenshotHotkeyModifiers, a.settings.ScreenshotHotkeyKey)
+	a.extraHotkeys = append(a.extraHotkeys, hk)
+
+	go func() {
+		for range hk.Keydown() {
+			a.captureScreenshot()
+		}
+	}()
+}
+
+// captureScreenshot lets the user pick a region or window to capture, stores the picture
+// as an attachment and opens the capture window with it embedded, ready to annotate
+func (a *App) captureScreenshot() {
+	data, err := takeScreenshot()
+	if err != nil {
+		a.logf("Failed to take screenshot: %v\n", err)
+		a.notify("SnapLog screenshot", "The screenshot failed: "+err.Error(), priorityNormal)
+		return
+	}
+	if len(data) == 0 {
+		a.logf("Screenshot cancelled\n")
+		return
+	}
+
+	name := "Screenshot " + a.clock.Now().Format("2006-01-02 15.04.05") + ".png"
+	attachment, err := a.storeAttachment(0, name, data)
+	if err != nil {
+		a.logf("Failed to store screenshot: %v\n", err)
+		a.notify("SnapLog screenshot", "The screenshot couldn't be saved: "+err.Error(), priorityNormal)
+		return
+	}
+	a.logf("Stored screenshot as attachment %d\n", attachment.ID)
+
+	// the window takes focus whatever capture_keep_focus says: the point is to type a note
+	a.restoreCaptureMode()
+	a.ShowWindow()
+	wailsRuntime.EventsEmit(a.ctx, "prefill-selection", attachmentMarkdown(attachment)+"\n\n")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// takeScreenshot runs the interactive screencapture: drag to pick a region, or press Space
+// and click a window. It returns the PNG, or nil when the user pressed Esc. This needs the
+// Screen Recording permission for SnapLog in System Settings.
+func takeScreenshot() ([]byte, error) {
+	dir, err := os.MkdirTemp("", "snaplog-screenshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "screenshot.png")
+	if err := exec.Command("screencapture", "-i", "-x", "-t", "png", path).Run(); err != nil {
+		return nil, fmt.Errorf("screencapture failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+const (
+	// screenshotTimeout is how long the snipping overlay is waited on before giving up
+	screenshotTimeout      = 2 * time.Minute
+	screenshotPollInterval = time.Second
+)
+
+// takeScreenshot opens the Windows snipping overlay, where a region or window is picked,
+// and waits for the snip to land on the clipboard. It returns the PNG, or nil when no new
+// image arrived in time, e.g. because the snip was cancelled.
+func takeScreenshot() ([]byte, error) {
+	before, err := clipboardImage()
+	if err != nil {
+		return nil, err
+	}
+	if err := exec.Command("explorer.exe", "ms-screenclip:").Start(); err != nil {
+		return nil, fmt.Errorf("failed to open the snipping overlay: %v", err)
+	}
+
+	deadline := time.Now().Add(screenshotTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(screenshotPollInterval)
+		data, err := clipboardImage()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 && !bytes.Equal(data, before) {
+			return data, nil
+		}
+	}
+	return nil, nil
+}
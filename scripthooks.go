@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Script hook events
+const (
+	hookEntryCreated = "entry_created"
+	hookDayEnd       = "day_end"
+	hookExport       = "export"
+)
+
+const (
+	defaultScriptTimeout = 10 * time.Second
+	maxScriptOutput      = 4096
+	maxExportPayload     = 16 << 20
+)
+
+// ScriptHook runs a user executable when an event fires. The event payload is
+// written to the process as JSON on stdin.
+type ScriptHook struct {
+	Name           string   `json:"name"`
+	Event          string   `json:"event"`
+	Command        string   `json:"command"`
+	Args           []string `json:"args"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
+type scriptHookPayload struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// limitedBuffer keeps the first maxScriptOutput bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxScriptOutput - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// runScriptHooks starts every hook registered for event in the background
+func (a *App) runScriptHooks(event string, data interface{}) {
+	for _, hook := range a.settings.ScriptHooks {
+		if hook.Event != event || hook.Command == "" {
+			continue
+		}
+		go func(hook ScriptHook) {
+			if err := a.runScriptHook(hook, event, data); err != nil {
+				a.logf("Script hook %s failed: %v\n", hook.label(), err)
+			}
+		}(hook)
+	}
+}
+
+func (h ScriptHook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Command
+}
+
+func (a *App) runScriptHook(hook ScriptHook, event string, data interface{}) error {
+	payload, err := json.Marshal(scriptHookPayload{
+		Event:     event,
+		Timestamp: a.clock.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %v", err)
+	}
+
+	timeout := defaultScriptTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output limitedBuffer
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(), "SNAPLOG_EVENT="+event)
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(output.String()))
+	}
+
+	a.logf("Script hook %s ran for %s\n", hook.label(), event)
+	return nil
+}
+
+// watchDayEnd fires day_end hooks with the previous day's entries once the local date changes
+func (a *App) watchDayEnd() {
+	ticks, stop := a.clock.Ticker(time.Minute)
+	defer stop()
+
+	current := a.dayKey(a.clock.Now())
+	for now := range ticks {
+		today := a.dayKey(now)
+		if today == current {
+			continue
+		}
+
+		if err := a.reconcileDayPlans(); err != nil {
+			a.logf("Warning: failed to reconcile day plans: %v\n", err)
+		}
+		day, err := time.ParseInLocation("2006-01-02", current, time.Local)
+		current = today
+		if err != nil {
+			continue
+		}
+		entries, err := a.entriesBetween(a.dayStart(day), a.dayStart(day.AddDate(0, 0, 1)))
+		if err != nil {
+			a.logf("Warning: failed to load entries for day_end hooks: %v\n", err)
+			continue
+		}
+		a.runScriptHooks(hookDayEnd, map[string]interface{}{
+			"date":        day.Format("2006-01-02"),
+			"entry_count": len(entries),
+			"entries":     entries,
+		})
+	}
+}
+
+// entriesBetween returns entries created in [from, to), oldest first
+func (a *App) entriesBetween(from, to time.Time) ([]LogEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE created_at >= ? AND created_at < ? AND deleted_at IS NULL ORDER BY created_at`
+	rows, err := a.db.Query(query, sqlTime(from), sqlTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log entries: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []LogEntry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// handleExportHookAPI lets the dashboard report an export: POST /api/hooks/export
+func (a *App) handleExportHookAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		Format     string `json:"format"`
+		Filename   string `json:"filename"`
+		EntryCount int    `json:"entry_count"`
+		Content    string `json:"content"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxExportPayload)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+		return
+	}
+
+	a.runScriptHooks(hookExport, req)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"golang.design/x/hotkey"
+)
+
+// The App reaches the operating system and the wall clock only through these seams.
+// NewApp installs the real implementations; fakes_test.go has deterministic ones for tests.
+
+// clock tells the time and drives the background schedulers
+type clock interface {
+	Now() time.Time
+	// Ticker delivers the current time every d until stop is called
+	Ticker(d time.Duration) (ticks <-chan time.Time, stop func())
+}
+
+// notifier shows a system notification
+type notifier interface {
+	Notify(title, message string) error
+}
+
+// actionNotifier is a notifier that can also offer buttons. NotifyActions waits until the
+// notification is answered or dismissed and returns the chosen action's key, or "" when
+// none was chosen. Notifiers without it get a plain notification instead.
+type actionNotifier interface {
+	NotifyActions(title, message string, actions []notificationAction) (string, error)
+}
+
+// browserOpener opens a URL or file with the default application
+type browserOpener interface {
+	Open(urlOrPath string) error
+}
+
+// globalHotkey is a registered system-wide hotkey
+type globalHotkey interface {
+	Keydown() <-chan hotkey.Event
+	Keyup() <-chan hotkey.Event
+	Unregister() error
+}
+
+// hotkeyRegistrar registers system-wide hotkeys
+type hotkeyRegistrar interface {
+	Register(modifiers []hotkey.Modifier, key hotkey.Key) (globalHotkey, error)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+type systemNotifier struct{}
+
+func (systemNotifier) Notify(title, message string) error {
+	return sendSystemNotification(title, message)
+}
+
+func (systemNotifier) NotifyActions(title, message string, actions []notificationAction) (string, error) {
+	return sendActionNotification(title, message, actions)
+}
+
+type systemBrowser struct{}
+
+func (systemBrowser) Open(urlOrPath string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", urlOrPath)
+	case "darwin":
+		cmd = exec.Command("open", urlOrPath)
+	case "linux":
+		cmd = exec.Command("xdg-open", urlOrPath)
+	default:
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+
+	return cmd.Start()
+}
+
+type systemHotkeys struct{}
+
+func (systemHotkeys) Register(modifiers []hotkey.Modifier, key hotkey.Key) (globalHotkey, error) {
+	hk := hotkey.New(modifiers, key)
+	if err := hk.Register(); err != nil {
+		return nil, err
+	}
+	return hk, nil
+}
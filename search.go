@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxSearchResults = 200
+	maxFacetValues   = 20
+)
+
+// SearchFilters are the query parameters accepted by /dash/search
+type SearchFilters struct {
+	Query   string
+	Tag     string
+	Person  string
+	Type    string
+	Source  string
+	From    string
+	To      string
+	Journal string
+}
+
+// FacetValue is one value of a facet with the number of matching entries.
+// URL toggles the value on or off while keeping the other filters.
+type FacetValue struct {
+	Value  string
+	Count  int
+	Active bool
+	URL    string
+}
+
+// HistogramBucket counts matching entries in one day, week or month
+type HistogramBucket struct {
+	Label string
+	Count int
+	Ratio float64
+	URL   string
+}
+
+// SearchPageData holds everything rendered on /dash/search
+type SearchPageData struct {
+	Filters   SearchFilters
+	Active    bool
+	Total     int
+	Results   []DisplayEntry
+	Truncated bool
+	Tags      []FacetValue
+	People    []FacetValue
+	Types     []FacetValue
+	Sources   []FacetValue
+	Journals  []FacetValue
+	Interval  string
+	Histogram []HistogramBucket
+}
+
+func parseSearchFilters(values url.Values) SearchFilters {
+	get := func(key string) string {
+		return strings.TrimSpace(values.Get(key))
+	}
+	return SearchFilters{
+		Query:   get("q"),
+		Tag:     strings.TrimPrefix(get("tag"), "#"),
+		Person:  strings.TrimPrefix(get("person"), "@"),
+		Type:    get("type"),
+		Source:  get("source"),
+		From:    get("from"),
+		To:      get("to"),
+		Journal: get("journal"),
+	}
+}
+
+func (f SearchFilters) values() url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"q": f.Query, "tag": f.Tag, "person": f.Person, "type": f.Type,
+		"source": f.Source, "from": f.From, "to": f.To, "journal": f.Journal,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values
+}
+
+// with returns the search URL with key set to value, or cleared when it is already set to value
+func (f SearchFilters) with(key, value string) string {
+	values := f.values()
+	if values.Get(key) == value {
+		values.Del(key)
+	} else {
+		values.Set(key, value)
+	}
+	return "/dash/search?" + values.Encode()
+}
+
+// where builds the SQL condition selecting the entries that match every filter.
+// From and To are ISO dates or natural ones read relative to today, such as "last tuesday";
+// dayStart maps them to the instants their logical days begin, and trigram searches the
+// text with the trigram index.
+func (f SearchFilters) where(today time.Time, dayStart func(time.Time) time.Time, trigram bool) (string, []interface{}, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if condition, matchArgs := ftsCondition(f.Query, trigram); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, matchArgs...)
+	}
+	if f.Tag != "" {
+		conditions = append(conditions, taggedEntryCondition)
+		args = append(args, canonicalTag(f.Tag))
+	}
+	if f.Person != "" {
+		conditions = append(conditions, `id IN (SELECT lp.log_entry_id FROM log_entries_people lp
+			JOIN people p ON p.id = lp.person_id WHERE p.name = ?)`)
+		args = append(args, f.Person)
+	}
+	if f.Type != "" {
+		conditions = append(conditions, `entry_type = ?`)
+		args = append(args, f.Type)
+	}
+	if f.Source != "" {
+		conditions = append(conditions, `source = ?`)
+		args = append(args, f.Source)
+	}
+	if f.Journal != "" {
+		conditions = append(conditions, journalCondition)
+		args = append(args, strings.ToLower(f.Journal))
+	}
+	if f.From != "" {
+		from, ok := parseFilterDay(f.From, today)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid from date: %s", f.From)
+		}
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, sqlTime(dayStart(from)))
+	}
+	if f.To != "" {
+		to, ok := parseFilterDay(f.To, today)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid to date: %s", f.To)
+		}
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, sqlTime(dayStart(to.AddDate(0, 0, 1))))
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// parseFilterDay reads a From or To filter: an ISO date or a natural one such as "yesterday"
+func parseFilterDay(value string, today time.Time) (time.Time, bool) {
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day, true
+	}
+	return parseNaturalDay(value, today)
+}
+
+// openDaySearch opens the dashboard search on one day, for /today and /on <day>
+func (a *App) openDaySearch(when string) error {
+	day, ok := parseFilterDay(strings.TrimSpace(when), a.today())
+	if !ok {
+		return fmt.Errorf("invalid date %q: use YYYY-MM-DD or a date like \"last tuesday\"", strings.TrimSpace(when))
+	}
+	date := day.Format("2006-01-02")
+	return a.openInBrowser(a.searchURL(url.Values{"from": {date}, "to": {date}}))
+}
+
+// searchEntries runs a faceted search, returning the newest matches and facet counts
+// over every match
+func (a *App) searchEntries(filters SearchFilters) (*SearchPageData, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	where, args, err := filters.where(a.today(), a.dayStart, a.settings.SearchTrigram)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &SearchPageData{Filters: filters, Active: len(filters.values()) > 0}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM log_entries WHERE `+where, args...).Scan(&data.Total); err != nil {
+		return nil, fmt.Errorf("failed to count search results: %v", err)
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM log_entries WHERE ` + where + ` ORDER BY created_at DESC LIMIT ?`
+	rows, err := a.db.Query(query, append(args, maxSearchResults)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search log entries: %v", err)
+	}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan log entry: %v", err)
+		}
+		data.Results = append(data.Results, a.toDisplayEntry(entry))
+	}
+	rows.Close()
+	data.Truncated = data.Total > len(data.Results)
+
+	matching := `SELECT id FROM log_entries WHERE ` + where
+	facets := []struct {
+		target *[]FacetValue
+		key    string
+		active string
+		query  string
+	}{
+		{&data.Tags, "tag", filters.Tag, `SELECT t.name, COUNT(*) FROM log_entries_tags lt
+			JOIN tags t ON t.id = lt.tag_id WHERE lt.log_entry_id IN (` + matching + `)
+			GROUP BY t.name ORDER BY COUNT(*) DESC, t.name LIMIT ?`},
+		{&data.People, "person", filters.Person, `SELECT p.name, COUNT(*) FROM log_entries_people lp
+			JOIN people p ON p.id = lp.person_id WHERE lp.log_entry_id IN (` + matching + `)
+			GROUP BY p.name ORDER BY COUNT(*) DESC, p.name LIMIT ?`},
+		{&data.Types, "type", filters.Type, `SELECT entry_type, COUNT(*) FROM log_entries WHERE ` + where + `
+			GROUP BY entry_type ORDER BY COUNT(*) DESC LIMIT ?`},
+		{&data.Sources, "source", filters.Source, `SELECT source, COUNT(*) FROM log_entries WHERE ` + where + `
+			GROUP BY source ORDER BY COUNT(*) DESC LIMIT ?`},
+		{&data.Journals, "journal", filters.Journal, `SELECT (SELECT name FROM journals WHERE journals.id = journal_id), COUNT(*) FROM log_entries
+			WHERE ` + where + ` GROUP BY journal_id ORDER BY COUNT(*) DESC LIMIT ?`},
+	}
+	for _, facet := range facets {
+		values, err := a.facetValues(facet.query, append(args, maxFacetValues), filters, facet.key, facet.active)
+		if err != nil {
+			return nil, err
+		}
+		*facet.target = values
+	}
+
+	if err := a.fillHistogram(data, where, args); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *App) facetValues(query string, args []interface{}, filters SearchFilters, key, active string) ([]FacetValue, error) {
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute %s facet: %v", key, err)
+	}
+	defer rows.Close()
+
+	var values []FacetValue
+	for rows.Next() {
+		var v FacetValue
+		if err := rows.Scan(&v.Value, &v.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s facet: %v", key, err)
+		}
+		v.Active = strings.EqualFold(v.Value, active)
+		v.URL = filters.with(key, v.Value)
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// fillHistogram buckets matches by local day, week or month depending on how far they span
+func (a *App) fillHistogram(data *SearchPageData, where string, args []interface{}) error {
+	rows, err := a.db.Query(`SELECT created_at FROM log_entries WHERE `+where+` ORDER BY created_at`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to compute date histogram: %v", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return fmt.Errorf("failed to scan entry date: %v", err)
+		}
+		times = append(times, t.Local())
+	}
+	if len(times) == 0 {
+		return nil
+	}
+
+	first, last := times[0], times[len(times)-1]
+	span := last.Sub(first)
+	var start func(time.Time) time.Time
+	var next func(time.Time) time.Time
+	var label string
+	switch {
+	case span <= 62*24*time.Hour:
+		data.Interval = "day"
+		start = func(t time.Time) time.Time { return a.dayStart(a.dayOf(t)) }
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+		label = "Jan 2"
+	case span <= 366*24*time.Hour:
+		data.Interval = "week"
+		start = func(t time.Time) time.Time { return a.dayStart(weekStart(a.dayOf(t))) }
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+		label = "Jan 2"
+	default:
+		data.Interval = "month"
+		start = func(t time.Time) time.Time {
+			day := a.dayOf(t)
+			return a.dayStart(time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.Local))
+		}
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+		label = "Jan 2006"
+	}
+
+	i, peak := 0, 0
+	for bucket := start(first); !bucket.After(last); bucket = next(bucket) {
+		end := next(bucket)
+		count := 0
+		for ; i < len(times) && times[i].Before(end); i++ {
+			count++
+		}
+
+		values := data.Filters.values()
+		values.Set("from", bucket.Format("2006-01-02"))
+		values.Set("to", end.AddDate(0, 0, -1).Format("2006-01-02"))
+		data.Histogram = append(data.Histogram, HistogramBucket{
+			Label: bucket.Format(label),
+			Count: count,
+			URL:   "/dash/search?" + values.Encode(),
+		})
+		if count > peak {
+			peak = count
+		}
+	}
+	for i := range data.Histogram {
+		data.Histogram[i].Ratio = float64(data.Histogram[i].Count) / float64(peak)
+	}
+	return nil
+}
+
+func (a *App) serveSearchPage(w http.ResponseWriter, r *http.Request) {
+	data, err := a.searchEntries(parseSearchFilters(r.URL.Query()))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Search failed: %v", err), http.StatusBadRequest)
+		a.logf("Error searching entries: %v\n", err)
+		return
+	}
+
+	a.renderPage(w, "search.html", "Search", data)
+}
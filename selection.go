@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	selectionCopyTimeout = 400 * time.Millisecond
+	selectionPollDelay   = 25 * time.Millisecond
+	// selectionSentinel is placed on the clipboard so an empty selection can be told apart from stale clipboard text
+	selectionSentinel = "snaplog-selection-probe"
+)
+
+// captureSelection copies the foreground app's selected text and restores the clipboard afterwards.
+// It returns "" when nothing is selected.
+func (a *App) captureSelection() string {
+	previous, err := wailsRuntime.ClipboardGetText(a.ctx)
+	if err != nil {
+		a.logf("Warning: failed to read clipboard: %v\n", err)
+		return ""
+	}
+	if err := wailsRuntime.ClipboardSetText(a.ctx, selectionSentinel); err != nil {
+		a.logf("Warning: failed to prepare clipboard: %v\n", err)
+		return ""
+	}
+	defer wailsRuntime.ClipboardSetText(a.ctx, previous)
+
+	if err := simulateCopy(); err != nil {
+		a.logf("Warning: failed to copy selection: %v\n", err)
+		return ""
+	}
+
+	deadline := time.Now().Add(selectionCopyTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(selectionPollDelay)
+		text, err := wailsRuntime.ClipboardGetText(a.ctx)
+		if err == nil && text != selectionSentinel {
+			return strings.TrimSpace(text)
+		}
+	}
+	return ""
+}
+
+// quoteSelection formats captured text as a markdown quote followed by an empty line to type into
+func quoteSelection(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// showCaptureWindow opens the capture window, prefilled with the current selection when enabled,
+// placed and focused as the capture window settings ask
+func (a *App) showCaptureWindow() {
+	if a.settings.CaptureSelection {
+		if text := a.captureSelection(); text != "" {
+			a.presentCaptureWindow()
+			wailsRuntime.EventsEmit(a.ctx, "prefill-selection", quoteSelection(text))
+			return
+		}
+	}
+	a.presentCaptureWindow()
+}
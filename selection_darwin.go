@@ -0,0 +1,10 @@
+package main
+
+import "os/exec"
+
+// simulateCopy sends Cmd+C to the frontmost application. This needs the
+// Accessibility permission for SnapLog in System Settings.
+func simulateCopy() error {
+	return exec.Command("osascript", "-e",
+		`tell application "System Events" to keystroke "c" using command down`).Run()
+}
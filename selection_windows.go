@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	vkShift        = 0x10
+	vkControl      = 0x11
+	vkMenu         = 0x12
+	vkLWin         = 0x5B
+	vkC            = 0x43
+	keyEventfKeyUp = 0x0002
+)
+
+var procKeybdEvent = windows.NewLazySystemDLL("user32.dll").NewProc("keybd_event")
+
+func keyEvent(vk byte, flags uintptr) {
+	procKeybdEvent.Call(uintptr(vk), 0, flags, 0)
+}
+
+// simulateCopy sends Ctrl+C to the foreground window. Modifiers still held from
+// the hotkey are released first so the target sees a plain Ctrl+C.
+func simulateCopy() error {
+	if err := procKeybdEvent.Find(); err != nil {
+		return err
+	}
+	for _, vk := range []byte{vkShift, vkMenu, vkLWin, vkControl} {
+		keyEvent(vk, keyEventfKeyUp)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	keyEvent(vkControl, 0)
+	keyEvent(vkC, 0)
+	keyEvent(vkC, keyEventfKeyUp)
+	keyEvent(vkControl, keyEventfKeyUp)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// The passwords, keys and passphrases in settings unlock copies of the log kept elsewhere.
+// While the database is encrypted they are kept in it, in settings_secrets, and left out
+// of settings.json.
+
+func (a *App) createSettingsSecretTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS settings_secrets (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create settings_secrets table: %v", err)
+	}
+	return nil
+}
+
+// settingsSecrets points at each secret in settings, by a key naming where it is
+func settingsSecrets(settings *Settings) map[string]*string {
+	secrets := map[string]*string{
+		"sync.passphrase":   &settings.Sync.Passphrase,
+		"backup_passphrase": &settings.BackupPassphrase,
+	}
+	addTargetSecrets(secrets, "sync.target", &settings.Sync.Target)
+	for i := range settings.BackupTargets {
+		addTargetSecrets(secrets, "backup_targets."+settings.BackupTargets[i].Name, &settings.BackupTargets[i])
+	}
+	return secrets
+}
+
+func addTargetSecrets(secrets map[string]*string, prefix string, target *BackupTarget) {
+	secrets[prefix+".secret_access_key"] = &target.SecretAccessKey
+	secrets[prefix+".password"] = &target.Password
+	secrets[prefix+".client_secret"] = &target.ClientSecret
+	secrets[prefix+".refresh_token"] = &target.RefreshToken
+}
+
+// storeSettingsSecrets saves the secrets of the current settings in the encrypted database
+// and returns a copy of the settings without them, for settings.json
+func (a *App) storeSettingsSecrets() (*Settings, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM settings_secrets`); err != nil {
+		return nil, fmt.Errorf("failed to save settings secrets: %v", err)
+	}
+	for key, value := range settingsSecrets(a.settings) {
+		if *value == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO settings_secrets (key, value) VALUES (?, ?)`, key, *value); err != nil {
+			return nil, fmt.Errorf("failed to save settings secrets: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save settings secrets: %v", err)
+	}
+
+	stripped := *a.settings
+	stripped.BackupTargets = append([]BackupTarget(nil), a.settings.BackupTargets...)
+	for _, value := range settingsSecrets(&stripped) {
+		*value = ""
+	}
+	return &stripped, nil
+}
+
+// loadSettingsSecrets fills in the secrets kept in the encrypted database once it is
+// unlocked. One set in settings.json while it was locked wins, and moves into the
+// database with the next save.
+func (a *App) loadSettingsSecrets() error {
+	rows, err := a.db.Query(`SELECT key, value FROM settings_secrets`)
+	if err != nil {
+		return fmt.Errorf("failed to load settings secrets: %v", err)
+	}
+	defer rows.Close()
+
+	secrets := settingsSecrets(a.settings)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return fmt.Errorf("failed to scan settings secret: %v", err)
+		}
+		if secret, ok := secrets[key]; ok && *secret == "" {
+			*secret = value
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// shortcutPattern matches a \trigger token, e.g. "\meet"
+var shortcutPattern = regexp.MustCompile(`\\([A-Za-z0-9_-]+)\b`)
+
+var shortcutTriggerPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Shortcut expands \trigger into a longer snippet
+type Shortcut struct {
+	ID        int64     `json:"id"`
+	Trigger   string    `json:"trigger"`
+	Expansion string    `json:"expansion"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TemplateBundle is the export/import format for templates, shortcuts and the
+// spellcheck dictionary
+type TemplateBundle struct {
+	Templates  map[string]string     `json:"templates"`
+	Shortcuts  []Shortcut            `json:"shortcuts"`
+	Dictionary *SpellcheckDictionary `json:"dictionary,omitempty"`
+}
+
+func (a *App) createShortcutTables() error {
+	createShortcutsSQL := `
+	CREATE TABLE IF NOT EXISTS shortcuts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trigger TEXT NOT NULL UNIQUE,
+		expansion TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createShortcutsSQL); err != nil {
+		return fmt.Errorf("failed to create shortcuts table: %v", err)
+	}
+	return nil
+}
+
+// GetShortcuts returns all shortcuts ordered by trigger
+func (a *App) GetShortcuts() ([]Shortcut, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT id, trigger, expansion, created_at FROM shortcuts ORDER BY trigger`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shortcuts: %v", err)
+	}
+	defer rows.Close()
+
+	var shortcuts []Shortcut
+	for rows.Next() {
+		var s Shortcut
+		if err := rows.Scan(&s.ID, &s.Trigger, &s.Expansion, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shortcut: %v", err)
+		}
+		shortcuts = append(shortcuts, s)
+	}
+	return shortcuts, nil
+}
+
+// SaveShortcut creates or replaces the shortcut for trigger
+func (a *App) SaveShortcut(trigger, expansion string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	trigger = strings.TrimPrefix(strings.TrimSpace(trigger), `\`)
+	if !shortcutTriggerPattern.MatchString(trigger) {
+		return fmt.Errorf("shortcut trigger may only contain letters, numbers, - and _")
+	}
+	if expansion == "" {
+		return fmt.Errorf("expansion cannot be empty")
+	}
+
+	query := `INSERT INTO shortcuts (trigger, expansion) VALUES (?, ?)
+		ON CONFLICT(trigger) DO UPDATE SET expansion = excluded.expansion`
+	if _, err := a.db.Exec(query, trigger, expansion); err != nil {
+		return fmt.Errorf("failed to save shortcut: %v", err)
+	}
+	return nil
+}
+
+// DeleteShortcut removes the shortcut for trigger
+func (a *App) DeleteShortcut(trigger string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	result, err := a.db.Exec(`DELETE FROM shortcuts WHERE trigger = ?`, strings.TrimPrefix(trigger, `\`))
+	if err != nil {
+		return fmt.Errorf("failed to delete shortcut: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("shortcut not found: %s", trigger)
+	}
+	return nil
+}
+
+// ExpandShortcuts replaces every known \trigger in text with its expansion.
+// Unknown triggers are left alone so a literal backslash survives.
+func (a *App) ExpandShortcuts(text string) string {
+	if a.db == nil || !strings.Contains(text, `\`) {
+		return text
+	}
+
+	shortcuts, err := a.GetShortcuts()
+	if err != nil || len(shortcuts) == 0 {
+		return text
+	}
+	expansions := make(map[string]string, len(shortcuts))
+	for _, s := range shortcuts {
+		expansions[s.Trigger] = s.Expansion
+	}
+
+	return shortcutPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if expansion, ok := expansions[match[1:]]; ok {
+			return a.expandPlaceholders(expansion)
+		}
+		return match
+	})
+}
+
+// ExportTemplateBundle returns the templates, shortcuts and dictionary as JSON
+func (a *App) ExportTemplateBundle() (string, error) {
+	shortcuts, err := a.GetShortcuts()
+	if err != nil {
+		return "", err
+	}
+	dictionary, err := a.GetDictionary()
+	if err != nil {
+		return "", err
+	}
+	// export the setting as stored, so "off" round-trips
+	dictionary.Language = a.settings.SpellcheckLanguage
+	templates := a.settings.Templates
+	if templates == nil {
+		templates = defaultTemplates()
+	}
+
+	data, err := json.MarshalIndent(TemplateBundle{Templates: templates, Shortcuts: shortcuts, Dictionary: dictionary}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode templates: %v", err)
+	}
+	return string(data), nil
+}
+
+// ImportTemplateBundle merges exported templates, shortcuts and dictionary words,
+// replacing same-named ones. The bundle's spellcheck language wins when it has one.
+func (a *App) ImportTemplateBundle(data string) error {
+	var bundle TemplateBundle
+	if err := json.Unmarshal([]byte(data), &bundle); err != nil {
+		return fmt.Errorf("invalid template bundle: %v", err)
+	}
+
+	for _, s := range bundle.Shortcuts {
+		if err := a.SaveShortcut(s.Trigger, s.Expansion); err != nil {
+			return fmt.Errorf("failed to import shortcut %s: %v", s.Trigger, err)
+		}
+	}
+
+	if dict := bundle.Dictionary; dict != nil {
+		for _, word := range dict.Words {
+			if err := a.AddDictionaryWord(word); err != nil {
+				return fmt.Errorf("failed to import dictionary word %s: %v", word, err)
+			}
+		}
+		if dict.Language != "" {
+			if err := a.SetSpellcheckLanguage(dict.Language); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(bundle.Templates) > 0 {
+		if a.settings.Templates == nil {
+			a.settings.Templates = defaultTemplates()
+		}
+		for name, text := range bundle.Templates {
+			a.settings.Templates[name] = text
+		}
+		if err := a.saveSettings(); err != nil {
+			return fmt.Errorf("failed to save settings: %v", err)
+		}
+	}
+
+	a.logf("Imported %d templates and %d shortcuts\n", len(bundle.Templates), len(bundle.Shortcuts))
+	return nil
+}
+
+// handleShortcutsAPI serves shortcut CRUD:
+// GET /api/shortcuts, POST /api/shortcuts, DELETE /api/shortcuts/{trigger}
+func (a *App) handleShortcutsAPI(w http.ResponseWriter, r *http.Request) {
+	trigger := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/shortcuts"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && trigger == "":
+		shortcuts, err := a.GetShortcuts()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		if shortcuts == nil {
+			shortcuts = []Shortcut{}
+		}
+		writeJSON(w, http.StatusOK, shortcuts)
+	case r.Method == http.MethodPost && trigger == "":
+		var req Shortcut
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		if err := a.SaveShortcut(req.Trigger, req.Expansion); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	case r.Method == http.MethodDelete && trigger != "":
+		if err := a.DeleteShortcut(trigger); err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleTemplateBundleAPI exports or imports templates and shortcuts:
+// GET /api/templates/export, POST /api/templates/import
+func (a *App) handleTemplateBundleAPI(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/templates/export":
+		data, err := a.ExportTemplateBundle()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="snaplog-templates.json"`)
+		io.WriteString(w, data)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/templates/import":
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundPayload))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "Failed to read request body")
+			return
+		}
+		if err := a.ImportTemplateBundle(string(body)); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusNotFound, codeNotFound, "Not found")
+	}
+}
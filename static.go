@@ -0,0 +1,74 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// staticAsset is a dashboard file served under a content-hashed name, so it can be
+// cached for good and a new build simply links to a new name
+type staticAsset struct {
+	contentType string
+	data        []byte
+}
+
+var staticAssets struct {
+	once  sync.Once
+	byURL map[string]staticAsset
+	urls  map[string]string
+}
+
+// loadStaticAssets hashes the logo and the files in templates/static
+func loadStaticAssets() {
+	staticAssets.byURL = make(map[string]staticAsset)
+	staticAssets.urls = make(map[string]string)
+
+	add := func(name, contentType string, data []byte) {
+		ext := path.Ext(name)
+		url := "/static/" + strings.TrimSuffix(name, ext) + "." + sha256Hex(data)[:12] + ext
+		staticAssets.byURL[url] = staticAsset{contentType: contentType, data: data}
+		staticAssets.urls[name] = url
+	}
+
+	if len(appIcon) > 0 {
+		add("logo.png", "image/png", appIcon)
+	}
+	for name, contentType := range map[string]string{
+		"layout.css":    "text/css; charset=utf-8",
+		"dashboard.css": "text/css; charset=utf-8",
+		"dashboard.js":  "application/javascript; charset=utf-8",
+		"trash.js":      "application/javascript; charset=utf-8",
+	} {
+		if data, err := readTemplate("static/" + name); err == nil {
+			add(name, contentType, data)
+		}
+	}
+}
+
+// assetURL returns the hashed URL of a static file, for use in templates as {{asset "layout.css"}}
+func assetURL(name string) string {
+	staticAssets.once.Do(loadStaticAssets)
+	return staticAssets.urls[name]
+}
+
+// logoURL is the hashed URL of the app icon used as favicon and header logo
+func logoURL() template.URL {
+	return template.URL(assetURL("logo.png"))
+}
+
+// serveStatic serves hashed static files with a one-year immutable cache lifetime
+func (a *App) serveStatic(w http.ResponseWriter, r *http.Request) {
+	staticAssets.once.Do(loadStaticAssets)
+	asset, ok := staticAssets.byURL[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", asset.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write(asset.data)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StreakStats describes the logging habit: consecutive days logged up to today, the
+// longest such run, and the days logged so far this month
+type StreakStats struct {
+	Current       int  `json:"current"`
+	Longest       int  `json:"longest"`
+	DaysThisMonth int  `json:"days_this_month"`
+	LoggedToday   bool `json:"logged_today"`
+}
+
+// getLoggedDays returns the set of local dates (YYYY-MM-DD) that have at least one entry,
+// in journal when it isn't empty
+func (a *App) getLoggedDays(journal string) (map[string]bool, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	query, args := `SELECT created_at FROM log_entries WHERE deleted_at IS NULL`, []interface{}{}
+	if journal != "" {
+		query, args = query+` AND `+journalCondition, append(args, journal)
+	}
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entry dates: %v", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]bool)
+	for rows.Next() {
+		var createdAt time.Time
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entry date: %v", err)
+		}
+		days[a.dayKey(createdAt)] = true
+	}
+
+	return days, nil
+}
+
+// calculateCurrentStreak counts consecutive logged days ending today.
+// A day without entries yet does not break the streak until it is over, and neither
+// do days off.
+func (a *App) calculateCurrentStreak() (int, error) {
+	days, err := a.getLoggedDays("")
+	if err != nil {
+		return 0, err
+	}
+	off, err := a.getDaysOff()
+	if err != nil {
+		return 0, err
+	}
+	return currentStreak(days, off, a.today()), nil
+}
+
+// currentStreak counts the logged days in the run ending today, stepping over days off
+// without entries
+func currentStreak(days, off map[string]bool, today time.Time) int {
+	day := today
+	if !days[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for {
+		date := day.Format("2006-01-02")
+		if days[date] {
+			streak++
+		} else if !off[date] {
+			break
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// longestStreak finds the longest run of consecutive logged days; days off between
+// logged days keep the run going
+func longestStreak(days, off map[string]bool) int {
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	longest, run := 0, 0
+	var previous time.Time
+	for _, date := range dates {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			continue
+		}
+		next := previous.AddDate(0, 0, 1)
+		for run > 0 && off[next.Format("2006-01-02")] && next.Before(day) {
+			next = next.AddDate(0, 0, 1)
+		}
+		if run > 0 && next.Format("2006-01-02") == date {
+			run++
+		} else {
+			run = 1
+		}
+		previous = day
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// GetStreakStats returns the current and longest streaks and the days logged this month
+func (a *App) GetStreakStats() (*StreakStats, error) {
+	return a.streakStats("")
+}
+
+// streakStats computes the streak stats over every entry, or over the entries of journal
+// when it isn't empty
+func (a *App) streakStats(journal string) (*StreakStats, error) {
+	days, err := a.getLoggedDays(journal)
+	if err != nil {
+		return nil, err
+	}
+
+	off, err := a.getDaysOff()
+	if err != nil {
+		return nil, err
+	}
+
+	today := a.today()
+	stats := &StreakStats{
+		Current:     currentStreak(days, off, today),
+		Longest:     longestStreak(days, off),
+		LoggedToday: days[today.Format("2006-01-02")],
+	}
+	month := today.Format("2006-01")
+	for date := range days {
+		if date[:7] == month {
+			stats.DaysThisMonth++
+		}
+	}
+	return stats, nil
+}
+
+func (a *App) countEntriesToday() (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+
+	dayStart := a.dayStart(a.today())
+
+	var count int
+	query := `SELECT COUNT(*) FROM log_entries WHERE created_at >= ? AND deleted_at IS NULL`
+	if err := a.db.QueryRow(query, sqlTime(dayStart)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count today's entries: %v", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func dateSet(dates ...string) map[string]bool {
+	set := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		set[date] = true
+	}
+	return set
+}
+
+func TestCurrentStreak(t *testing.T) {
+	today := time.Date(2026, time.March, 6, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		days []string
+		off  []string
+		want int
+	}{
+		{"nothing logged", nil, nil, 0},
+		{"logged today", []string{"2026-03-04", "2026-03-05", "2026-03-06"}, nil, 3},
+		{"today not logged yet", []string{"2026-03-04", "2026-03-05"}, nil, 2},
+		{"gap breaks the run", []string{"2026-03-02", "2026-03-04", "2026-03-05"}, nil, 2},
+		{"days off bridge the gap", []string{"2026-03-02", "2026-03-05", "2026-03-06"}, []string{"2026-03-03", "2026-03-04"}, 3},
+		{"missed yesterday", []string{"2026-03-03", "2026-03-04"}, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := currentStreak(dateSet(tt.days...), dateSet(tt.off...), today); got != tt.want {
+			t.Errorf("%s: currentStreak = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLongestStreak(t *testing.T) {
+	tests := []struct {
+		name string
+		days []string
+		off  []string
+		want int
+	}{
+		{"nothing logged", nil, nil, 0},
+		{"longest run in the past", []string{"2026-02-01", "2026-02-02", "2026-02-03", "2026-03-01"}, nil, 3},
+		{"across a month end", []string{"2026-02-27", "2026-02-28", "2026-03-01", "2026-03-02"}, nil, 4},
+		{"day off keeps the run", []string{"2026-03-01", "2026-03-03"}, []string{"2026-03-02"}, 2},
+	}
+	for _, tt := range tests {
+		if got := longestStreak(dateSet(tt.days...), dateSet(tt.off...)); got != tt.want {
+			t.Errorf("%s: longestStreak = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStreakStatsFollowDayStartHour(t *testing.T) {
+	// 01:30 on Wednesday is still Tuesday when the day starts at 4am
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 4, 1, 30, 0, 0, time.Local))
+	a.settings.DayStartHour = 4
+
+	for _, at := range []time.Time{
+		time.Date(2026, time.March, 2, 10, 0, 0, 0, time.Local),
+		time.Date(2026, time.March, 3, 3, 0, 0, 0, time.Local), // Monday evening
+		time.Date(2026, time.March, 4, 1, 0, 0, 0, time.Local),
+	} {
+		if _, err := a.logEntryAt("worked late", sourceHotkey, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := a.GetStreakStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Current != 2 || stats.Longest != 2 || !stats.LoggedToday || stats.DaysThisMonth != 2 {
+		t.Errorf("got %+v, want a current and longest streak of 2 logged today, 2 days this month", *stats)
+	}
+}
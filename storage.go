@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxEntries       = 50000
+	defaultMaxDatabaseMB    = 500
+	defaultMaxAttachmentsMB = 1000
+	storageCheckInterval    = time.Minute
+)
+
+// StorageSettings are the thresholds above which SnapLog warns about storage use.
+// Zero uses the default, negative disables the warning.
+type StorageSettings struct {
+	MaxEntries       int `json:"max_entries"`
+	MaxDatabaseMB    int `json:"max_database_mb"`
+	MaxAttachmentsMB int `json:"max_attachments_mb"`
+}
+
+// StorageWarning is a threshold that has been crossed, with what the user can do about it
+type StorageWarning struct {
+	Kind        string   `json:"kind"`
+	Message     string   `json:"message"`
+	Value       int64    `json:"value"`
+	Limit       int64    `json:"limit"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// StorageStats is returned by GetStorageStats for the settings UI
+type StorageStats struct {
+	EntryCount       int              `json:"entry_count"`
+	DatabaseBytes    int64            `json:"database_bytes"`
+	ReclaimableBytes int64            `json:"reclaimable_bytes"`
+	AttachmentsBytes int64            `json:"attachments_bytes"`
+	BackupsBytes     int64            `json:"backups_bytes"`
+	Warnings         []StorageWarning `json:"warnings"`
+	CheckedAt        time.Time        `json:"checked_at"`
+}
+
+// storageMonitor remembers which warnings were already notified, so crossing a threshold
+// notifies once rather than on every entry
+type storageMonitor struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	notified  map[string]bool
+}
+
+func storageLimit(value, fallback int) int64 {
+	if value == 0 {
+		return int64(fallback)
+	}
+	return int64(value)
+}
+
+// dirSize adds up the sizes of the regular files below dir; a missing dir is empty
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func formatMB(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
+
+// GetStorageStats reports entry count and disk use of the database, attachments and
+// backups, with a warning for every threshold in settings that has been crossed
+func (a *App) GetStorageStats() (*StorageStats, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	stats := &StorageStats{CheckedAt: a.clock.Now()}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM log_entries WHERE deleted_at IS NULL`).Scan(&stats.EntryCount); err != nil {
+		return nil, fmt.Errorf("failed to count log entries: %v", err)
+	}
+
+	// an unlocked encrypted database runs in memory; on disk it is the one sealed file
+	files := []string{"", "-wal", "-shm"}
+	if a.vault.unlocked() {
+		files = []string{encryptedDatabaseSuffix}
+	}
+	if dbPath, err := plainDatabasePath(); err == nil {
+		for _, suffix := range files {
+			if info, err := os.Stat(dbPath + suffix); err == nil {
+				stats.DatabaseBytes += info.Size()
+			}
+		}
+	}
+	var pageSize, freePages int64
+	a.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize)
+	a.db.QueryRow(`PRAGMA freelist_count`).Scan(&freePages)
+	stats.ReclaimableBytes = pageSize * freePages
+
+	if dir, err := snaplogDataDir(); err == nil {
+		stats.AttachmentsBytes = dirSize(filepath.Join(dir, "attachments"))
+		stats.BackupsBytes = dirSize(filepath.Join(dir, "backups"))
+	}
+
+	limits := a.settings.Storage
+	if limit := storageLimit(limits.MaxEntries, defaultMaxEntries); limit > 0 && int64(stats.EntryCount) >= limit {
+		stats.Warnings = append(stats.Warnings, StorageWarning{
+			Kind:    "entries",
+			Message: fmt.Sprintf("%d entries logged (warning at %d)", stats.EntryCount, limit),
+			Value:   int64(stats.EntryCount),
+			Limit:   limit,
+			Suggestions: []string{
+				"Export older entries from the dashboard and delete them",
+				"Raise storage.max_entries in settings.json if the dashboard is still fast",
+			},
+		})
+	}
+	if limit := storageLimit(limits.MaxDatabaseMB, defaultMaxDatabaseMB) << 20; limit > 0 && stats.DatabaseBytes >= limit {
+		suggestions := []string{"Export older entries from the dashboard and delete them"}
+		if stats.ReclaimableBytes > 0 {
+			suggestions = append([]string{fmt.Sprintf("Compact the database to reclaim %s", formatMB(stats.ReclaimableBytes))}, suggestions...)
+		}
+		stats.Warnings = append(stats.Warnings, StorageWarning{
+			Kind:        "database",
+			Message:     fmt.Sprintf("Database uses %s (warning at %s)", formatMB(stats.DatabaseBytes), formatMB(limit)),
+			Value:       stats.DatabaseBytes,
+			Limit:       limit,
+			Suggestions: suggestions,
+		})
+	}
+	if limit := storageLimit(limits.MaxAttachmentsMB, defaultMaxAttachmentsMB) << 20; limit > 0 && stats.AttachmentsBytes >= limit {
+		stats.Warnings = append(stats.Warnings, StorageWarning{
+			Kind:        "attachments",
+			Message:     fmt.Sprintf("Attachments use %s (warning at %s)", formatMB(stats.AttachmentsBytes), formatMB(limit)),
+			Value:       stats.AttachmentsBytes,
+			Limit:       limit,
+			Suggestions: []string{"Move large attachments out of the attachments folder"},
+		})
+	}
+
+	return stats, nil
+}
+
+// CompactDatabase rebuilds the database file to return the space of deleted entries to the disk
+func (a *App) CompactDatabase() (*StorageStats, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	if _, err := a.db.Exec(`VACUUM`); err != nil {
+		return nil, fmt.Errorf("failed to compact database: %v", err)
+	}
+	if _, err := a.db.Exec(`PRAGMA optimize`); err != nil {
+		a.logf("Warning: failed to optimize database: %v\n", err)
+	}
+	a.logf("Database compacted\n")
+	return a.GetStorageStats()
+}
+
+// checkStorageQuota notifies once when a storage threshold is crossed. It runs at most
+// once a minute, since entries can arrive in bursts.
+func (a *App) checkStorageQuota() {
+	a.storage.mu.Lock()
+	if a.clock.Now().Sub(a.storage.lastCheck) < storageCheckInterval {
+		a.storage.mu.Unlock()
+		return
+	}
+	a.storage.lastCheck = a.clock.Now()
+	a.storage.mu.Unlock()
+
+	stats, err := a.GetStorageStats()
+	if err != nil {
+		a.logf("Warning: failed to check storage use: %v\n", err)
+		return
+	}
+
+	a.storage.mu.Lock()
+	defer a.storage.mu.Unlock()
+	if a.storage.notified == nil {
+		a.storage.notified = make(map[string]bool)
+	}
+	crossed := make(map[string]bool)
+	for _, warning := range stats.Warnings {
+		crossed[warning.Kind] = true
+		if a.storage.notified[warning.Kind] {
+			continue
+		}
+		a.logf("Storage warning: %s\n", warning.Message)
+		a.notify("SnapLog storage", warning.Message+". "+warning.Suggestions[0]+".", priorityNormal)
+	}
+	// a warning that cleared notifies again if it is crossed later
+	a.storage.notified = crossed
+}
@@ -0,0 +1,836 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	syncOpUpsert = "upsert"
+	syncOpDelete = "delete"
+
+	defaultSyncIntervalMinutes = 5
+	maxSyncBatch               = 500
+)
+
+// Conflict resolutions accepted by ResolveConflict
+const (
+	syncKeepLocal  = "local"
+	syncKeepRemote = "remote"
+)
+
+// syncBatchPattern matches change batch files: sync-<device>-<seq>.json
+var syncBatchPattern = regexp.MustCompile(`^sync-([0-9a-f]+)-([0-9]+)\.json$`)
+
+// SyncSettings configures sync between devices through a shared remote folder or bucket
+type SyncSettings struct {
+	Enabled         bool         `json:"enabled"`
+	IntervalMinutes int          `json:"interval_minutes"`
+	Target          BackupTarget `json:"target"`
+	// Passphrase enables end-to-end encryption of everything written to the target
+	Passphrase string `json:"passphrase"`
+	// DeviceName is shown to other devices; it defaults to the host name
+	DeviceName string `json:"device_name"`
+	// LocalOnlyTags marks entries that never leave this device, e.g. ["work", "private"]
+	LocalOnlyTags []string `json:"local_only_tags"`
+	// LocalOnlyJournals does the same for every entry of a journal, e.g. ["work"]
+	LocalOnlyJournals []string `json:"local_only_journals"`
+}
+
+// SyncStatus is shown in settings and reported by /api/health
+type SyncStatus struct {
+	Enabled    bool       `json:"enabled"`
+	Encrypted  bool       `json:"encrypted"`
+	DeviceID   string     `json:"device_id"`
+	LastSync   *time.Time `json:"last_sync"`
+	LastError  string     `json:"last_error"`
+	PendingOps int        `json:"pending_ops"`
+	Conflicts  int        `json:"conflicts"`
+	Peers      int        `json:"peers"`
+}
+
+// SyncConflict is an entry changed on this device and another one since they last synced
+type SyncConflict struct {
+	ID            int64     `json:"id"`
+	EntryID       int       `json:"entry_id"`
+	LocalContent  string    `json:"local_content"`
+	RemoteContent string    `json:"remote_content"`
+	RemoteDeleted bool      `json:"remote_deleted"`
+	RemoteDevice  string    `json:"remote_device"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// syncChange is the state of one entry as sent to other devices. BaseHash is the hash of
+// the last version the sender received, which tells the receiver whether it was edited on top of it.
+type syncChange struct {
+	UID         string    `json:"uid"`
+	Op          string    `json:"op"`
+	Content     string    `json:"content,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Source      string    `json:"source,omitempty"`
+	RepeatCount int       `json:"repeat_count,omitempty"`
+	BaseHash    string    `json:"base_hash,omitempty"`
+	// Journal is empty from devices that predate journals
+	Journal string `json:"journal,omitempty"`
+}
+
+type syncBatch struct {
+	Device    string       `json:"device"`
+	Seq       int64        `json:"seq"`
+	CreatedAt time.Time    `json:"created_at"`
+	Changes   []syncChange `json:"changes"`
+}
+
+func (a *App) createSyncTables() error {
+	if _, err := a.addColumnIfMissing("log_entries", "uid", "TEXT"); err != nil {
+		return err
+	}
+
+	createSyncSQL := `
+	CREATE TABLE IF NOT EXISTS sync_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS sync_outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_uid TEXT NOT NULL,
+		op TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS sync_entries (
+		entry_uid TEXT PRIMARY KEY,
+		synced_hash TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS sync_peers (
+		device_id TEXT PRIMARY KEY,
+		last_seq INTEGER NOT NULL DEFAULT 0,
+		last_seen DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS sync_conflicts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_uid TEXT NOT NULL,
+		local_content TEXT NOT NULL,
+		remote_content TEXT NOT NULL,
+		remote_deleted INTEGER NOT NULL DEFAULT 0,
+		remote_device TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		resolved_at DATETIME,
+		resolution TEXT
+	);`
+
+	if _, err := a.db.Exec(createSyncSQL); err != nil {
+		return fmt.Errorf("failed to create sync tables: %v", err)
+	}
+	for _, column := range []string{"name", "fingerprint"} {
+		if _, err := a.addColumnIfMissing("sync_peers", column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	if _, err := a.addColumnIfMissing("sync_peers", "revoked_at", "DATETIME"); err != nil {
+		return err
+	}
+	// the agreed content is the base for merging concurrent edits
+	if _, err := a.addColumnIfMissing("sync_entries", "synced_content", "TEXT"); err != nil {
+		return err
+	}
+
+	if _, err := a.db.Exec(`UPDATE log_entries SET uid = lower(hex(randomblob(16))) WHERE uid IS NULL`); err != nil {
+		return fmt.Errorf("failed to assign entry uids: %v", err)
+	}
+
+	// Triggers catch every write path. Changes are only queued once sync has started
+	// tracking, so the outbox stays empty for people who never enable sync. The update
+	// trigger is recreated so databases from before journals also queue moved entries.
+	createTriggersSQL := `
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_log_entries_uid ON log_entries(uid);
+	CREATE TRIGGER IF NOT EXISTS sync_log_entries_insert AFTER INSERT ON log_entries BEGIN
+		UPDATE log_entries SET uid = lower(hex(randomblob(16))) WHERE id = NEW.id AND uid IS NULL;
+		INSERT INTO sync_outbox (entry_uid, op)
+			SELECT uid, 'upsert' FROM log_entries
+			WHERE id = NEW.id AND EXISTS (SELECT 1 FROM sync_state WHERE key = 'tracking');
+	END;
+	DROP TRIGGER IF EXISTS sync_log_entries_update;
+	CREATE TRIGGER sync_log_entries_update AFTER UPDATE OF content, repeat_count, journal_id ON log_entries
+	WHEN EXISTS (SELECT 1 FROM sync_state WHERE key = 'tracking') BEGIN
+		INSERT INTO sync_outbox (entry_uid, op) VALUES (NEW.uid, 'upsert');
+	END;
+	CREATE TRIGGER IF NOT EXISTS sync_log_entries_delete AFTER DELETE ON log_entries
+	WHEN EXISTS (SELECT 1 FROM sync_state WHERE key = 'tracking') BEGIN
+		INSERT INTO sync_outbox (entry_uid, op) VALUES (OLD.uid, 'delete');
+	END;
+	CREATE TRIGGER IF NOT EXISTS sync_log_entries_trash AFTER UPDATE OF deleted_at ON log_entries
+	WHEN EXISTS (SELECT 1 FROM sync_state WHERE key = 'tracking') BEGIN
+		INSERT INTO sync_outbox (entry_uid, op)
+			VALUES (NEW.uid, CASE WHEN NEW.deleted_at IS NULL THEN 'upsert' ELSE 'delete' END);
+	END;`
+
+	if _, err := a.db.Exec(createTriggersSQL); err != nil {
+		return fmt.Errorf("failed to create sync triggers: %v", err)
+	}
+	return nil
+}
+
+func (a *App) syncState(key string) string {
+	var value string
+	a.db.QueryRow(`SELECT value FROM sync_state WHERE key = ?`, key).Scan(&value)
+	return value
+}
+
+func (a *App) setSyncState(key, value string) error {
+	_, err := a.db.Exec(`INSERT INTO sync_state (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %v", err)
+	}
+	return nil
+}
+
+// syncDeviceID returns this installation's random device id, creating it on first use
+func (a *App) syncDeviceID() (string, error) {
+	if id := a.syncState("device_id"); id != "" {
+		return id, nil
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate device id: %v", err)
+	}
+	id := hex.EncodeToString(buf)
+	return id, a.setSyncState("device_id", id)
+}
+
+// startSyncTracking queues every existing entry the first time sync runs
+func (a *App) startSyncTracking() error {
+	if a.syncState("tracking") != "" {
+		return nil
+	}
+	if _, err := a.db.Exec(`INSERT INTO sync_outbox (entry_uid, op) SELECT uid, 'upsert' FROM log_entries WHERE deleted_at IS NULL ORDER BY id`); err != nil {
+		return fmt.Errorf("failed to queue entries for sync: %v", err)
+	}
+	return a.setSyncState("tracking", "1")
+}
+
+// runSyncLoop syncs every IntervalMinutes while sync is enabled
+func (a *App) runSyncLoop() {
+	ticks, stop := a.clock.Ticker(time.Minute)
+	defer stop()
+
+	var last time.Time
+	for now := range ticks {
+		cfg := a.settings.Sync
+		if !cfg.Enabled {
+			continue
+		}
+		interval := cfg.IntervalMinutes
+		if interval <= 0 {
+			interval = defaultSyncIntervalMinutes
+		}
+		if now.Sub(last) < time.Duration(interval)*time.Minute {
+			continue
+		}
+		last = now
+		if err := a.SyncNow(); err != nil {
+			a.logf("Sync failed: %v\n", err)
+		}
+	}
+}
+
+// SyncNow pushes local changes and applies changes from other devices
+func (a *App) SyncNow() error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	if !a.settings.Sync.Enabled {
+		return fmt.Errorf("sync is not enabled")
+	}
+
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	err := a.syncOnce()
+	message := ""
+	if err != nil {
+		message = err.Error()
+	} else {
+		a.setSyncState("last_sync", a.clock.Now().UTC().Format(time.RFC3339))
+	}
+	a.setSyncState("last_error", message)
+	return err
+}
+
+func (a *App) syncOnce() error {
+	store, err := newRemoteStore(a.settings.Sync.Target)
+	if err != nil {
+		return err
+	}
+	device, err := a.syncDeviceID()
+	if err != nil {
+		return err
+	}
+	if err := a.startSyncTracking(); err != nil {
+		return err
+	}
+	session, err := a.openSyncSession(store, device)
+	if err != nil {
+		return err
+	}
+	if err := a.exchangeRevocations(session); err != nil {
+		return err
+	}
+	if err := a.publishDevice(session); err != nil {
+		return err
+	}
+	if err := a.refreshDevices(session); err != nil {
+		return err
+	}
+
+	for {
+		pushed, err := a.pushChanges(session)
+		if err != nil {
+			return err
+		}
+		if pushed < maxSyncBatch {
+			break
+		}
+	}
+	return a.pullChanges(session)
+}
+
+// localOnly reports whether an entry is in one of the LocalOnlyJournals or its content
+// carries one of the LocalOnlyTags
+func (a *App) localOnly(content, journal string) bool {
+	for _, name := range a.settings.Sync.LocalOnlyJournals {
+		if strings.EqualFold(strings.TrimSpace(name), journal) {
+			return true
+		}
+	}
+	if len(a.settings.Sync.LocalOnlyTags) == 0 {
+		return false
+	}
+
+	// tags are resolved the way the entry is filed, so with wrk aliased to work, #wrk is
+	// local-only when either of them is
+	aliases := a.tagAliases()
+	local := make(map[string]bool)
+	for _, tag := range a.settings.Sync.LocalOnlyTags {
+		tag = canonicalTag(tag)
+		local[tag] = true
+		if target, ok := aliases[tag]; ok {
+			local[target] = true
+		}
+	}
+	for _, tag := range spanTags(a.findTagSpans(content)) {
+		if local[tag] {
+			return true
+		}
+	}
+	// a blacklisted name isn't filed as a tag but still marks the entry
+	for _, name := range extractTagNames(content) {
+		if local[canonicalTag(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+func contentHash(content string) string {
+	return sha256Hex([]byte(content))[:32]
+}
+
+// pushChanges uploads up to maxSyncBatch queued entries as one batch file, leaving out
+// local-only entries. A local-only entry is sent as a delete instead, so the copies on
+// other devices go when an entry that was synced becomes local-only.
+func (a *App) pushChanges(s *syncSession) (int, error) {
+	// the latest queued op per entry wins; SQLite returns the row holding MAX(id)
+	rows, err := a.db.Query(`SELECT MAX(id), entry_uid, op FROM sync_outbox
+		GROUP BY entry_uid ORDER BY MAX(id) LIMIT ?`, maxSyncBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sync outbox: %v", err)
+	}
+	type queued struct {
+		id  int64
+		uid string
+		op  string
+	}
+	var ops []queued
+	for rows.Next() {
+		var q queued
+		if err := rows.Scan(&q.id, &q.uid, &q.op); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan sync outbox: %v", err)
+		}
+		ops = append(ops, q)
+	}
+	rows.Close()
+	if len(ops) == 0 {
+		return 0, nil
+	}
+
+	batch := syncBatch{Device: s.device, CreatedAt: a.clock.Now().UTC()}
+	skipped := 0
+	var withdrawn []string
+	for _, q := range ops {
+		change := syncChange{UID: q.uid, Op: syncOpDelete}
+		a.db.QueryRow(`SELECT synced_hash FROM sync_entries WHERE entry_uid = ?`, q.uid).Scan(&change.BaseHash)
+		if q.op == syncOpUpsert {
+			err := a.db.QueryRow(`SELECT content, created_at, source, repeat_count,
+				COALESCE((SELECT name FROM journals WHERE journals.id = journal_id), '')
+				FROM log_entries WHERE uid = ? AND deleted_at IS NULL`, q.uid).
+				Scan(&change.Content, &change.CreatedAt, &change.Source, &change.RepeatCount, &change.Journal)
+			if err == nil {
+				change.Op = syncOpUpsert
+			} else if err != sql.ErrNoRows {
+				return 0, fmt.Errorf("failed to read entry for sync: %v", err)
+			}
+			// This device doesn't record what it pushed, so a local-only entry is always
+			// sent as a delete, which carries only its uid. Devices that never had it
+			// ignore it.
+			if change.Op == syncOpUpsert && a.localOnly(change.Content, change.Journal) {
+				skipped++
+				change = syncChange{UID: q.uid, Op: syncOpDelete, BaseHash: change.BaseHash}
+				withdrawn = append(withdrawn, q.uid)
+			}
+		}
+		batch.Changes = append(batch.Changes, change)
+	}
+
+	if len(batch.Changes) > 0 {
+		seq, _ := strconv.ParseInt(a.syncState("seq"), 10, 64)
+		batch.Seq = seq + 1
+		data, err := s.encode(batch)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.store.upload(fmt.Sprintf("sync-%s-%012d.json", s.device, batch.Seq), data); err != nil {
+			return 0, fmt.Errorf("failed to upload sync batch: %v", err)
+		}
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin sync transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Only rows already read are cleared; an entry edited meanwhile stays queued.
+	// synced_hash is not advanced here: it stays the last version both sides agreed on.
+	for _, q := range ops {
+		if _, err := tx.Exec(`DELETE FROM sync_outbox WHERE entry_uid = ? AND id <= ?`, q.uid, q.id); err != nil {
+			return 0, fmt.Errorf("failed to clear sync outbox: %v", err)
+		}
+	}
+	// a withdrawn entry is new to other devices if it stops being local-only
+	for _, uid := range withdrawn {
+		if _, err := tx.Exec(`DELETE FROM sync_entries WHERE entry_uid = ?`, uid); err != nil {
+			return 0, fmt.Errorf("failed to forget withdrawn entry: %v", err)
+		}
+	}
+	if batch.Seq > 0 {
+		if _, err := tx.Exec(`INSERT INTO sync_state (key, value) VALUES ('seq', ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value`, strconv.FormatInt(batch.Seq, 10)); err != nil {
+			return 0, fmt.Errorf("failed to save sync sequence: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sync transaction: %v", err)
+	}
+
+	if batch.Seq > 0 {
+		a.logf("Sync pushed %d changes (batch %d)\n", len(batch.Changes), batch.Seq)
+	}
+	if skipped > 0 {
+		a.logf("Sync kept %d local-only entries on this device\n", skipped)
+	}
+	return len(ops), nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func setSyncedHash(db execer, change syncChange) error {
+	var err error
+	if change.Op == syncOpDelete {
+		_, err = db.Exec(`DELETE FROM sync_entries WHERE entry_uid = ?`, change.UID)
+	} else {
+		_, err = db.Exec(`INSERT INTO sync_entries (entry_uid, synced_hash, synced_content) VALUES (?, ?, ?)
+			ON CONFLICT(entry_uid) DO UPDATE SET synced_hash = excluded.synced_hash, synced_content = excluded.synced_content`,
+			change.UID, contentHash(change.Content), change.Content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record synced state: %v", err)
+	}
+	return nil
+}
+
+// pullChanges downloads and applies batches from other devices newer than the last seen ones
+func (a *App) pullChanges(s *syncSession) error {
+	names, err := s.store.list()
+	if err != nil {
+		return fmt.Errorf("failed to list sync batches: %v", err)
+	}
+
+	type pending struct {
+		name   string
+		device string
+		seq    int64
+	}
+	revoked, err := a.revokedDevices()
+	if err != nil {
+		return err
+	}
+
+	var batches []pending
+	lastSeq := make(map[string]int64)
+	for _, name := range names {
+		m := syncBatchPattern.FindStringSubmatch(name)
+		if m == nil || m[1] == s.device || revoked[m[1]] {
+			continue
+		}
+		if _, ok := lastSeq[m[1]]; !ok {
+			var seq int64
+			a.db.QueryRow(`SELECT last_seq FROM sync_peers WHERE device_id = ?`, m[1]).Scan(&seq)
+			lastSeq[m[1]] = seq
+		}
+		seq, _ := strconv.ParseInt(m[2], 10, 64)
+		if seq > lastSeq[m[1]] {
+			batches = append(batches, pending{name: name, device: m[1], seq: seq})
+		}
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		if batches[i].device != batches[j].device {
+			return batches[i].device < batches[j].device
+		}
+		return batches[i].seq < batches[j].seq
+	})
+
+	for _, p := range batches {
+		data, err := s.store.download(p.name)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %v", p.name, err)
+		}
+		batch, err := s.decode(p.device, p.seq, data)
+		if err != nil {
+			return err
+		}
+		for _, change := range batch.Changes {
+			if err := a.applySyncChange(change, p.device); err != nil {
+				return err
+			}
+		}
+		if _, err := a.db.Exec(`INSERT INTO sync_peers (device_id, last_seq) VALUES (?, ?)
+			ON CONFLICT(device_id) DO UPDATE SET last_seq = excluded.last_seq`, p.device, p.seq); err != nil {
+			return fmt.Errorf("failed to record sync progress: %v", err)
+		}
+		a.logf("Sync applied %d changes from device %s (batch %d)\n", len(batch.Changes), p.device, p.seq)
+	}
+	return nil
+}
+
+// applySyncChange applies a change from another device. When this device also changed the
+// entry since they last synced, the two edits are merged field by field; only edits that
+// overlap are stored as a conflict.
+func (a *App) applySyncChange(change syncChange, remoteDevice string) error {
+	var localID, localRepeat int
+	var localContent, synced string
+	var trashed bool
+	err := a.db.QueryRow(`SELECT id, content, repeat_count, deleted_at IS NOT NULL FROM log_entries WHERE uid = ?`, change.UID).
+		Scan(&localID, &localContent, &localRepeat, &trashed)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up synced entry: %v", err)
+	}
+	a.db.QueryRow(`SELECT synced_hash FROM sync_entries WHERE entry_uid = ?`, change.UID).Scan(&synced)
+
+	var before int64
+	a.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM sync_outbox`).Scan(&before)
+
+	resend := false
+	switch {
+	case (!exists || trashed) && change.Op == syncOpDelete:
+		// already gone here, or in the trash
+	case !exists:
+		// new on the other device, or deleted here while edited there: keep the edit
+		if err := a.insertSyncedEntry(change); err != nil {
+			return err
+		}
+	case trashed:
+		// trashed here, or withdrawn by a device where it was local-only, and edited or
+		// shared again there: keep the edit
+		if err := a.RestoreEntry(localID); err != nil {
+			return err
+		}
+		if err := a.replaceSyncedContent(localID, change); err != nil {
+			return err
+		}
+	case localContent == change.Content && change.Op == syncOpUpsert:
+		// the text agrees; repeat counts only grow, so the larger one wins
+		if change.RepeatCount > localRepeat {
+			if _, err := a.db.Exec(`UPDATE log_entries SET repeat_count = ? WHERE id = ?`, change.RepeatCount, localID); err != nil {
+				return fmt.Errorf("failed to update synced entry: %v", err)
+			}
+		}
+	case contentHash(localContent) == synced || contentHash(localContent) == change.BaseHash:
+		if change.Op == syncOpDelete {
+			if err := a.DeleteEntry(localID); err != nil {
+				return err
+			}
+		} else if err := a.replaceSyncedContent(localID, change); err != nil {
+			return err
+		}
+	default:
+		merged, ok := "", false
+		if base, found := a.syncBase(localID, change, synced); found && change.Op == syncOpUpsert {
+			merged, ok = mergeSyncContent(base, localContent, change.Content)
+		}
+		if !ok {
+			_, err := a.db.Exec(`INSERT INTO sync_conflicts (entry_uid, local_content, remote_content, remote_deleted, remote_device)
+				VALUES (?, ?, ?, ?, ?)`, change.UID, localContent, change.Content, change.Op == syncOpDelete, remoteDevice)
+			if err != nil {
+				return fmt.Errorf("failed to record sync conflict: %v", err)
+			}
+			a.logf("Sync conflict on entry %d with device %s\n", localID, remoteDevice)
+			return nil
+		}
+
+		remote := change
+		remote.Content = merged
+		if err := a.replaceSyncedContent(localID, remote); err != nil {
+			return err
+		}
+		// the other device still has its own version and needs the merge
+		resend = merged != change.Content
+		a.logf("Sync merged concurrent edits of entry %d with device %s\n", localID, remoteDevice)
+	}
+
+	// the journal follows the other device, e.g. after MoveEntry there
+	if change.Op == syncOpUpsert && change.Journal != "" {
+		if err := a.setSyncedJournal(change.UID, change.Journal); err != nil {
+			return err
+		}
+	}
+
+	// changes made by applying a remote change must not be echoed back
+	if _, err := a.db.Exec(`DELETE FROM sync_outbox WHERE entry_uid = ? AND id > ?`, change.UID, before); err != nil {
+		return fmt.Errorf("failed to clear sync outbox: %v", err)
+	}
+	if resend {
+		if _, err := a.db.Exec(`INSERT INTO sync_outbox (entry_uid, op) VALUES (?, ?)`, change.UID, syncOpUpsert); err != nil {
+			return fmt.Errorf("failed to queue merged entry: %v", err)
+		}
+	}
+	// the other device has settled an earlier conflict on this entry
+	if _, err := a.db.Exec(`UPDATE sync_conflicts SET resolved_at = CURRENT_TIMESTAMP, resolution = ?
+		WHERE entry_uid = ? AND resolved_at IS NULL`, syncKeepRemote, change.UID); err != nil {
+		return fmt.Errorf("failed to close sync conflicts: %v", err)
+	}
+	return setSyncedHash(a.db, change)
+}
+
+// syncBase finds the common ancestor of a local and a remote edit: the version the other
+// device last received, looked up among the synced content and this entry's history
+func (a *App) syncBase(localID int, change syncChange, synced string) (string, bool) {
+	var content sql.NullString
+	a.db.QueryRow(`SELECT synced_content FROM sync_entries WHERE entry_uid = ?`, change.UID).Scan(&content)
+	if content.Valid && (change.BaseHash == "" || change.BaseHash == synced) {
+		return content.String, true
+	}
+	if change.BaseHash == "" {
+		return "", false
+	}
+
+	rows, err := a.db.Query(`SELECT content FROM entry_history WHERE log_entry_id = ? ORDER BY id DESC`, localID)
+	if err != nil {
+		return "", false
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var revision string
+		if rows.Scan(&revision) == nil && contentHash(revision) == change.BaseHash {
+			return revision, true
+		}
+	}
+	return "", false
+}
+
+func (a *App) insertSyncedEntry(change syncChange) error {
+	if change.Source == "" {
+		change.Source = sourceHotkey
+	}
+	if change.RepeatCount < 1 {
+		change.RepeatCount = 1
+	}
+	result, err := a.db.Exec(`INSERT INTO log_entries (content, created_at, source, repeat_count, uid) VALUES (?, ?, ?, ?, ?)`,
+		change.Content, sqlTime(change.CreatedAt), change.Source, change.RepeatCount, change.UID)
+	if err != nil {
+		return fmt.Errorf("failed to insert synced entry: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get synced entry id: %v", err)
+	}
+	a.processEntryContent(context.Background(), id, change.Content)
+	return nil
+}
+
+func (a *App) replaceSyncedContent(id int, change syncChange) error {
+	previous, err := a.GetEntryByID(id)
+	if err != nil {
+		return err
+	}
+	if err := a.recordEntryRevision(context.Background(), id, previous.Content); err != nil {
+		return err
+	}
+	if change.RepeatCount < previous.RepeatCount {
+		change.RepeatCount = previous.RepeatCount
+	}
+	if _, err := a.db.Exec(`UPDATE log_entries SET content = ?, repeat_count = ? WHERE id = ?`, change.Content, change.RepeatCount, id); err != nil {
+		return fmt.Errorf("failed to update synced entry: %v", err)
+	}
+	a.processEntryContent(context.Background(), int64(id), change.Content)
+	return nil
+}
+
+// processEntryContent runs the tag, task and metadata pipeline of a local edit on an
+// entry whose text was written some other way, such as by sync, a merge or a split
+func (a *App) processEntryContent(ctx context.Context, id int64, content string) {
+	if err := a.processTags(ctx, id, content); err != nil {
+		a.logf("Warning: failed to process tags for entry %d: %v\n", id, err)
+	}
+	if err := a.syncTasks(ctx, id, content); err != nil {
+		a.logf("Warning: failed to process tasks for entry %d: %v\n", id, err)
+	}
+	if err := a.updateEntryMetadata(ctx, id, content); err != nil {
+		a.logf("Warning: failed to process metadata for entry %d: %v\n", id, err)
+	}
+}
+
+// GetSyncStatus reports the last sync, queued changes, the last error and open conflicts
+func (a *App) GetSyncStatus() (*SyncStatus, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	status := &SyncStatus{
+		Enabled:   a.settings.Sync.Enabled,
+		Encrypted: a.settings.Sync.Passphrase != "",
+		DeviceID:  a.syncState("device_id"),
+		LastError: a.syncState("last_error"),
+	}
+	if last, err := time.Parse(time.RFC3339, a.syncState("last_sync")); err == nil {
+		status.LastSync = &last
+	}
+	if err := a.db.QueryRow(`SELECT COUNT(DISTINCT entry_uid) FROM sync_outbox`).Scan(&status.PendingOps); err != nil {
+		return nil, fmt.Errorf("failed to count pending sync changes: %v", err)
+	}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM sync_conflicts WHERE resolved_at IS NULL`).Scan(&status.Conflicts); err != nil {
+		return nil, fmt.Errorf("failed to count sync conflicts: %v", err)
+	}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM sync_peers WHERE revoked_at IS NULL`).Scan(&status.Peers); err != nil {
+		return nil, fmt.Errorf("failed to count sync peers: %v", err)
+	}
+	return status, nil
+}
+
+// syncHealth summarizes sync for /api/health
+func (a *App) syncHealth() string {
+	status, err := a.GetSyncStatus()
+	switch {
+	case err != nil:
+		return err.Error()
+	case !status.Enabled:
+		return "not configured"
+	case status.LastError != "":
+		return "error: " + status.LastError
+	case status.Conflicts > 0:
+		return fmt.Sprintf("%d conflicts", status.Conflicts)
+	default:
+		return "ok"
+	}
+}
+
+// ListConflicts returns unresolved sync conflicts, oldest first
+func (a *App) ListConflicts() ([]SyncConflict, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT c.id, COALESCE(e.id, 0), c.local_content, c.remote_content, c.remote_deleted, c.remote_device, c.created_at
+		FROM sync_conflicts c LEFT JOIN log_entries e ON e.uid = c.entry_uid
+		WHERE c.resolved_at IS NULL ORDER BY c.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync conflicts: %v", err)
+	}
+	defer rows.Close()
+
+	conflicts := []SyncConflict{}
+	for rows.Next() {
+		var c SyncConflict
+		if err := rows.Scan(&c.ID, &c.EntryID, &c.LocalContent, &c.RemoteContent, &c.RemoteDeleted, &c.RemoteDevice, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan sync conflict: %v", err)
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, nil
+}
+
+// ResolveConflict keeps this device's version ("local") or the other device's ("remote")
+// of a conflicting entry. Either way the chosen version is sent to the other devices.
+func (a *App) ResolveConflict(id int64, choice string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+	if choice != syncKeepLocal && choice != syncKeepRemote {
+		return fmt.Errorf("choice must be %q or %q", syncKeepLocal, syncKeepRemote)
+	}
+
+	var uid, remoteContent string
+	var remoteDeleted bool
+	err := a.db.QueryRow(`SELECT entry_uid, remote_content, remote_deleted FROM sync_conflicts
+		WHERE id = ? AND resolved_at IS NULL`, id).Scan(&uid, &remoteContent, &remoteDeleted)
+	if err != nil {
+		return fmt.Errorf("conflict not found: %v", err)
+	}
+
+	var entryID int
+	if err := a.db.QueryRow(`SELECT id FROM log_entries WHERE uid = ?`, uid).Scan(&entryID); err != nil {
+		return fmt.Errorf("conflicting entry no longer exists: %v", err)
+	}
+
+	if choice == syncKeepRemote {
+		if remoteDeleted {
+			err = a.DeleteEntry(entryID)
+		} else {
+			err = a.UpdateEntry(entryID, remoteContent)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		// base the re-sent local version on the remote one so the other device accepts it
+		change := syncChange{UID: uid, Op: syncOpUpsert, Content: remoteContent}
+		if remoteDeleted {
+			change.Op = syncOpDelete
+		}
+		if err := setSyncedHash(a.db, change); err != nil {
+			return err
+		}
+		if _, err := a.db.Exec(`INSERT INTO sync_outbox (entry_uid, op) VALUES (?, ?)`, uid, syncOpUpsert); err != nil {
+			return fmt.Errorf("failed to queue resolved entry: %v", err)
+		}
+	}
+
+	if _, err := a.db.Exec(`UPDATE sync_conflicts SET resolved_at = CURRENT_TIMESTAMP, resolution = ? WHERE id = ?`, choice, id); err != nil {
+		return fmt.Errorf("failed to resolve conflict: %v", err)
+	}
+	a.logf("Resolved sync conflict %d keeping %s version\n", id, choice)
+	return nil
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	syncKeyringFile    = "sync-keyring.json"
+	syncRecoveryCodes  = 5
+	syncKeyringVersion = 1
+)
+
+// syncKeyring is stored in the sync folder. The random master key is sealed once with the
+// passphrase and once per recovery code, so any of them unlocks it.
+type syncKeyring struct {
+	Version  int              `json:"version"`
+	Salt     []byte           `json:"salt"`
+	Master   []byte           `json:"master"`
+	Recovery []syncKeyringKey `json:"recovery"`
+}
+
+type syncKeyringKey struct {
+	Salt   []byte `json:"salt"`
+	Master []byte `json:"master"`
+}
+
+// syncEnvelope is an encrypted change batch
+type syncEnvelope struct {
+	Device      string `json:"device"`
+	Seq         int64  `json:"seq"`
+	Fingerprint string `json:"fingerprint"`
+	Payload     []byte `json:"payload"`
+}
+
+// syncSession holds the remote store and keys for one sync run
+type syncSession struct {
+	store     remoteStore
+	device    string
+	master    []byte
+	deviceKey []byte
+	// previousKeys are this device's batch keys from before the master key was rotated,
+	// which other devices need for the batches sealed with them
+	previousKeys [][]byte
+	// peerKeys holds every key of each other device, by fingerprint
+	peerKeys map[string]map[string][]byte
+}
+
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *syncSession) encrypted() bool {
+	return s.master != nil
+}
+
+// openSyncSession unlocks the shared master key with the sync passphrase, creating the
+// keyring on first use, and loads this device's batch key
+func (a *App) openSyncSession(store remoteStore, device string) (*syncSession, error) {
+	session := &syncSession{store: store, device: device, peerKeys: make(map[string]map[string][]byte)}
+	passphrase := a.settings.Sync.Passphrase
+	if passphrase == "" {
+		return session, nil
+	}
+
+	keyring, err := loadSyncKeyring(store)
+	if err != nil {
+		return nil, err
+	}
+	if keyring == nil {
+		master, err := randomBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		keyring = &syncKeyring{Version: syncKeyringVersion}
+		if err := keyring.sealMaster(master, passphrase); err != nil {
+			return nil, err
+		}
+		if err := saveSyncKeyring(store, keyring); err != nil {
+			return nil, err
+		}
+		a.logf("Created sync keyring\n")
+		session.master = master
+	} else {
+		key, err := passphraseKey(passphrase, keyring.Salt)
+		if err != nil {
+			return nil, err
+		}
+		session.master, err = openWithKey(key, keyring.Master, []byte(syncKeyringFile))
+		if err != nil {
+			return nil, fmt.Errorf("the sync passphrase does not unlock this sync folder; it changes when a device is revoked, or use a recovery code to reset it")
+		}
+	}
+
+	if err := a.loadDeviceKey(session); err != nil {
+		return nil, err
+	}
+	if err := a.followMasterKey(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func loadSyncKeyring(store remoteStore) (*syncKeyring, error) {
+	names, err := store.list()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync folder: %v", err)
+	}
+	if !containsString(names, syncKeyringFile) {
+		return nil, nil
+	}
+
+	data, err := store.download(syncKeyringFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download sync keyring: %v", err)
+	}
+	var keyring syncKeyring
+	if err := json.Unmarshal(data, &keyring); err != nil {
+		return nil, fmt.Errorf("failed to decode sync keyring: %v", err)
+	}
+	return &keyring, nil
+}
+
+func saveSyncKeyring(store remoteStore, keyring *syncKeyring) error {
+	data, err := json.Marshal(keyring)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync keyring: %v", err)
+	}
+	if err := store.upload(syncKeyringFile, data); err != nil {
+		return fmt.Errorf("failed to upload sync keyring: %v", err)
+	}
+	return nil
+}
+
+// sealMaster seals master with a fresh salt derived from passphrase
+func (k *syncKeyring) sealMaster(master []byte, passphrase string) error {
+	salt, sealed, err := sealMasterWith(master, passphrase)
+	if err != nil {
+		return err
+	}
+	k.Salt, k.Master = salt, sealed
+	return nil
+}
+
+func sealMasterWith(master []byte, secret string) ([]byte, []byte, error) {
+	salt, err := randomBytes(16)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := passphraseKey(secret, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	sealed, err := sealWithKey(key, master, []byte(syncKeyringFile))
+	return salt, sealed, err
+}
+
+// loadDeviceKey reads this device's batch keys, creating the current one on first use
+func (a *App) loadDeviceKey(session *syncSession) error {
+	for _, stored := range strings.Split(a.syncState("previous_device_keys"), ",") {
+		if stored == "" {
+			continue
+		}
+		key, err := hex.DecodeString(stored)
+		if err != nil {
+			return fmt.Errorf("invalid stored device key: %v", err)
+		}
+		session.previousKeys = append(session.previousKeys, key)
+	}
+
+	if stored := a.syncState("device_key"); stored != "" {
+		key, err := hex.DecodeString(stored)
+		if err != nil {
+			return fmt.Errorf("invalid stored device key: %v", err)
+		}
+		session.deviceKey = key
+		return nil
+	}
+
+	key, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	session.deviceKey = key
+	return a.setSyncState("device_key", hex.EncodeToString(key))
+}
+
+// rotateDeviceKey gives this device a new batch key, keeping the old one for the batches
+// already sealed with it
+func (a *App) rotateDeviceKey(session *syncSession) error {
+	key, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	previous := append(session.previousKeys, session.deviceKey)
+	encoded := make([]string, len(previous))
+	for i, old := range previous {
+		encoded[i] = hex.EncodeToString(old)
+	}
+	if err := a.setSyncState("previous_device_keys", strings.Join(encoded, ",")); err != nil {
+		return err
+	}
+	if err := a.setSyncState("device_key", hex.EncodeToString(key)); err != nil {
+		return err
+	}
+	session.deviceKey, session.previousKeys = key, previous
+	return nil
+}
+
+// followMasterKey rotates this device's batch key when another device has rotated the
+// master key, since the device it revoked may have unsealed the current one
+func (a *App) followMasterKey(session *syncSession) error {
+	fingerprint := keyFingerprint(session.master)
+	known := a.syncState("master_fingerprint")
+	if known == fingerprint {
+		return nil
+	}
+	if known != "" {
+		if err := a.rotateDeviceKey(session); err != nil {
+			return err
+		}
+		a.logf("The sync master key was rotated, so this device has a new batch key\n")
+	}
+	return a.setSyncState("master_fingerprint", fingerprint)
+}
+
+// sealDeviceFile seals a device's current and previous batch keys with the master key
+func (s *syncSession) sealDeviceFile(file *syncDeviceFile, key []byte, previous [][]byte) error {
+	sealed, err := sealWithKey(s.master, key, []byte(file.Device))
+	if err != nil {
+		return err
+	}
+	file.Key, file.Fingerprint, file.PreviousKeys = sealed, keyFingerprint(key), nil
+	for _, old := range previous {
+		sealed, err := sealWithKey(s.master, old, []byte(file.Device))
+		if err != nil {
+			return err
+		}
+		file.PreviousKeys = append(file.PreviousKeys, sealed)
+	}
+	return nil
+}
+
+// openDeviceFile unseals another device's current and previous batch keys
+func (s *syncSession) openDeviceFile(file syncDeviceFile) ([]byte, [][]byte, error) {
+	notSealed := fmt.Errorf("key of device %s is not sealed with this sync folder's master key", file.Device)
+	key, err := openWithKey(s.master, file.Key, []byte(file.Device))
+	if err != nil || keyFingerprint(key) != file.Fingerprint {
+		return nil, nil, notSealed
+	}
+	var previous [][]byte
+	for _, sealed := range file.PreviousKeys {
+		old, err := openWithKey(s.master, sealed, []byte(file.Device))
+		if err != nil {
+			return nil, nil, notSealed
+		}
+		previous = append(previous, old)
+	}
+	return key, previous, nil
+}
+
+// encode serializes a batch, encrypted with this device's key when a passphrase is set
+func (s *syncSession) encode(batch syncBatch) ([]byte, error) {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sync batch: %v", err)
+	}
+	if !s.encrypted() {
+		return data, nil
+	}
+
+	sealed, err := sealWithKey(s.deviceKey, data, batchAAD(batch.Device, batch.Seq))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(syncEnvelope{Device: batch.Device, Seq: batch.Seq, Fingerprint: keyFingerprint(s.deviceKey), Payload: sealed})
+}
+
+// decode reads a batch written by device. With a passphrase set, unencrypted batches are
+// refused so a compromised provider cannot inject changes.
+func (s *syncSession) decode(device string, seq int64, data []byte) (syncBatch, error) {
+	var envelope syncEnvelope
+	var batch syncBatch
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return batch, fmt.Errorf("failed to decode sync batch: %v", err)
+	}
+
+	if envelope.Payload == nil {
+		if s.encrypted() {
+			return batch, fmt.Errorf("refusing unencrypted batch %d from device %s", seq, device)
+		}
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return batch, fmt.Errorf("failed to decode sync batch: %v", err)
+		}
+		return batch, nil
+	}
+
+	if !s.encrypted() {
+		return batch, fmt.Errorf("sync batches are encrypted; set the sync passphrase to read them")
+	}
+	key, ok := s.peerKeys[device][envelope.Fingerprint]
+	if !ok {
+		return batch, fmt.Errorf("device %s has not published the key of batch %d", device, seq)
+	}
+	plain, err := openWithKey(key, envelope.Payload, batchAAD(device, seq))
+	if err != nil {
+		return batch, fmt.Errorf("failed to decrypt batch %d from device %s", seq, device)
+	}
+	if err := json.Unmarshal(plain, &batch); err != nil {
+		return batch, fmt.Errorf("failed to decode sync batch: %v", err)
+	}
+	return batch, nil
+}
+
+// batchAAD binds a batch's ciphertext to its device and sequence number, so a batch
+// cannot be replayed under another name
+func batchAAD(device string, seq int64) []byte {
+	return []byte(fmt.Sprintf("%s/%d", device, seq))
+}
+
+// newRecoveryCode returns a random code like ABCDEF-GHIJKL-MNOPQR-STUVWX with 120 bits of entropy
+func newRecoveryCode() (string, error) {
+	buf, err := randomBytes(15)
+	if err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.EncodeToString(buf)
+	var groups []string
+	for i := 0; i < len(code); i += 6 {
+		groups = append(groups, code[i:i+6])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// GenerateSyncRecoveryCodes replaces the sync folder's recovery codes with new ones. Each
+// code can reset the sync passphrase. They are shown once and should be stored offline.
+func (a *App) GenerateSyncRecoveryCodes() ([]string, error) {
+	session, keyring, err := a.unlockSyncKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := keyring.sealRecoveryCodes(session.master)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSyncKeyring(session.store, keyring); err != nil {
+		return nil, err
+	}
+	a.logf("Generated %d sync recovery codes\n", len(codes))
+	return codes, nil
+}
+
+// sealRecoveryCodes replaces the keyring's recovery codes with new ones sealing master
+func (k *syncKeyring) sealRecoveryCodes(master []byte) ([]string, error) {
+	codes := make([]string, 0, syncRecoveryCodes)
+	k.Recovery = nil
+	for i := 0; i < syncRecoveryCodes; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		salt, sealed, err := sealMasterWith(master, normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		k.Recovery = append(k.Recovery, syncKeyringKey{Salt: salt, Master: sealed})
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func (a *App) unlockSyncKeyring() (*syncSession, *syncKeyring, error) {
+	if a.db == nil {
+		return nil, nil, errDatabaseUnavailable()
+	}
+	if a.settings.Sync.Passphrase == "" {
+		return nil, nil, fmt.Errorf("set a sync passphrase first")
+	}
+	store, err := newRemoteStore(a.settings.Sync.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	device, err := a.syncDeviceID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+	session, err := a.openSyncSession(store, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyring, err := loadSyncKeyring(store)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, keyring, nil
+}
+
+// RecoverSyncPassphrase unlocks the sync folder with a recovery code and sets a new
+// passphrase for it. The code stays valid; other devices need the new passphrase.
+func (a *App) RecoverSyncPassphrase(code, newPassphrase string) error {
+	if newPassphrase == "" {
+		return fmt.Errorf("the new passphrase cannot be empty")
+	}
+	store, err := newRemoteStore(a.settings.Sync.Target)
+	if err != nil {
+		return err
+	}
+
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	keyring, err := loadSyncKeyring(store)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return fmt.Errorf("this sync folder is not encrypted")
+	}
+
+	var master []byte
+	for _, slot := range keyring.Recovery {
+		key, err := passphraseKey(normalizeRecoveryCode(code), slot.Salt)
+		if err != nil {
+			return err
+		}
+		if master, err = openWithKey(key, slot.Master, []byte(syncKeyringFile)); err == nil {
+			break
+		}
+	}
+	if master == nil {
+		return fmt.Errorf("recovery code not recognised")
+	}
+
+	if err := keyring.sealMaster(master, newPassphrase); err != nil {
+		return err
+	}
+	if err := saveSyncKeyring(store, keyring); err != nil {
+		return err
+	}
+
+	a.settings.Sync.Passphrase = newPassphrase
+	if err := a.saveSettings(); err != nil {
+		return err
+	}
+	a.logf("Sync passphrase reset with a recovery code\n")
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+)
+
+// mergeSyncContent merges two edits of the same entry made since base. It tries, in order:
+//   - edits to different lines: a line-level three-way merge
+//   - one side only retagged: the other side's text gets the tag changes
+//   - both sides appended to base: both additions are kept
+//
+// Both devices merge the same pair of edits, so the sides are put in a fixed order first
+// and both arrive at the same text. ok is false when the edits overlap and the user has to choose.
+func mergeSyncContent(base, local, remote string) (merged string, ok bool) {
+	if local > remote {
+		local, remote = remote, local
+	}
+	if merged, ok := mergeLines(base, local, remote); ok {
+		return merged, true
+	}
+	switch {
+	case stripTags(local) == stripTags(base):
+		return retag(remote, extractTagNames(base), extractTagNames(local)), true
+	case stripTags(remote) == stripTags(base):
+		return retag(local, extractTagNames(base), extractTagNames(remote)), true
+	case strings.HasPrefix(local, base) && strings.HasPrefix(remote, base):
+		return local + remote[len(base):], true
+	}
+	return "", false
+}
+
+// stripTags returns text without its #tags, with whitespace collapsed
+func stripTags(text string) string {
+	return strings.Join(strings.Fields(tagPattern.ReplaceAllString(text, "")), " ")
+}
+
+// retag applies the tags added and removed between from and to to text. Added tags are
+// appended; removed ones are cut out together with the space before them.
+func retag(text string, from, to []string) string {
+	had := make(map[string]bool, len(from))
+	for _, name := range from {
+		had[name] = true
+	}
+	keep := make(map[string]bool, len(to))
+	for _, name := range to {
+		keep[name] = true
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(text, -1) {
+		name := text[m[2]:m[3]]
+		if !had[name] || keep[name] {
+			continue
+		}
+		start := m[0]
+		if start > last && text[start-1] == ' ' {
+			start--
+		}
+		b.WriteString(text[last:start])
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	result := b.String()
+
+	present := make(map[string]bool)
+	for _, name := range extractTagNames(result) {
+		present[name] = true
+	}
+	for _, name := range to {
+		if !had[name] && !present[name] {
+			result += " #" + name
+		}
+	}
+	return result
+}
+
+// lineHunk replaces base lines [start, end) with lines
+type lineHunk struct {
+	start, end int
+	lines      []string
+}
+
+// mergeLines is a three-way merge on whole lines. Changes to separate lines are both
+// applied; lines inserted at the same place by both sides are all kept, local ones first.
+func mergeLines(base, local, remote string) (string, bool) {
+	baseLines := strings.Split(base, "\n")
+	ours := diffLines(baseLines, strings.Split(local, "\n"))
+	theirs := diffLines(baseLines, strings.Split(remote, "\n"))
+
+	var out []string
+	pos, i, j := 0, 0, 0
+	for i < len(ours) || j < len(theirs) {
+		var next lineHunk
+		switch {
+		case j == len(theirs):
+			next, i = ours[i], i+1
+		case i == len(ours):
+			next, j = theirs[j], j+1
+		default:
+			a, b := ours[i], theirs[j]
+			switch {
+			case a.start == b.start && a.end == b.end && strings.Join(a.lines, "\n") == strings.Join(b.lines, "\n"):
+				next, i, j = a, i+1, j+1
+			case a.start == a.end && b.start == b.end && a.start == b.start:
+				next = lineHunk{start: a.start, end: a.end, lines: append(append([]string{}, a.lines...), b.lines...)}
+				i, j = i+1, j+1
+			case a.end <= b.start:
+				next, i = a, i+1
+			case b.end <= a.start:
+				next, j = b, j+1
+			default:
+				return "", false
+			}
+		}
+		if next.start < pos {
+			return "", false
+		}
+		out = append(out, baseLines[pos:next.start]...)
+		out = append(out, next.lines...)
+		pos = next.end
+	}
+	out = append(out, baseLines[pos:]...)
+	return strings.Join(out, "\n"), true
+}
+
+// diffLines returns the hunks turning base into changed, found with a longest common
+// subsequence. Entries are short, so the quadratic table is fine.
+func diffLines(base, changed []string) []lineHunk {
+	n, m := len(base), len(changed)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if base[i] == changed[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var hunks []lineHunk
+	var open *lineHunk
+	flush := func() {
+		if open != nil {
+			hunks = append(hunks, *open)
+			open = nil
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && base[i] == changed[j]:
+			flush()
+			i, j = i+1, j+1
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			if open == nil {
+				open = &lineHunk{start: i, end: i}
+			}
+			open.lines = append(open.lines, changed[j])
+			j++
+		default:
+			if open == nil {
+				open = &lineHunk{start: i, end: i}
+			}
+			open.end = i + 1
+			i++
+		}
+	}
+	flush()
+	return hunks
+}
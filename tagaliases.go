@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var tagNamePattern = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)
+
+// TagAlias maps a tag as typed to the tag it is filed under, e.g. mtg → meeting.
+// Aliases match regardless of case and accents.
+type TagAlias struct {
+	Alias string `json:"alias"`
+	Tag   string `json:"tag"`
+}
+
+// TagAliasReport is returned by ApplyTagAliases
+type TagAliasReport struct {
+	TagsMerged   int `json:"tags_merged"`
+	EntriesMoved int `json:"entries_moved"`
+}
+
+func (a *App) createTagAliasTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS tag_aliases (
+		alias TEXT PRIMARY KEY COLLATE NOCASE,
+		tag TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create tag aliases table: %v", err)
+	}
+	return nil
+}
+
+// GetTagAliases returns every alias ordered by alias
+func (a *App) GetTagAliases() ([]TagAlias, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT alias, tag FROM tag_aliases ORDER BY alias`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag aliases: %v", err)
+	}
+	defer rows.Close()
+
+	aliases := []TagAlias{}
+	for rows.Next() {
+		var alias TagAlias
+		if err := rows.Scan(&alias.Alias, &alias.Tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag alias: %v", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, nil
+}
+
+// SetTagAlias files alias under tag from now on. Run ApplyTagAliases to move entries
+// tagged before.
+func (a *App) SetTagAlias(alias, tag string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	alias = strings.TrimPrefix(strings.TrimSpace(alias), "#")
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	for _, name := range []string{alias, tag} {
+		if !tagNamePattern.MatchString(name) {
+			return newAPIError(codeInvalidRequest, fmt.Sprintf("invalid tag name %q: use letters, numbers, - and _", name))
+		}
+	}
+	tag = canonicalTag(tag)
+	if canonicalTag(alias) == tag {
+		return newAPIError(codeInvalidRequest, "a tag cannot be an alias of itself")
+	}
+	// chains would make the result depend on the order aliases are applied in
+	if target, ok := a.tagAliases()[tag]; ok {
+		return newAPIError(codeInvalidRequest, fmt.Sprintf("%s is itself an alias of %s", tag, target))
+	}
+
+	query := `INSERT INTO tag_aliases (alias, tag) VALUES (?, ?)
+		ON CONFLICT(alias) DO UPDATE SET tag = excluded.tag`
+	if _, err := a.db.Exec(query, alias, tag); err != nil {
+		return fmt.Errorf("failed to save tag alias: %v", err)
+	}
+	a.logf("Tag alias saved: %s -> %s\n", alias, tag)
+	return nil
+}
+
+// RemoveTagAlias stops filing alias under another tag
+func (a *App) RemoveTagAlias(alias string) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	result, err := a.db.Exec(`DELETE FROM tag_aliases WHERE alias = ?`, strings.TrimPrefix(alias, "#"))
+	if err != nil {
+		return fmt.Errorf("failed to delete tag alias: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return newAPIError(codeNotFound, fmt.Sprintf("tag alias not found: %s", alias))
+	}
+	return nil
+}
+
+// tagAliases returns the alias rules keyed by the canonical form of the alias
+func (a *App) tagAliases() map[string]string {
+	aliases := make(map[string]string)
+	if a.db == nil {
+		return aliases
+	}
+	rows, err := a.db.Query(`SELECT alias, tag FROM tag_aliases`)
+	if err != nil {
+		a.logf("Warning: failed to load tag aliases: %v\n", err)
+		return aliases
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var alias, tag string
+		if rows.Scan(&alias, &tag) == nil {
+			aliases[canonicalTag(alias)] = canonicalTag(tag)
+		}
+	}
+	return aliases
+}
+
+// entryTags returns the tags text is filed under: blacklisted names are dropped and
+// aliases applied
+func (a *App) entryTags(text string) []string {
+	return spanTags(a.findTagSpans(text))
+}
+
+// ApplyTagAliases moves entries tagged with an alias before the alias existed to its
+// tag, and removes the alias tags. Entry text is left as typed.
+func (a *App) ApplyTagAliases() (*TagAliasReport, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	report := &TagAliasReport{}
+	for alias, tag := range a.tagAliases() {
+		rows, err := a.db.Query(`SELECT id FROM tags WHERE name = ? COLLATE NOCASE AND name != ?`, alias, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find aliased tags: %v", err)
+		}
+		var aliasIDs []int64
+		for rows.Next() {
+			var id int64
+			if rows.Scan(&id) == nil {
+				aliasIDs = append(aliasIDs, id)
+			}
+		}
+		rows.Close()
+		if len(aliasIDs) == 0 {
+			continue
+		}
+
+		tagID, err := a.getOrCreateTag(context.Background(), tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, aliasID := range aliasIDs {
+			result, err := a.db.Exec(`INSERT OR IGNORE INTO log_entries_tags (log_entry_id, tag_id)
+				SELECT log_entry_id, ? FROM log_entries_tags WHERE tag_id = ?`, tagID, aliasID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to move entries to %s: %v", tag, err)
+			}
+			moved, _ := result.RowsAffected()
+			report.EntriesMoved += int(moved)
+
+			if _, err := a.db.Exec(`DELETE FROM log_entries_tags WHERE tag_id = ?`, aliasID); err != nil {
+				return nil, fmt.Errorf("failed to untag aliased entries: %v", err)
+			}
+			if _, err := a.db.Exec(`DELETE FROM tags WHERE id = ?`, aliasID); err != nil {
+				return nil, fmt.Errorf("failed to delete aliased tag: %v", err)
+			}
+			report.TagsMerged++
+		}
+	}
+
+	a.logf("Tag aliases applied: %d tags merged, %d entries moved\n", report.TagsMerged, report.EntriesMoved)
+	return report, nil
+}
+
+// handleTagAliasesAPI serves tag alias CRUD:
+// GET /api/tag-aliases, POST /api/tag-aliases {"alias", "tag"},
+// DELETE /api/tag-aliases/{alias}, POST /api/tag-aliases/apply
+func (a *App) handleTagAliasesAPI(w http.ResponseWriter, r *http.Request) {
+	alias := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tag-aliases"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && alias == "":
+		aliases, err := a.GetTagAliases()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, aliases)
+	case r.Method == http.MethodPost && alias == "apply":
+		report, err := a.ApplyTagAliases()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	case r.Method == http.MethodPost && alias == "":
+		var req TagAlias
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundPayload)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidPayload, fmt.Sprintf("Invalid JSON payload: %v", err))
+			return
+		}
+		if err := a.SetTagAlias(req.Alias, req.Tag); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	case r.Method == http.MethodDelete && alias != "":
+		if err := a.RemoveTagAlias(alias); err != nil {
+			writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in tag blacklist rules. The colon keeps them apart from tag names, which
+// can't contain one.
+const (
+	tagRuleNumbers   = ":numbers"
+	tagRuleHexColors = ":hex-colors"
+)
+
+// defaultTagBlacklist is used while tag_blacklist is unset: "issue #1" and pasted
+// CSS colours don't create tags
+var defaultTagBlacklist = []string{tagRuleNumbers, tagRuleHexColors}
+
+var (
+	numericTagPattern = regexp.MustCompile(`^[0-9]+$`)
+	hexColorPattern   = regexp.MustCompile(`^(?i:[0-9a-f]{3}|[0-9a-f]{4}|[0-9a-f]{6}|[0-9a-f]{8})$`)
+)
+
+// isHexColor reports whether name looks like a colour code. Words spelled with a-f
+// only (#bad, #cafe) are kept unless every letter is the same (#fff).
+func isHexColor(name string) bool {
+	if !hexColorPattern.MatchString(name) {
+		return false
+	}
+	lower := strings.ToLower(name)
+	return strings.ContainsAny(lower, "0123456789") || strings.Count(lower, lower[:1]) == len(lower)
+}
+
+// tagMatcher decides whether a name found after # is left untagged
+type tagMatcher func(name string) bool
+
+// compileTagBlacklist turns tag_blacklist rules into matchers. A rule is a built-in
+// (:numbers, :hex-colors), a /regular expression/ matched against the whole name, or a
+// tag name. Regular expressions ignore case; names also ignore accents.
+func compileTagBlacklist(rules []string) ([]tagMatcher, error) {
+	var matchers []tagMatcher
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		switch {
+		case rule == "":
+			continue
+		case rule == tagRuleNumbers:
+			matchers = append(matchers, numericTagPattern.MatchString)
+		case rule == tagRuleHexColors:
+			matchers = append(matchers, isHexColor)
+		case strings.HasPrefix(rule, ":"):
+			return nil, fmt.Errorf("unknown tag blacklist rule %s: use %s or %s", rule, tagRuleNumbers, tagRuleHexColors)
+		case len(rule) > 2 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/"):
+			pattern, err := regexp.Compile(`^(?i:` + rule[1:len(rule)-1] + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag blacklist pattern %s: %v", rule, err)
+			}
+			matchers = append(matchers, pattern.MatchString)
+		default:
+			name := canonicalTag(rule)
+			matchers = append(matchers, func(tag string) bool { return canonicalTag(tag) == name })
+		}
+	}
+	return matchers, nil
+}
+
+// tagBlacklist returns the configured matchers; tag_blacklist set to [] turns them off
+func (a *App) tagBlacklist() []tagMatcher {
+	rules := a.settings.TagBlacklist
+	if rules == nil {
+		rules = defaultTagBlacklist
+	}
+	matchers, err := compileTagBlacklist(rules)
+	if err != nil {
+		// SetSettings rejects bad rules, so this is a hand-edited settings.json
+		a.logf("Warning: ignoring tag blacklist: %v\n", err)
+		return nil
+	}
+	return matchers
+}
+
+func isBlacklisted(name string, matchers []tagMatcher) bool {
+	for _, match := range matchers {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// diacriticFolds maps accented Latin letters to the letters they are spelled with
+// without accents
+var diacriticFolds = func() map[rune]string {
+	folds := make(map[rune]string)
+	for plain, letters := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ", "h": "ĥħ",
+		"i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő",
+		"r": "ŕŗř", "s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ", "z": "źżž",
+		"ss": "ß", "ae": "æ", "oe": "œ", "th": "þ",
+	} {
+		for _, letter := range letters {
+			folds[letter] = plain
+		}
+	}
+	return folds
+}()
+
+// unicodeTagPattern finds entries with a tag that had non-ASCII letters cut off before
+// tags could contain them, "#café" being filed under #caf
+var unicodeTagPattern = regexp.MustCompile(`#[a-zA-Z0-9_-]*[^\x00-\x7F]`)
+
+// canonicalTag is the form a tag is stored and matched in: lower case without
+// diacritics, so #Café, #cafe and #CAFÉ are one tag
+func canonicalTag(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#")) {
+		if fold, ok := diacriticFolds[r]; ok {
+			b.WriteString(fold)
+		} else if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// canonicalizeTags brings tags stored before tags were matched regardless of case and
+// accents into their canonical form. Tags that fold to the same name are merged, and
+// entries whose tags had non-ASCII letters cut off are tagged again.
+func (a *App) canonicalizeTags() error {
+	rows, err := a.db.Query(`SELECT id, name FROM tags`)
+	if err != nil {
+		return fmt.Errorf("failed to query tags: %v", err)
+	}
+	renames := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var name string
+		if rows.Scan(&id, &name) == nil && canonicalTag(name) != name {
+			renames[id] = name
+		}
+	}
+	rows.Close()
+
+	for id, name := range renames {
+		if err := a.mergeTagInto(id, canonicalTag(name)); err != nil {
+			return err
+		}
+		for _, statement := range []string{
+			`UPDATE log_entries_tag_spans SET tag = ? WHERE tag = ?`,
+			`UPDATE projects SET tag = ? WHERE tag = ?`,
+			`UPDATE tag_aliases SET tag = ? WHERE tag = ?`,
+			`UPDATE OR IGNORE task_snapshots SET tag = ? WHERE tag = ?`,
+		} {
+			if _, err := a.db.Exec(statement, canonicalTag(name), name); err != nil {
+				return fmt.Errorf("failed to rename tag %s: %v", name, err)
+			}
+		}
+		if _, err := a.db.Exec(`DELETE FROM task_snapshots WHERE tag = ?`, name); err != nil {
+			return fmt.Errorf("failed to rename tag %s: %v", name, err)
+		}
+	}
+	if len(renames) > 0 {
+		a.logf("Normalized %d tags to lower case without accents\n", len(renames))
+	}
+	return a.retagUnicodeEntries()
+}
+
+// mergeTagInto renames tag id to name, or moves its entries to the tag already called
+// name and deletes it
+func (a *App) mergeTagInto(id int64, name string) error {
+	var targetID int64
+	err := a.db.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&targetID)
+	if err == sql.ErrNoRows {
+		if _, err := a.db.Exec(`UPDATE tags SET name = ? WHERE id = ?`, name, id); err != nil {
+			return fmt.Errorf("failed to rename tag: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query tag: %v", err)
+	}
+
+	if _, err := a.db.Exec(`INSERT OR IGNORE INTO log_entries_tags (log_entry_id, tag_id)
+		SELECT log_entry_id, ? FROM log_entries_tags WHERE tag_id = ?`, targetID, id); err != nil {
+		return fmt.Errorf("failed to merge tag into %s: %v", name, err)
+	}
+	if _, err := a.db.Exec(`DELETE FROM log_entries_tags WHERE tag_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to merge tag into %s: %v", name, err)
+	}
+	if _, err := a.db.Exec(`DELETE FROM tags WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete merged tag: %v", err)
+	}
+	return nil
+}
+
+// retagUnicodeEntries tags again the entries whose tags differ from the ones found in
+// their text now, and removes the cut-off tags nothing is filed under anymore
+func (a *App) retagUnicodeEntries() error {
+	rows, err := a.db.Query(`SELECT id, content FROM log_entries WHERE content LIKE '%#%' AND deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query entries for tags: %v", err)
+	}
+	type pending struct {
+		id      int64
+		content string
+	}
+	var entries []pending
+	for rows.Next() {
+		var entry pending
+		if rows.Scan(&entry.id, &entry.content) == nil && unicodeTagPattern.MatchString(entry.content) {
+			entries = append(entries, entry)
+		}
+	}
+	rows.Close()
+
+	var previous []int64
+	retagged := 0
+	for _, entry := range entries {
+		ids, names, err := a.entryTagIDs(entry.id)
+		if err != nil {
+			return err
+		}
+		found := spanTags(a.findTagSpans(entry.content))
+		sort.Strings(names)
+		sort.Strings(found)
+		if strings.Join(names, " ") == strings.Join(found, " ") {
+			continue
+		}
+		previous = append(previous, ids...)
+		if _, err := a.db.Exec(`DELETE FROM log_entries_tags WHERE log_entry_id = ?`, entry.id); err != nil {
+			return fmt.Errorf("failed to clear tags for entry %d: %v", entry.id, err)
+		}
+		if err := a.processTags(context.Background(), entry.id, entry.content); err != nil {
+			return err
+		}
+		retagged++
+	}
+
+	for _, id := range previous {
+		if _, err := a.db.Exec(`DELETE FROM tags WHERE id = ? AND id NOT IN (SELECT tag_id FROM log_entries_tags)`, id); err != nil {
+			return fmt.Errorf("failed to remove unused tag: %v", err)
+		}
+	}
+	if retagged > 0 {
+		a.logf("Tagged %d entries with accented tags again\n", retagged)
+	}
+	return nil
+}
+
+// entryTagIDs returns the ids and names of the tags an entry is filed under
+func (a *App) entryTagIDs(entryID int64) ([]int64, []string, error) {
+	rows, err := a.db.Query(`SELECT t.id, t.name FROM log_entries_tags lt
+		JOIN tags t ON t.id = lt.tag_id WHERE lt.log_entry_id = ?`, entryID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query entry tags: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var names []string
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan entry tag: %v", err)
+		}
+		ids, names = append(ids, id), append(names, name)
+	}
+	return ids, names, nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// TagSpan is where a tag is written in an entry: the byte offsets of "#name" in its
+// content. Name is the tag as typed and Tag the one it is filed under.
+type TagSpan struct {
+	Tag   string `json:"tag"`
+	Name  string `json:"name"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+var tagPlaceholderPattern = regexp.MustCompile(`SNAPLOGTAG(\d+)X`)
+
+func (a *App) createTagSpanTables() error {
+	var exists int
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'log_entries_tag_spans'`).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check tag spans table: %v", err)
+	}
+
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS log_entries_tag_spans (
+		log_entry_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		name TEXT NOT NULL,
+		start_offset INTEGER NOT NULL,
+		end_offset INTEGER NOT NULL,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_tag_spans_entry ON log_entries_tag_spans(log_entry_id, start_offset);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create tag spans table: %v", err)
+	}
+	if exists == 0 {
+		return a.backfillTagSpans()
+	}
+	return nil
+}
+
+// backfillTagSpans records the tag positions of entries logged before they were stored
+func (a *App) backfillTagSpans() error {
+	rows, err := a.db.Query(`SELECT id, content FROM log_entries WHERE content LIKE '%#%' AND deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query entries for tag spans: %v", err)
+	}
+	type pending struct {
+		id      int64
+		content string
+	}
+	var entries []pending
+	for rows.Next() {
+		var entry pending
+		if rows.Scan(&entry.id, &entry.content) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	rows.Close()
+
+	for _, entry := range entries {
+		if err := a.storeTagSpans(context.Background(), entry.id, a.findTagSpans(entry.content)); err != nil {
+			return err
+		}
+	}
+	if len(entries) > 0 {
+		a.logf("Recorded tag positions for %d entries\n", len(entries))
+	}
+	return nil
+}
+
+// findTagSpans locates the tags in text, skipping escaped and blacklisted ones and
+// applying aliases
+func (a *App) findTagSpans(text string) []TagSpan {
+	matches := tagPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	blacklist, aliases := a.tagBlacklist(), a.tagAliases()
+	var spans []TagSpan
+	for _, match := range matches {
+		if match[0] > 0 && text[match[0]-1] == '\\' {
+			continue
+		}
+		name := text[match[2]:match[3]]
+		if isBlacklisted(name, blacklist) {
+			continue
+		}
+		tag := canonicalTag(name)
+		if target, ok := aliases[tag]; ok {
+			tag = target
+		}
+		spans = append(spans, TagSpan{Tag: tag, Name: name, Start: match[0], End: match[1]})
+	}
+	return spans
+}
+
+// spanTags returns the distinct tags of spans in order of appearance
+func spanTags(spans []TagSpan) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, span := range spans {
+		if !seen[span.Tag] {
+			seen[span.Tag] = true
+			tags = append(tags, span.Tag)
+		}
+	}
+	return tags
+}
+
+// storeTagSpans replaces the recorded tag positions of an entry
+func (a *App) storeTagSpans(ctx context.Context, entryID int64, spans []TagSpan) error {
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM log_entries_tag_spans WHERE log_entry_id = ?`, entryID); err != nil {
+		return fmt.Errorf("failed to clear tag spans: %v", err)
+	}
+	for _, span := range spans {
+		if _, err := a.db.ExecContext(ctx, `INSERT INTO log_entries_tag_spans (log_entry_id, tag, name, start_offset, end_offset)
+			VALUES (?, ?, ?, ?, ?)`, entryID, span.Tag, span.Name, span.Start, span.End); err != nil {
+			return fmt.Errorf("failed to store tag span: %v", err)
+		}
+	}
+	return nil
+}
+
+// entryTagSpans returns the recorded tag positions of an entry, in order
+func (a *App) entryTagSpans(entryID int) []TagSpan {
+	if a.db == nil {
+		return nil
+	}
+	rows, err := a.db.Query(`SELECT tag, name, start_offset, end_offset FROM log_entries_tag_spans
+		WHERE log_entry_id = ? ORDER BY start_offset`, entryID)
+	if err != nil {
+		a.logf("Warning: failed to load tag spans for entry %d: %v\n", entryID, err)
+		return nil
+	}
+	defer rows.Close()
+
+	var spans []TagSpan
+	for rows.Next() {
+		var span TagSpan
+		if rows.Scan(&span.Tag, &span.Name, &span.Start, &span.End) == nil {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// validSpans drops spans that no longer line up with content, e.g. after an edit that
+// bypassed processTags
+func validSpans(content string, spans []TagSpan) []TagSpan {
+	var valid []TagSpan
+	last := 0
+	for _, span := range spans {
+		if span.Start < last || span.End > len(content) || content[span.Start:span.End] != "#"+span.Name {
+			continue
+		}
+		valid = append(valid, span)
+		last = span.End
+	}
+	return valid
+}
+
+// renderEntryHTML renders an entry's markdown with its tags as chips linking to the tag
+// search. Tags are swapped for placeholders before rendering so markdown can't split
+// them, then the placeholders become chips; in code, links and attributes they go back
+// to plain text.
+func (a *App) renderEntryHTML(content string, spans []TagSpan) (string, error) {
+	spans = validSpans(content, spans)
+	if len(spans) == 0 {
+		return a.RenderMarkdown(content)
+	}
+
+	var marked strings.Builder
+	last := 0
+	for i, span := range spans {
+		marked.WriteString(content[last:span.Start])
+		fmt.Fprintf(&marked, "SNAPLOGTAG%dX", i)
+		last = span.End
+	}
+	marked.WriteString(content[last:])
+
+	rendered, err := a.RenderMarkdown(marked.String())
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	last = 0
+	for _, match := range tagPlaceholderPattern.FindAllStringSubmatchIndex(rendered, -1) {
+		var i int
+		fmt.Sscanf(rendered[match[2]:match[3]], "%d", &i)
+		if i >= len(spans) {
+			continue
+		}
+		prefix := rendered[:match[0]]
+		out.WriteString(rendered[last:match[0]])
+		last = match[1]
+
+		span := spans[i]
+		inTag := strings.LastIndexByte(prefix, '<') > strings.LastIndexByte(prefix, '>')
+		inCode := strings.Count(prefix, "<code") > strings.Count(prefix, "</code>")
+		inLink := strings.Count(prefix, "<a ") > strings.Count(prefix, "</a>")
+		if inTag || inCode || inLink {
+			out.WriteString("#" + span.Name)
+			continue
+		}
+		fmt.Fprintf(&out, `<a class="entry-tag" href="/dash/search?tag=%s" data-tag="%s">#%s</a>`,
+			url.QueryEscape(span.Tag), html.EscapeString(span.Tag), html.EscapeString(span.Name))
+	}
+	out.WriteString(rendered[last:])
+	return out.String(), nil
+}
+
+// stripTagSpans removes the tags from content for exports that want prose only,
+// tidying the spaces left behind
+func stripTagSpans(content string, spans []TagSpan) string {
+	spans = validSpans(content, spans)
+	if len(spans) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(content[last:span.Start])
+		last = span.End
+	}
+	b.WriteString(content[last:])
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// taskPattern matches markdown checkboxes such as "- [ ] write report" or "* [x] done"
+var taskPattern = regexp.MustCompile(`(?m)^\s*[-*]\s+\[([ xX])\]\s+(.+?)\s*$`)
+
+// Task is a markdown checkbox found in an entry
+type Task struct {
+	ID          int64      `json:"id"`
+	EntryID     int64      `json:"entry_id"`
+	Position    int        `json:"position"`
+	Text        string     `json:"text"`
+	Done        bool       `json:"done"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type parsedTask struct {
+	text string
+	done bool
+}
+
+func parseTasks(content string) []parsedTask {
+	var tasks []parsedTask
+	for _, match := range taskPattern.FindAllStringSubmatch(content, -1) {
+		tasks = append(tasks, parsedTask{
+			text: match[2],
+			done: match[1] != " ",
+		})
+	}
+	return tasks
+}
+
+func (a *App) createTaskTables() error {
+	createTasksSQL := `
+	CREATE TABLE IF NOT EXISTS tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		log_entry_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		done INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		completed_at DATETIME,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE,
+		UNIQUE(log_entry_id, position)
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_done ON tasks(done);`
+
+	if _, err := a.db.Exec(createTasksSQL); err != nil {
+		return fmt.Errorf("failed to create tasks table: %v", err)
+	}
+	return nil
+}
+
+// syncTasks mirrors the checkboxes in an entry into the tasks table. Tasks are
+// matched by position so ticking a box in an edit records its completion time.
+func (a *App) syncTasks(ctx context.Context, entryID int64, content string) error {
+	tasks := parseTasks(content)
+	now := sqlTime(a.clock.Now())
+
+	for i, task := range tasks {
+		_, err := a.db.ExecContext(ctx, `
+			INSERT INTO tasks (log_entry_id, position, text, done, completed_at)
+			VALUES (?, ?, ?, ?, CASE WHEN ? THEN ? END)
+			ON CONFLICT(log_entry_id, position) DO UPDATE SET
+				text = excluded.text,
+				completed_at = CASE
+					WHEN excluded.done = 0 THEN NULL
+					WHEN tasks.done = 0 THEN excluded.completed_at
+					ELSE tasks.completed_at
+				END,
+				done = excluded.done`,
+			entryID, i, task.text, task.done, task.done, now)
+		if err != nil {
+			return fmt.Errorf("failed to save task: %v", err)
+		}
+	}
+
+	if _, err := a.db.ExecContext(ctx, `DELETE FROM tasks WHERE log_entry_id = ? AND position >= ?`, entryID, len(tasks)); err != nil {
+		return fmt.Errorf("failed to remove stale tasks: %v", err)
+	}
+	return nil
+}
+
+// GetOpenTasks returns unchecked tasks, oldest first
+func (a *App) GetOpenTasks(limit int) ([]Task, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	return a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE done = 0
+		ORDER BY created_at, log_entry_id, position
+		LIMIT ?`, limit)
+}
+
+// getEntryTasks returns the tasks of one entry in checkbox order
+func (a *App) getEntryTasks(entryID int) ([]Task, error) {
+	return a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE log_entry_id = ?
+		ORDER BY position`, entryID)
+}
+
+func (a *App) queryTasks(query string, args ...interface{}) ([]Task, error) {
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %v", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		var task Task
+		if err := rows.Scan(&task.ID, &task.EntryID, &task.Position, &task.Text, &task.Done, &task.CreatedAt, &task.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// formatTaskList renders tasks as a markdown checklist
+func formatTaskList(tasks []Task) string {
+	lines := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		mark := " "
+		if task.Done {
+			mark = "x"
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s", mark, task.Text))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,992 @@
+// Store original data for filtering
+let originalData = { totalEntries: 0, totalDays: 0, thisWeek: 0, dayGroups: [], tags: [] };
+const dataElement = document.getElementById('snaplog-data');
+if (dataElement) {
+    try {
+        const parsed = JSON.parse(dataElement.textContent || '{}');
+        if (parsed && typeof parsed === 'object') {
+            originalData = parsed;
+        }
+    } catch (err) {
+        console.warn('Unable to parse dashboard data', err);
+    }
+}
+
+// Selected tags for filtering
+let selectedTags = [];
+
+// Store currently displayed (filtered) day groups
+let currentFilteredDayGroups = [];
+
+function showDateError(message) {
+    const banner = document.getElementById('date-error');
+    if (!banner) return;
+    banner.textContent = message;
+    banner.classList.add('show');
+}
+
+function hideDateError() {
+    const banner = document.getElementById('date-error');
+    if (!banner) return;
+    banner.textContent = '';
+    banner.classList.remove('show');
+}
+
+function applyFilters() {
+    const startDate = document.getElementById('start-date').value;
+    const endDate = document.getElementById('end-date').value;
+
+    console.log('Filtering by date range:', startDate, 'to', endDate);
+    console.log('Selected tags:', selectedTags);
+    console.log('Available entries:', originalData.dayGroups.map(dg => ({ date: dg.date, count: dg.count })));
+
+    const selectedSource = document.getElementById('source-select').value;
+
+    hideDateError();
+
+    // If no filters are active, clear and show all
+    if (!startDate && !endDate && selectedTags.length === 0 && !selectedSource) {
+        clearFilter();
+        return;
+    }
+
+    const start = startDate ? parseLocalDate(startDate) : new Date('1900-01-01');
+    const end = endDate ? parseLocalDate(endDate) : new Date('2100-12-31');
+    // Set end date to end of day (23:59:59.999) for inclusive comparison
+    if (endDate) {
+        end.setHours(23, 59, 59, 999);
+    }
+
+    if (startDate && endDate && start > end) {
+        showDateError('Start date must be earlier than the end date.');
+        return;
+    }
+
+    console.log('Parsed dates:', start, 'to', end);
+
+    let filteredCount = 0;
+    let filteredDays = 0;
+
+    // Apply both date and tag filters together
+    const filteredDayGroups = [];
+    originalData.dayGroups.forEach(dayGroup => {
+        const dayDate = parseLocalDate(dayGroup.date);
+        console.log('Checking day:', dayGroup.date, 'against range:', start, 'to', end);
+
+        // First filter by date at the day level
+        if (dayDate < start || dayDate > end) {
+            return; // Skip this day group if it's outside the date range
+        }
+
+        // Filter entries by both date and tags
+        const filteredEntries = dayGroup.entries.filter(entry => {
+            // Check date filter - entry.date is already in YYYY-MM-DD format
+            const entryDate = parseLocalDate(entry.date);
+            const dateMatch = entryDate >= start && entryDate <= end;
+
+            if (!dateMatch) {
+                return false;
+            }
+
+            if (selectedSource && (entry.source || 'hotkey') !== selectedSource) {
+                return false;
+            }
+
+            // Check tag filter if tags are selected
+            if (selectedTags.length > 0) {
+                const tagMatch = selectedTags.every(tag => {
+                    // Tags are filed under their alias targets, so prefer the parsed list
+                    if (entry.tags) return entry.tags.includes(tag);
+                    const content = entry.rawContent || entry.content || '';
+                    return content.includes(`#${tag}`);
+                });
+                return tagMatch;
+            }
+
+            return true; // No tag filter, so include if date matches
+        });
+
+        if (filteredEntries.length > 0) {
+            filteredDayGroups.push({
+                dayName: dayGroup.dayName,
+                date: dayGroup.date,
+                count: filteredEntries.length,
+                entries: filteredEntries
+            });
+        }
+    });
+
+    filteredCount = filteredDayGroups.reduce((sum, dg) => sum + dg.count, 0);
+    filteredDays = filteredDayGroups.length;
+
+    console.log('Filtered results:', filteredCount, 'entries from', filteredDays, 'days');
+
+    // Update display
+    updateDisplay(filteredDayGroups, filteredCount, filteredDays);
+
+    // Show filter info
+    const filterInfo = document.getElementById('filter-info');
+    const filterDetails = document.getElementById('filter-details');
+    let filterText = `${filteredCount} entries from ${filteredDays} days`;
+    if (selectedTags.length > 0) {
+        filterText += ` with tags: ${selectedTags.map(t => `#${t}`).join(', ')}`;
+    }
+    if (selectedSource) {
+        filterText += ` from source: ${selectedSource}`;
+    }
+    if (startDate || endDate) {
+        const dateRange = startDate && endDate 
+            ? `${startDate} to ${endDate}`
+            : startDate 
+            ? `from ${startDate}`
+            : `until ${endDate}`;
+        filterText += ` (${dateRange})`;
+    }
+    filterDetails.textContent = filterText;
+    filterInfo.style.display = 'block';
+}
+
+// Keep filterByDate for backward compatibility, but make it call applyFilters
+function filterByDate() {
+    applyFilters();
+}
+
+// Helper function to format date as YYYY-MM-DD in local time
+function formatLocalDate(date) {
+    const year = date.getFullYear();
+    const month = String(date.getMonth() + 1).padStart(2, '0');
+    const day = String(date.getDate()).padStart(2, '0');
+    return `${year}-${month}-${day}`;
+}
+
+// Helper function to create date at local midnight from YYYY-MM-DD string
+function parseLocalDate(dateStr) {
+    const [year, month, day] = dateStr.split('-').map(Number);
+    return new Date(year, month - 1, day, 0, 0, 0, 0);
+}
+
+// logicalToday is now shifted back by the day start hour, so "today" matches the
+// server's day grouping when the day rolls over after midnight
+function logicalToday() {
+    return new Date(Date.now() - (originalData.dayStartHour || 0) * 60 * 60 * 1000);
+}
+
+function setQuickFilter(type, evt) {
+    const today = logicalToday();
+    const startDateInput = document.getElementById('start-date');
+    const endDateInput = document.getElementById('end-date');
+
+    // Remove active class from all buttons
+    document.querySelectorAll('.quick-filter-btn').forEach(btn => {
+        btn.classList.remove('active');
+        btn.setAttribute('aria-pressed', 'false');
+    });
+
+    // Add active class to clicked button
+    if (evt && evt.target) {
+        evt.target.classList.add('active');
+        evt.target.setAttribute('aria-pressed', 'true');
+    }
+
+    hideDateError();
+
+    switch(type) {
+        case 'today':
+            const todayStr = formatLocalDate(today);
+            startDateInput.value = todayStr;
+            endDateInput.value = todayStr;
+            console.log('Today filter set to:', todayStr);
+            break;
+        case 'week':
+            const weekStart = new Date(today);
+            weekStart.setDate(today.getDate() - today.getDay()); // Start of week (Sunday)
+            const weekEnd = new Date(weekStart);
+            weekEnd.setDate(weekStart.getDate() + 6); // End of week (Saturday)
+            startDateInput.value = formatLocalDate(weekStart);
+            endDateInput.value = formatLocalDate(weekEnd);
+            console.log('Week filter set to:', formatLocalDate(weekStart), 'to', formatLocalDate(weekEnd));
+            break;
+        case 'pastWeek':
+            const pastWeekEnd = new Date(today);
+            pastWeekEnd.setDate(today.getDate() - 1);
+            const pastWeekStart = new Date(pastWeekEnd);
+            pastWeekStart.setDate(pastWeekEnd.getDate() - 6);
+            startDateInput.value = formatLocalDate(pastWeekStart);
+            endDateInput.value = formatLocalDate(pastWeekEnd);
+            console.log('Past week filter set to:', formatLocalDate(pastWeekStart), 'to', formatLocalDate(pastWeekEnd));
+            break;
+        case 'month':
+            const monthStart = new Date(today.getFullYear(), today.getMonth(), 1);
+            const monthEnd = new Date(today.getFullYear(), today.getMonth() + 1, 0);
+            startDateInput.value = formatLocalDate(monthStart);
+            endDateInput.value = formatLocalDate(monthEnd);
+            console.log('Month filter set to:', formatLocalDate(monthStart), 'to', formatLocalDate(monthEnd));
+            break;
+    }
+
+    // Apply the filter
+    filterByDate();
+}
+
+function clearFilter() {
+    // Reset date inputs
+    document.getElementById('start-date').value = '';
+    document.getElementById('end-date').value = '';
+
+    // Remove active class from all quick filter buttons
+    document.querySelectorAll('.quick-filter-btn').forEach(btn => {
+        btn.classList.remove('active');
+    });
+
+    // Clear selected tags
+    selectedTags = [];
+    renderSelectedTags();
+    document.getElementById('source-select').value = '';
+
+    // Hide filter info
+    document.getElementById('filter-info').style.display = 'none';
+    hideDateError();
+
+    // Restore original display
+    updateDisplay(originalData.dayGroups, originalData.pageEntries ?? originalData.totalEntries, originalData.totalDays);
+    // Update current filtered groups to all original data
+    currentFilteredDayGroups = originalData.dayGroups;
+}
+
+function addTag() {
+    const select = document.getElementById('tag-select');
+    const tagName = select.value;
+
+    if (tagName && !selectedTags.includes(tagName)) {
+        selectedTags.push(tagName);
+        renderSelectedTags();
+        filterByTags();
+    }
+
+    // Reset select
+    select.value = '';
+}
+
+// Clicking a tag inside an entry opens the dashboard for that tag, which pages through
+// all of its entries rather than filtering the current page
+document.addEventListener('click', event => {
+    const chip = event.target.closest('.entry-tag');
+    if (!chip || !document.getElementById('selected-tags')) return;
+    event.preventDefault();
+    window.location.href = '/dash?tag=' + encodeURIComponent(chip.dataset.tag);
+});
+
+function removeTag(tagName) {
+    selectedTags = selectedTags.filter(tag => tag !== tagName);
+    renderSelectedTags();
+    filterByTags();
+}
+
+function renderSelectedTags() {
+    const container = document.getElementById('selected-tags');
+    container.innerHTML = '';
+
+    selectedTags.forEach(tagName => {
+        const chip = document.createElement('div');
+        chip.className = 'tag-chip';
+        chip.innerHTML = `<span title="#${tagName}">#${tagName}</span><button type="button" class="tag-remove" onclick="removeTag('${tagName}')" aria-label="Remove #${tagName}">×</button>`;
+        container.appendChild(chip);
+    });
+}
+
+// Keep filterByTags for backward compatibility, but make it call applyFilters
+function filterByTags() {
+    applyFilters();
+}
+
+function updateDisplay(dayGroups, totalCount, totalDays) {
+    // Store the currently displayed day groups for copying
+    currentFilteredDayGroups = dayGroups;
+
+    // Update stats
+    document.getElementById('total-entries').textContent = originalData.totalEntries;
+    document.getElementById('total-days').textContent = originalData.totalDays;
+    document.getElementById('this-week').textContent = originalData.thisWeek;
+    document.getElementById('filtered-count').textContent = totalCount;
+
+    // Update entries container
+    const container = document.getElementById('entries-container');
+
+    if (dayGroups.length === 0) {
+        container.innerHTML = `
+            <div class="no-entries">
+                <div class="no-entries-icon" aria-hidden="true">🔍</div>
+                <p>No entries found for the selected date range.</p>
+            </div>
+        `;
+        return;
+    }
+
+    let html = '';
+    dayGroups.forEach(dayGroup => {
+        html += `
+            <section class="day-group" data-date="${dayGroup.date}" aria-labelledby="day-${dayGroup.date}">
+                <div class="day-header" onclick="toggleDay('${dayGroup.date}')">
+                    <h2 class="day-info" id="day-${dayGroup.date}">
+                        <button type="button" class="day-toggle" id="toggle-${dayGroup.date}" aria-expanded="true" aria-controls="content-${dayGroup.date}" aria-label="Entries of ${dayGroup.dayName} ${dayGroup.date}">▼</button>
+                        <span class="day-name">${dayGroup.dayName}</span>
+                        <span class="day-date">${dayGroup.date}</span>
+                    </h2>
+                    <div class="day-header-actions" onclick="event.stopPropagation();">
+                        <div class="day-count">${dayGroup.count} entries</div>
+                        <button type="button" class="copy-day-btn" onclick="copyDayToClipboard('${dayGroup.date}', event)" title="Copy all entries for this day" aria-label="Copy all entries of ${dayGroup.dayName}">📋</button>
+                    </div>
+                </div>
+                <div class="day-content" id="content-${dayGroup.date}">
+                    <div class="entries-container" role="list">
+        `;
+
+        dayGroup.entries.forEach(entry => {
+            html += `
+                <article class="entry${entry.entryType === 'milestone' ? ' milestone' : ''}" role="listitem" data-date="${entry.date}" data-id="${entry.id}" aria-labelledby="time-${entry.id}">
+                    <a class="entry-time" id="time-${entry.id}" href="/dash/entry/${entry.id}" title="${entry.localTimeFull || entry.localTime}"><time datetime="${entry.createdAt}">${entry.localTime}</time></a>
+                    <div class="entry-content-wrapper">
+                        <div class="entry-content">${entry.content}</div>
+                        ${entry.source && entry.source !== 'hotkey' ? `<span class="entry-source" title="Created via ${entry.source}">${entry.source}</span>` : ''}
+                        ${entry.repeatCount > 1 ? `<span class="entry-source" title="Similar events merged into this entry">×${entry.repeatCount}</span>` : ''}
+                    </div>
+                    <div class="entry-actions">
+                        <button type="button" class="copy-btn" onclick="copyToClipboard('${entry.id}')" title="Copy text" aria-label="Copy text">📋</button>
+                        <button type="button" class="copy-btn" onclick="copyPermalink('${entry.id}')" title="Copy link to entry" aria-label="Copy link to entry">🔗</button>
+                        <button type="button" class="edit-btn" onclick="editEntry('${entry.id}')" title="Edit entry" aria-label="Edit entry">✏️</button>
+                        <button type="button" class="edit-btn" onclick="splitEntry('${entry.id}')" title="Split entry" aria-label="Split entry">✂️</button>
+                        <input type="checkbox" class="merge-select" value="${entry.id}" onchange="updateMergeBar()" title="Select to merge" aria-label="Select entry to merge">
+                        <button type="button" class="delete-btn" onclick="copyDeleteCommand('${entry.id}')" title="Delete entry" aria-label="Delete entry">🗑️</button>
+                    </div>
+                </article>
+            `;
+        });
+
+        html += `
+                    </div>
+                </div>
+            </section>
+        `;
+    });
+
+    container.innerHTML = html;
+    // re-rendering drops the ticks of the merge selection
+    updateMergeBar();
+}
+
+function copyToClipboard(entryId) {
+    // Find the entry content by looking for the entry with this ID
+    const entries = document.querySelectorAll('.entry');
+    let targetEntry = null;
+
+    for (let entry of entries) {
+        if (entry.getAttribute('data-id') === entryId) {
+            targetEntry = entry;
+            break;
+        }
+    }
+
+    if (!targetEntry) {
+        console.error('Entry not found:', entryId);
+        return;
+    }
+
+    const contentElement = targetEntry.querySelector('.entry-content');
+    if (!contentElement) {
+        console.error('Content element not found');
+        return;
+    }
+
+    // Get text content (strip HTML)
+    const textContent = contentElement.textContent || contentElement.innerText;
+
+    // Copy to clipboard
+    navigator.clipboard.writeText(textContent).then(() => {
+        showCopyFeedback();
+        // Visual feedback on the button
+        const copyBtn = targetEntry.querySelector('.copy-btn');
+        if (copyBtn) {
+            copyBtn.classList.add('copied');
+            setTimeout(() => {
+                copyBtn.classList.remove('copied');
+            }, 2000);
+        }
+    }).catch(err => {
+        console.error('Failed to copy: ', err);
+        // Fallback for older browsers
+        const textArea = document.createElement('textarea');
+        textArea.value = textContent;
+        document.body.appendChild(textArea);
+        textArea.select();
+        document.execCommand('copy');
+        document.body.removeChild(textArea);
+        showCopyFeedback();
+    });
+}
+
+function toggleDay(dateKey) {
+    const toggle = document.getElementById(`toggle-${dateKey}`);
+    const content = document.getElementById(`content-${dateKey}`);
+
+    if (!toggle || !content) return;
+
+    if (content.classList.contains('collapsed')) {
+        // Expand
+        content.classList.remove('collapsed');
+        toggle.classList.remove('collapsed');
+        toggle.textContent = '▼';
+        toggle.setAttribute('aria-expanded', 'true');
+    } else {
+        // Collapse
+        content.classList.add('collapsed');
+        toggle.classList.add('collapsed');
+        toggle.textContent = '▶';
+        toggle.setAttribute('aria-expanded', 'false');
+    }
+}
+
+function showCopyFeedback() {
+    const feedback = document.getElementById('copy-feedback');
+    feedback.classList.add('show');
+    setTimeout(() => {
+        feedback.classList.remove('show');
+    }, 2000);
+}
+
+function copyPermalink(entryId) {
+    const link = `${window.location.origin}/dash/entry/${entryId}`;
+    navigator.clipboard.writeText(link).then(() => {
+        showCopyFeedback();
+    }).catch(err => {
+        console.error('Failed to copy: ', err);
+        // Fallback for older browsers
+        const textArea = document.createElement('textarea');
+        textArea.value = link;
+        document.body.appendChild(textArea);
+        textArea.select();
+        document.execCommand('copy');
+        document.body.removeChild(textArea);
+        showCopyFeedback();
+    });
+}
+
+function findEntryData(entryId) {
+    for (const dayGroup of originalData.dayGroups) {
+        const entry = dayGroup.entries.find(e => String(e.id) === String(entryId));
+        if (entry) return entry;
+    }
+    return null;
+}
+
+function editEntry(entryId) {
+    // Edit in place; Ctrl/Cmd+Enter saves, Escape cancels
+    const entryElement = document.querySelector(`.entry[data-id="${entryId}"]`);
+    const entryData = findEntryData(entryId);
+    if (!entryElement || !entryData) {
+        alert('Entry not found');
+        return;
+    }
+    const wrapper = entryElement.querySelector('.entry-content-wrapper');
+    if (wrapper.querySelector('.entry-editor')) return;
+
+    const contentElement = wrapper.querySelector('.entry-content');
+    const editor = document.createElement('div');
+    editor.className = 'entry-editor';
+    editor.innerHTML = `
+        <textarea rows="4" aria-label="Entry text"></textarea>
+        <div class="entry-editor-actions">
+            <button class="entry-editor-save">Save</button>
+            <button class="entry-editor-cancel">Cancel</button>
+        </div>
+    `;
+    const textarea = editor.querySelector('textarea');
+    textarea.value = entryData.rawContent || '';
+
+    const close = () => {
+        editor.remove();
+        contentElement.style.display = '';
+    };
+    const save = () => {
+        const content = textarea.value.trim();
+        if (!content) {
+            alert('Content cannot be empty');
+            return;
+        }
+        fetch(`/api/entries/${entryId}`, {
+            method: 'PATCH',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ content })
+        })
+        .then(response => response.json().then(data => {
+            if (!response.ok) {
+                throw new Error(data.message || 'Failed to update entry');
+            }
+            return data;
+        }))
+        .then(() => {
+            // Reload so tags, counts and rendering reflect the edit
+            window.location.reload();
+        })
+        .catch(err => {
+            console.error('Failed to update entry:', err);
+            alert(`Failed to update entry: ${err.message}`);
+        });
+    };
+
+    editor.querySelector('.entry-editor-save').addEventListener('click', save);
+    editor.querySelector('.entry-editor-cancel').addEventListener('click', close);
+    textarea.addEventListener('keydown', e => {
+        if (e.key === 'Enter' && (e.ctrlKey || e.metaKey)) {
+            e.preventDefault();
+            save();
+        } else if (e.key === 'Escape') {
+            close();
+        }
+    });
+
+    contentElement.style.display = 'none';
+    wrapper.insertBefore(editor, contentElement);
+    textarea.focus();
+}
+
+function updateMergeBar() {
+    // The bar shows while entries are ticked; merging needs at least two
+    const selected = document.querySelectorAll('.merge-select:checked');
+    document.querySelectorAll('.entry').forEach(entry => {
+        const box = entry.querySelector('.merge-select');
+        entry.classList.toggle('selected', Boolean(box && box.checked));
+    });
+    const bar = document.getElementById('merge-bar');
+    if (!bar) return;
+    bar.hidden = selected.length === 0;
+    document.getElementById('merge-count').textContent = `${selected.length} selected`;
+    bar.querySelector('.merge-bar-merge').disabled = selected.length < 2;
+}
+
+function clearMergeSelection() {
+    document.querySelectorAll('.merge-select:checked').forEach(box => {
+        box.checked = false;
+    });
+    updateMergeBar();
+}
+
+function mergeSelected() {
+    const ids = Array.from(document.querySelectorAll('.merge-select:checked'), box => Number(box.value));
+    if (ids.length < 2) return;
+    if (!confirm(`Merge ${ids.length} entries into the earliest one? The others move to the trash.`)) return;
+
+    fetch('/api/entries/merge', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ ids })
+    })
+    .then(response => response.json().then(data => {
+        if (!response.ok) {
+            throw new Error(data.message || 'Failed to merge entries');
+        }
+        return data;
+    }))
+    .then(() => {
+        window.location.reload();
+    })
+    .catch(err => {
+        console.error('Failed to merge entries:', err);
+        alert(`Failed to merge entries: ${err.message}`);
+    });
+}
+
+function moveSelected(select) {
+    // Files the ticked entries under another journal, one request per entry
+    const journal = select.value;
+    select.value = '';
+    const ids = Array.from(document.querySelectorAll('.merge-select:checked'), box => box.value);
+    if (!journal || ids.length === 0) return;
+
+    Promise.all(ids.map(id => fetch(`/api/entries/${id}/move`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ journal })
+    })
+    .then(response => response.json().then(data => {
+        if (!response.ok) {
+            throw new Error(data.message || 'Failed to move entry');
+        }
+        return data;
+    }))))
+    .then(() => {
+        window.location.reload();
+    })
+    .catch(err => {
+        console.error('Failed to move entries:', err);
+        alert(`Failed to move entries: ${err.message}`);
+    });
+}
+
+function splitEntry(entryId) {
+    // Tick the lines that start a new entry; the text before the first one stays in this entry
+    const entryElement = document.querySelector(`.entry[data-id="${entryId}"]`);
+    const entryData = findEntryData(entryId);
+    if (!entryElement || !entryData) {
+        alert('Entry not found');
+        return;
+    }
+    const wrapper = entryElement.querySelector('.entry-content-wrapper');
+    if (wrapper.querySelector('.entry-editor')) return;
+
+    const lines = (entryData.rawContent || '').split('\n');
+    if (lines.filter(line => line.trim()).length < 2) {
+        alert('Only an entry with more than one line can be split');
+        return;
+    }
+
+    const contentElement = wrapper.querySelector('.entry-content');
+    const editor = document.createElement('div');
+    editor.className = 'entry-editor entry-splitter';
+    const list = document.createElement('div');
+    // offsets count characters, not UTF-16 units, as the API does
+    let offset = 0;
+    let seenText = false;
+    lines.forEach(line => {
+        const row = document.createElement('label');
+        row.className = 'split-line';
+        if (seenText && line.trim()) {
+            const box = document.createElement('input');
+            box.type = 'checkbox';
+            box.dataset.offset = offset;
+            box.setAttribute('aria-label', `Start a new entry at "${line.trim()}"`);
+            row.appendChild(box);
+        }
+        seenText = seenText || Boolean(line.trim());
+        row.appendChild(document.createTextNode(line || ' '));
+        list.appendChild(row);
+        offset += Array.from(line).length + 1;
+    });
+    editor.appendChild(list);
+
+    const actions = document.createElement('div');
+    actions.className = 'entry-editor-actions';
+    actions.innerHTML = `
+        <button class="entry-editor-save">Split</button>
+        <button class="entry-editor-cancel">Cancel</button>
+    `;
+    editor.appendChild(actions);
+
+    const close = () => {
+        editor.remove();
+        contentElement.style.display = '';
+    };
+    const save = () => {
+        const offsets = Array.from(list.querySelectorAll('input:checked'), box => Number(box.dataset.offset));
+        if (offsets.length === 0) {
+            alert('Tick the lines where new entries should start');
+            return;
+        }
+        fetch(`/api/entries/${entryId}/split`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ offsets })
+        })
+        .then(response => response.json().then(data => {
+            if (!response.ok) {
+                throw new Error(data.message || 'Failed to split entry');
+            }
+            return data;
+        }))
+        .then(() => {
+            window.location.reload();
+        })
+        .catch(err => {
+            console.error('Failed to split entry:', err);
+            alert(`Failed to split entry: ${err.message}`);
+        });
+    };
+
+    actions.querySelector('.entry-editor-save').addEventListener('click', save);
+    actions.querySelector('.entry-editor-cancel').addEventListener('click', close);
+    editor.addEventListener('keydown', e => {
+        if (e.key === 'Escape') {
+            close();
+        }
+    });
+
+    contentElement.style.display = 'none';
+    wrapper.insertBefore(editor, contentElement);
+    const firstBox = list.querySelector('input');
+    if (firstBox) firstBox.focus();
+}
+
+function copyDeleteCommand(entryId) {
+    // Delete directly via API, asking first unless confirmation is turned off in settings
+    if (originalData.confirmDelete === false || confirm('Are you sure you want to delete this entry?')) {
+        // Get references before deletion
+        const entryElement = document.querySelector(`.entry[data-id="${entryId}"]`);
+        if (!entryElement) {
+            alert('Entry not found');
+            return;
+        }
+
+        fetch(`/api/entries/${entryId}?confirm=true`, {
+            method: 'DELETE'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to delete entry');
+            }
+            return response.json();
+        })
+        .then(data => {
+            // Fade out animation
+            entryElement.style.transition = 'opacity 0.3s';
+            entryElement.style.opacity = '0';
+
+            // Refresh page after animation to ensure data consistency
+            setTimeout(() => {
+                window.location.reload();
+            }, 300);
+        })
+        .catch(err => {
+            console.error('Failed to delete entry:', err);
+            alert('Failed to delete entry. Please try again.');
+        });
+    }
+}
+
+function copyDayToClipboard(dateKey, event) {
+    if (event) {
+        event.stopPropagation();
+    }
+
+    // Find the day group in the original data to get all entries for this day
+    const dayGroupData = originalData.dayGroups.find(dg => dg.date === dateKey);
+    if (!dayGroupData) {
+        console.error('Day group not found in data:', dateKey);
+        return;
+    }
+
+    if (dayGroupData.entries.length === 0) {
+        console.error('No entries found for day:', dateKey);
+        return;
+    }
+
+    // Format day header
+    const dayName = dayGroupData.dayName || '';
+    const date = dayGroupData.date || dateKey;
+    let dayText = `${dayName}, ${formatDateForDisplay(date)}\n`;
+    dayGroupData.entries.forEach(entry => {
+        const time = entry.localTime || '';
+        // Use rawContent if available, otherwise use content (which might be HTML)
+        const content = entry.rawContent || entry.content || '';
+        // If content is HTML, extract text content
+        let textContent = content;
+        if (content.includes('<')) {
+            // Create a temporary element to extract text from HTML
+            const tempDiv = document.createElement('div');
+            tempDiv.innerHTML = content;
+            textContent = tempDiv.textContent || tempDiv.innerText || '';
+        }
+        dayText += `[${time}] ${textContent}\n`;
+    });
+
+    // Copy to clipboard
+    navigator.clipboard.writeText(dayText.trim()).then(() => {
+        showCopyFeedback();
+    }).catch(err => {
+        console.error('Failed to copy: ', err);
+        // Fallback for older browsers
+        const textArea = document.createElement('textarea');
+        textArea.value = dayText.trim();
+        document.body.appendChild(textArea);
+        textArea.select();
+        document.execCommand('copy');
+        document.body.removeChild(textArea);
+        showCopyFeedback();
+    });
+}
+
+function copyAllFilteredEntries() {
+    // Use the currently displayed (filtered) day groups
+    let dayGroupsToCopy = currentFilteredDayGroups;
+    if (dayGroupsToCopy.length === 0) {
+        // If no filtered groups, use all original data
+        dayGroupsToCopy = originalData.dayGroups;
+    }
+
+    if (dayGroupsToCopy.length === 0) {
+        console.error('No entries to copy');
+        return;
+    }
+
+    let allText = '';
+
+    // Format each day group
+    dayGroupsToCopy.forEach((dayGroup, index) => {
+        const dayName = dayGroup.dayName || '';
+        const date = dayGroup.date || '';
+
+        // Add day header
+        allText += `${dayName}, ${formatDateForDisplay(date)}\n`;
+
+        // Add all entries for this day (compact, no extra whitespace between entries)
+        dayGroup.entries.forEach(entry => {
+            const time = entry.localTime || '';
+            // Use rawContent if available, otherwise use content
+            const content = entry.rawContent || entry.content || '';
+            // If content is HTML, extract text content
+            let textContent = content;
+            if (content.includes('<')) {
+                // Create a temporary element to extract text from HTML
+                const tempDiv = document.createElement('div');
+                tempDiv.innerHTML = content;
+                textContent = tempDiv.textContent || tempDiv.innerText || '';
+            }
+            allText += `[${time}] ${textContent}\n`;
+        });
+
+        // Add spacing between days (but not after the last day)
+        if (index < dayGroupsToCopy.length - 1) {
+            allText += '\n';
+        }
+    });
+
+    // Copy to clipboard
+    navigator.clipboard.writeText(allText.trim()).then(() => {
+        showCopyFeedback();
+    }).catch(err => {
+        console.error('Failed to copy: ', err);
+        // Fallback for older browsers
+        const textArea = document.createElement('textarea');
+        textArea.value = allText.trim();
+        document.body.appendChild(textArea);
+        textArea.select();
+        document.execCommand('copy');
+        document.body.removeChild(textArea);
+        showCopyFeedback();
+    });
+}
+
+function exportAsMarkdown() {
+    // Get currently displayed entries
+    const container = document.getElementById('entries-container');
+    const dayGroups = container.querySelectorAll('.day-group');
+
+    if (dayGroups.length === 0) {
+        alert('No entries to export');
+        return;
+    }
+
+    const stripTags = document.getElementById('strip-tags')?.checked;
+    let markdown = '# SnapLog Export\n\n';
+    markdown += `Generated: ${new Date().toLocaleString()}\n\n`;
+    markdown += '---\n\n';
+
+    dayGroups.forEach(dayGroup => {
+        const date = dayGroup.getAttribute('data-date');
+        const dayName = dayGroup.querySelector('.day-name')?.textContent || date;
+        const entries = dayGroup.querySelectorAll('.entry');
+
+        markdown += `## ${dayName} (${date})\n\n`;
+
+        entries.forEach(entry => {
+            const timeElement = entry.querySelector('.entry-time');
+            const contentElement = entry.querySelector('.entry-content');
+            if (timeElement && contentElement) {
+                const time = timeElement.textContent.trim();
+                // Get the raw markdown content from the data
+                const entryId = entry.getAttribute('data-id');
+                // Find the original entry data to get raw markdown
+                let rawContent = '';
+                for (let dg of originalData.dayGroups) {
+                    const foundEntry = dg.entries.find(e => e.id.toString() === entryId);
+                    if (foundEntry) {
+                        rawContent = stripTags ? foundEntry.prose : (foundEntry.rawContent || foundEntry.content);
+                        break;
+                    }
+                }
+                // Fallback to text content if raw not found
+                if (!rawContent) {
+                    rawContent = contentElement.textContent || contentElement.innerText;
+                }
+                markdown += `### ${time}\n\n${rawContent}\n\n---\n\n`;
+            }
+        });
+    });
+
+    // Create a blob and download
+    const blob = new Blob([markdown], { type: 'text/markdown;charset=utf-8' });
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement('a');
+    const filename = `snaplog-export-${new Date().toISOString().split('T')[0]}.md`;
+    a.href = url;
+    a.download = filename;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+
+    // Let export script hooks know
+    fetch('/api/hooks/export', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+            format: 'markdown',
+            filename: filename,
+            entry_count: container.querySelectorAll('.entry').length,
+            content: markdown
+        })
+    }).catch(err => console.error('Failed to notify export hooks:', err));
+}
+
+function formatDateForDisplay(isoDate) {
+    const date = new Date(isoDate);
+    return date.toLocaleDateString('en-US', { 
+        month: 'short', 
+        day: 'numeric', 
+        year: 'numeric' 
+    });
+}
+
+// Set default date range to last 7 days
+document.addEventListener('DOMContentLoaded', function() {
+    console.log('Dashboard loaded with data:', originalData);
+    console.log('Available day groups:', originalData.dayGroups.map(dg => ({ 
+        dayName: dg.dayName, 
+        date: dg.date, 
+        count: dg.count,
+        entryDates: dg.entries.map(e => e.date)
+    })));
+
+    // Initialize current filtered day groups with all original data
+    currentFilteredDayGroups = originalData.dayGroups;
+
+    // Format all day dates for display
+    document.querySelectorAll('.day-date[data-iso-date]').forEach(element => {
+        const isoDate = element.getAttribute('data-iso-date');
+        element.textContent = formatDateForDisplay(isoDate);
+    });
+
+    // Show tag filter section if tags exist
+    if (originalData.tags && originalData.tags.length > 0) {
+        document.getElementById('tag-filter-section').style.display = 'block';
+    }
+
+    // Saved filters from custom commands arrive as query parameters
+    const params = new URLSearchParams(window.location.search);
+    if (params.has('start') || params.has('end') || params.has('tags') || params.has('source')) {
+        document.getElementById('start-date').value = params.get('start') || '';
+        document.getElementById('end-date').value = params.get('end') || '';
+        document.getElementById('source-select').value = params.get('source') || '';
+        selectedTags = (params.get('tags') || '').split(',').map(t => t.trim().replace(/^#/, '')).filter(t => /^[a-zA-Z0-9_-]+$/.test(t));
+        renderSelectedTags();
+        applyFilters();
+        return;
+    }
+
+    const today = logicalToday();
+    const lastWeek = new Date(today.getTime() - 7 * 24 * 60 * 60 * 1000);
+
+    document.getElementById('end-date').value = formatLocalDate(today);
+    document.getElementById('start-date').value = formatLocalDate(lastWeek);
+
+    // Apply initial filter
+    filterByDate();
+});
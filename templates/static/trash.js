@@ -0,0 +1,39 @@
+// Restore and empty-trash buttons of /dash/trash
+const trashStatus = document.getElementById('trash-status');
+
+function showTrashError(message) {
+    trashStatus.setAttribute('role', 'alert');
+    trashStatus.textContent = message;
+}
+
+document.querySelectorAll('[data-restore]').forEach(button => {
+    button.addEventListener('click', () => {
+        const id = button.dataset.restore;
+        fetch(`/api/trash/${id}/restore`, { method: 'POST' })
+            .then(response => response.json().then(data => {
+                if (!response.ok) {
+                    throw new Error(data.message || 'Failed to restore entry');
+                }
+                button.closest('.result').remove();
+                trashStatus.textContent = `Entry #${id} restored`;
+            }))
+            .catch(error => showTrashError(error.message));
+    });
+});
+
+const purgeButton = document.getElementById('purge-trash');
+if (purgeButton) {
+    purgeButton.addEventListener('click', () => {
+        if (purgeButton.dataset.confirm === 'true' && !confirm('Delete every entry in the trash for good? This cannot be undone.')) {
+            return;
+        }
+        fetch('/api/trash?confirm=true', { method: 'DELETE' })
+            .then(response => response.json().then(data => {
+                if (!response.ok) {
+                    throw new Error(data.message || 'Failed to empty the trash');
+                }
+                window.location.reload();
+            }))
+            .catch(error => showTrashError(error.message));
+    });
+}
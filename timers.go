@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+var timerPattern = regexp.MustCompile(`(?i)^\s*timer:`)
+
+// ActiveTimer is the timer started with /timer and not yet stopped. TaskID is set when
+// the timer was started for an open task.
+type ActiveTimer struct {
+	ID        int64     `json:"id"`
+	EntryID   int64     `json:"entry_id"`
+	TaskID    *int64    `json:"task_id,omitempty"`
+	Label     string    `json:"label"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// TimerSpan is a stopped timer: what it was for and how long it ran
+type TimerSpan struct {
+	EntryID   int64     `json:"entry_id"`
+	TaskID    *int64    `json:"task_id,omitempty"`
+	Label     string    `json:"label"`
+	StartedAt time.Time `json:"started_at"`
+	Minutes   float64   `json:"minutes"`
+}
+
+func (a *App) createTimerTables() error {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS timers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		log_entry_id INTEGER NOT NULL,
+		task_id INTEGER,
+		label TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		FOREIGN KEY (log_entry_id) REFERENCES log_entries(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_timers_started ON timers(started_at);`
+
+	if _, err := a.db.Exec(createSQL); err != nil {
+		return fmt.Errorf("failed to create timers table: %v", err)
+	}
+	return nil
+}
+
+// StartTimer logs "Timer: <label>" and starts timing it. When the label is the text of
+// an open task, tags aside, the timer counts towards that task.
+func (a *App) StartTimer(label string) (*ActiveTimer, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, fmt.Errorf("invalid command. Usage: /timer <task or activity> [#tag]")
+	}
+	if active, err := a.GetActiveTimer(); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf("the timer for %q is still running: /stoptimer first", active.Label)
+	}
+
+	timer := &ActiveTimer{Label: label, StartedAt: a.clock.Now()}
+	tasks, err := a.queryTasks(`
+		SELECT id, log_entry_id, position, text, done, created_at, completed_at
+		FROM tasks WHERE done = 0 ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if sameTaskTitle(task.Text, label) {
+			timer.TaskID = &task.ID
+			break
+		}
+	}
+
+	if timer.EntryID, err = a.logEntry("Timer: "+label, sourceHotkey); err != nil {
+		return nil, err
+	}
+	result, err := a.db.Exec(`INSERT INTO timers (log_entry_id, task_id, label, started_at) VALUES (?, ?, ?, ?)`,
+		timer.EntryID, timer.TaskID, label, sqlTime(timer.StartedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start timer: %v", err)
+	}
+	timer.ID, _ = result.LastInsertId()
+
+	a.logf("Timer started: %s (entry %d)\n", label, timer.EntryID)
+	a.emitTimer(timer)
+	return timer, nil
+}
+
+// GetActiveTimer returns the running timer, or nil when there is none
+func (a *App) GetActiveTimer() (*ActiveTimer, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	var timer ActiveTimer
+	err := a.db.QueryRow(`SELECT id, log_entry_id, task_id, label, started_at FROM timers
+		WHERE ended_at IS NULL ORDER BY started_at DESC LIMIT 1`).Scan(&timer.ID, &timer.EntryID, &timer.TaskID, &timer.Label, &timer.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active timer: %v", err)
+	}
+	return &timer, nil
+}
+
+// StopTimer stops the running timer, appends its duration to the timer entry and
+// returns the duration
+func (a *App) StopTimer() (string, error) {
+	timer, err := a.GetActiveTimer()
+	if err != nil {
+		return "", err
+	}
+	if timer == nil {
+		return "", fmt.Errorf("no timer is running: start one with /timer")
+	}
+
+	now := a.clock.Now()
+	if _, err := a.db.Exec(`UPDATE timers SET ended_at = ? WHERE id = ?`, sqlTime(now), timer.ID); err != nil {
+		return "", fmt.Errorf("failed to stop timer: %v", err)
+	}
+	duration := formatMeetingDuration(now.Sub(timer.StartedAt))
+	if entry, err := a.GetEntryByID(int(timer.EntryID)); err == nil {
+		if err := a.UpdateEntry(entry.ID, entry.Content+"\nDuration: "+duration); err != nil {
+			a.logf("Warning: failed to add duration to timer entry %d: %v\n", entry.ID, err)
+		}
+	}
+
+	a.logf("Timer stopped: %s after %s\n", timer.Label, duration)
+	a.emitTimer(nil)
+	return duration, nil
+}
+
+// sameTaskTitle reports whether two task texts are the same once tags, estimates, case
+// and spacing are set aside
+func sameTaskTitle(a, b string) bool {
+	return strings.EqualFold(planTaskTitle(stripTags(a)), planTaskTitle(stripTags(b)))
+}
+
+// timerSpans returns the stopped timers started in [from, to), oldest first, leaving out
+// those of entries in the trash
+func (a *App) timerSpans(from, to time.Time) ([]TimerSpan, error) {
+	rows, err := a.db.Query(`SELECT t.log_entry_id, t.task_id, t.label, t.started_at,
+			(julianday(t.ended_at) - julianday(t.started_at)) * 1440
+		FROM timers t JOIN log_entries e ON e.id = t.log_entry_id
+		WHERE e.deleted_at IS NULL AND t.ended_at IS NOT NULL AND t.started_at >= ? AND t.started_at < ?
+		ORDER BY t.started_at`, sqlTime(from), sqlTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timers: %v", err)
+	}
+	defer rows.Close()
+
+	var spans []TimerSpan
+	for rows.Next() {
+		var span TimerSpan
+		if err := rows.Scan(&span.EntryID, &span.TaskID, &span.Label, &span.StartedAt, &span.Minutes); err != nil {
+			return nil, fmt.Errorf("failed to scan timer: %v", err)
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+// emitTimer tells the input window a timer started or stopped (nil)
+func (a *App) emitTimer(timer *ActiveTimer) {
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "timer", timer)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimesheetLine is the time timed for one project on one day. Minutes is what the timers
+// ran; BilledMinutes is that rounded up to the project's increment and is what Hours and
+// Amount are based on.
+type TimesheetLine struct {
+	Client        string  `json:"client"`
+	Project       string  `json:"project"`
+	Date          string  `json:"date"`
+	Minutes       float64 `json:"minutes"`
+	BilledMinutes int     `json:"billed_minutes"`
+	Hours         float64 `json:"hours"`
+	Rate          float64 `json:"rate"`
+	Amount        float64 `json:"amount"`
+}
+
+// TimesheetReport says where ExportTimesheet wrote the month's CSV and PDF and what
+// they add up to
+type TimesheetReport struct {
+	Month   string  `json:"month"`
+	CSVPath string  `json:"csv_path"`
+	PDFPath string  `json:"pdf_path"`
+	Lines   int     `json:"lines"`
+	Hours   float64 `json:"hours"`
+	Amount  float64 `json:"amount"`
+}
+
+// GetTimesheet sums the timers of a month (2006-01) per project and logical day, sorted
+// by client, project and date with unbilled time last. A timer belongs to the project whose tag is on the timer
+// entry or on its task's entry; timers of no project are listed without one.
+func (a *App) GetTimesheet(month string) ([]TimesheetLine, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+	first, err := time.ParseInLocation("2006-01", strings.TrimSpace(month), time.Local)
+	if err != nil {
+		return nil, newAPIError(codeInvalidRequest, fmt.Sprintf("invalid month %q: use YYYY-MM", month))
+	}
+
+	projects, err := a.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	rows, err := a.db.Query(`SELECT t.started_at, (julianday(t.ended_at) - julianday(t.started_at)) * 1440,
+			(SELECT p.id FROM projects p
+				JOIN tags tg ON tg.name = p.tag COLLATE NOCASE
+				JOIN log_entries_tags lt ON lt.tag_id = tg.id
+				WHERE lt.log_entry_id = t.log_entry_id
+					OR lt.log_entry_id = (SELECT log_entry_id FROM tasks WHERE id = t.task_id)
+				ORDER BY p.name LIMIT 1)
+		FROM timers t JOIN log_entries e ON e.id = t.log_entry_id
+		WHERE e.deleted_at IS NULL AND t.ended_at IS NOT NULL AND t.started_at >= ? AND t.started_at < ?`,
+		sqlTime(a.dayStart(first)), sqlTime(a.dayStart(first.AddDate(0, 1, 0))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timers: %v", err)
+	}
+	defer rows.Close()
+
+	type lineKey struct {
+		project int64
+		date    string
+	}
+	lines := make(map[lineKey]*TimesheetLine)
+	for rows.Next() {
+		var startedAt time.Time
+		var minutes float64
+		var projectID *int64
+		if err := rows.Scan(&startedAt, &minutes, &projectID); err != nil {
+			return nil, fmt.Errorf("failed to scan timer: %v", err)
+		}
+		key := lineKey{date: a.dayKey(startedAt)}
+		if projectID != nil {
+			key.project = *projectID
+		}
+		line := lines[key]
+		if line == nil {
+			project := byID[key.project]
+			line = &TimesheetLine{Client: project.Client, Project: project.Name, Date: key.date, Rate: project.HourlyRate}
+			lines[key] = line
+		}
+		line.Minutes += minutes
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read timers: %v", err)
+	}
+
+	timesheet := make([]TimesheetLine, 0, len(lines))
+	for key, line := range lines {
+		line.Minutes = math.Round(line.Minutes)
+		line.BilledMinutes = roundUpMinutes(int(line.Minutes), byID[key.project].RoundingMinutes)
+		line.Hours = float64(line.BilledMinutes) / 60
+		line.Amount = math.Round(line.Hours*line.Rate*100) / 100
+		timesheet = append(timesheet, *line)
+	}
+	sort.Slice(timesheet, func(i, j int) bool {
+		x, y := timesheet[i], timesheet[j]
+		if x.Client != y.Client {
+			return y.Client == "" || x.Client != "" && x.Client < y.Client
+		}
+		if x.Project != y.Project {
+			return y.Project == "" || x.Project != "" && x.Project < y.Project
+		}
+		return x.Date < y.Date
+	})
+	return timesheet, nil
+}
+
+// roundUpMinutes rounds minutes up to a multiple of increment; 0 leaves them as they are
+func roundUpMinutes(minutes, increment int) int {
+	if increment <= 0 {
+		return minutes
+	}
+	return (minutes + increment - 1) / increment * increment
+}
+
+func timesheetExportDir() (string, error) {
+	dir, err := snaplogDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "exports", "timesheets"), nil
+}
+
+// ExportTimesheet writes a month's timesheet (2006-01, or the current month when empty)
+// as timesheet-2006-01.csv and timesheet-2006-01.pdf, grouped by client, project and day
+// with subtotals in the PDF. An empty dir uses exports/timesheets in the SnapLog folder.
+func (a *App) ExportTimesheet(month, dir string) (*TimesheetReport, error) {
+	if strings.TrimSpace(month) == "" {
+		month = a.today().Format("2006-01")
+	}
+	lines, err := a.GetTimesheet(month)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		if dir, err = timesheetExportDir(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+
+	month = strings.TrimSpace(month)
+	report := &TimesheetReport{Month: month, Lines: len(lines),
+		CSVPath: filepath.Join(dir, "timesheet-"+month+".csv"),
+		PDFPath: filepath.Join(dir, "timesheet-"+month+".pdf")}
+	for _, line := range lines {
+		report.Hours += line.Hours
+		report.Amount += line.Amount
+	}
+
+	if err := writeTimesheetCSV(report.CSVPath, lines); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(report.PDFPath, a.timesheetPDF(month, lines, report), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write timesheet PDF: %v", err)
+	}
+
+	a.logf("Exported the %s timesheet: %.2f hours in %d lines to %s\n", month, report.Hours, report.Lines, dir)
+	return report, nil
+}
+
+// writeTimesheetCSV writes one row per client, project and day
+func writeTimesheetCSV(path string, lines []TimesheetLine) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create timesheet CSV: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"client", "project", "date", "minutes", "billed_minutes", "hours", "rate", "amount"})
+	for _, line := range lines {
+		writer.Write([]string{line.Client, line.Project, line.Date,
+			fmt.Sprintf("%.0f", line.Minutes), fmt.Sprint(line.BilledMinutes), fmt.Sprintf("%.2f", line.Hours),
+			fmt.Sprintf("%.2f", line.Rate), fmt.Sprintf("%.2f", line.Amount)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write timesheet CSV: %v", err)
+	}
+	return nil
+}
+
+// timesheetPDF lays the timesheet out for an invoice: a section per client, a table of
+// days per project with its subtotal, and the month's total
+func (a *App) timesheetPDF(month string, lines []TimesheetLine, report *TimesheetReport) []byte {
+	currency := strings.TrimSpace(a.settings.TimesheetCurrency)
+	money := func(amount float64) string {
+		if currency == "" {
+			return fmt.Sprintf("%.2f", amount)
+		}
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	}
+	title := month
+	if first, err := time.Parse("2006-01", month); err == nil {
+		title = first.Format("January 2006")
+	}
+	const (
+		dateX   = pdfMargin + 12
+		hoursX  = 380.0
+		rateX   = 460.0
+		amountX = pdfPageWidth - pdfMargin
+	)
+
+	pdf := a.pdfDisplay(newPDFWriter())
+	pdf.row(18, true, pdfCell{x: pdfMargin, text: "Timesheet " + title})
+	pdf.space(8)
+	if len(lines) == 0 {
+		pdf.row(10, false, pdfCell{x: pdfMargin, text: "No timers ran this month."})
+	}
+
+	for start := 0; start < len(lines); {
+		client := lines[start].Client
+		name := client
+		if name == "" {
+			name = "No client"
+		}
+		pdf.space(10)
+		pdf.row(14, true, pdfCell{x: pdfMargin, text: name})
+
+		for start < len(lines) && lines[start].Client == client {
+			project := lines[start].Project
+			name := project
+			if name == "" {
+				name = "No project"
+			}
+			pdf.space(4)
+			pdf.row(11, true, pdfCell{x: pdfMargin, text: name},
+				pdfCell{x: hoursX, text: "Hours", right: true}, pdfCell{x: rateX, text: "Rate", right: true},
+				pdfCell{x: amountX, text: "Amount", right: true})
+			pdf.rule()
+
+			var hours, amount float64
+			for ; start < len(lines) && lines[start].Client == client && lines[start].Project == project; start++ {
+				line := lines[start]
+				pdf.row(10, false, pdfCell{x: dateX, text: line.Date},
+					pdfCell{x: hoursX, text: fmt.Sprintf("%.2f", line.Hours), right: true},
+					pdfCell{x: rateX, text: fmt.Sprintf("%.2f", line.Rate), right: true},
+					pdfCell{x: amountX, text: money(line.Amount), right: true})
+				hours += line.Hours
+				amount += line.Amount
+			}
+			pdf.rule()
+			pdf.row(10, true, pdfCell{x: dateX, text: "Subtotal"},
+				pdfCell{x: hoursX, text: fmt.Sprintf("%.2f", hours), right: true},
+				pdfCell{x: amountX, text: money(amount), right: true})
+		}
+	}
+
+	pdf.space(16)
+	pdf.row(12, true, pdfCell{x: pdfMargin, text: "Total"},
+		pdfCell{x: hoursX, text: fmt.Sprintf("%.2f", report.Hours), right: true},
+		pdfCell{x: amountX, text: money(report.Amount), right: true})
+	return pdf.bytes()
+}
+
+// runTimesheetCommand exports the timesheet of the month in args, or of this month
+func (a *App) runTimesheetCommand(args string) error {
+	report, err := a.ExportTimesheet(args, "")
+	if err != nil {
+		return err
+	}
+	a.notify("SnapLog timesheet", fmt.Sprintf("%s: %.2f hours", report.Month, report.Hours), priorityNormal)
+	return a.openInBrowser(report.PDFPath)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	topicsPerPeriod = 10
+	// n-grams seen fewer times than this in a period are ignored
+	minTopicCount = 2
+)
+
+var (
+	topicWordPattern = regexp.MustCompile(`[\p{L}][\p{L}\p{N}'-]*`)
+	topicURLPattern  = regexp.MustCompile(`https?://\S+`)
+)
+
+// topicStopwords are common English words left out of n-grams
+var topicStopwords = makeStopwords(`a about above after again against all also am an and any are as at be
+because been before being below between both but by can could did do does doing done down during each
+few for from further get got had has have having he her here hers herself him himself his how i if in
+into is it its itself just let me more most my myself no nor not now of off on once only or other our
+ours ourselves out over own same she should so some still such than that the their theirs them
+themselves then there these they this those through to too under until up very was we were what when
+where which while who whom why will with would you your yours yourself yourselves today yesterday
+tomorrow one two really need needs want going it's i'm don't didn't can't won't`)
+
+func makeStopwords(list string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		words[word] = true
+	}
+	return words
+}
+
+// TopicCount is an n-gram with the number of times it appeared in a period
+type TopicCount struct {
+	Term  string
+	Count int
+}
+
+// TopicPeriod is the top n-grams of one month or quarter, with how they moved
+// compared to the previous period
+type TopicPeriod struct {
+	Label   string
+	Entries int
+	Top     []TopicCount
+	Rising  []string
+	Fading  []string
+}
+
+// TopicsPageData holds everything rendered on /dash/topics
+type TopicsPageData struct {
+	By      string
+	Periods []TopicPeriod
+}
+
+// topicTerms returns the unigrams and bigrams of text, skipping stopwords, numbers and URLs.
+// Bigrams never span a stopword, so "call with bank" does not yield "call bank".
+func topicTerms(text string) []string {
+	text = topicURLPattern.ReplaceAllString(strings.ToLower(text), " ")
+
+	var terms []string
+	previous := ""
+	for _, line := range strings.Split(text, "\n") {
+		previous = ""
+		for _, loc := range topicWordPattern.FindAllStringIndex(line, -1) {
+			word := strings.Trim(line[loc[0]:loc[1]], "'-")
+			// a word right after a tag marker is a tag, counted as its own term
+			isTag := loc[0] > 0 && line[loc[0]-1] == '#'
+			if len(word) < 3 || topicStopwords[word] {
+				previous = ""
+				continue
+			}
+			if isTag {
+				terms = append(terms, "#"+word)
+				previous = ""
+				continue
+			}
+			terms = append(terms, word)
+			if previous != "" {
+				terms = append(terms, previous+" "+word)
+			}
+			previous = word
+		}
+	}
+	return terms
+}
+
+func topicPeriodLabel(t time.Time, by string) string {
+	if by == "quarter" {
+		return fmt.Sprintf("%d Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	}
+	return t.Format("2006-01")
+}
+
+// GetTopicDrift counts n-grams per month (or quarter) over the last months, newest first
+func (a *App) GetTopicDrift(months int, by string) ([]TopicPeriod, error) {
+	now := a.clock.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -(months - 1), 0)
+	entries, err := a.entriesBetween(from, now.Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	counts := make(map[string]map[string]int)
+	entryCounts := make(map[string]int)
+	for _, entry := range entries {
+		label := topicPeriodLabel(entry.CreatedAt.Local(), by)
+		if counts[label] == nil {
+			counts[label] = make(map[string]int)
+			labels = append(labels, label)
+		}
+		entryCounts[label]++
+		// count each term once per entry so a single long note cannot dominate a month
+		seen := make(map[string]bool)
+		for _, term := range topicTerms(entry.Content) {
+			if !seen[term] {
+				seen[term] = true
+				counts[label][term]++
+			}
+		}
+	}
+
+	periods := make([]TopicPeriod, 0, len(labels))
+	var previousTop map[string]bool
+	for _, label := range labels {
+		period := TopicPeriod{Label: label, Entries: entryCounts[label], Top: topTopics(counts[label], topicsPerPeriod)}
+
+		top := make(map[string]bool, len(period.Top))
+		for _, topic := range period.Top {
+			top[topic.Term] = true
+			if previousTop != nil && !previousTop[topic.Term] {
+				period.Rising = append(period.Rising, topic.Term)
+			}
+		}
+		for term := range previousTop {
+			if !top[term] {
+				period.Fading = append(period.Fading, term)
+			}
+		}
+		sort.Strings(period.Fading)
+
+		periods = append(periods, period)
+		previousTop = top
+	}
+
+	for i, j := 0, len(periods)-1; i < j; i, j = i+1, j-1 {
+		periods[i], periods[j] = periods[j], periods[i]
+	}
+	return periods, nil
+}
+
+// topTopics returns the most frequent terms, preferring a bigram over the words it
+// contains when they occur about as often
+func topTopics(counts map[string]int, limit int) []TopicCount {
+	var topics []TopicCount
+	for term, count := range counts {
+		if count >= minTopicCount {
+			topics = append(topics, TopicCount{Term: term, Count: count})
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].Count != topics[j].Count {
+			return topics[i].Count > topics[j].Count
+		}
+		// bigrams first on ties, then alphabetical
+		if si, sj := strings.Contains(topics[i].Term, " "), strings.Contains(topics[j].Term, " "); si != sj {
+			return si
+		}
+		return topics[i].Term < topics[j].Term
+	})
+
+	var result []TopicCount
+	covered := make(map[string]int)
+	for _, topic := range topics {
+		if len(result) == limit {
+			break
+		}
+		if bigram, ok := covered[topic.Term]; ok && topic.Count <= bigram+bigram/4 {
+			continue
+		}
+		result = append(result, topic)
+		if words := strings.Fields(topic.Term); len(words) == 2 {
+			covered[words[0]] = topic.Count
+			covered[words[1]] = topic.Count
+		}
+	}
+	return result
+}
+
+func (a *App) serveTopicsPage(w http.ResponseWriter, r *http.Request) {
+	by, months := "month", 12
+	if r.URL.Query().Get("by") == "quarter" {
+		by, months = "quarter", 24
+	}
+
+	periods, err := a.GetTopicDrift(months, by)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to analyse topics: %v", err), http.StatusInternalServerError)
+		a.logf("Error analysing topics: %v\n", err)
+		return
+	}
+
+	a.renderPage(w, "topics.html", "Topics", TopicsPageData{By: by, Periods: periods})
+}
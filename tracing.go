@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSlowQueryThreshold = 100 * time.Millisecond
+	slowRequestThreshold      = time.Second
+	maxSlowOperations         = 50
+)
+
+type traceContextKey int
+
+const (
+	requestIDKey traceContextKey = iota
+	untracedKey
+)
+
+// SlowOperation is a query or dashboard request that took longer than its threshold
+type SlowOperation struct {
+	Kind       string    `json:"kind"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Operation  string    `json:"operation"`
+	DurationMs float64   `json:"duration_ms"`
+	Plan       []string  `json:"plan,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// slowOperationLog keeps the most recent slow operations for the diagnostics API
+type slowOperationLog struct {
+	mu  sync.Mutex
+	ops []SlowOperation
+}
+
+func (l *slowOperationLog) add(op SlowOperation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ops = append(l.ops, op)
+	if len(l.ops) > maxSlowOperations {
+		l.ops = l.ops[len(l.ops)-maxSlowOperations:]
+	}
+}
+
+// slowQueryThreshold returns the slow query threshold. Zero uses the default, negative disables logging.
+func (a *App) slowQueryThreshold() time.Duration {
+	ms := a.settings.SlowQueryMs
+	if ms == 0 {
+		return defaultSlowQueryThreshold
+	}
+	if ms < 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// requestID returns the ID of the dashboard request ctx belongs to, if any
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// traceRequests gives every dashboard request an ID, taken from X-Request-ID when the
+// client sends a sensible one. Handlers pass r.Context() on to queries so slow queries
+// can be matched to the request that ran them.
+func (a *App) traceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get("X-Request-ID"))
+		if id == "" || len(id) > 64 || strings.ContainsAny(id, "\r\n") {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+
+		if elapsed := time.Since(start); elapsed >= slowRequestThreshold {
+			operation := r.Method + " " + r.URL.Path
+			a.logf("Slow request %s took %v (request %s)\n", operation, elapsed.Round(time.Millisecond), id)
+			a.slowOps.add(SlowOperation{
+				Kind:       "request",
+				RequestID:  id,
+				Operation:  operation,
+				DurationMs: float64(elapsed.Microseconds()) / 1000,
+				At:         start,
+			})
+		}
+	})
+}
+
+// sqliteDriverConn is what the modernc driver's connections implement
+type sqliteDriverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	sqliteConn
+	serializingConn
+}
+
+// tracedConnector opens driver connections that time every statement
+type tracedConnector struct {
+	app    *App
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := conn.(sqliteDriverConn)
+	if !ok {
+		return conn, nil
+	}
+	return &tracedConn{sqliteDriverConn: sc, app: c.app}, nil
+}
+
+func (c *tracedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type tracedConn struct {
+	sqliteDriverConn
+	app *App
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	result, err := c.sqliteDriverConn.ExecContext(ctx, query, args)
+	c.app.traceQuery(ctx, query, args, start, err)
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.sqliteDriverConn.QueryContext(ctx, query, args)
+	if err != nil {
+		c.app.traceQuery(ctx, query, args, start, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, finish: func() { c.app.traceQuery(ctx, query, args, start, nil) }}, nil
+}
+
+// tracedRows counts the time spent reading rows as part of the query
+type tracedRows struct {
+	driver.Rows
+	finish func()
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.finish()
+	return err
+}
+
+// openDatabase opens the SQLite database at path with slow query tracing
+func (a *App) openDatabase(path string) (*sql.DB, error) {
+	probe, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+	return sql.OpenDB(&tracedConnector{app: a, dsn: path, driver: drv}), nil
+}
+
+// traceQuery records a statement that ran for longer than the slow query threshold. Its
+// plan is looked up afterwards on another connection, so the caller is not held up.
+func (a *App) traceQuery(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	threshold := a.slowQueryThreshold()
+	elapsed := time.Since(start)
+	if threshold <= 0 || elapsed < threshold || ctx.Value(untracedKey) != nil {
+		return
+	}
+
+	op := SlowOperation{
+		Kind:       "query",
+		RequestID:  requestID(ctx),
+		Operation:  strings.Join(strings.Fields(query), " "),
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+		At:         start,
+	}
+	if err != nil {
+		op.Error = err.Error()
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+		if arg.Name != "" {
+			values[i] = sql.Named(arg.Name, arg.Value)
+		}
+	}
+
+	go func() {
+		op.Plan = a.queryPlan(op.Operation, values)
+		request := ""
+		if op.RequestID != "" {
+			request = ", request " + op.RequestID
+		}
+		a.logf("Slow query (%v%s): %s\n", elapsed.Round(time.Millisecond), request, op.Operation)
+		for _, step := range op.Plan {
+			a.logf("  plan: %s\n", step)
+		}
+		a.slowOps.add(op)
+	}()
+}
+
+// queryPlan returns SQLite's EXPLAIN QUERY PLAN for a single data statement
+func (a *App) queryPlan(query string, args []interface{}) []string {
+	if a.db == nil || strings.Contains(strings.TrimSuffix(strings.TrimSpace(query), ";"), ";") {
+		return nil
+	}
+	verb := strings.ToUpper(strings.SplitN(query, " ", 2)[0])
+	switch verb {
+	case "SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "REPLACE":
+	default:
+		return nil
+	}
+
+	ctx := context.WithValue(context.Background(), untracedKey, true)
+	rows, err := a.db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return []string{"unavailable: " + err.Error()}
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			break
+		}
+		plan = append(plan, detail)
+	}
+	return plan
+}
+
+// GetSlowOperations returns the most recent slow queries and requests, newest first
+func (a *App) GetSlowOperations(limit int) []SlowOperation {
+	a.slowOps.mu.Lock()
+	defer a.slowOps.mu.Unlock()
+
+	if limit <= 0 || limit > len(a.slowOps.ops) {
+		limit = len(a.slowOps.ops)
+	}
+	ops := make([]SlowOperation, 0, limit)
+	for i := len(a.slowOps.ops) - 1; i >= 0 && len(ops) < limit; i-- {
+		ops = append(ops, a.slowOps.ops[i])
+	}
+	return ops
+}
+
+// handleSlowOperationsAPI serves GET /api/diagnostics/slow?limit=20
+func (a *App) handleSlowOperationsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "limit must be a number")
+			return
+		}
+		limit = n
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"threshold_ms": a.slowQueryThreshold().Milliseconds(),
+		"operations":   a.GetSlowOperations(limit),
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryWritesCarryTheRequestID(t *testing.T) {
+	a, _, _ := newFakeApp(t, time.Date(2026, time.March, 2, 9, 0, 0, 0, time.Local))
+	a.settings.SlowQueryMs = 1
+	// every write to an entry counts far past the threshold
+	for _, event := range []string{"INSERT", "UPDATE"} {
+		if _, err := a.db.Exec(`CREATE TRIGGER slow_entry_` + strings.ToLower(event) + ` AFTER ` + event + ` ON log_entries BEGIN
+			SELECT COUNT(*) FROM (WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 300000) SELECT x FROM n);
+		END`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/entries", a.handleEntriesAPI)
+	mux.HandleFunc("/api/entries/", a.handleEntryAPI)
+	server := a.traceRequests(mux)
+
+	tests := []struct {
+		method, path, body string
+		requestID          string
+		statement          string
+	}{
+		{http.MethodPost, "/api/entries", `{"content": "Shipped #release"}`, "create-1", "INSERT INTO log_entries"},
+		{http.MethodPatch, "/api/entries/1", `{"content": "Shipped #release to staging"}`, "update-1", "UPDATE log_entries SET content"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("X-Request-ID", tt.requestID)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", tt.method, tt.path, rec.Code, rec.Body)
+		}
+
+		// slow queries are recorded once their plan has been looked up
+		deadline := time.Now().Add(5 * time.Second)
+		for !slowQueryFrom(a, tt.requestID, tt.statement) {
+			if time.Now().After(deadline) {
+				t.Fatalf("no slow %q query recorded for request %s: %+v", tt.statement, tt.requestID, a.GetSlowOperations(0))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// slowQueryFrom reports whether a slow query starting with statement was recorded for requestID
+func slowQueryFrom(a *App, requestID, statement string) bool {
+	for _, op := range a.GetSlowOperations(0) {
+		if op.Kind == "query" && op.RequestID == requestID && strings.HasPrefix(op.Operation, statement) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrashedEntry is an entry deleted with DeleteEntry, kept until it is restored or the
+// trash is emptied
+type TrashedEntry struct {
+	ID        int       `json:"id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Source    string    `json:"source"`
+	EntryType string    `json:"entry_type"`
+}
+
+// TrashPageData holds everything rendered on /dash/trash
+type TrashPageData struct {
+	Entries      []TrashPageEntry
+	ConfirmPurge bool
+}
+
+// TrashPageEntry is a trashed entry rendered for the trash page
+type TrashPageEntry struct {
+	DisplayEntry
+	DeletedAt string
+}
+
+// GetTrashedEntries returns the entries in the trash, most recently deleted first
+func (a *App) GetTrashedEntries() ([]TrashedEntry, error) {
+	if a.db == nil {
+		return nil, errDatabaseUnavailable()
+	}
+
+	rows, err := a.db.Query(`SELECT id, content, created_at, deleted_at, source, entry_type FROM log_entries
+		WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []TrashedEntry{}
+	for rows.Next() {
+		var entry TrashedEntry
+		if err := rows.Scan(&entry.ID, &entry.Content, &entry.CreatedAt, &entry.DeletedAt, &entry.Source, &entry.EntryType); err != nil {
+			return nil, fmt.Errorf("failed to scan trashed entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// RestoreEntry takes an entry out of the trash and derives its tags, tasks and people
+// again
+func (a *App) RestoreEntry(id int) error {
+	return a.restoreEntry(context.Background(), id)
+}
+
+// restoreEntry is RestoreEntry with ctx carrying the request ID for tracing
+func (a *App) restoreEntry(ctx context.Context, id int) error {
+	if a.db == nil {
+		return errDatabaseUnavailable()
+	}
+
+	var content string
+	err := a.db.QueryRowContext(ctx, `SELECT content FROM log_entries WHERE id = ? AND deleted_at IS NOT NULL`, id).Scan(&content)
+	if err == sql.ErrNoRows {
+		return newAPIError(codeNotFound, fmt.Sprintf("entry %d is not in the trash", id))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query trashed entry: %v", err)
+	}
+
+	if _, err := a.db.ExecContext(ctx, `UPDATE log_entries SET deleted_at = NULL WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to restore entry: %v", err)
+	}
+	if err := a.processTags(ctx, int64(id), content); err != nil {
+		a.logf("Warning: failed to restore tags for entry %d: %v\n", id, err)
+	}
+	if err := a.syncTasks(ctx, int64(id), content); err != nil {
+		a.logf("Warning: failed to restore tasks for entry %d: %v\n", id, err)
+	}
+	if err := a.updateEntryMetadata(ctx, int64(id), content); err != nil {
+		a.logf("Warning: failed to restore metadata for entry %d: %v\n", id, err)
+	}
+
+	a.logf("Entry %d restored from the trash\n", id)
+	return nil
+}
+
+// PurgeTrash deletes the entries in the trash and everything attached to them for good and
+// returns how many entries were deleted
+func (a *App) PurgeTrash() (int, error) {
+	if a.db == nil {
+		return 0, errDatabaseUnavailable()
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin purge: %v", err)
+	}
+	defer tx.Rollback()
+
+	// foreign keys aren't enforced, so nothing cascades from log_entries
+	if _, err := tx.Exec(`UPDATE timers SET task_id = NULL WHERE task_id IN (SELECT id FROM tasks
+		WHERE log_entry_id IN (SELECT id FROM log_entries WHERE deleted_at IS NOT NULL))`); err != nil {
+		return 0, fmt.Errorf("failed to detach timers from trashed tasks: %v", err)
+	}
+	for _, table := range []string{"entry_history", "meetings", "timers", "day_plans", "tasks", "log_entries_tags", "log_entries_tag_spans", "log_entries_people"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table + `
+			WHERE log_entry_id IN (SELECT id FROM log_entries WHERE deleted_at IS NOT NULL)`); err != nil {
+			return 0, fmt.Errorf("failed to delete %s of trashed entries: %v", table, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM capture_drafts
+		WHERE entry_id IN (SELECT id FROM log_entries WHERE deleted_at IS NOT NULL)`); err != nil {
+		return 0, fmt.Errorf("failed to delete drafts of trashed entries: %v", err)
+	}
+	result, err := tx.Exec(`DELETE FROM log_entries WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty the trash: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %v", err)
+	}
+
+	purged, _ := result.RowsAffected()
+	if err := a.removeEntryAttachments(); err != nil {
+		a.logf("Warning: failed to remove attachments of purged entries: %v\n", err)
+	}
+	a.logf("Emptied the trash: %d entries deleted\n", purged)
+	return int(purged), nil
+}
+
+func (a *App) serveTrashPage(w http.ResponseWriter, r *http.Request) {
+	trashed, err := a.GetTrashedEntries()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get trash: %v", err), http.StatusInternalServerError)
+		a.logf("Error getting trash: %v\n", err)
+		return
+	}
+
+	data := TrashPageData{ConfirmPurge: a.requiresConfirmation(actionPurgeTrash)}
+	for _, entry := range trashed {
+		display := a.toDisplayEntry(LogEntry{ID: entry.ID, Content: entry.Content, CreatedAt: entry.CreatedAt,
+			Source: entry.Source, EntryType: entry.EntryType})
+		data.Entries = append(data.Entries, TrashPageEntry{DisplayEntry: display, DeletedAt: entry.DeletedAt.Local().Format("2006-01-02 15:04")})
+	}
+	a.renderPage(w, "trash.html", "Trash", data)
+}
+
+// handleTrashAPI serves GET /api/trash, POST /api/trash/{id}/restore and DELETE /api/trash,
+// which empties the trash
+func (a *App) handleTrashAPI(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/trash"), "/")
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		entries, err := a.GetTrashedEntries()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/restore"):
+		id, err := strconv.Atoi(strings.TrimSuffix(path, "/restore"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "Invalid entry ID")
+			return
+		}
+		if err := a.restoreEntry(r.Context(), id); err != nil {
+			status := http.StatusInternalServerError
+			if asAPIError(err).Code == codeNotFound {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, asAPIError(err).Code, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	case r.Method == http.MethodDelete && path == "":
+		if !a.confirmedRequest(w, r, actionPurgeTrash) {
+			return
+		}
+		purged, err := a.PurgeTrash()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "purged": purged})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// analyticsViews are read-only shapes over the raw tables for the SQL console, exports
+// and external tools reading the database file. Their columns are documented in the
+// README; add columns at the end rather than renaming or removing them.
+var analyticsViews = []struct {
+	name string
+	sql  string
+}{
+	{
+		// one row per entry and tag; untagged entries have a NULL tag
+		name: "entries_with_tags",
+		sql: `SELECT e.id AS entry_id,
+			e.created_at AS created_at,
+			date(e.created_at, 'localtime') AS date,
+			e.content AS content,
+			e.source AS source,
+			e.entry_type AS entry_type,
+			t.name AS tag
+		FROM log_entries e
+		LEFT JOIN log_entries_tags lt ON lt.log_entry_id = e.id
+		LEFT JOIN tags t ON t.id = lt.tag_id
+		WHERE e.deleted_at IS NULL`,
+	},
+	{
+		name: "daily_counts",
+		sql: `SELECT date(created_at, 'localtime') AS date,
+			COUNT(*) AS entries,
+			SUM(CASE WHEN entry_type = 'task' THEN 1 ELSE 0 END) AS tasks,
+			SUM(CASE WHEN entry_type = 'marker' THEN 1 ELSE 0 END) AS markers
+		FROM log_entries
+		WHERE deleted_at IS NULL
+		GROUP BY date(created_at, 'localtime')`,
+	},
+	{
+		// minutes until the next entry on the same day, counted when no more than
+		// stretchGap apart, the same rule the pivot CSV export uses
+		name: "weekly_time_by_tag",
+		sql: fmt.Sprintf(`WITH spans AS (
+			SELECT id, created_at,
+				(julianday(LEAD(created_at) OVER (ORDER BY created_at, id)) - julianday(created_at)) * 1440 AS minutes,
+				date(LEAD(created_at) OVER (ORDER BY created_at, id), 'localtime') = date(created_at, 'localtime') AS same_day
+			FROM log_entries
+			WHERE deleted_at IS NULL
+		)
+		SELECT date(s.created_at, 'localtime', 'weekday 0', '-6 days') AS week_start,
+			COALESCE(t.name, '') AS tag,
+			ROUND(SUM(s.minutes), 1) AS minutes,
+			COUNT(*) AS entries
+		FROM spans s
+		LEFT JOIN log_entries_tags lt ON lt.log_entry_id = s.id
+		LEFT JOIN tags t ON t.id = lt.tag_id
+		WHERE s.same_day AND s.minutes <= %d
+		GROUP BY week_start, tag`, int(stretchGap.Minutes())),
+	},
+}
+
+// createAnalyticsViews recreates the analytics views on every start so their definitions
+// always match the running version, and when the day start hour changes
+func (a *App) createAnalyticsViews() error {
+	// dates follow the logical day, which may start after midnight
+	localDay := "'localtime'"
+	if h := a.dayStartHour(); h > 0 {
+		localDay = fmt.Sprintf("'localtime', '-%d hours'", h)
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin views migration: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, view := range analyticsViews {
+		if _, err := tx.Exec(`DROP VIEW IF EXISTS ` + view.name); err != nil {
+			return fmt.Errorf("failed to drop %s view: %v", view.name, err)
+		}
+		viewSQL := strings.ReplaceAll(strings.TrimSpace(view.sql), "'localtime'", localDay)
+		if _, err := tx.Exec(`CREATE VIEW ` + view.name + ` AS ` + viewSQL); err != nil {
+			return fmt.Errorf("failed to create %s view: %v", view.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit views migration: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// entries this close together belong to one uninterrupted stretch of work
+	stretchGap = 90 * time.Minute
+	// late night runs from lateNightStart until lateNightEnd, local time
+	lateNightStart = 22
+	lateNightEnd   = 5
+	// weeks at the end of the range compared against the earlier ones for warnings
+	workloadRecentWeeks = 2
+)
+
+var meetingTags = map[string]bool{"meeting": true, "meetings": true, "call": true, "1on1": true}
+
+// WeeklyWorkload summarizes when and how much was logged in one week (Monday start)
+type WeeklyWorkload struct {
+	WeekOf         string  `json:"week_of"`
+	Entries        int     `json:"entries"`
+	LateNightRatio float64 `json:"late_night_ratio"`
+	WeekendRatio   float64 `json:"weekend_ratio"`
+	MeetingRatio   float64 `json:"meeting_ratio"`
+	LongestStretch float64 `json:"longest_stretch_hours"`
+}
+
+// WorkloadStats holds weekly workload figures, newest first, and gentle trend warnings
+type WorkloadStats struct {
+	Weeks    []WeeklyWorkload `json:"weeks"`
+	Warnings []string         `json:"warnings"`
+}
+
+// GetWorkloadStats computes late-night, weekend and meeting ratios and the longest
+// uninterrupted stretch for each of the last weeks
+func (a *App) GetWorkloadStats(weeks int) (*WorkloadStats, error) {
+	thisMonday := a.dayStart(weekStart(a.today()))
+	from := thisMonday.AddDate(0, 0, -7*(weeks-1))
+
+	entries, err := a.entriesBetween(from, thisMonday.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &WorkloadStats{}
+	for i := 0; i < weeks; i++ {
+		start := thisMonday.AddDate(0, 0, -7*i)
+		var week []LogEntry
+		for _, entry := range entries {
+			local := entry.CreatedAt.Local()
+			if !local.Before(start) && local.Before(start.AddDate(0, 0, 7)) {
+				week = append(week, entry)
+			}
+		}
+		stats.Weeks = append(stats.Weeks, summarizeWeek(start, week))
+	}
+	stats.Warnings = workloadWarnings(stats.Weeks)
+	return stats, nil
+}
+
+func summarizeWeek(start time.Time, entries []LogEntry) WeeklyWorkload {
+	week := WeeklyWorkload{WeekOf: start.Format("2006-01-02"), Entries: len(entries)}
+	if len(entries) == 0 {
+		return week
+	}
+
+	var lateNight, weekend, meetings int
+	var stretchStart, previous time.Time
+	for i, entry := range entries {
+		local := entry.CreatedAt.Local()
+		if hour := local.Hour(); hour >= lateNightStart || hour < lateNightEnd {
+			lateNight++
+		}
+		if day := local.Weekday(); day == time.Saturday || day == time.Sunday {
+			weekend++
+		}
+		for _, tag := range extractTagNames(entry.Content) {
+			if meetingTags[strings.ToLower(tag)] {
+				meetings++
+				break
+			}
+		}
+
+		if i == 0 || local.Sub(previous) > stretchGap {
+			stretchStart = local
+		}
+		if hours := local.Sub(stretchStart).Hours(); hours > week.LongestStretch {
+			week.LongestStretch = hours
+		}
+		previous = local
+	}
+
+	total := float64(len(entries))
+	week.LateNightRatio = float64(lateNight) / total
+	week.WeekendRatio = float64(weekend) / total
+	week.MeetingRatio = float64(meetings) / total
+	return week
+}
+
+// workloadWarnings compares the most recent weeks with the ones before them
+func workloadWarnings(weeks []WeeklyWorkload) []string {
+	if len(weeks) <= workloadRecentWeeks {
+		return nil
+	}
+	recent := averageWorkload(weeks[:workloadRecentWeeks])
+	baseline := averageWorkload(weeks[workloadRecentWeeks:])
+	if recent.Entries == 0 {
+		return nil
+	}
+
+	var warnings []string
+	rising := func(now, before, floor float64) bool {
+		return now >= floor && now > before*1.5
+	}
+	if rising(recent.LateNightRatio, baseline.LateNightRatio, 0.1) {
+		warnings = append(warnings, fmt.Sprintf("%.0f%% of recent entries were logged late at night, up from %.0f%%. Maybe time to wind down earlier?",
+			recent.LateNightRatio*100, baseline.LateNightRatio*100))
+	}
+	if rising(recent.WeekendRatio, baseline.WeekendRatio, 0.15) {
+		warnings = append(warnings, fmt.Sprintf("Weekend logging is up to %.0f%% of entries (from %.0f%%). Weekends are for resting too.",
+			recent.WeekendRatio*100, baseline.WeekendRatio*100))
+	}
+	if rising(recent.MeetingRatio, baseline.MeetingRatio, 0.3) {
+		warnings = append(warnings, fmt.Sprintf("Meetings make up %.0f%% of recent entries (from %.0f%%). Consider protecting some focus time.",
+			recent.MeetingRatio*100, baseline.MeetingRatio*100))
+	}
+	if rising(recent.LongestStretch, baseline.LongestStretch, 8) {
+		warnings = append(warnings, fmt.Sprintf("Your longest recent stretch without a break was %.1f hours. Short breaks help.",
+			recent.LongestStretch))
+	}
+	return warnings
+}
+
+// averageWorkload averages the ratios over weeks with entries; LongestStretch is the maximum
+func averageWorkload(weeks []WeeklyWorkload) WeeklyWorkload {
+	var avg WeeklyWorkload
+	active := 0
+	for _, week := range weeks {
+		if week.Entries == 0 {
+			continue
+		}
+		active++
+		avg.Entries += week.Entries
+		avg.LateNightRatio += week.LateNightRatio
+		avg.WeekendRatio += week.WeekendRatio
+		avg.MeetingRatio += week.MeetingRatio
+		if week.LongestStretch > avg.LongestStretch {
+			avg.LongestStretch = week.LongestStretch
+		}
+	}
+	if active > 0 {
+		avg.LateNightRatio /= float64(active)
+		avg.WeekendRatio /= float64(active)
+		avg.MeetingRatio /= float64(active)
+	}
+	return avg
+}